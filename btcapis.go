@@ -1,14 +1,21 @@
 package btcapis
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/crazycloudcc/btcapis/internal/adapters/bitcoindrpc"
 	"github.com/crazycloudcc/btcapis/internal/adapters/electrumx"
+	"github.com/crazycloudcc/btcapis/internal/adapters/esplora"
 	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/internal/adapters/ordserver"
 	"github.com/crazycloudcc/btcapis/internal/address"
 	"github.com/crazycloudcc/btcapis/internal/chain"
+	"github.com/crazycloudcc/btcapis/internal/transport"
 	"github.com/crazycloudcc/btcapis/internal/tx"
+	"github.com/crazycloudcc/btcapis/internal/wallet"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -18,55 +25,194 @@ import (
  */
 
 type Config struct {
-	Network         string // 网络类型: mainnet, testnet, signet
-	Timeout         int    // 超时时间（秒）
-	RPCUrl          string // Bitcoin Core RPC服务器地址
-	RPCUser         string // Bitcoin Core RPC用户名
-	RPCPass         string // Bitcoin Core RPC密码
-	MempoolSpaceUrl string // mempool.space API地址
-	ElectrumXUrl    string // ElectrumX服务器地址
+	Network         string       // 网络类型: mainnet, testnet, signet
+	Timeout         int          // 超时时间（秒）
+	RPCUrl          string       // Bitcoin Core RPC服务器地址
+	RPCUser         string       // Bitcoin Core RPC用户名
+	RPCPass         string       // Bitcoin Core RPC密码
+	RPCOptions      *RPCOptions  // 可选, cookie 认证/TLS/代理/请求头等连接选项; User/Pass/Timeout 为空时使用上面的配置
+	RPCUseREST      bool         // 可选, 节点开启 -rest 时通过 REST 获取原始区块/交易, 失败时回退到 RPC
+	RPCWallet       string       // 可选, bitcoind 钱包名称, 钱包关注的地址从钱包查询余额/UTXO(适用于只读描述符钱包)
+	MempoolSpaceUrl string       // mempool.space API地址
+	EsploraUrl      string       // 可选, Esplora API地址(如 https://blockstream.info/api), 设置后替代 mempool.space 作为 REST 数据源
+	ElectrumXUrl    string       // ElectrumX服务器地址
+	OrdServerUrl    string       // 可选, ord server 地址, 用于识别带有铭文/rune 的 UTXO
+	RetryPolicy     *RetryPolicy // 可选, 各数据源的重试与熔断策略, 为空时使用 DefaultRetryPolicy(Timeout)
+
+	Cache       Cache        // 可选, 缓存确认数足够的原始交易/区块/区块头, 以及短时间内的手续费等数据
+	CachePolicy *CachePolicy // 可选, 为空时使用 DefaultCachePolicy()
+
+	Logger Logger // 可选, 库内日志输出(全局), 可直接使用 *slog.Logger; 为空时不输出日志
+
+	// 可选, 按数据源限流, key 为 Backend* 常量; 未设置时公共服务(mempool.space, blockstream.info)默认每秒 5 次, 其他不限流
+	RateLimits map[string]RateLimit
 }
 
-type Client struct {
-	// bitcoindrpcClient *bitcoindrpc.Client // bitcoindrpc接口调用集合.
-	// mempoolapisClient *mempoolapis.Client // mempool.space接口调用集合.
-	// electrumxClient   *electrumx.Client   // electrumx接口调用集合.
-	addressClient *address.Client // 钱包地址操作
-	txClient      *tx.Client      // 交易操作
-	chainClient   *chain.Client   // 链操作 - 无法归类到钱包和交易类的其他链上操作
+// 数据源名称, 用于 Config.RateLimits
+const (
+	BackendBitcoind     = "bitcoind"
+	BackendMempoolSpace = "mempool.space"
+	BackendEsplora      = "esplora"
+	BackendElectrumX    = "electrumx"
+	BackendOrdServer    = "ord"
+)
+
+// RateLimit 令牌桶限流: 每秒 Rate 个请求, 允许突发 Burst 个; Rate 为 0 时不限流.
+// 同一数据源的并发请求(含重试)共享额度, 超出时等待, 直到 ctx 结束.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RPCOptions bitcoind 连接选项
+type RPCOptions = bitcoindrpc.Options
+
+// RetryPolicy 重试与熔断策略: 只重试幂等请求(查询, 以及按 txid 幂等的广播), 遵循 Retry-After, 每个数据源独立熔断
+type RetryPolicy = transport.Policy
+
+// DefaultRetryPolicy 默认策略: 最多重试 3 次, 连续失败 5 次熔断 30 秒; attemptTimeout 为单次请求超时
+func DefaultRetryPolicy(attemptTimeout time.Duration) RetryPolicy {
+	return transport.DefaultPolicy(attemptTimeout)
 }
 
-var bitcoindrpcClient *bitcoindrpc.Client
-var mempoolapisClient *mempoolapis.Client
-var electrumxClient *electrumx.Client
+// GenerateRPCAuth 生成 bitcoin.conf 的 rpcauth 配置行, 客户端使用 user 与明文 pass 连接
+func GenerateRPCAuth(user, pass string) (string, error) {
+	return bitcoindrpc.GenerateRPCAuth(user, pass)
+}
 
+// Client 各数据源客户端按实例保存, 同一进程中可以创建多个配置不同的 Client
+type Client struct {
+	bitcoindrpcClient *bitcoindrpc.Client // bitcoindrpc接口调用集合.
+	mempoolapisClient *mempoolapis.Client // mempool.space接口调用集合.
+	electrumxClient   *electrumx.Client   // electrumx接口调用集合.
+	addressClient     *address.Client     // 钱包地址操作
+	txClient          *tx.Client          // 交易操作
+	chainClient       *chain.Client       // 链操作 - 无法归类到钱包和交易类的其他链上操作
+}
+
+// New 创建客户端, 配置错误时打印日志并返回 nil; 需要处理错误时使用 NewE
 func New(cfg *Config) *Client {
-	if cfg == nil {
-		fmt.Println("cfg == nil")
+	client, err := NewE(cfg)
+	if err != nil {
+		logger.Error("%v", err)
 		return nil
 	}
+	return client
+}
+
+// NewE 创建客户端, 配置错误时返回错误
+func NewE(cfg *Config) (*Client, error) {
+	if cfg == nil {
+		return nil, errors.New("btcapis: cfg == nil")
+	}
 
+	if cfg.Logger != nil {
+		logger.SetLogger(cfg.Logger)
+	}
 	types.SetCurrentNetwork(cfg.Network)
 
 	client := &Client{}
 
-	if cfg.RPCUrl != "" {
-		bitcoindrpcClient = bitcoindrpc.New(cfg.RPCUrl, cfg.RPCUser, cfg.RPCPass, cfg.Timeout)
+	if cfg.RPCUrl != "" && cfg.RPCOptions != nil {
+		opts := *cfg.RPCOptions
+		if opts.User == "" && opts.CookieFile == "" {
+			opts.User, opts.Pass = cfg.RPCUser, cfg.RPCPass
+		}
+		if opts.Timeout == 0 {
+			opts.Timeout = time.Duration(cfg.Timeout) * time.Second
+		}
+		rpc, err := bitcoindrpc.NewWithOptions(cfg.RPCUrl, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("btcapis: bitcoind rpc options: %w", err)
+		}
+		client.bitcoindrpcClient = rpc
+	} else if cfg.RPCUrl != "" {
+		client.bitcoindrpcClient = bitcoindrpc.New(cfg.RPCUrl, cfg.RPCUser, cfg.RPCPass, cfg.Timeout)
+	}
+	if client.bitcoindrpcClient != nil {
+		if cfg.RPCUseREST {
+			client.bitcoindrpcClient.EnableREST()
+		}
 	}
 
 	if cfg.MempoolSpaceUrl != "" {
-		mempoolapisClient = mempoolapis.New(cfg.MempoolSpaceUrl, cfg.Timeout)
+		client.mempoolapisClient = mempoolapis.New(cfg.MempoolSpaceUrl, cfg.Timeout)
 	}
 
 	if cfg.ElectrumXUrl != "" {
-		electrumxClient = electrumx.New(cfg.ElectrumXUrl, cfg.Timeout)
+		client.electrumxClient = electrumx.New(cfg.ElectrumXUrl, cfg.Timeout)
 	}
 
-	client.addressClient = address.New(bitcoindrpcClient, mempoolapisClient, electrumxClient)
-	client.txClient = tx.New(bitcoindrpcClient, mempoolapisClient, electrumxClient, client.addressClient)
-	client.chainClient = chain.New(bitcoindrpcClient, mempoolapisClient)
+	backend := restBackend(client.mempoolapisClient)
+	if cfg.EsploraUrl != "" {
+		esploraClient := esplora.New(cfg.EsploraUrl, cfg.Timeout)
+		if cfg.RetryPolicy != nil {
+			esploraClient.SetRetryPolicy(*cfg.RetryPolicy)
+		}
+		if l, ok := cfg.RateLimits[BackendEsplora]; ok {
+			esploraClient.SetRateLimit(l.Rate, l.Burst)
+		}
+		backend = esploraClient
+	}
 
-	return client
+	if cfg.RetryPolicy != nil {
+		if client.bitcoindrpcClient != nil {
+			client.bitcoindrpcClient.SetRetryPolicy(*cfg.RetryPolicy)
+		}
+		if client.mempoolapisClient != nil {
+			client.mempoolapisClient.SetRetryPolicy(*cfg.RetryPolicy)
+		}
+		if client.electrumxClient != nil {
+			client.electrumxClient.SetRetryPolicy(*cfg.RetryPolicy)
+		}
+	}
+	if l, ok := cfg.RateLimits[BackendBitcoind]; ok && client.bitcoindrpcClient != nil {
+		client.bitcoindrpcClient.SetRateLimit(l.Rate, l.Burst)
+	}
+	if l, ok := cfg.RateLimits[BackendMempoolSpace]; ok && client.mempoolapisClient != nil {
+		client.mempoolapisClient.SetRateLimit(l.Rate, l.Burst)
+	}
+	if l, ok := cfg.RateLimits[BackendElectrumX]; ok && client.electrumxClient != nil {
+		client.electrumxClient.SetRateLimit(l.Rate, l.Burst)
+	}
+
+	if cfg.Cache != nil {
+		policy := DefaultCachePolicy()
+		if cfg.CachePolicy != nil {
+			policy = *cfg.CachePolicy
+		}
+		if client.bitcoindrpcClient != nil {
+			client.bitcoindrpcClient.SetCache(cfg.Cache, policy)
+		}
+		if backend != nil {
+			name := "mempool.space"
+			if cfg.EsploraUrl != "" {
+				name = "esplora"
+			}
+			backend = mempoolapis.NewCached(backend, name, cfg.Cache, policy)
+		}
+	}
+
+	client.addressClient = address.New(client.bitcoindrpcClient, backend, client.electrumxClient)
+	client.txClient = tx.New(client.bitcoindrpcClient, backend, client.electrumxClient, client.addressClient)
+	client.chainClient = chain.New(client.bitcoindrpcClient, backend)
+
+	if client.bitcoindrpcClient != nil && cfg.RPCWallet != "" {
+		client.addressClient.SetWallet(wallet.New(client.bitcoindrpcClient, cfg.RPCWallet))
+	}
+
+	if cfg.OrdServerUrl != "" {
+		ordClient := ordserver.New(cfg.OrdServerUrl, cfg.Timeout)
+		if cfg.RetryPolicy != nil {
+			ordClient.SetRetryPolicy(*cfg.RetryPolicy)
+		}
+		if l, ok := cfg.RateLimits[BackendOrdServer]; ok {
+			ordClient.SetRateLimit(l.Rate, l.Burst)
+		}
+		client.txClient.SetUTXOClassifier(tx.NewOrdServerClassifier(ordClient))
+	}
+
+	return client, nil
 }
 
 // NewWithElectrumX 创建包含ElectrumX支持的客户端
@@ -76,7 +222,7 @@ func NewWithElectrumX(network string, rpc_url, rpc_user, rpc_pass string, electr
 	client := &Client{}
 
 	if rpc_url != "" {
-		bitcoindrpcClient = bitcoindrpc.New(rpc_url, rpc_user, rpc_pass, timeout)
+		client.bitcoindrpcClient = bitcoindrpc.New(rpc_url, rpc_user, rpc_pass, timeout)
 	}
 
 	mempool_rpc_url := ""
@@ -89,24 +235,33 @@ func NewWithElectrumX(network string, rpc_url, rpc_user, rpc_pass string, electr
 	}
 
 	if mempool_rpc_url != "" {
-		mempoolapisClient = mempoolapis.New(mempool_rpc_url, timeout)
+		client.mempoolapisClient = mempoolapis.New(mempool_rpc_url, timeout)
 	}
 
 	if electrumx_url != "" {
-		electrumxClient = electrumx.New(electrumx_url, timeout)
+		client.electrumxClient = electrumx.New(electrumx_url, timeout)
 	}
 
-	client.addressClient = address.New(bitcoindrpcClient, mempoolapisClient, electrumxClient)
-	client.txClient = tx.New(bitcoindrpcClient, mempoolapisClient, electrumxClient, client.addressClient)
-	client.chainClient = chain.New(bitcoindrpcClient, mempoolapisClient)
+	backend := restBackend(client.mempoolapisClient)
+	client.addressClient = address.New(client.bitcoindrpcClient, backend, client.electrumxClient)
+	client.txClient = tx.New(client.bitcoindrpcClient, backend, client.electrumxClient, client.addressClient)
+	client.chainClient = chain.New(client.bitcoindrpcClient, backend)
 
 	return client
 }
 
+// mempool.space 作为 REST 数据源; 未配置时返回 nil 接口, 避免内部 nil 判断失效
+func restBackend(mempoolapisClient *mempoolapis.Client) mempoolapis.Backend {
+	if mempoolapisClient == nil {
+		return nil
+	}
+	return mempoolapisClient
+}
+
 func NewTestClient(client *Client) *TestClient {
 	return &TestClient{
-		bitcoindrpcClient: bitcoindrpcClient,
-		mempoolapisClient: mempoolapisClient,
-		electrumxClient:   electrumxClient,
+		bitcoindrpcClient: client.bitcoindrpcClient,
+		mempoolapisClient: client.mempoolapisClient,
+		electrumxClient:   client.electrumxClient,
 	}
 }
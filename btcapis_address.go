@@ -2,14 +2,14 @@ package btcapis
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
 // 上传钱包+publickey, 用于后续组装PSBT等数据, 后续需要在postgres创建映射;
 func (c *Client) ImportAddressAndPublickey(ctx context.Context, address string, publickey string) error {
-	fmt.Printf("!!! (unsupport) import address: %s, publickey: %s\n", address, publickey)
+	logger.Warn("!!! (unsupport) import address: %s, publickey: %s", address, publickey)
 	return nil
 }
 
@@ -52,3 +52,28 @@ func (c *Client) FilterAddressesWithBalanceWithElectrumX(ctx context.Context, ad
 func (c *Client) BatchGetBalancesWithElectrumX(ctx context.Context, addresses []string, concurrent int) ([]types.AddressBalanceInfo, error) {
 	return c.addressClient.BatchGetBalancesWithElectrumX(ctx, addresses, concurrent)
 }
+
+// GetAddressTxsChain 返回地址已确认交易历史, 每页 25 条; afterTxid 为上一页最后一个 txid, 为空时返回第一页.
+func (c *Client) GetAddressTxsChain(ctx context.Context, addr string, afterTxid string) ([]*types.TxDetail, error) {
+	return c.addressClient.GetAddressTxsChain(ctx, addr, afterTxid)
+}
+
+// GetAddressTxsMempool 返回地址未确认交易.
+func (c *Client) GetAddressTxsMempool(ctx context.Context, addr string) ([]*types.TxDetail, error) {
+	return c.addressClient.GetAddressTxsMempool(ctx, addr)
+}
+
+// GetScriptBalance 通过锁定脚本返回确认余额和未确认余额(需要配置 Esplora).
+func (c *Client) GetScriptBalance(ctx context.Context, pkScript []byte) (confirmed float64, mempool float64, err error) {
+	return c.addressClient.GetScriptBalance(ctx, pkScript)
+}
+
+// GetScriptUTXOs 通过锁定脚本返回UTXO(需要配置 Esplora).
+func (c *Client) GetScriptUTXOs(ctx context.Context, pkScript []byte) ([]types.TxUTXO, error) {
+	return c.addressClient.GetScriptUTXOs(ctx, pkScript)
+}
+
+// GetScriptTxsChain 通过锁定脚本返回已确认交易历史(需要配置 Esplora).
+func (c *Client) GetScriptTxsChain(ctx context.Context, pkScript []byte, afterTxid string) ([]*types.TxDetail, error) {
+	return c.addressClient.GetScriptTxsChain(ctx, pkScript, afterTxid)
+}
@@ -0,0 +1,34 @@
+package btcapis
+
+import (
+	"github.com/crazycloudcc/btcapis/internal/cache"
+)
+
+// Cache 链上数据缓存存储, 实现该接口即可接入其他存储(BoltDB, Badger 等)
+type Cache = cache.Cache
+
+// CachePolicy 缓存策略: 确认数要求, 已确认数据与变化数据(手续费等)的缓存时间
+type CachePolicy = cache.Policy
+
+// RedisCacheOptions Redis 缓存连接选项
+type RedisCacheOptions = cache.RedisOptions
+
+// DefaultCachePolicy 默认策略: 6 个确认后永久缓存, 手续费等变化数据缓存 30 秒
+func DefaultCachePolicy() CachePolicy {
+	return cache.DefaultPolicy()
+}
+
+// 内存 LRU 缓存, maxEntries/maxBytes 为 0 时不限制
+func NewMemoryCache(maxEntries, maxBytes int) Cache {
+	return cache.NewMemory(maxEntries, maxBytes)
+}
+
+// 本地文件缓存, 进程重启后仍然有效; 总大小超过 maxBytes 时删除过期与最久未使用的文件, 0 为不限制
+func NewDiskCache(dir string, maxBytes int64) (Cache, error) {
+	return cache.NewDisk(dir, maxBytes)
+}
+
+// Redis 缓存, 多个进程共享
+func NewRedisCache(opts RedisCacheOptions) Cache {
+	return cache.NewRedis(opts)
+}
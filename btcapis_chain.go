@@ -2,6 +2,10 @@ package btcapis
 
 import (
 	"context"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/bitcoindrpc"
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/types"
 )
 
 // EstimateFeeRate 估计手续费.
@@ -9,6 +13,46 @@ func (c *Client) EstimateFeeRate(ctx context.Context, targetBlocks int) (float64
 	return c.chainClient.EstimateFeeRate(ctx, targetBlocks)
 }
 
+// 按费率预估的内存池区块, 第一个为下一个区块
+func (c *Client) GetMempoolBlocks(ctx context.Context) ([]types.MempoolBlockFees, error) {
+	return c.chainClient.GetMempoolBlocks(ctx)
+}
+
+// 最近的 15 个区块; startHeight >= 0 时从该高度向下分页
+func (c *Client) GetRecentBlocks(ctx context.Context, startHeight int) ([]*types.ChainBlock, error) {
+	return c.chainClient.GetRecentBlocks(ctx, startHeight)
+}
+
+// 使用区块高度 查询区块哈希
+func (c *Client) GetBlockHash(ctx context.Context, height int) (string, error) {
+	return c.chainClient.GetBlockHash(ctx, height)
+}
+
+// 使用区块哈希 查询区块摘要
+func (c *Client) GetBlockInfo(ctx context.Context, hash string) (*types.ChainBlock, error) {
+	return c.chainClient.GetBlockInfo(ctx, hash)
+}
+
+// 使用区块哈希 查询区块头
+func (c *Client) GetBlockHeader(ctx context.Context, hash string) (*types.ChainBlock, error) {
+	return c.chainClient.GetBlockHeader(ctx, hash)
+}
+
+// 使用区块哈希 查询区块是否在主链上
+func (c *Client) GetBlockStatus(ctx context.Context, hash string) (*types.BlockStatus, error) {
+	return c.chainClient.GetBlockStatus(ctx, hash)
+}
+
+// 使用区块哈希 查询区块内全部 txid
+func (c *Client) GetBlockTxids(ctx context.Context, hash string) ([]string, error) {
+	return c.chainClient.GetBlockTxids(ctx, hash)
+}
+
+// 使用区块哈希 分页查询区块内的交易, 每页 25 条
+func (c *Client) GetBlockTxs(ctx context.Context, hash string, startIndex int) ([]*types.TxDetail, error) {
+	return c.chainClient.GetBlockTxs(ctx, hash, startIndex)
+}
+
 // // 查询 UTXO
 // func (c *Client) GetUTXO(ctx context.Context, hash [32]byte, index uint32) ([]byte, int64, error) {
 // 	return c.chainClient.GetUTXO(ctx, hash, index)
@@ -38,3 +82,35 @@ func (c *Client) EstimateFeeRate(ctx context.Context, targetBlocks int) (float64
 // func (c *Client) GetBlock(ctx context.Context, hash string) (*types.ChainBlock, error) {
 // 	return c.chainClient.GetBlock(ctx, hash)
 // }
+
+// MempoolWatcher mempool.space WebSocket 客户端
+type MempoolWatcher = mempoolapis.WSClient
+
+// 创建 mempool.space WebSocket 客户端: 订阅地址/交易后调用 Run 保持连接, 从 Events 读取进入内存池, 确认, 替换等事件.
+// 用于替代轮询 GetAddressBalance 的充值检测.
+func (c *Client) NewMempoolWatcher() (*MempoolWatcher, error) {
+	return c.chainClient.NewMempoolWatcher()
+}
+
+// 批量查询输出是否未花费(bitcoind REST getutxos, 需要节点开启 -rest), outpoints 格式为 txid:vout.
+// 返回结果与 outpoints 一一对应, 已花费或不存在时为 nil.
+func (c *Client) GetOutpointUTXOs(ctx context.Context, outpoints []string, includeMempool bool) ([]*types.TxUTXO, error) {
+	return c.chainClient.GetOutpointUTXOs(ctx, outpoints, includeMempool)
+}
+
+// NodeSubscriber bitcoind ZMQ 订阅者
+type NodeSubscriber = bitcoindrpc.ZMQSubscriber
+
+// bitcoind ZMQ 主题, 需要节点配置对应的 -zmqpub<topic>
+const (
+	ZMQTopicRawTx     = bitcoindrpc.ZMQTopicRawTx
+	ZMQTopicHashTx    = bitcoindrpc.ZMQTopicHashTx
+	ZMQTopicRawBlock  = bitcoindrpc.ZMQTopicRawBlock
+	ZMQTopicHashBlock = bitcoindrpc.ZMQTopicHashBlock
+	ZMQTopicSequence  = bitcoindrpc.ZMQTopicSequence
+)
+
+// 创建 bitcoind ZMQ 订阅者: Subscribe 添加端点与主题后调用 Run 保持连接, 从 Events 读取解码后的交易, 区块及内存池增删事件.
+func NewNodeSubscriber() *NodeSubscriber {
+	return bitcoindrpc.NewZMQSubscriber()
+}
@@ -0,0 +1,22 @@
+package btcapis
+
+import (
+	"github.com/crazycloudcc/btcapis/internal/lightclient"
+)
+
+// BIP157/158 轻客户端相关类型
+type (
+	LightClient       = lightclient.Client
+	LightFilterSource = lightclient.FilterSource
+)
+
+// 创建轻客户端, 使用 bitcoind 提供区块头, 过滤器与区块(需要开启 blockfilterindex).
+// startHeight 为钱包创建高度; 先调用 WatchAddresses 再 Sync, 之后按 GetAddressUTXOs/GetAddressBalance/GetAddressTxsChain 查询.
+func (c *Client) NewLightClient(startHeight int) *LightClient {
+	return lightclient.New(c.chainClient, startHeight)
+}
+
+// 使用自定义数据源(如 P2P 节点)创建轻客户端
+func NewLightClient(src LightFilterSource, startHeight int) *LightClient {
+	return lightclient.New(src, startHeight)
+}
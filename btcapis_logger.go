@@ -0,0 +1,19 @@
+package btcapis
+
+import (
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+)
+
+// Logger 库内日志接口, 与 log/slog 一致, *slog.Logger 可直接使用
+type Logger = logger.Logger
+
+// 设置库内日志输出(全局, 对所有 Client 生效), nil 为静默; 默认静默, 不向 stdout 输出.
+// 私钥(WIF/xprv), 助记词, 密码与 PSBT 在输出前会被屏蔽
+func SetLogger(l Logger) {
+	logger.SetLogger(l)
+}
+
+// 屏蔽 s 中的私钥(WIF/xprv), 助记词, 密码与 PSBT, 用于自行记录请求参数等场景
+func RedactLog(s string) string {
+	return logger.Redact(s)
+}
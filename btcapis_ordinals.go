@@ -0,0 +1,73 @@
+package btcapis
+
+import (
+	"context"
+
+	"github.com/crazycloudcc/btcapis/internal/ordinals"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 创建铭刻交易: 返回待钱包签名的 commit PSBT 与已签名的 reveal 交易(有父铭文时为 reveal PSBT).
+// 先广播 commit, 再广播 reveal; RecoveryKeyWIF 需要保存到 reveal 确认为止.
+func (c *Client) CreateInscription(ctx context.Context, params *types.InscribeParams) (*types.InscribeResult, error) {
+	return c.txClient.CreateInscription(ctx, params)
+}
+
+// 创建取回 commit 输出的交易(reveal 失败时使用), 返回已签名交易 hex
+func (c *Client) CreateInscriptionRecoveryTx(params *types.InscriptionRecoveryParams) (string, error) {
+	return c.txClient.CreateInscriptionRecoveryTx(params)
+}
+
+// 解析铭文中的 BRC-20 操作(只做格式校验), 不是合法的 BRC-20 铭文时返回错误
+func (c *Client) ParseBRC20(ins *types.Inscription) (*types.BRC20Operation, error) {
+	return ordinals.ParseBRC20(ins)
+}
+
+// BRC-20 索引器相关类型
+type (
+	BRC20Indexer     = ordinals.BRC20Indexer
+	BRC20State       = ordinals.BRC20State
+	BRC20BlockSource = ordinals.BRC20BlockSource
+)
+
+// 创建 BRC-20 索引器, 使用 bitcoind 作为区块来源(需要开启 txindex), 调用 Sync 逐块索引到最新高度
+func (c *Client) NewBRC20Indexer() *BRC20Indexer {
+	return ordinals.NewBRC20Indexer(c.chainClient, types.CurrentNetwork)
+}
+
+// 本地铭文索引, 可作为 NewLocalUTXOClassifier 的 InscriptionLocator
+type InscriptionIndex = ordinals.InscriptionIndex
+
+// 创建本地铭文索引, 使用 bitcoind 作为区块来源(需要开启 txindex), 调用 Sync 逐块索引到最新高度
+func (c *Client) NewInscriptionIndex() *InscriptionIndex {
+	return ordinals.NewInscriptionIndex(c.chainClient, types.CurrentNetwork)
+}
+
+// 区块新产生的第一个 sat(序数)
+func (c *Client) FirstSat(height uint64) uint64 {
+	return ordinals.FirstSat(height)
+}
+
+// sat 被挖出的高度与稀有度
+func (c *Client) SatInfo(sat uint64) (height uint64, rarity string, err error) {
+	if height, err = ordinals.SatHeight(sat); err != nil {
+		return 0, "", err
+	}
+	rarity, err = ordinals.Rarity(sat)
+	return height, rarity, err
+}
+
+// 按 FIFO 规则计算交易每个输出的 sat 区间, inputs 为各输入所花费 UTXO 的区间; 第二个返回值为手续费区间
+func (c *Client) TxSatRanges(tx *types.Tx, inputs [][]types.SatRange) ([][]types.SatRange, []types.SatRange, error) {
+	return ordinals.TxSatRanges(tx, inputs)
+}
+
+// sat 在 UTXO 区间中的偏移, 不在其中时返回 false
+func (c *Client) SatOffset(ranges []types.SatRange, sat uint64) (uint64, bool) {
+	return ordinals.SatOffset(ranges, sat)
+}
+
+// 创建拆分 UTXO 的交易, 使指定偏移处的 sat 位于独立输出的第 0 个位置
+func (c *Client) CreateSatSplit(ctx context.Context, params *types.SatSplitParams) (*types.SatSplitResult, error) {
+	return c.txClient.CreateSatSplit(ctx, params)
+}
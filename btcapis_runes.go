@@ -0,0 +1,63 @@
+package btcapis
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/crazycloudcc/btcapis/internal/runes"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 解析交易中的 runestone, 没有 runestone 输出时返回 nil
+func (c *Client) DecodeRunestone(tx *types.Tx) *types.Runestone {
+	return runes.DecipherTx(tx)
+}
+
+// 按交易输出脚本解析 runestone(需要传入全部输出, 输出数量影响合法性判断)
+func (c *Client) DecodeRunestoneFromScripts(pkScripts [][]byte) *types.Runestone {
+	return runes.Decipher(pkScripts)
+}
+
+// 把 runestone 编码为 OP_RETURN 输出脚本, 用于构建 etching/mint/transfer 交易
+func (c *Client) EncodeRunestone(rs *types.Runestone) ([]byte, error) {
+	return runes.Encipher(rs)
+}
+
+// Runes 索引器相关类型
+type (
+	RunesIndexer         = runes.Indexer
+	RunesStore           = runes.Store
+	RunesBlockSource     = runes.BlockSource
+	RunesEntry           = runes.RuneEntry
+	RunesState           = runes.RuneState
+	RunesOutpointBalance = runes.OutpointBalance
+	RunesBlockChanges    = runes.BlockChanges
+	RunesMemoryStore     = runes.MemoryStore
+	RunesPostgresStore   = runes.PostgresStore
+)
+
+// 创建内存存储
+func NewRunesMemoryStore() *RunesMemoryStore {
+	return runes.NewMemoryStore()
+}
+
+// 创建 PostgreSQL 存储, db 由调用方使用自己的驱动打开; 首次使用前调用 Migrate 建表
+func NewRunesPostgresStore(db *sql.DB) *RunesPostgresStore {
+	return runes.NewPostgresStore(db)
+}
+
+// 创建 Runes 索引器, 使用 bitcoind 作为区块来源, 调用 Sync 逐块索引到最新高度
+func (c *Client) NewRunesIndexer(store RunesStore) *RunesIndexer {
+	return runes.NewIndexer(c.chainClient, store, types.CurrentNetwork)
+}
+
+// 构建 rune 转账 PSBT: 选择持有该 rune 的 UTXO, 按接收方生成 edict, rune 找零由 pointer 指定.
+// 需要先通过 SetUTXOClassifier 设置 ord server 或本地索引分类器.
+func (c *Client) CreateRuneTransfer(ctx context.Context, params *types.RuneTransferParams) (*types.RuneTxResult, error) {
+	return c.txClient.CreateRuneTransfer(ctx, params)
+}
+
+// 构建 rune 公开铸造 PSBT, 构建前检查铸造窗口与次数上限
+func (c *Client) CreateRuneMint(ctx context.Context, params *types.RuneMintParams) (*types.RuneTxResult, error) {
+	return c.txClient.CreateRuneMint(ctx, params)
+}
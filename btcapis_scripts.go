@@ -2,6 +2,7 @@ package btcapis
 
 import (
 	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/internal/ordinals"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -37,10 +38,25 @@ func (c *Client) DecodePkScriptToAsmString(pkScript []byte) (ops []types.ScriptO
 
 // 解析一笔交易元数据 => 适用于外部直接输入交易元数据解析结构
 func (c *Client) DecodeRawTx(rawtx []byte) (*types.Tx, error) {
-	return decoders.DecodeRawTx(rawtx)
+	tx, err := decoders.DecodeRawTx(rawtx)
+	if err != nil {
+		return nil, err
+	}
+	ordinals.ParseTx(tx)
+	return tx, nil
 }
 
 // 解析一笔交易元数据 => 适用于外部直接输入交易元数据解析结构(十六进制字符串)
 func (c *Client) DecodeRawTxString(rawHex string) (*types.Tx, error) {
-	return decoders.DecodeRawTxString(rawHex)
+	tx, err := decoders.DecodeRawTxString(rawHex)
+	if err != nil {
+		return nil, err
+	}
+	ordinals.ParseTx(tx)
+	return tx, nil
+}
+
+// 解析交易中的全部铭文(ord 信封), 交易需要已经通过 DecodeRawTx 等接口解码
+func (c *Client) DecodeTxInscriptions(tx *types.Tx) []types.Inscription {
+	return ordinals.ParseTx(tx)
 }
@@ -0,0 +1,40 @@
+package btcapis
+
+import (
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+)
+
+// Metrics 指标钩子: 每个数据源/方法的请求耗时与错误分类, 以及缓存命中情况
+type Metrics = telemetry.Metrics
+
+// Tracer 链路追踪钩子, 每次数据源请求生成一个 span(属性 rpc.system=数据源, rpc.method=方法)
+type Tracer = telemetry.Tracer
+
+// Span 一次请求的 span
+type Span = telemetry.Span
+
+// SpanAttr span 属性
+type SpanAttr = telemetry.Attr
+
+// PrometheusMetrics 内置的 Metrics 实现, 输出 Prometheus 文本格式, 可直接作为 /metrics 的 http.Handler
+type PrometheusMetrics = telemetry.Prometheus
+
+// 设置指标钩子(全局, 对所有 Client 生效), nil 为关闭; 默认关闭, 没有额外开销
+func SetMetrics(m Metrics) {
+	telemetry.SetMetrics(m)
+}
+
+// 设置追踪钩子(全局, 对所有 Client 生效), nil 为关闭; 默认关闭, 没有额外开销
+func SetTracer(t Tracer) {
+	telemetry.SetTracer(t)
+}
+
+// 创建 Prometheus 指标收集器, buckets 为请求耗时直方图分桶(秒), 为空时使用默认分桶
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	return telemetry.NewPrometheus(buckets...)
+}
+
+// 错误分类(not_found, rejected, insufficient_funds, rate_limited, invalid_request, canceled, timeout, unavailable, other), 成功时为空
+func ErrorClass(err error) string {
+	return telemetry.ErrorClass(err)
+}
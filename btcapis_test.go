@@ -0,0 +1,49 @@
+package btcapis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 先后创建的两个客户端互不影响: 第二个客户端没有配置的数据源不会沿用第一个客户端的
+func TestNewESequentialClients(t *testing.T) {
+	ctx := context.Background()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprintf(w, "%064x", 1)
+	}))
+	defer srv.Close()
+
+	first, err := NewE(&Config{Network: "regtest", MempoolSpaceUrl: srv.URL + "/api", ElectrumXUrl: "127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewE(&Config{Network: "regtest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hash, err := first.GetBlockHash(ctx, 1); err != nil || hash != fmt.Sprintf("%064x", 1) {
+		t.Fatalf("first client = %q, %v", hash, err)
+	}
+	if _, err := second.GetBlockHash(ctx, 1); !errors.Is(err, types.ErrBackendUnavailable) {
+		t.Errorf("second client err = %v, want ErrBackendUnavailable", err)
+	}
+	if _, err := second.NodeWallet("w"); !errors.Is(err, types.ErrBackendUnavailable) {
+		t.Errorf("second client wallet err = %v, want ErrBackendUnavailable", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server hits = %d, want 1", n)
+	}
+	if tc := NewTestClient(second); tc.mempoolapisClient != nil || tc.electrumxClient != nil {
+		t.Errorf("second client inherited backends: %+v", tc)
+	}
+}
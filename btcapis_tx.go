@@ -2,9 +2,12 @@ package btcapis
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/crazycloudcc/btcapis/internal/adapters/ordserver"
 	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/internal/ordinals"
+	"github.com/crazycloudcc/btcapis/internal/tx"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -23,19 +26,20 @@ func (c *Client) GetTx(ctx context.Context, txid string) (*types.Tx, error) {
 	if err != nil {
 		return nil, err
 	}
+	ordinals.ParseTx(ret)
 
 	return ret, err
 }
 
 // 创建PSBT预览交易数据(钱包未签名状态)
 func (c *Client) CreatePSBT(ctx context.Context, inputParams *types.TxInputParams) (string, error) {
-	fmt.Printf("create psbt: %+v\n", inputParams)
+	logger.Debug("create psbt: %+v", inputParams)
 	return c.txClient.CreateTxUsePSBTv0(ctx, inputParams)
 }
 
 // 上传经过钱包签名的PSBT数据并进行广播;
 func (c *Client) FinalizePSBTAndBroadcast(ctx context.Context, psbt string) (string, error) {
-	fmt.Printf("FinalizePSBTAndBroadcast: %s\n", psbt)
+	logger.Debug("FinalizePSBTAndBroadcast: psbt len %d", len(psbt))
 	rawTx, err := c.txClient.FinalizePSBT(ctx, psbt)
 	if err != nil {
 		return "", err
@@ -64,3 +68,81 @@ func (c *Client) ValidateSignedPsbtBase64(ctx context.Context, psbtBase64 string
 func (c *Client) TransferAllToNewAddress(ctx context.Context, toAddress string, privateKeyWIF string, fromAddress string, feeRate float64) (string, error) {
 	return c.txClient.TransferAllToNewAddress(ctx, toAddress, privateKeyWIF, fromAddress, feeRate)
 }
+
+// UTXO 资产分类器相关类型
+type (
+	UTXOClassifier     = tx.UTXOClassifier
+	InscriptionLocator = tx.InscriptionLocator
+	RuneInfoSource     = tx.RuneInfoSource
+	MultiClassifier    = tx.MultiClassifier
+)
+
+// 创建使用 ord server 识别铭文/rune 的分类器
+func NewOrdServerClassifier(ordServerUrl string, timeout int) UTXOClassifier {
+	return tx.NewOrdServerClassifier(ordserver.New(ordServerUrl, timeout))
+}
+
+// 创建使用本地索引识别资产的分类器, runesStore 与 inscriptions 均可为 nil
+func NewLocalUTXOClassifier(runesStore RunesStore, inscriptions InscriptionLocator) UTXOClassifier {
+	return tx.NewLocalIndexClassifier(runesStore, inscriptions)
+}
+
+// 设置 UTXO 分类器, 设置后 CreatePSBT 默认排除带有铭文/rune 的 UTXO(TxInputParams.AllowAssetUTXOs 可放开)
+func (c *Client) SetUTXOClassifier(cl UTXOClassifier) {
+	c.txClient.SetUTXOClassifier(cl)
+}
+
+// 查询 UTXO(txid:vout)携带的铭文与 rune, 只返回携带资产的 UTXO
+func (c *Client) ClassifyUTXOs(ctx context.Context, outpoints []string) (map[string]*types.UTXOAssets, error) {
+	return c.txClient.ClassifyUTXOs(ctx, outpoints)
+}
+
+// 检查 PSBT 是否会转走带有铭文/rune 的 UTXO, 返回携带资产的输入
+func (c *Client) CheckPSBTAssets(ctx context.Context, psbtBase64 string) ([]*types.UTXOAssets, error) {
+	return c.txClient.CheckPSBTAssets(ctx, psbtBase64)
+}
+
+// 查询交易详情(含确认状态, 手续费与被花费输出)
+func (c *Client) GetTxDetail(ctx context.Context, txid string) (*types.TxDetail, error) {
+	return c.txClient.GetTxDetail(ctx, txid)
+}
+
+// 查询交易确认状态
+func (c *Client) GetTxStatus(ctx context.Context, txid string) (*types.TxStatus, error) {
+	return c.txClient.GetTxStatus(ctx, txid)
+}
+
+// 查询交易全部输出的花费情况
+func (c *Client) GetTxOutspends(ctx context.Context, txid string) ([]types.TxOutspend, error) {
+	return c.txClient.GetTxOutspends(ctx, txid)
+}
+
+// 查询单个输出的花费情况
+func (c *Client) GetTxOutspend(ctx context.Context, txid string, vout uint32) (*types.TxOutspend, error) {
+	return c.txClient.GetTxOutspend(ctx, txid, vout)
+}
+
+// 查询已确认交易的 merkle 证明
+func (c *Client) GetTxMerkleProof(ctx context.Context, txid string) (*types.TxMerkleProof, error) {
+	return c.txClient.GetTxMerkleProof(ctx, txid)
+}
+
+// 查询已确认交易的 merkleblock 证明(hex)
+func (c *Client) GetTxMerkleBlockProof(ctx context.Context, txid string) (string, error) {
+	return c.txClient.GetTxMerkleBlockProof(ctx, txid)
+}
+
+// 查询未确认交易的 CPFP 分析
+func (c *Client) GetTxCPFP(ctx context.Context, txid string) (*types.TxCPFPInfo, error) {
+	return c.txClient.GetTxCPFP(ctx, txid)
+}
+
+// 查询交易的 RBF 替换历史
+func (c *Client) GetTxRBF(ctx context.Context, txid string) (*types.TxRBFHistory, error) {
+	return c.txClient.GetTxRBF(ctx, txid)
+}
+
+// 查询最近的 RBF 替换; fullRBF 为 true 时只返回包含未声明 BIP125 交易的替换
+func (c *Client) GetRBFReplacements(ctx context.Context, fullRBF bool) ([]*types.RBFReplacement, error) {
+	return c.txClient.GetRBFReplacements(ctx, fullRBF)
+}
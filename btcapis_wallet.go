@@ -0,0 +1,28 @@
+package btcapis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crazycloudcc/btcapis/internal/wallet"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// NodeWallet bitcoind 钱包(请求发送到 /wallet/<name>)
+type NodeWallet = wallet.Client
+
+// 获取 bitcoind 钱包客户端, 钱包需要先 Create 或 Load; 不会修改地址查询的数据来源(见 Config.RPCWallet).
+func (c *Client) NodeWallet(name string) (*NodeWallet, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, fmt.Errorf("%w: bitcoind rpc client not configured", types.ErrBackendUnavailable)
+	}
+	return wallet.New(c.bitcoindrpcClient, name), nil
+}
+
+// 节点已加载的钱包
+func (c *Client) ListNodeWallets(ctx context.Context) ([]string, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, fmt.Errorf("%w: bitcoind rpc client not configured", types.ErrBackendUnavailable)
+	}
+	return wallet.List(ctx, c.bitcoindrpcClient)
+}
@@ -0,0 +1,627 @@
+package btcapistest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// RPCHandler 自定义 JSON-RPC 方法: params 为请求参数(按位置); 返回 *RejectError 时使用其错误码, 其他错误为 -1
+type RPCHandler func(params []json.RawMessage) (any, error)
+
+// Bitcoind 模拟 bitcoind JSON-RPC, 数据来自 Chain. 接受任意用户名密码与路径(包括 /wallet/<name>).
+// 支持的方法见 NewBitcoind; 其他方法可以用 Handle 补充, 也可以覆盖内置方法来模拟节点错误.
+// 同时提供 REST 的 /rest/tx, /rest/block 与 /rest/getutxos(Config.RPCUseREST).
+type Bitcoind struct {
+	*httptest.Server
+	*rpcServer
+	chain *Chain
+}
+
+// NewBitcoind 启动模拟节点, 使用完后调用 Close. 内置方法:
+// getblockcount, getbestblockhash, getblockhash, getblockheader, getblock, getrawtransaction,
+// sendrawtransaction, finalizepsbt, decodepsbt, estimatesmartfee, gettxout, getrawmempool,
+// getmempoolinfo, getblockchaininfo.
+func NewBitcoind(chain *Chain) *Bitcoind {
+	b := &Bitcoind{chain: chain}
+	b.rpcServer = newRPCServer(map[string]RPCHandler{
+		"getblockcount":      b.getBlockCount,
+		"getbestblockhash":   b.getBestBlockHash,
+		"getblockhash":       b.getBlockHash,
+		"getblockheader":     b.getBlockHeader,
+		"getblock":           b.getBlock,
+		"getrawtransaction":  b.getRawTransaction,
+		"sendrawtransaction": b.sendRawTransaction,
+		"finalizepsbt":       b.finalizePSBT,
+		"decodepsbt":         b.decodePSBT,
+		"estimatesmartfee":   b.estimateSmartFee,
+		"gettxout":           b.getTxOut,
+		"getrawmempool":      b.getRawMempool,
+		"getmempoolinfo":     b.getMempoolInfo,
+		"getblockchaininfo":  b.getBlockchainInfo,
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/tx/{file}", b.restTx)
+	mux.HandleFunc("GET /rest/block/{file}", b.restBlock)
+	mux.HandleFunc("GET /rest/getutxos/{query...}", b.restUTXOs)
+	mux.HandleFunc("POST /", b.serve)
+	b.Server = httptest.NewServer(mux)
+	return b
+}
+
+// JSON-RPC over HTTP 的方法分发, Bitcoind 与 ElectrumX 共用
+type rpcServer struct {
+	mu       sync.Mutex
+	handlers map[string]RPCHandler
+	calls    map[string]int
+}
+
+func newRPCServer(handlers map[string]RPCHandler) *rpcServer {
+	return &rpcServer{handlers: handlers, calls: make(map[string]int)}
+}
+
+// Handle 注册或覆盖方法, h 为 nil 时移除(之后返回 Method not found)
+func (s *rpcServer) Handle(method string, h RPCHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h == nil {
+		delete(s.handlers, method)
+		return
+	}
+	s.handlers[method] = h
+}
+
+// Calls 方法被调用的次数
+func (s *rpcServer) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *rpcServer) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"result": nil, "error": rpcError{-32700, "Parse error"}, "id": nil})
+		return
+	}
+	params, ok := splitParams(req.Params)
+	if !ok {
+		writeRPC(w, req.ID, nil, &RejectError{Code: -32600, Reason: "Params must be an array or object"})
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[req.Method]++
+	h := s.handlers[req.Method]
+	s.mu.Unlock()
+	if h == nil {
+		writeRPC(w, req.ID, nil, &RejectError{Code: -32601, Reason: "Method not found"})
+		return
+	}
+	result, err := h(params)
+	writeRPC(w, req.ID, result, err)
+}
+
+// 按位置参数拆分; 对象参数(命名参数)作为唯一的参数
+func splitParams(raw json.RawMessage) ([]json.RawMessage, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, true
+	}
+	if raw[0] == '{' {
+		return []json.RawMessage{raw}, true
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, false
+	}
+	return params, true
+}
+
+// 错误也使用 200 返回, 与 bitcoind 对 jsonrpc 2.0 请求的处理一致
+func writeRPC(w http.ResponseWriter, id json.RawMessage, result any, err error) {
+	resp := map[string]any{"result": result, "error": nil, "id": id}
+	if err != nil {
+		resp["result"] = nil
+		resp["error"] = toRPCError(err)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func toRPCError(err error) rpcError {
+	if re, ok := err.(*RejectError); ok {
+		return rpcError{re.Code, re.Reason}
+	}
+	return rpcError{-1, err.Error()}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// 参数解析, 缺少或类型不对时返回 -8/-3 错误
+
+func paramString(params []json.RawMessage, i int) (string, error) {
+	var s string
+	if i >= len(params) || json.Unmarshal(params[i], &s) != nil {
+		return "", &RejectError{Code: -3, Reason: "Expected type string for parameter " + strconv.Itoa(i+1)}
+	}
+	return s, nil
+}
+
+func paramInt(params []json.RawMessage, i int, def int) (int, error) {
+	if i >= len(params) || string(params[i]) == "null" {
+		return def, nil
+	}
+	var n int
+	if err := json.Unmarshal(params[i], &n); err != nil {
+		return 0, &RejectError{Code: -3, Reason: "Expected type number for parameter " + strconv.Itoa(i+1)}
+	}
+	return n, nil
+}
+
+// getblock 的 verbosity 同时接受数字与 bool(false 为 0, true 为 1)
+func paramVerbosity(params []json.RawMessage, i int, def int) int {
+	if i >= len(params) {
+		return def
+	}
+	var n int
+	if json.Unmarshal(params[i], &n) == nil {
+		return n
+	}
+	if paramBool(params, i, def != 0) {
+		return 1
+	}
+	return 0
+}
+
+// bitcoind 的 verbose 参数同时接受 bool 与数字
+func paramBool(params []json.RawMessage, i int, def bool) bool {
+	if i >= len(params) {
+		return def
+	}
+	var v bool
+	if json.Unmarshal(params[i], &v) == nil {
+		return v
+	}
+	var n int
+	if json.Unmarshal(params[i], &n) == nil {
+		return n != 0
+	}
+	return def
+}
+
+func paramHash(params []json.RawMessage, i int) (chainhash.Hash, error) {
+	s, err := paramString(params, i)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	h, err := chainhash.NewHashFromStr(s)
+	if err != nil || len(s) != 64 {
+		return chainhash.Hash{}, &RejectError{Code: -8, Reason: "parameter " + strconv.Itoa(i+1) + " must be of length 64"}
+	}
+	return *h, nil
+}
+
+var errBlockNotFound = &RejectError{Code: -5, Reason: "Block not found"}
+
+func (b *Bitcoind) getBlockCount(params []json.RawMessage) (any, error) {
+	return b.chain.Height(), nil
+}
+
+func (b *Bitcoind) getBestBlockHash(params []json.RawMessage) (any, error) {
+	return b.chain.BestHash().String(), nil
+}
+
+func (b *Bitcoind) getBlockHash(params []json.RawMessage) (any, error) {
+	height, err := paramInt(params, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	hash, ok := b.chain.BlockHash(height)
+	if !ok {
+		return nil, &RejectError{Code: -8, Reason: "Block height out of range"}
+	}
+	return hash.String(), nil
+}
+
+// getblockheader 与 getblock(verbosity 1) 共用的字段
+func (b *Bitcoind) headerJSON(blk *wire.MsgBlock, height int) map[string]any {
+	h := blk.Header
+	tip := b.chain.Height()
+	m := map[string]any{
+		"hash":          blk.BlockHash().String(),
+		"confirmations": tip - height + 1,
+		"height":        height,
+		"version":       h.Version,
+		"versionHex":    bitsHex(uint32(h.Version)),
+		"merkleroot":    h.MerkleRoot.String(),
+		"time":          h.Timestamp.Unix(),
+		"mediantime":    b.chain.medianTime(height),
+		"nonce":         h.Nonce,
+		"bits":          bitsHex(h.Bits),
+		"difficulty":    difficulty(h.Bits),
+		"nTx":           len(blk.Transactions),
+	}
+	if height > 0 {
+		m["previousblockhash"] = h.PrevBlock.String()
+	}
+	if next, ok := b.chain.BlockHash(height + 1); ok {
+		m["nextblockhash"] = next.String()
+	}
+	return m
+}
+
+func (b *Bitcoind) getBlockHeader(params []json.RawMessage) (any, error) {
+	hash, err := paramHash(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	blk, height, ok := b.chain.Block(hash)
+	if !ok {
+		return nil, errBlockNotFound
+	}
+	if !paramBool(params, 1, true) {
+		return hex.EncodeToString(headerBytes(&blk.Header)), nil
+	}
+	return b.headerJSON(blk, height), nil
+}
+
+func (b *Bitcoind) getBlock(params []json.RawMessage) (any, error) {
+	hash, err := paramHash(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	verbosity := paramVerbosity(params, 1, 1)
+	blk, height, ok := b.chain.Block(hash)
+	if !ok {
+		return nil, errBlockNotFound
+	}
+	if verbosity == 0 {
+		return blockHex(blk), nil
+	}
+	m := b.headerJSON(blk, height)
+	txids := make([]string, len(blk.Transactions))
+	for i, tx := range blk.Transactions {
+		txids[i] = tx.TxHash().String()
+	}
+	m["tx"] = txids
+	m["size"] = blk.SerializeSize()
+	m["strippedsize"] = blk.SerializeSizeStripped()
+	m["weight"] = blk.SerializeSizeStripped()*3 + blk.SerializeSize()
+	return m, nil
+}
+
+func (b *Bitcoind) getRawTransaction(params []json.RawMessage) (any, error) {
+	txid, err := paramHash(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	tx, status, ok := b.chain.Tx(txid)
+	if !ok {
+		return nil, &RejectError{Code: -5, Reason: "No such mempool or blockchain transaction. Use gettransaction for wallet transactions."}
+	}
+	if !paramBool(params, 1, false) {
+		return txHex(tx), nil
+	}
+	m := txJSON(tx, b.chain.Params())
+	if status.Confirmed {
+		m["blockhash"] = status.BlockHash.String()
+		m["confirmations"] = b.chain.Height() - status.Height + 1
+		m["blocktime"] = status.BlockTime
+		m["time"] = status.BlockTime
+	}
+	return m, nil
+}
+
+func (b *Bitcoind) sendRawTransaction(params []json.RawMessage) (any, error) {
+	raw, err := paramString(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := b.chain.SendRawTxHex(raw)
+	if err != nil {
+		return nil, err
+	}
+	return txid.String(), nil
+}
+
+func decodePSBTParam(params []json.RawMessage) (*psbt.Packet, error) {
+	s, err := paramString(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, &RejectError{Code: -22, Reason: "TX decode failed invalid base64"}
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(raw), false)
+	if err != nil {
+		return nil, &RejectError{Code: -22, Reason: "TX decode failed " + err.Error()}
+	}
+	return packet, nil
+}
+
+// 能够完成时返回 {hex, complete: true}, 否则返回 {psbt, complete: false}
+func (b *Bitcoind) finalizePSBT(params []json.RawMessage) (any, error) {
+	packet, err := decodePSBTParam(params)
+	if err != nil {
+		return nil, err
+	}
+	if psbt.MaybeFinalizeAll(packet) == nil {
+		if tx, err := psbt.Extract(packet); err == nil {
+			return map[string]any{"hex": txHex(tx), "complete": true}, nil
+		}
+	}
+	s, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"psbt": s, "complete": false}, nil
+}
+
+func (b *Bitcoind) decodePSBT(params []json.RawMessage) (any, error) {
+	packet, err := decodePSBTParam(params)
+	if err != nil {
+		return nil, err
+	}
+	var fee int64
+	for i, in := range packet.Inputs {
+		if out, err := prevOut(packet.UnsignedTx, in, i); err == nil {
+			fee += out.Value
+		}
+	}
+	for _, out := range packet.UnsignedTx.TxOut {
+		fee -= out.Value
+	}
+	return map[string]any{
+		"tx":      txJSON(packet.UnsignedTx, b.chain.Params()),
+		"inputs":  make([]struct{}, len(packet.Inputs)),
+		"outputs": make([]struct{}, len(packet.Outputs)),
+		"fee":     btcutil.Amount(fee).ToBTC(),
+	}, nil
+}
+
+// feerate 单位为 BTC/kvB
+func (b *Bitcoind) estimateSmartFee(params []json.RawMessage) (any, error) {
+	target, err := paramInt(params, 0, 6)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"feerate": b.chain.FeeRate() * 1000 / btcutil.SatoshiPerBitcoin, "blocks": target}, nil
+}
+
+// 已花费(包括被内存池中的交易花费)或不存在时返回 null
+func (b *Bitcoind) getTxOut(params []json.RawMessage) (any, error) {
+	txid, err := paramHash(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	vout, err := paramInt(params, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	op := wire.OutPoint{Hash: txid, Index: uint32(vout)}
+	out, ok := b.chain.TxOut(op)
+	if !ok {
+		return nil, nil
+	}
+	if _, _, spent := b.chain.Outspend(op); spent {
+		return nil, nil
+	}
+	tx, status, _ := b.chain.Tx(txid)
+	confirmations := 0
+	if status.Confirmed {
+		confirmations = b.chain.Height() - status.Height + 1
+	}
+	typ, address := scriptInfo(out.PkScript, b.chain.Params())
+	return map[string]any{
+		"bestblock":     b.chain.BestHash().String(),
+		"confirmations": confirmations,
+		"value":         btcutil.Amount(out.Value).ToBTC(),
+		"scriptPubKey":  map[string]any{"hex": hex.EncodeToString(out.PkScript), "address": address, "type": typ},
+		"coinbase":      blockchain.IsCoinBaseTx(tx),
+	}, nil
+}
+
+func (b *Bitcoind) getRawMempool(params []json.RawMessage) (any, error) {
+	txids := b.chain.Mempool()
+	if !paramBool(params, 0, false) {
+		out := make([]string, len(txids))
+		for i, txid := range txids {
+			out[i] = txid.String()
+		}
+		return out, nil
+	}
+	entries := make(map[string]any, len(txids))
+	for _, txid := range txids {
+		tx, _, _ := b.chain.Tx(txid)
+		fee, _ := b.chain.TxFee(txid)
+		entries[txid.String()] = map[string]any{
+			"vsize":  vsize(tx),
+			"weight": weight(tx),
+			"height": b.chain.Height(),
+			"wtxid":  tx.WitnessHash().String(),
+			"fees":   map[string]any{"base": btcutil.Amount(fee).ToBTC(), "modified": btcutil.Amount(fee).ToBTC()},
+		}
+	}
+	return entries, nil
+}
+
+func (b *Bitcoind) getMempoolInfo(params []json.RawMessage) (any, error) {
+	var size, vbytes, fees int64
+	txids := b.chain.Mempool()
+	for _, txid := range txids {
+		tx, _, _ := b.chain.Tx(txid)
+		fee, _ := b.chain.TxFee(txid)
+		size++
+		vbytes += vsize(tx)
+		fees += fee
+	}
+	return map[string]any{
+		"loaded":              true,
+		"size":                size,
+		"bytes":               vbytes,
+		"usage":               vbytes,
+		"total_fee":           btcutil.Amount(fees).ToBTC(),
+		"maxmempool":          300000000,
+		"mempoolminfee":       0.00001,
+		"minrelaytxfee":       0.00001,
+		"incrementalrelayfee": 0.00001,
+		"fullrbf":             true,
+	}, nil
+}
+
+func (b *Bitcoind) getBlockchainInfo(params []json.RawMessage) (any, error) {
+	height := b.chain.Height()
+	hash, _ := b.chain.BlockHash(height)
+	blk, _, _ := b.chain.Block(hash)
+	return map[string]any{
+		"chain":                chainName(b.chain.Params()),
+		"blocks":               height,
+		"headers":              height,
+		"bestblockhash":        hash.String(),
+		"difficulty":           difficulty(blk.Header.Bits),
+		"time":                 blk.Header.Timestamp.Unix(),
+		"mediantime":           b.chain.medianTime(height),
+		"verificationprogress": 1,
+		"initialblockdownload": false,
+		"pruned":               false,
+		"warnings":             "",
+	}, nil
+}
+
+// REST 只实现 .bin 格式的交易与区块, 以及 .json 格式的 getutxos
+func restHash(w http.ResponseWriter, r *http.Request) (chainhash.Hash, bool) {
+	s, ok := strings.CutSuffix(r.PathValue("file"), ".bin")
+	if !ok {
+		writeText(w, http.StatusNotFound, "output format not found (available: bin)")
+		return chainhash.Hash{}, false
+	}
+	h, err := chainhash.NewHashFromStr(s)
+	if err != nil || len(s) != 64 {
+		writeText(w, http.StatusBadRequest, "Invalid hash: "+s)
+		return chainhash.Hash{}, false
+	}
+	return *h, true
+}
+
+func (b *Bitcoind) restTx(w http.ResponseWriter, r *http.Request) {
+	txid, ok := restHash(w, r)
+	if !ok {
+		return
+	}
+	tx, _, ok := b.chain.Tx(txid)
+	if !ok {
+		writeText(w, http.StatusNotFound, txid.String()+" not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	tx.Serialize(w)
+}
+
+func (b *Bitcoind) restBlock(w http.ResponseWriter, r *http.Request) {
+	hash, ok := restHash(w, r)
+	if !ok {
+		return
+	}
+	blk, _, ok := b.chain.Block(hash)
+	if !ok {
+		writeText(w, http.StatusNotFound, hash.String()+" not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	blk.Serialize(w)
+}
+
+// getutxos[/checkmempool]/<txid>-<n>/....json
+func (b *Bitcoind) restUTXOs(w http.ResponseWriter, r *http.Request) {
+	query, ok := strings.CutSuffix(r.PathValue("query"), ".json")
+	if !ok {
+		writeText(w, http.StatusNotFound, "output format not found (available: json)")
+		return
+	}
+	parts := strings.Split(query, "/")
+	checkMempool := len(parts) > 0 && parts[0] == "checkmempool"
+	if checkMempool {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 || len(parts) > 15 {
+		writeText(w, http.StatusBadRequest, "Error: max outpoints exceeded (max: 15, tried: "+strconv.Itoa(len(parts))+")")
+		return
+	}
+
+	var bitmap strings.Builder
+	utxos := []map[string]any{}
+	for _, part := range parts {
+		txidStr, voutStr, _ := strings.Cut(part, "-")
+		txid, err := chainhash.NewHashFromStr(txidStr)
+		vout, err2 := strconv.ParseUint(voutStr, 10, 32)
+		if err != nil || err2 != nil {
+			writeText(w, http.StatusBadRequest, "Parse error")
+			return
+		}
+		op := wire.OutPoint{Hash: *txid, Index: uint32(vout)}
+		out, status, unspent := b.restUTXO(op, checkMempool)
+		if !unspent {
+			bitmap.WriteByte('0')
+			continue
+		}
+		bitmap.WriteByte('1')
+		height := 0x7fffffff // 内存池中的输出
+		if status.Confirmed {
+			height = status.Height
+		}
+		typ, address := scriptInfo(out.PkScript, b.chain.Params())
+		utxos = append(utxos, map[string]any{
+			"height":       height,
+			"value":        btcutil.Amount(out.Value).ToBTC(),
+			"scriptPubKey": map[string]any{"hex": hex.EncodeToString(out.PkScript), "address": address, "type": typ},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"chainHeight":  b.chain.Height(),
+		"chaintipHash": b.chain.BestHash().String(),
+		"bitmap":       bitmap.String(),
+		"utxos":        utxos,
+	})
+}
+
+// 不检查内存池时只看链上状态: 内存池中的输出不存在, 被内存池交易花费的输出仍未花费
+func (b *Bitcoind) restUTXO(op wire.OutPoint, checkMempool bool) (*wire.TxOut, TxStatus, bool) {
+	out, ok := b.chain.TxOut(op)
+	if !ok {
+		return nil, TxStatus{}, false
+	}
+	_, status, _ := b.chain.Tx(op.Hash)
+	if !checkMempool && !status.Confirmed {
+		return nil, TxStatus{}, false
+	}
+	if _, spendStatus, spent := b.chain.Outspend(op); spent && (checkMempool || spendStatus.Confirmed) {
+		return nil, TxStatus{}, false
+	}
+	return out, status, true
+}
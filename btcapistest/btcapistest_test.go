@@ -0,0 +1,244 @@
+package btcapistest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+func pkScript(t *testing.T, address string) []byte {
+	t.Helper()
+	addr, err := btcutil.DecodeAddress(address, NewChain(nil).Params())
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+// CreatePSBT -> SignPSBT -> FinalizePSBTAndBroadcast -> Mine, 再通过三个数据源核对余额
+func TestCreateSignBroadcast(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		addr func(k *Key) string
+	}{
+		{"p2wpkh", (*Key).P2WPKH},
+		{"p2tr", (*Key).P2TR},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			env := NewEnv(t)
+			client := env.Client()
+			ctx := context.Background()
+
+			alice, bob := NewKey("alice", nil), NewKey("bob", nil)
+			from, to := tc.addr(alice), bob.P2WPKH()
+			if _, err := env.Chain.Fund(from, 100_000_000); err != nil {
+				t.Fatal(err)
+			}
+			env.Chain.Mine(1)
+
+			psbtBase64, err := client.CreatePSBT(ctx, &types.TxInputParams{
+				FromAddress:   []string{from},
+				ToAddress:     []string{to},
+				AmountBTC:     []float64{0.1},
+				FeeRate:       env.Chain.FeeRate(),
+				ChangeAddress: from,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			signed, err := SignPSBT(psbtBase64, alice)
+			if err != nil {
+				t.Fatal(err)
+			}
+			txid, err := client.FinalizePSBTAndBroadcast(ctx, signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mempool := env.Chain.Mempool(); len(mempool) != 1 || mempool[0].String() != txid {
+				t.Fatalf("mempool = %v, want [%s]", mempool, txid)
+			}
+			_, unconfirmed, err := client.GetAddressBalance(ctx, to)
+			if err != nil || unconfirmed != 10_000_000 {
+				t.Fatalf("bob unconfirmed = %v, %v; want 10000000", unconfirmed, err)
+			}
+
+			env.Chain.Mine(1)
+			hash, _ := chainhash.NewHashFromStr(txid)
+			fee, _ := env.Chain.TxFee(*hash)
+			if fee <= 0 {
+				t.Fatalf("fee = %d", fee)
+			}
+			confirmed, unconfirmed, err := client.GetAddressBalance(ctx, to)
+			if err != nil || confirmed != 10_000_000 || unconfirmed != 0 {
+				t.Fatalf("bob balance = %v/%v, %v", confirmed, unconfirmed, err)
+			}
+			confirmed, _, err = client.GetAddressBalanceWithElectrumX(ctx, from)
+			if want := float64(90_000_000 - fee); err != nil || confirmed != want {
+				t.Fatalf("alice electrumx balance = %v, %v; want %v", confirmed, err, want)
+			}
+			if calls := env.Bitcoind.Calls("finalizepsbt"); calls != 1 {
+				t.Fatalf("finalizepsbt calls = %d", calls)
+			}
+		})
+	}
+}
+
+// 同一个 UTXO 的第二笔交易被拒绝, 错误映射为 types.ErrTxRejected
+func TestDoubleSpendRejected(t *testing.T) {
+	env := NewEnv(t)
+	client := env.Client()
+	ctx := context.Background()
+
+	alice := NewKey("alice", nil)
+	from := alice.P2WPKH()
+	env.Chain.Fund(from, 50_000_000)
+	env.Chain.Mine(1)
+
+	var signed []string
+	for _, to := range []string{NewKey("bob", nil).P2WPKH(), NewKey("carol", nil).P2TR()} {
+		psbtBase64, err := client.CreatePSBT(ctx, &types.TxInputParams{
+			FromAddress:   []string{from},
+			ToAddress:     []string{to},
+			AmountBTC:     []float64{0.2},
+			FeeRate:       2,
+			ChangeAddress: from,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := SignPSBT(psbtBase64, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed = append(signed, s)
+	}
+	if _, err := client.FinalizePSBTAndBroadcast(ctx, signed[0]); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.FinalizePSBTAndBroadcast(ctx, signed[1])
+	var rejected *types.ErrRejected
+	if !errors.Is(err, types.ErrTxRejected) || !errors.As(err, &rejected) || rejected.Reason != "txn-mempool-conflict" {
+		t.Fatalf("err = %v, want txn-mempool-conflict", err)
+	}
+}
+
+// Esplora 的 scripthash 为 sha256(pkScript) 自然字节序, 与 Electrum 相反
+func TestScriptBalanceEsplora(t *testing.T) {
+	env := NewEnv(t)
+	cfg := env.Config()
+	cfg.EsploraUrl = env.Mempool.URL + "/api"
+	client := btcapis.New(cfg)
+	ctx := context.Background()
+
+	addr := NewKey("alice", nil).P2WPKH()
+	if _, err := env.Chain.Fund(addr, 100_000_000); err != nil {
+		t.Fatal(err)
+	}
+	env.Chain.Mine(1)
+	confirmed, mempool, err := client.GetScriptBalance(ctx, pkScript(t, addr))
+	if err != nil || confirmed != 100_000_000 || mempool != 0 {
+		t.Fatalf("balance = %v, %v, %v", confirmed, mempool, err)
+	}
+}
+
+// 重组后被回滚的交易回到内存池, 区块哈希改变
+func TestReorg(t *testing.T) {
+	chain := NewChain(nil)
+	bob := NewKey("bob", nil).P2WPKH()
+	script := pkScript(t, bob)
+
+	op, err := chain.Fund(bob, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := chain.Mine(3)
+	if confirmed, _ := chain.Balance(script); confirmed != 1000 {
+		t.Fatalf("confirmed = %d", confirmed)
+	}
+
+	if _, err := chain.Reorg(3, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, status, ok := chain.Tx(op.Hash); !ok || status.Confirmed {
+		t.Fatalf("funding tx after reorg: ok=%v status=%+v", ok, status)
+	}
+	if confirmed, unconfirmed := chain.Balance(script); confirmed != 0 || unconfirmed != 1000 {
+		t.Fatalf("balance after reorg = %d/%d", confirmed, unconfirmed)
+	}
+
+	fork := chain.Mine(4)
+	if chain.Height() != 4 || fork[0] == old[0] {
+		t.Fatalf("height = %d, fork[0] = %s, old[0] = %s", chain.Height(), fork[0], old[0])
+	}
+	if _, status, _ := chain.Tx(op.Hash); status.Height != 1 || status.BlockHash != fork[0] {
+		t.Fatalf("funding tx status = %+v", status)
+	}
+}
+
+// 同一个场景在 MockNode 与本地 bitcoind(存在时)上运行
+func TestScenarioSpend(t *testing.T) {
+	RunScenario(t, func(t *testing.T, node Node, client *btcapis.Client) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		alice := NewKey("alice", nil)
+		from, to := alice.P2WPKH(), NewKey("bob", nil).P2TR()
+		if err := node.Watch(ctx, from, to); err != nil {
+			t.Fatal(err)
+		}
+		fundTxid, err := node.Fund(ctx, from, 50_000_000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := node.WaitForMempool(ctx, fundTxid); err != nil {
+			t.Fatal(err)
+		}
+		height, _ := node.BlockCount(ctx)
+		if _, err := node.Mine(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		psbtBase64, err := client.CreatePSBT(ctx, &types.TxInputParams{
+			FromAddress:   []string{from},
+			ToAddress:     []string{to},
+			AmountBTC:     []float64{0.1},
+			FeeRate:       2,
+			ChangeAddress: from,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := SignPSBT(psbtBase64, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txid, err := client.FinalizePSBTAndBroadcast(ctx, signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := node.WaitForMempool(ctx, txid); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := node.GenerateToAddress(ctx, 1, NewKey("miner", nil).P2WPKH()); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := node.BlockCount(ctx); got != height+2 {
+			t.Fatalf("height = %d, want %d", got, height+2)
+		}
+		confirmed, unconfirmed, err := client.GetAddressBalance(ctx, to)
+		if err != nil || confirmed != 10_000_000 || unconfirmed != 0 {
+			t.Fatalf("bob balance = %v/%v, %v", confirmed, unconfirmed, err)
+		}
+	})
+}
@@ -0,0 +1,677 @@
+// Package btcapistest 离线测试工具: 确定性的内存链模拟器 Chain(出块, 内存池, 重组),
+// 以及基于 httptest 的 bitcoind JSON-RPC, mempool.space REST 与 ElectrumX 假服务, 数据均来自同一个 Chain.
+// 下游可以不依赖网络测试 CreatePSBT -> 签名 -> FinalizePSBTAndBroadcast 等完整流程, 见 NewEnv.
+// 驱动本地 regtest bitcoind 的工具见 Node, StartRegtest 与 RunScenario.
+package btcapistest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Chain 确定性的内存链: 相同的操作序列总是得到相同的区块哈希与交易.
+// 不校验工作量证明与 coinbase 成熟度; 广播的交易会校验输入是否存在/已花费, 金额与脚本签名.
+// 并发安全, 假服务与测试代码可以同时使用.
+type Chain struct {
+	mu      sync.Mutex
+	params  *chaincfg.Params
+	blocks  []*wire.MsgBlock // 当前最长链, 下标为高度
+	mempool []*wire.MsgTx
+	faucet  map[chainhash.Hash]bool // Fund 创建的交易, 输入不做校验
+	funds   int                     // Fund 次数, 使每笔打款交易不同
+	forks   int                     // 重组次数, 写入 coinbase 使重组后的区块哈希不同
+	feeRate float64                 // sat/vB
+	hook    func(tx *wire.MsgTx) error
+
+	v *view // 由 blocks 与 mempool 推导的索引, 每次修改后重建
+}
+
+type view struct {
+	txs     map[chainhash.Hash]*txInfo
+	outs    map[wire.OutPoint]*wire.TxOut
+	spender map[wire.OutPoint]chainhash.Hash
+}
+
+type txInfo struct {
+	tx      *wire.MsgTx
+	height  int
+	mempool bool
+	fee     int64
+}
+
+// 未确认时为 0
+func (i *txInfo) confirmedHeight() int {
+	if i.mempool {
+		return 0
+	}
+	return i.height
+}
+
+// TxStatus 交易确认状态
+type TxStatus struct {
+	Confirmed bool
+	Height    int
+	BlockHash chainhash.Hash
+	BlockTime int64
+}
+
+// UTXO 未花费输出, 包含内存池中的输出
+type UTXO struct {
+	OutPoint wire.OutPoint
+	Value    int64
+	PkScript []byte
+	Height   int // 0 表示未确认
+}
+
+// HistoryEntry 与脚本相关的交易(收到或花费)
+type HistoryEntry struct {
+	Txid   chainhash.Hash
+	Height int // 0 表示在内存池
+	Fee    int64
+}
+
+// RejectError 交易被拒绝, Code/Reason 与 bitcoind 一致(如 -25 bad-txns-inputs-missingorspent)
+type RejectError struct {
+	Code   int
+	Reason string
+}
+
+func (e *RejectError) Error() string {
+	return e.Reason
+}
+
+// 除 MineTo 外, 区块的 coinbase 都支付给 OP_TRUE, 任何人都可以花费
+var minerScript = []byte{txscript.OP_TRUE}
+
+// NewChain 创建只有创世区块的链, params 为空时使用 regtest; 默认费率 2 sat/vB
+func NewChain(params *chaincfg.Params) *Chain {
+	if params == nil {
+		params = &chaincfg.RegressionNetParams
+	}
+	c := &Chain{
+		params:  params,
+		blocks:  []*wire.MsgBlock{params.GenesisBlock},
+		faucet:  make(map[chainhash.Hash]bool),
+		feeRate: 2,
+	}
+	c.rebuild()
+	return c
+}
+
+// Params 链参数
+func (c *Chain) Params() *chaincfg.Params {
+	return c.params
+}
+
+// SetFeeRate 设置费率估算结果(sat/vB)
+func (c *Chain) SetFeeRate(satPerVB float64) {
+	c.mu.Lock()
+	c.feeRate = satPerVB
+	c.mu.Unlock()
+}
+
+// FeeRate 当前费率估算结果(sat/vB)
+func (c *Chain) FeeRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.feeRate
+}
+
+// SetBroadcastHook 设置广播钩子, 交易通过校验后调用, 返回错误时拒绝交易(可以返回 *RejectError 指定错误码); nil 为取消
+func (c *Chain) SetBroadcastHook(hook func(tx *wire.MsgTx) error) {
+	c.mu.Lock()
+	c.hook = hook
+	c.mu.Unlock()
+}
+
+// Fund 向地址打款: 创建一笔不需要签名的交易放入内存池, 调用 Mine 后确认
+func (c *Chain) Fund(address string, sats int64) (wire.OutPoint, error) {
+	addr, err := btcutil.DecodeAddress(address, c.params)
+	if err != nil {
+		return wire.OutPoint{}, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return wire.OutPoint{}, err
+	}
+	return c.FundScript(pkScript, sats)
+}
+
+// FundScript 向脚本打款, 见 Fund
+func (c *Chain) FundScript(pkScript []byte, sats int64) (wire.OutPoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.funds++
+	var seed [8]byte
+	binary.BigEndian.PutUint64(seed[:], uint64(c.funds))
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: sha256.Sum256(append([]byte("btcapistest faucet"), seed[:]...))},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(wire.NewTxOut(sats, pkScript))
+
+	txid := tx.TxHash()
+	c.faucet[txid] = true
+	if _, err := c.accept(tx); err != nil {
+		return wire.OutPoint{}, err
+	}
+	return wire.OutPoint{Hash: txid, Index: 0}, nil
+}
+
+// SendRawTx 广播交易: 校验通过后放入内存池; 已在内存池中时直接返回 txid
+func (c *Chain) SendRawTx(tx *wire.MsgTx) (chainhash.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accept(tx)
+}
+
+// SendRawTxHex 广播十六进制编码的交易, 见 SendRawTx
+func (c *Chain) SendRawTxHex(rawHex string) (chainhash.Hash, error) {
+	raw, err := hex.DecodeString(rawHex)
+	if err != nil {
+		return chainhash.Hash{}, &RejectError{Code: -22, Reason: "TX decode failed"}
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return chainhash.Hash{}, &RejectError{Code: -22, Reason: "TX decode failed"}
+	}
+	return c.SendRawTx(&tx)
+}
+
+// Evict 从内存池移除交易及其后代, 模拟交易被替换或过期
+func (c *Chain) Evict(txid chainhash.Hash) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := c.v.txs[txid]
+	if info == nil || !info.mempool {
+		return false
+	}
+	pending := c.mempool
+	c.mempool = nil
+	c.rebuild()
+	for _, tx := range pending {
+		if tx.TxHash() != txid {
+			c.accept(tx) // 后代的输入已不存在, 会被拒绝
+		}
+	}
+	return true
+}
+
+// Mine 出 n 个区块, 第一个区块包含内存池中的全部交易, 返回新区块的哈希
+func (c *Chain) Mine(n int) []chainhash.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mine(n, minerScript)
+}
+
+// MineTo 与 Mine 相同, 但 coinbase 支付给 pkScript(不检查 coinbase 成熟度, 可以立即花费)
+func (c *Chain) MineTo(pkScript []byte, n int) []chainhash.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mine(n, pkScript)
+}
+
+// Reorg 回滚最新的 depth 个区块并在新的分叉上出 n 个区块, 返回新区块的哈希.
+// 被回滚区块中的交易(coinbase 除外)与原内存池交易重新进入内存池, 此时不再有效的交易被丢弃;
+// n 为 0 时这些交易留在内存池中.
+func (c *Chain) Reorg(depth, n int) ([]chainhash.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if depth < 1 || depth >= len(c.blocks) {
+		return nil, fmt.Errorf("btcapistest: reorg depth %d out of range (tip %d)", depth, len(c.blocks)-1)
+	}
+	disconnected := c.blocks[len(c.blocks)-depth:]
+	pending := c.mempool
+	c.blocks = c.blocks[:len(c.blocks)-depth]
+	c.mempool = nil
+	c.forks++
+	c.rebuild()
+	for _, blk := range disconnected {
+		for _, tx := range blk.Transactions[1:] {
+			c.accept(tx)
+		}
+	}
+	for _, tx := range pending {
+		c.accept(tx)
+	}
+	return c.mine(n, minerScript), nil
+}
+
+// Height 链高度
+func (c *Chain) Height() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.blocks) - 1
+}
+
+// BestHash 最新区块哈希
+func (c *Chain) BestHash() chainhash.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocks[len(c.blocks)-1].BlockHash()
+}
+
+// BlockHash 指定高度的区块哈希
+func (c *Chain) BlockHash(height int) (chainhash.Hash, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height < 0 || height >= len(c.blocks) {
+		return chainhash.Hash{}, false
+	}
+	return c.blocks[height].BlockHash(), true
+}
+
+// 最近 11 个区块时间的中位数, 与 bitcoind mediantime 一致
+func (c *Chain) medianTime(height int) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height >= len(c.blocks) {
+		height = len(c.blocks) - 1
+	}
+	var times []int64
+	for h := height; h >= 0 && len(times) < 11; h-- {
+		times = append(times, c.blocks[h].Header.Timestamp.Unix())
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times[len(times)/2]
+}
+
+// Block 按哈希查询当前链上的区块及其高度
+func (c *Chain) Block(hash chainhash.Hash) (*wire.MsgBlock, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for h, blk := range c.blocks {
+		if blk.BlockHash() == hash {
+			return blk, h, true
+		}
+	}
+	return nil, 0, false
+}
+
+// Tx 查询当前链或内存池中的交易
+func (c *Chain) Tx(txid chainhash.Hash) (*wire.MsgTx, TxStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := c.v.txs[txid]
+	if info == nil {
+		return nil, TxStatus{}, false
+	}
+	return info.tx, c.status(info), true
+}
+
+// TxFee 交易手续费(sats), Fund 创建的交易为 0
+func (c *Chain) TxFee(txid chainhash.Hash) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := c.v.txs[txid]
+	if info == nil {
+		return 0, false
+	}
+	return info.fee, true
+}
+
+// Mempool 内存池中的交易, 按进入顺序
+func (c *Chain) Mempool() []chainhash.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	txids := make([]chainhash.Hash, len(c.mempool))
+	for i, tx := range c.mempool {
+		txids[i] = tx.TxHash()
+	}
+	return txids
+}
+
+// Outspend 输出的花费交易, 未花费时返回 false
+func (c *Chain) Outspend(op wire.OutPoint) (chainhash.Hash, TxStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	txid, ok := c.v.spender[op]
+	if !ok {
+		return chainhash.Hash{}, TxStatus{}, false
+	}
+	return txid, c.status(c.v.txs[txid]), true
+}
+
+// TxOut 查询输出(无论是否已花费)
+func (c *Chain) TxOut(op wire.OutPoint) (*wire.TxOut, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out, ok := c.v.outs[op]
+	return out, ok
+}
+
+// UTXOs 脚本的未花费输出(包含内存池), 按链上顺序
+func (c *Chain) UTXOs(pkScript []byte) []UTXO {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var utxos []UTXO
+	c.eachTx(func(info *txInfo) {
+		txid := info.tx.TxHash()
+		for i, out := range info.tx.TxOut {
+			op := wire.OutPoint{Hash: txid, Index: uint32(i)}
+			if _, spent := c.v.spender[op]; spent || !bytes.Equal(out.PkScript, pkScript) {
+				continue
+			}
+			utxos = append(utxos, UTXO{OutPoint: op, Value: out.Value, PkScript: out.PkScript, Height: info.confirmedHeight()})
+		}
+	})
+	return utxos
+}
+
+// Balance 脚本余额: confirmed 为链上收到减去链上花费, unconfirmed 为内存池中的变化(可以为负)
+func (c *Chain) Balance(pkScript []byte) (confirmed, unconfirmed int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eachTx(func(info *txInfo) {
+		delta := int64(0)
+		for _, out := range info.tx.TxOut {
+			if bytes.Equal(out.PkScript, pkScript) {
+				delta += out.Value
+			}
+		}
+		if !c.faucet[info.tx.TxHash()] {
+			for _, in := range info.tx.TxIn {
+				if out := c.v.outs[in.PreviousOutPoint]; out != nil && bytes.Equal(out.PkScript, pkScript) {
+					delta -= out.Value
+				}
+			}
+		}
+		if !info.mempool {
+			confirmed += delta
+		} else {
+			unconfirmed += delta
+		}
+	})
+	return confirmed, unconfirmed
+}
+
+// mempool.space 地址接口的 chain_stats/mempool_stats
+type scriptStats struct {
+	FundedTxoCount int   `json:"funded_txo_count"`
+	FundedTxoSum   int64 `json:"funded_txo_sum"`
+	SpentTxoCount  int   `json:"spent_txo_count"`
+	SpentTxoSum    int64 `json:"spent_txo_sum"`
+	TxCount        int   `json:"tx_count"`
+}
+
+// 脚本在链上与内存池中的收支统计
+func (c *Chain) stats(pkScript []byte) (chain, mempool scriptStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eachTx(func(info *txInfo) {
+		if !c.touches(info.tx, pkScript) {
+			return
+		}
+		st := &chain
+		if info.mempool {
+			st = &mempool
+		}
+		st.TxCount++
+		for _, out := range info.tx.TxOut {
+			if bytes.Equal(out.PkScript, pkScript) {
+				st.FundedTxoCount++
+				st.FundedTxoSum += out.Value
+			}
+		}
+		if c.faucet[info.tx.TxHash()] {
+			return
+		}
+		for _, in := range info.tx.TxIn {
+			if out := c.v.outs[in.PreviousOutPoint]; out != nil && bytes.Equal(out.PkScript, pkScript) {
+				st.SpentTxoCount++
+				st.SpentTxoSum += out.Value
+			}
+		}
+	})
+	return chain, mempool
+}
+
+// 按 scripthash 查找出现过的锁定脚本, hash 为 ScriptHash(Electrum) 或 EsploraScriptHash
+func (c *Chain) scriptByHash(scriptHash string, hash func(pkScript []byte) string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, out := range c.v.outs {
+		if hash(out.PkScript) == scriptHash {
+			return out.PkScript, true
+		}
+	}
+	return nil, false
+}
+
+// ScriptHash Electrum 协议的 scripthash: sha256(pkScript) 字节逆序的 hex
+func ScriptHash(pkScript []byte) string {
+	sum := sha256.Sum256(pkScript)
+	for i, j := 0, len(sum)-1; i < j; i, j = i+1, j-1 {
+		sum[i], sum[j] = sum[j], sum[i]
+	}
+	return hex.EncodeToString(sum[:])
+}
+
+// EsploraScriptHash Esplora/mempool.space /scripthash 接口的 scripthash: sha256(pkScript) 自然字节序的 hex
+func EsploraScriptHash(pkScript []byte) string {
+	sum := sha256.Sum256(pkScript)
+	return hex.EncodeToString(sum[:])
+}
+
+// History 与脚本相关的交易, 已确认的在前
+func (c *Chain) History(pkScript []byte) []HistoryEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entries []HistoryEntry
+	c.eachTx(func(info *txInfo) {
+		if c.touches(info.tx, pkScript) {
+			entries = append(entries, HistoryEntry{Txid: info.tx.TxHash(), Height: info.confirmedHeight(), Fee: info.fee})
+		}
+	})
+	return entries
+}
+
+func (c *Chain) touches(tx *wire.MsgTx, pkScript []byte) bool {
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, pkScript) {
+			return true
+		}
+	}
+	if c.faucet[tx.TxHash()] {
+		return false
+	}
+	for _, in := range tx.TxIn {
+		if out := c.v.outs[in.PreviousOutPoint]; out != nil && bytes.Equal(out.PkScript, pkScript) {
+			return true
+		}
+	}
+	return false
+}
+
+// 按链上顺序遍历交易, 最后是内存池
+func (c *Chain) eachTx(fn func(info *txInfo)) {
+	for _, blk := range c.blocks {
+		for _, tx := range blk.Transactions {
+			fn(c.v.txs[tx.TxHash()])
+		}
+	}
+	for _, tx := range c.mempool {
+		fn(c.v.txs[tx.TxHash()])
+	}
+}
+
+func (c *Chain) status(info *txInfo) TxStatus {
+	if info.mempool {
+		return TxStatus{}
+	}
+	blk := c.blocks[info.height]
+	return TxStatus{Confirmed: true, Height: info.height, BlockHash: blk.BlockHash(), BlockTime: blk.Header.Timestamp.Unix()}
+}
+
+// 校验交易并放入内存池
+func (c *Chain) accept(tx *wire.MsgTx) (chainhash.Hash, error) {
+	txid := tx.TxHash()
+	if info := c.v.txs[txid]; info != nil {
+		if !info.mempool {
+			return chainhash.Hash{}, &RejectError{Code: -27, Reason: "Transaction already in block chain"}
+		}
+		return txid, nil
+	}
+	if err := c.validate(tx); err != nil {
+		return chainhash.Hash{}, err
+	}
+	if c.hook != nil && !c.faucet[txid] {
+		if err := c.hook(tx); err != nil {
+			return chainhash.Hash{}, err
+		}
+	}
+	c.mempool = append(c.mempool, tx)
+	c.rebuild()
+	return txid, nil
+}
+
+func (c *Chain) validate(tx *wire.MsgTx) error {
+	if len(tx.TxIn) == 0 {
+		return &RejectError{Code: -26, Reason: "bad-txns-vin-empty"}
+	}
+	if len(tx.TxOut) == 0 {
+		return &RejectError{Code: -26, Reason: "bad-txns-vout-empty"}
+	}
+	if c.faucet[tx.TxHash()] {
+		return nil
+	}
+
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(tx.TxIn))
+	var in, out int64
+	for _, txIn := range tx.TxIn {
+		op := txIn.PreviousOutPoint
+		if spender, spent := c.v.spender[op]; spent {
+			if c.v.txs[spender].mempool {
+				return &RejectError{Code: -26, Reason: "txn-mempool-conflict"}
+			}
+			return &RejectError{Code: -25, Reason: "bad-txns-inputs-missingorspent"}
+		}
+		prev := c.v.outs[op]
+		if prev == nil {
+			return &RejectError{Code: -25, Reason: "bad-txns-inputs-missingorspent"}
+		}
+		prevOuts[op] = prev
+		in += prev.Value
+	}
+	for _, txOut := range tx.TxOut {
+		out += txOut.Value
+	}
+	if in < out {
+		return &RejectError{Code: -26, Reason: "bad-txns-in-belowout"}
+	}
+
+	fetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	for i, txIn := range tx.TxIn {
+		prev := prevOuts[txIn.PreviousOutPoint]
+		vm, err := txscript.NewEngine(prev.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prev.Value, fetcher)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return &RejectError{Code: -26, Reason: fmt.Sprintf("mandatory-script-verify-flag-failed (%v)", err)}
+		}
+	}
+	return nil
+}
+
+func (c *Chain) mine(n int, pkScript []byte) []chainhash.Hash {
+	hashes := make([]chainhash.Hash, 0, n)
+	for i := 0; i < n; i++ {
+		height := len(c.blocks)
+		var fees int64
+		for _, tx := range c.mempool {
+			fees += c.v.txs[tx.TxHash()].fee
+		}
+		txs := append([]*wire.MsgTx{c.coinbase(height, fees, pkScript)}, c.mempool...)
+		blk := &wire.MsgBlock{
+			Header: wire.BlockHeader{
+				Version:    0x20000000,
+				PrevBlock:  c.blocks[height-1].BlockHash(),
+				MerkleRoot: merkleRoot(txs),
+				Timestamp:  c.params.GenesisBlock.Header.Timestamp.Add(time.Duration(height) * 10 * time.Minute),
+				Bits:       c.params.PowLimitBits,
+				Nonce:      uint32(c.forks),
+			},
+			Transactions: txs,
+		}
+		c.blocks = append(c.blocks, blk)
+		c.mempool = nil
+		c.rebuild()
+		hashes = append(hashes, blk.BlockHash())
+	}
+	return hashes
+}
+
+// coinbase 的输入脚本包含高度与重组次数, 保证不同分叉上的区块哈希不同
+func (c *Chain) coinbase(height int, fees int64, pkScript []byte) *wire.MsgTx {
+	sigScript, _ := txscript.NewScriptBuilder().AddInt64(int64(height)).AddInt64(int64(c.forks)).Script()
+	subsidy := int64(50*btcutil.SatoshiPerBitcoin) >> uint(int32(height)/c.params.SubsidyReductionInterval)
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  sigScript,
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(wire.NewTxOut(subsidy+fees, pkScript))
+	return tx
+}
+
+func (c *Chain) rebuild() {
+	v := &view{
+		txs:     make(map[chainhash.Hash]*txInfo),
+		outs:    make(map[wire.OutPoint]*wire.TxOut),
+		spender: make(map[wire.OutPoint]chainhash.Hash),
+	}
+	add := func(tx *wire.MsgTx, height int, coinbase bool) {
+		txid := tx.TxHash()
+		info := &txInfo{tx: tx, height: height, mempool: height < 0}
+		if !coinbase && !c.faucet[txid] {
+			for _, in := range tx.TxIn {
+				if prev := v.outs[in.PreviousOutPoint]; prev != nil {
+					info.fee += prev.Value
+				}
+				v.spender[in.PreviousOutPoint] = txid
+			}
+			for _, out := range tx.TxOut {
+				info.fee -= out.Value
+			}
+		}
+		for i, out := range tx.TxOut {
+			v.outs[wire.OutPoint{Hash: txid, Index: uint32(i)}] = out
+		}
+		v.txs[txid] = info
+	}
+	for h, blk := range c.blocks {
+		for i, tx := range blk.Transactions {
+			add(tx, h, i == 0)
+		}
+	}
+	for _, tx := range c.mempool {
+		add(tx, -1, false)
+	}
+	c.v = v
+}
+
+func merkleRoot(txs []*wire.MsgTx) chainhash.Hash {
+	utxs := make([]*btcutil.Tx, len(txs))
+	for i, tx := range txs {
+		utxs[i] = btcutil.NewTx(tx)
+	}
+	return blockchain.CalcMerkleRoot(utxs, false)
+}
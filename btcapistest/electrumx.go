@@ -0,0 +1,274 @@
+package btcapistest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// ElectrumX 模拟 HTTP 方式访问的 ElectrumX JSON-RPC, 数据来自 Chain.
+// 支持的方法见 NewElectrumX; 与 Bitcoind 相同, 可以用 Handle 补充或覆盖方法.
+type ElectrumX struct {
+	*httptest.Server
+	*rpcServer
+	chain *Chain
+}
+
+// NewElectrumX 启动模拟服务, 使用完后调用 Close. 内置方法:
+// blockchain.scripthash.get_balance/get_history/listunspent/get_mempool/subscribe,
+// blockchain.transaction.get/broadcast, blockchain.block.header/headers, blockchain.headers.subscribe,
+// blockchain.estimatefee, blockchain.relayfee, server.version/features/ping/banner.
+func NewElectrumX(chain *Chain) *ElectrumX {
+	e := &ElectrumX{chain: chain}
+	e.rpcServer = newRPCServer(map[string]RPCHandler{
+		"blockchain.scripthash.get_balance": e.getBalance,
+		"blockchain.scripthash.get_history": e.getHistory,
+		"blockchain.scripthash.listunspent": e.listUnspent,
+		"blockchain.scripthash.get_mempool": e.getMempool,
+		"blockchain.scripthash.subscribe":   e.subscribe,
+		"blockchain.transaction.get":        e.transactionGet,
+		"blockchain.transaction.broadcast":  e.broadcast,
+		"blockchain.block.header":           e.blockHeader,
+		"blockchain.block.headers":          e.blockHeaders,
+		"blockchain.headers.subscribe":      e.headersSubscribe,
+		"blockchain.estimatefee":            e.estimateFee,
+		"blockchain.relayfee":               e.relayFee,
+		"server.version":                    e.serverVersion,
+		"server.features":                   e.serverFeatures,
+		"server.ping":                       e.ping,
+		"server.banner":                     e.banner,
+	})
+	e.Server = httptest.NewServer(http.HandlerFunc(e.serve))
+	return e
+}
+
+// ElectrumX 的参数错误码为 1
+func badRequest(format string, args ...any) error {
+	return &RejectError{Code: 1, Reason: fmt.Sprintf(format, args...)}
+}
+
+// 参数中的 scripthash 对应的锁定脚本; 没有出现过的脚本返回 nil
+func (e *ElectrumX) script(params []json.RawMessage) ([]byte, error) {
+	sh, err := paramString(params, 0)
+	if err != nil || len(sh) != 64 {
+		return nil, badRequest("%q is not a valid script hash", sh)
+	}
+	script, _ := e.chain.scriptByHash(sh, ScriptHash)
+	return script, nil
+}
+
+func (e *ElectrumX) getBalance(params []json.RawMessage) (any, error) {
+	script, err := e.script(params)
+	if err != nil {
+		return nil, err
+	}
+	var confirmed, unconfirmed int64
+	if script != nil {
+		confirmed, unconfirmed = e.chain.Balance(script)
+	}
+	return map[string]int64{"confirmed": confirmed, "unconfirmed": unconfirmed}, nil
+}
+
+// 已确认的按高度排列, 之后是内存池交易(高度 0, 带手续费)
+func (e *ElectrumX) history(params []json.RawMessage, mempoolOnly bool) ([]map[string]any, error) {
+	script, err := e.script(params)
+	if err != nil {
+		return nil, err
+	}
+	entries := []map[string]any{}
+	if script == nil {
+		return entries, nil
+	}
+	for _, h := range e.chain.History(script) {
+		_, status, _ := e.chain.Tx(h.Txid)
+		if status.Confirmed {
+			if !mempoolOnly {
+				entries = append(entries, map[string]any{"tx_hash": h.Txid.String(), "height": status.Height})
+			}
+			continue
+		}
+		entries = append(entries, map[string]any{"tx_hash": h.Txid.String(), "height": 0, "fee": h.Fee})
+	}
+	return entries, nil
+}
+
+func (e *ElectrumX) getHistory(params []json.RawMessage) (any, error) {
+	return e.history(params, false)
+}
+
+func (e *ElectrumX) getMempool(params []json.RawMessage) (any, error) {
+	return e.history(params, true)
+}
+
+func (e *ElectrumX) listUnspent(params []json.RawMessage) (any, error) {
+	script, err := e.script(params)
+	if err != nil {
+		return nil, err
+	}
+	utxos := []map[string]any{}
+	if script == nil {
+		return utxos, nil
+	}
+	for _, u := range e.chain.UTXOs(script) {
+		utxos = append(utxos, map[string]any{
+			"tx_hash": u.OutPoint.Hash.String(),
+			"tx_pos":  u.OutPoint.Index,
+			"value":   u.Value,
+			"height":  u.Height,
+		})
+	}
+	return utxos, nil
+}
+
+// 状态为 "txid:height:" 依次拼接后的 sha256 hex, 没有历史时为 null
+func (e *ElectrumX) subscribe(params []json.RawMessage) (any, error) {
+	entries, err := e.history(params, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s:%d:", entry["tx_hash"], entry["height"])
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (e *ElectrumX) transactionGet(params []json.RawMessage) (any, error) {
+	s, err := paramString(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := chainhash.NewHashFromStr(s)
+	if err != nil || len(s) != 64 {
+		return nil, badRequest("%s should be a transaction hash", s)
+	}
+	tx, status, ok := e.chain.Tx(*txid)
+	if !ok {
+		return nil, &RejectError{Code: 2, Reason: "daemon error: DaemonError({'code': -5, 'message': 'No such mempool or blockchain transaction. Use gettransaction for wallet transactions.'})"}
+	}
+	if !paramBool(params, 1, false) {
+		return txHex(tx), nil
+	}
+	m := txJSON(tx, e.chain.Params())
+	if status.Confirmed {
+		m["blockhash"] = status.BlockHash.String()
+		m["confirmations"] = e.chain.Height() - status.Height + 1
+		m["blocktime"] = status.BlockTime
+		m["time"] = status.BlockTime
+	}
+	return m, nil
+}
+
+// 被拒绝时与 ElectrumX 相同, 错误码 1, 信息中包含 bitcoind 的拒绝原因
+func (e *ElectrumX) broadcast(params []json.RawMessage) (any, error) {
+	raw, err := paramString(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := e.chain.SendRawTxHex(raw)
+	if err != nil {
+		return nil, badRequest("the transaction was rejected by network rules.\n\n%s\n[%s]", err.Error(), raw)
+	}
+	return txid.String(), nil
+}
+
+func (e *ElectrumX) headerHex(height int) (string, error) {
+	hash, ok := e.chain.BlockHash(height)
+	if !ok {
+		return "", badRequest("height %d out of range", height)
+	}
+	blk, _, _ := e.chain.Block(hash)
+	return hex.EncodeToString(headerBytes(&blk.Header)), nil
+}
+
+func (e *ElectrumX) blockHeader(params []json.RawMessage) (any, error) {
+	height, err := paramInt(params, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	return e.headerHex(height)
+}
+
+// 最多返回 2016 个区块头, 依次拼接
+func (e *ElectrumX) blockHeaders(params []json.RawMessage) (any, error) {
+	start, err := paramInt(params, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	count, err := paramInt(params, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || count < 0 {
+		return nil, badRequest("invalid start height or count")
+	}
+	const max = 2016
+	if count > max {
+		count = max
+	}
+	var b strings.Builder
+	n := 0
+	for h := start; n < count && h <= e.chain.Height(); h++ {
+		s, err := e.headerHex(h)
+		if err != nil {
+			break
+		}
+		b.WriteString(s)
+		n++
+	}
+	return map[string]any{"count": n, "hex": b.String(), "max": max}, nil
+}
+
+func (e *ElectrumX) headersSubscribe(params []json.RawMessage) (any, error) {
+	height := e.chain.Height()
+	s, err := e.headerHex(height)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"height": height, "hex": s}, nil
+}
+
+// 单位为 BTC/kB
+func (e *ElectrumX) estimateFee(params []json.RawMessage) (any, error) {
+	return e.chain.FeeRate() * 1000 / btcutil.SatoshiPerBitcoin, nil
+}
+
+func (e *ElectrumX) relayFee(params []json.RawMessage) (any, error) {
+	return 0.00001, nil
+}
+
+func (e *ElectrumX) serverVersion(params []json.RawMessage) (any, error) {
+	return []string{"ElectrumX 1.16.0", "1.4"}, nil
+}
+
+func (e *ElectrumX) serverFeatures(params []json.RawMessage) (any, error) {
+	genesis, _ := e.chain.BlockHash(0)
+	return map[string]any{
+		"genesis_hash":   genesis.String(),
+		"hosts":          map[string]any{},
+		"protocol_max":   "1.4",
+		"protocol_min":   "1.4",
+		"pruning":        nil,
+		"server_version": "ElectrumX 1.16.0",
+		"hash_function":  "sha256",
+	}, nil
+}
+
+func (e *ElectrumX) ping(params []json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (e *ElectrumX) banner(params []json.RawMessage) (any, error) {
+	return "btcapistest ElectrumX", nil
+}
@@ -0,0 +1,144 @@
+package btcapistest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func txHex(tx *wire.MsgTx) string {
+	var buf bytes.Buffer
+	tx.Serialize(&buf)
+	return hex.EncodeToString(buf.Bytes())
+}
+
+func blockHex(blk *wire.MsgBlock) string {
+	var buf bytes.Buffer
+	blk.Serialize(&buf)
+	return hex.EncodeToString(buf.Bytes())
+}
+
+func headerBytes(h *wire.BlockHeader) []byte {
+	var buf bytes.Buffer
+	h.Serialize(&buf)
+	return buf.Bytes()
+}
+
+func vsize(tx *wire.MsgTx) int64 {
+	return (weight(tx) + 3) / 4
+}
+
+func weight(tx *wire.MsgTx) int64 {
+	return int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+}
+
+// 锁定脚本的类型名(与 mempool.space 一致)与地址
+func scriptInfo(pkScript []byte, params *chaincfg.Params) (typ, address string) {
+	class, addrs, _, _ := txscript.ExtractPkScriptAddrs(pkScript, params)
+	switch class {
+	case txscript.PubKeyHashTy:
+		typ = "p2pkh"
+	case txscript.ScriptHashTy:
+		typ = "p2sh"
+	case txscript.WitnessV0PubKeyHashTy:
+		typ = "v0_p2wpkh"
+	case txscript.WitnessV0ScriptHashTy:
+		typ = "v0_p2wsh"
+	case txscript.WitnessV1TaprootTy:
+		typ = "v1_p2tr"
+	case txscript.NullDataTy:
+		typ = "op_return"
+	default:
+		typ = "unknown"
+	}
+	if len(addrs) == 1 {
+		address = addrs[0].EncodeAddress()
+	}
+	return typ, address
+}
+
+func bitsHex(bits uint32) string {
+	s := strconv.FormatUint(uint64(bits), 16)
+	for len(s) < 8 {
+		s = "0" + s
+	}
+	return s
+}
+
+// 与 bitcoind 相同的难度计算: 难度 1 的目标(0x1d00ffff) / 当前目标
+func difficulty(bits uint32) float64 {
+	target := blockchain.CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return 0
+	}
+	d, _ := new(big.Float).Quo(new(big.Float).SetInt(blockchain.CompactToBig(0x1d00ffff)), new(big.Float).SetInt(target)).Float64()
+	if math.IsInf(d, 0) {
+		return 0
+	}
+	return d
+}
+
+// bitcoind getblockchaininfo 中的链名称
+func chainName(params *chaincfg.Params) string {
+	switch params.Net {
+	case wire.MainNet:
+		return "main"
+	case wire.TestNet3:
+		return "test"
+	case wire.TestNet:
+		return "regtest"
+	}
+	return params.Name
+}
+
+// 与 bitcoind decoderawtransaction 相同格式的交易
+func txJSON(tx *wire.MsgTx, params *chaincfg.Params) map[string]any {
+	vin := make([]map[string]any, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		m := map[string]any{"sequence": in.Sequence}
+		if blockchain.IsCoinBaseTx(tx) {
+			m["coinbase"] = hex.EncodeToString(in.SignatureScript)
+		} else {
+			m["txid"] = in.PreviousOutPoint.Hash.String()
+			m["vout"] = in.PreviousOutPoint.Index
+			m["scriptSig"] = map[string]any{"hex": hex.EncodeToString(in.SignatureScript)}
+		}
+		if len(in.Witness) > 0 {
+			witness := make([]string, len(in.Witness))
+			for j, w := range in.Witness {
+				witness[j] = hex.EncodeToString(w)
+			}
+			m["txinwitness"] = witness
+		}
+		vin[i] = m
+	}
+	vout := make([]map[string]any, len(tx.TxOut))
+	for i, out := range tx.TxOut {
+		typ, address := scriptInfo(out.PkScript, params)
+		spk := map[string]any{"hex": hex.EncodeToString(out.PkScript), "type": typ}
+		if address != "" {
+			spk["address"] = address
+		}
+		vout[i] = map[string]any{"value": btcutil.Amount(out.Value).ToBTC(), "n": i, "scriptPubKey": spk}
+	}
+	return map[string]any{
+		"txid":     tx.TxHash().String(),
+		"hash":     tx.WitnessHash().String(),
+		"version":  tx.Version,
+		"size":     tx.SerializeSize(),
+		"vsize":    vsize(tx),
+		"weight":   weight(tx),
+		"locktime": tx.LockTime,
+		"vin":      vin,
+		"vout":     vout,
+		"hex":      txHex(tx),
+	}
+}
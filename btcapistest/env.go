@@ -0,0 +1,55 @@
+package btcapistest
+
+import (
+	"testing"
+
+	"github.com/crazycloudcc/btcapis"
+)
+
+// Env 一条 regtest Chain 与连接到它的三个假服务
+type Env struct {
+	Chain     *Chain
+	Bitcoind  *Bitcoind
+	Mempool   *MempoolSpace
+	ElectrumX *ElectrumX
+}
+
+// NewEnv 创建 regtest 链并启动 bitcoind, mempool.space 与 ElectrumX 假服务, 测试结束时自动关闭
+func NewEnv(t testing.TB) *Env {
+	t.Helper()
+	chain := NewChain(nil)
+	env := &Env{
+		Chain:     chain,
+		Bitcoind:  NewBitcoind(chain),
+		Mempool:   NewMempoolSpace(chain),
+		ElectrumX: NewElectrumX(chain),
+	}
+	t.Cleanup(env.Close)
+	return env
+}
+
+// Close 关闭全部假服务
+func (e *Env) Close() {
+	e.Bitcoind.Close()
+	e.Mempool.Close()
+	e.ElectrumX.Close()
+}
+
+// Config 指向假服务的 regtest 配置, 可以在此基础上修改后传给 btcapis.New
+func (e *Env) Config() *btcapis.Config {
+	return &btcapis.Config{
+		Network:         "regtest",
+		Timeout:         5,
+		RPCUrl:          e.Bitcoind.URL,
+		RPCUser:         "btcapistest",
+		RPCPass:         "btcapistest",
+		MempoolSpaceUrl: e.Mempool.URL,
+		ElectrumXUrl:    e.ElectrumX.URL,
+	}
+}
+
+// Client 使用 Config 创建客户端.
+// 注意 btcapis.New 会设置全局的网络, 使用 Env 的测试不要并行运行.
+func (e *Env) Client() *btcapis.Client {
+	return btcapis.New(e.Config())
+}
@@ -0,0 +1,151 @@
+package btcapistest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Key 测试用私钥, 由种子确定性生成; 可以作为 P2PKH, P2WPKH 与 P2TR(key path) 地址使用
+type Key struct {
+	Priv   *btcec.PrivateKey
+	params *chaincfg.Params
+}
+
+// NewKey 由 seed 生成私钥(sha256(seed)), params 为空时使用 regtest
+func NewKey(seed string, params *chaincfg.Params) *Key {
+	if params == nil {
+		params = &chaincfg.RegressionNetParams
+	}
+	sum := sha256.Sum256([]byte(seed))
+	priv, _ := btcec.PrivKeyFromBytes(sum[:])
+	return &Key{Priv: priv, params: params}
+}
+
+// WIF 压缩公钥格式的 WIF
+func (k *Key) WIF() string {
+	wif, _ := btcutil.NewWIF(k.Priv, k.params, true)
+	return wif.String()
+}
+
+// P2PKH 地址
+func (k *Key) P2PKH() string {
+	addr, _ := btcutil.NewAddressPubKeyHash(btcutil.Hash160(k.Priv.PubKey().SerializeCompressed()), k.params)
+	return addr.EncodeAddress()
+}
+
+// P2WPKH 地址
+func (k *Key) P2WPKH() string {
+	addr, _ := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(k.Priv.PubKey().SerializeCompressed()), k.params)
+	return addr.EncodeAddress()
+}
+
+// P2TR key path 地址, 没有脚本树
+func (k *Key) P2TR() string {
+	outputKey := txscript.ComputeTaprootKeyNoScript(k.Priv.PubKey())
+	addr, _ := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), k.params)
+	return addr.EncodeAddress()
+}
+
+// 三种地址对应的锁定脚本
+func (k *Key) scripts() (p2pkh, p2wpkh, p2tr []byte) {
+	for i, a := range []string{k.P2PKH(), k.P2WPKH(), k.P2TR()} {
+		addr, _ := btcutil.DecodeAddress(a, k.params)
+		script, _ := txscript.PayToAddrScript(addr)
+		switch i {
+		case 0:
+			p2pkh = script
+		case 1:
+			p2wpkh = script
+		case 2:
+			p2tr = script
+		}
+	}
+	return
+}
+
+// SignPSBT 使用 keys 签名 PSBT 中属于这些私钥的输入(P2PKH, P2WPKH, P2TR key path), 返回签名后的 base64;
+// 模拟钱包签名, 之后交给 FinalizePSBTAndBroadcast. 输入需要带有 WitnessUtxo 或 NonWitnessUtxo.
+func SignPSBT(psbtBase64 string, keys ...*Key) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(psbtBase64)
+	if err != nil {
+		return "", fmt.Errorf("decode psbt base64: %w", err)
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(raw), false)
+	if err != nil {
+		return "", fmt.Errorf("parse psbt: %w", err)
+	}
+	tx := packet.UnsignedTx
+
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(tx.TxIn))
+	for i, in := range packet.Inputs {
+		prev, err := prevOut(tx, in, i)
+		if err != nil {
+			return "", err
+		}
+		prevOuts[tx.TxIn[i].PreviousOutPoint] = prev
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	signed := 0
+	for i := range packet.Inputs {
+		in := &packet.Inputs[i]
+		prev := prevOuts[tx.TxIn[i].PreviousOutPoint]
+		for _, k := range keys {
+			p2pkh, p2wpkh, p2tr := k.scripts()
+			pub := k.Priv.PubKey().SerializeCompressed()
+			switch {
+			case bytes.Equal(prev.PkScript, p2pkh):
+				sig, err := txscript.RawTxInSignature(tx, i, prev.PkScript, txscript.SigHashAll, k.Priv)
+				if err != nil {
+					return "", err
+				}
+				in.PartialSigs = append(in.PartialSigs, &psbt.PartialSig{PubKey: pub, Signature: sig})
+			case bytes.Equal(prev.PkScript, p2wpkh):
+				sig, err := txscript.RawTxInWitnessSignature(tx, sigHashes, i, prev.Value, prev.PkScript, txscript.SigHashAll, k.Priv)
+				if err != nil {
+					return "", err
+				}
+				in.PartialSigs = append(in.PartialSigs, &psbt.PartialSig{PubKey: pub, Signature: sig})
+			case bytes.Equal(prev.PkScript, p2tr):
+				sig, err := txscript.RawTxInTaprootSignature(tx, sigHashes, i, prev.Value, prev.PkScript, nil, txscript.SigHashDefault, k.Priv)
+				if err != nil {
+					return "", err
+				}
+				in.TaprootKeySpendSig = sig
+				in.TaprootInternalKey = schnorr.SerializePubKey(k.Priv.PubKey())
+			default:
+				continue
+			}
+			signed++
+		}
+	}
+	if signed == 0 {
+		return "", errors.New("btcapistest: no input belongs to the given keys")
+	}
+	return packet.B64Encode()
+}
+
+func prevOut(tx *wire.MsgTx, in psbt.PInput, i int) (*wire.TxOut, error) {
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo, nil
+	}
+	if in.NonWitnessUtxo != nil {
+		idx := tx.TxIn[i].PreviousOutPoint.Index
+		if int(idx) < len(in.NonWitnessUtxo.TxOut) {
+			return in.NonWitnessUtxo.TxOut[idx], nil
+		}
+	}
+	return nil, fmt.Errorf("btcapistest: psbt input %d has no utxo", i)
+}
@@ -0,0 +1,559 @@
+package btcapistest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// 地址/区块交易列表每页条数, 与 mempool.space 一致
+const pageSize = 25
+
+// MempoolSpace 模拟 mempool.space REST API(/api/...), 数据来自 Chain.
+// 同时提供 Esplora 的 /api/blocks, /api/fee-estimates 与 /api/scripthash/..., URL+"/api" 可以作为 Config.EsploraUrl.
+type MempoolSpace struct {
+	*httptest.Server
+	chain *Chain
+
+	mux       *http.ServeMux
+	mu        sync.Mutex
+	overrides *http.ServeMux
+	calls     map[string]int
+}
+
+// NewMempoolSpace 启动模拟服务, 使用完后调用 Close
+func NewMempoolSpace(chain *Chain) *MempoolSpace {
+	m := &MempoolSpace{chain: chain, mux: http.NewServeMux(), overrides: http.NewServeMux(), calls: make(map[string]int)}
+
+	m.mux.HandleFunc("GET /api/address/{addr}", m.address)
+	m.mux.HandleFunc("GET /api/address/{addr}/utxo", m.addressUTXOs)
+	m.mux.HandleFunc("GET /api/address/{addr}/txs/chain", m.addressTxsChain)
+	m.mux.HandleFunc("GET /api/address/{addr}/txs/chain/{after}", m.addressTxsChain)
+	m.mux.HandleFunc("GET /api/address/{addr}/txs/mempool", m.addressTxsMempool)
+	m.mux.HandleFunc("GET /api/scripthash/{sh}", m.address)
+	m.mux.HandleFunc("GET /api/scripthash/{sh}/utxo", m.addressUTXOs)
+	m.mux.HandleFunc("GET /api/scripthash/{sh}/txs/chain", m.addressTxsChain)
+	m.mux.HandleFunc("GET /api/scripthash/{sh}/txs/chain/{after}", m.addressTxsChain)
+	m.mux.HandleFunc("GET /api/scripthash/{sh}/txs/mempool", m.addressTxsMempool)
+
+	m.mux.HandleFunc("GET /api/tx/{txid}", m.tx)
+	m.mux.HandleFunc("GET /api/tx/{txid}/hex", m.txHex)
+	m.mux.HandleFunc("GET /api/tx/{txid}/status", m.txStatus)
+	m.mux.HandleFunc("GET /api/tx/{txid}/outspends", m.txOutspends)
+	m.mux.HandleFunc("GET /api/tx/{txid}/outspend/{vout}", m.txOutspend)
+	m.mux.HandleFunc("POST /api/tx", m.broadcast)
+
+	m.mux.HandleFunc("GET /api/v1/fees/recommended", m.feesRecommended)
+	m.mux.HandleFunc("GET /api/fee-estimates", m.feeEstimates)
+
+	m.mux.HandleFunc("GET /api/blocks/tip/height", m.tipHeight)
+	m.mux.HandleFunc("GET /api/blocks/tip/hash", m.tipHash)
+	m.mux.HandleFunc("GET /api/block-height/{height}", m.blockHeight)
+	m.mux.HandleFunc("GET /api/block/{hash}", m.block)
+	m.mux.HandleFunc("GET /api/block/{hash}/header", m.blockHeader)
+	m.mux.HandleFunc("GET /api/block/{hash}/raw", m.blockRaw)
+	m.mux.HandleFunc("GET /api/block/{hash}/status", m.blockStatus)
+	m.mux.HandleFunc("GET /api/block/{hash}/txids", m.blockTxids)
+	m.mux.HandleFunc("GET /api/block/{hash}/txs", m.blockTxs)
+	m.mux.HandleFunc("GET /api/block/{hash}/txs/{start}", m.blockTxs)
+	m.mux.HandleFunc("GET /api/v1/blocks", m.blocks)
+	m.mux.HandleFunc("GET /api/v1/blocks/{height}", m.blocks)
+	m.mux.HandleFunc("GET /api/blocks", m.blocks)
+	m.mux.HandleFunc("GET /api/blocks/{height}", m.blocks)
+
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serve))
+	return m
+}
+
+// Handle 注册自定义接口, pattern 与 http.ServeMux 相同(如 "GET /api/v1/fees/recommended"),
+// 优先于内置接口, 用于模拟限流, 服务错误或补充未实现的接口
+func (m *MempoolSpace) Handle(pattern string, h http.HandlerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides.HandleFunc(pattern, h)
+}
+
+// Calls 路径(不含查询参数)被请求的次数
+func (m *MempoolSpace) Calls(path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[path]
+}
+
+func (m *MempoolSpace) serve(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.calls[r.URL.Path]++
+	h, pattern := m.overrides.Handler(r)
+	m.mu.Unlock()
+	if pattern != "" {
+		h.ServeHTTP(w, r)
+		return
+	}
+	m.mux.ServeHTTP(w, r)
+}
+
+func writeText(w http.ResponseWriter, status int, s string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	io.WriteString(w, s)
+}
+
+// 路径中的地址或 scripthash 对应的锁定脚本
+func (m *MempoolSpace) pkScript(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if sh := r.PathValue("sh"); sh != "" {
+		script, ok := m.chain.scriptByHash(sh, EsploraScriptHash)
+		if !ok {
+			// 没有出现过的脚本, 返回空结果
+			return nil, true
+		}
+		return script, true
+	}
+	addr, err := btcutil.DecodeAddress(r.PathValue("addr"), m.chain.Params())
+	if err != nil {
+		writeText(w, http.StatusBadRequest, "Invalid Bitcoin address")
+		return nil, false
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		writeText(w, http.StatusBadRequest, "Invalid Bitcoin address")
+		return nil, false
+	}
+	return script, true
+}
+
+func (m *MempoolSpace) txHash(w http.ResponseWriter, r *http.Request) (chainhash.Hash, bool) {
+	s := r.PathValue("txid")
+	h, err := chainhash.NewHashFromStr(s)
+	if err != nil || len(s) != 64 {
+		writeText(w, http.StatusBadRequest, "Invalid hex string")
+		return chainhash.Hash{}, false
+	}
+	return *h, true
+}
+
+func (m *MempoolSpace) blockHash(w http.ResponseWriter, r *http.Request) (*wire.MsgBlock, int, bool) {
+	s := r.PathValue("hash")
+	h, err := chainhash.NewHashFromStr(s)
+	if err != nil || len(s) != 64 {
+		writeText(w, http.StatusBadRequest, "Invalid hex string")
+		return nil, 0, false
+	}
+	blk, height, ok := m.chain.Block(*h)
+	if !ok {
+		writeText(w, http.StatusNotFound, "Block not found")
+		return nil, 0, false
+	}
+	return blk, height, true
+}
+
+func (m *MempoolSpace) address(w http.ResponseWriter, r *http.Request) {
+	script, ok := m.pkScript(w, r)
+	if !ok {
+		return
+	}
+	chainStats, mempoolStats := m.chain.stats(script)
+	resp := map[string]any{"chain_stats": chainStats, "mempool_stats": mempoolStats}
+	if addr := r.PathValue("addr"); addr != "" {
+		resp["address"] = addr
+	} else {
+		resp["scripthash"] = r.PathValue("sh")
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (m *MempoolSpace) addressUTXOs(w http.ResponseWriter, r *http.Request) {
+	script, ok := m.pkScript(w, r)
+	if !ok {
+		return
+	}
+	utxos := []map[string]any{}
+	if script != nil {
+		for _, u := range m.chain.UTXOs(script) {
+			utxos = append(utxos, map[string]any{
+				"txid":   u.OutPoint.Hash.String(),
+				"vout":   u.OutPoint.Index,
+				"value":  u.Value,
+				"status": m.statusJSON(m.txStatusOf(u.OutPoint.Hash)),
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, utxos)
+}
+
+// 已确认交易, 最新的在前, 每页 25 条; after 为上一页最后一个 txid
+func (m *MempoolSpace) addressTxsChain(w http.ResponseWriter, r *http.Request) {
+	script, ok := m.pkScript(w, r)
+	if !ok {
+		return
+	}
+	var history []HistoryEntry
+	if script != nil {
+		history = m.chain.History(script)
+	}
+	var txids []chainhash.Hash
+	for i := len(history) - 1; i >= 0; i-- {
+		if m.isConfirmed(history[i].Txid) {
+			txids = append(txids, history[i].Txid)
+		}
+	}
+	if after := r.PathValue("after"); after != "" {
+		for i, txid := range txids {
+			if txid.String() == after {
+				txids = txids[i+1:]
+				break
+			}
+		}
+	}
+	if len(txids) > pageSize {
+		txids = txids[:pageSize]
+	}
+	m.writeTxs(w, txids)
+}
+
+// 未确认交易, 最新的在前, 最多 50 条
+func (m *MempoolSpace) addressTxsMempool(w http.ResponseWriter, r *http.Request) {
+	script, ok := m.pkScript(w, r)
+	if !ok {
+		return
+	}
+	var history []HistoryEntry
+	if script != nil {
+		history = m.chain.History(script)
+	}
+	var txids []chainhash.Hash
+	for i := len(history) - 1; i >= 0 && len(txids) < 50; i-- {
+		if !m.isConfirmed(history[i].Txid) {
+			txids = append(txids, history[i].Txid)
+		}
+	}
+	m.writeTxs(w, txids)
+}
+
+func (m *MempoolSpace) isConfirmed(txid chainhash.Hash) bool {
+	return m.txStatusOf(txid).Confirmed
+}
+
+func (m *MempoolSpace) txStatusOf(txid chainhash.Hash) TxStatus {
+	_, status, _ := m.chain.Tx(txid)
+	return status
+}
+
+func (m *MempoolSpace) writeTxs(w http.ResponseWriter, txids []chainhash.Hash) {
+	txs := make([]map[string]any, 0, len(txids))
+	for _, txid := range txids {
+		if tx, status, ok := m.chain.Tx(txid); ok {
+			txs = append(txs, m.txJSON(tx, status))
+		}
+	}
+	writeJSON(w, http.StatusOK, txs)
+}
+
+func (m *MempoolSpace) statusJSON(s TxStatus) map[string]any {
+	if !s.Confirmed {
+		return map[string]any{"confirmed": false}
+	}
+	return map[string]any{"confirmed": true, "block_height": s.Height, "block_hash": s.BlockHash.String(), "block_time": s.BlockTime}
+}
+
+func (m *MempoolSpace) voutJSON(out *wire.TxOut) map[string]any {
+	typ, address := scriptInfo(out.PkScript, m.chain.Params())
+	v := map[string]any{"scriptpubkey": hex.EncodeToString(out.PkScript), "scriptpubkey_type": typ, "value": out.Value}
+	if address != "" {
+		v["scriptpubkey_address"] = address
+	}
+	return v
+}
+
+// 与 mempool.space /api/tx/:txid 相同格式的交易
+func (m *MempoolSpace) txJSON(tx *wire.MsgTx, status TxStatus) map[string]any {
+	coinbase := blockchain.IsCoinBaseTx(tx)
+	vin := make([]map[string]any, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		witness := make([]string, len(in.Witness))
+		for j, w := range in.Witness {
+			witness[j] = hex.EncodeToString(w)
+		}
+		v := map[string]any{
+			"txid":        in.PreviousOutPoint.Hash.String(),
+			"vout":        in.PreviousOutPoint.Index,
+			"prevout":     nil,
+			"scriptsig":   hex.EncodeToString(in.SignatureScript),
+			"witness":     witness,
+			"is_coinbase": coinbase,
+			"sequence":    in.Sequence,
+		}
+		if prev, ok := m.chain.TxOut(in.PreviousOutPoint); ok && !coinbase {
+			v["prevout"] = m.voutJSON(prev)
+		}
+		vin[i] = v
+	}
+	vout := make([]map[string]any, len(tx.TxOut))
+	for i, out := range tx.TxOut {
+		vout[i] = m.voutJSON(out)
+	}
+	fee, _ := m.chain.TxFee(tx.TxHash())
+	return map[string]any{
+		"txid":     tx.TxHash().String(),
+		"version":  tx.Version,
+		"locktime": tx.LockTime,
+		"vin":      vin,
+		"vout":     vout,
+		"size":     tx.SerializeSize(),
+		"weight":   weight(tx),
+		"fee":      fee,
+		"status":   m.statusJSON(status),
+	}
+}
+
+func (m *MempoolSpace) lookupTx(w http.ResponseWriter, r *http.Request) (*wire.MsgTx, TxStatus, bool) {
+	txid, ok := m.txHash(w, r)
+	if !ok {
+		return nil, TxStatus{}, false
+	}
+	tx, status, ok := m.chain.Tx(txid)
+	if !ok {
+		writeText(w, http.StatusNotFound, "Transaction not found")
+		return nil, TxStatus{}, false
+	}
+	return tx, status, true
+}
+
+func (m *MempoolSpace) tx(w http.ResponseWriter, r *http.Request) {
+	if tx, status, ok := m.lookupTx(w, r); ok {
+		writeJSON(w, http.StatusOK, m.txJSON(tx, status))
+	}
+}
+
+func (m *MempoolSpace) txHex(w http.ResponseWriter, r *http.Request) {
+	if tx, _, ok := m.lookupTx(w, r); ok {
+		writeText(w, http.StatusOK, txHex(tx))
+	}
+}
+
+func (m *MempoolSpace) txStatus(w http.ResponseWriter, r *http.Request) {
+	if _, status, ok := m.lookupTx(w, r); ok {
+		writeJSON(w, http.StatusOK, m.statusJSON(status))
+	}
+}
+
+func (m *MempoolSpace) outspendJSON(op wire.OutPoint) map[string]any {
+	spender, status, ok := m.chain.Outspend(op)
+	if !ok {
+		return map[string]any{"spent": false}
+	}
+	vin := 0
+	if tx, _, ok := m.chain.Tx(spender); ok {
+		for i, in := range tx.TxIn {
+			if in.PreviousOutPoint == op {
+				vin = i
+			}
+		}
+	}
+	return map[string]any{"spent": true, "txid": spender.String(), "vin": vin, "status": m.statusJSON(status)}
+}
+
+func (m *MempoolSpace) txOutspends(w http.ResponseWriter, r *http.Request) {
+	tx, _, ok := m.lookupTx(w, r)
+	if !ok {
+		return
+	}
+	txid := tx.TxHash()
+	outspends := make([]map[string]any, len(tx.TxOut))
+	for i := range tx.TxOut {
+		outspends[i] = m.outspendJSON(wire.OutPoint{Hash: txid, Index: uint32(i)})
+	}
+	writeJSON(w, http.StatusOK, outspends)
+}
+
+func (m *MempoolSpace) txOutspend(w http.ResponseWriter, r *http.Request) {
+	tx, _, ok := m.lookupTx(w, r)
+	if !ok {
+		return
+	}
+	vout, err := strconv.Atoi(r.PathValue("vout"))
+	if err != nil || vout < 0 || vout >= len(tx.TxOut) {
+		writeText(w, http.StatusBadRequest, "Invalid vout")
+		return
+	}
+	writeJSON(w, http.StatusOK, m.outspendJSON(wire.OutPoint{Hash: tx.TxHash(), Index: uint32(vout)}))
+}
+
+// 请求体为交易 hex; 被拒绝时与 mempool.space 相同, 返回 400 与 bitcoind 的错误信息
+func (m *MempoolSpace) broadcast(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	txid, err := m.chain.SendRawTxHex(strings.TrimSpace(string(body)))
+	if err != nil {
+		e, _ := json.Marshal(toRPCError(err))
+		writeText(w, http.StatusBadRequest, fmt.Sprintf("sendrawtransaction RPC error: %s", e))
+		return
+	}
+	writeText(w, http.StatusOK, txid.String())
+}
+
+// 所有档位都使用 Chain 的费率
+func (m *MempoolSpace) feesRecommended(w http.ResponseWriter, r *http.Request) {
+	rate := m.chain.FeeRate()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"fastestFee":  rate,
+		"halfHourFee": rate,
+		"hourFee":     rate,
+		"economyFee":  rate,
+		"minimumFee":  1,
+	})
+}
+
+func (m *MempoolSpace) feeEstimates(w http.ResponseWriter, r *http.Request) {
+	rate := m.chain.FeeRate()
+	est := make(map[string]float64)
+	for _, target := range []int{1, 2, 3, 4, 5, 6, 10, 25, 144, 504, 1008} {
+		est[strconv.Itoa(target)] = rate
+	}
+	writeJSON(w, http.StatusOK, est)
+}
+
+func (m *MempoolSpace) tipHeight(w http.ResponseWriter, r *http.Request) {
+	writeText(w, http.StatusOK, strconv.Itoa(m.chain.Height()))
+}
+
+func (m *MempoolSpace) tipHash(w http.ResponseWriter, r *http.Request) {
+	writeText(w, http.StatusOK, m.chain.BestHash().String())
+}
+
+func (m *MempoolSpace) blockHeight(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.Atoi(r.PathValue("height"))
+	if err != nil {
+		writeText(w, http.StatusBadRequest, "Invalid height")
+		return
+	}
+	hash, ok := m.chain.BlockHash(height)
+	if !ok {
+		writeText(w, http.StatusNotFound, "Block not found")
+		return
+	}
+	writeText(w, http.StatusOK, hash.String())
+}
+
+func (m *MempoolSpace) blockJSON(blk *wire.MsgBlock, height int) map[string]any {
+	h := blk.Header
+	return map[string]any{
+		"id":                blk.BlockHash().String(),
+		"height":            height,
+		"version":           h.Version,
+		"timestamp":         h.Timestamp.Unix(),
+		"tx_count":          len(blk.Transactions),
+		"size":              blk.SerializeSize(),
+		"weight":            blk.SerializeSizeStripped()*3 + blk.SerializeSize(),
+		"merkle_root":       h.MerkleRoot.String(),
+		"previousblockhash": h.PrevBlock.String(),
+		"mediantime":        m.chain.medianTime(height),
+		"nonce":             h.Nonce,
+		"bits":              h.Bits,
+		"difficulty":        difficulty(h.Bits),
+	}
+}
+
+func (m *MempoolSpace) block(w http.ResponseWriter, r *http.Request) {
+	if blk, height, ok := m.blockHash(w, r); ok {
+		writeJSON(w, http.StatusOK, m.blockJSON(blk, height))
+	}
+}
+
+func (m *MempoolSpace) blockHeader(w http.ResponseWriter, r *http.Request) {
+	if blk, _, ok := m.blockHash(w, r); ok {
+		writeText(w, http.StatusOK, hex.EncodeToString(headerBytes(&blk.Header)))
+	}
+}
+
+func (m *MempoolSpace) blockRaw(w http.ResponseWriter, r *http.Request) {
+	if blk, _, ok := m.blockHash(w, r); ok {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		blk.Serialize(w)
+	}
+}
+
+func (m *MempoolSpace) blockStatus(w http.ResponseWriter, r *http.Request) {
+	_, height, ok := m.blockHash(w, r)
+	if !ok {
+		return
+	}
+	status := map[string]any{"in_best_chain": true, "height": height}
+	if next, ok := m.chain.BlockHash(height + 1); ok {
+		status["next_best"] = next.String()
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (m *MempoolSpace) blockTxids(w http.ResponseWriter, r *http.Request) {
+	blk, _, ok := m.blockHash(w, r)
+	if !ok {
+		return
+	}
+	txids := make([]string, len(blk.Transactions))
+	for i, tx := range blk.Transactions {
+		txids[i] = tx.TxHash().String()
+	}
+	writeJSON(w, http.StatusOK, txids)
+}
+
+func (m *MempoolSpace) blockTxs(w http.ResponseWriter, r *http.Request) {
+	blk, _, ok := m.blockHash(w, r)
+	if !ok {
+		return
+	}
+	start := 0
+	if s := r.PathValue("start"); s != "" {
+		var err error
+		if start, err = strconv.Atoi(s); err != nil || start < 0 || start%pageSize != 0 {
+			writeText(w, http.StatusBadRequest, "start index must be a multipication of 25")
+			return
+		}
+	}
+	var txids []chainhash.Hash
+	for i := start; i < len(blk.Transactions) && i < start+pageSize; i++ {
+		txids = append(txids, blk.Transactions[i].TxHash())
+	}
+	m.writeTxs(w, txids)
+}
+
+// 从 height(默认为最新高度)向下的区块, mempool.space 返回 15 个, Esplora 返回 10 个
+func (m *MempoolSpace) blocks(w http.ResponseWriter, r *http.Request) {
+	height := m.chain.Height()
+	if s := r.PathValue("height"); s != "" {
+		h, err := strconv.Atoi(s)
+		if err != nil {
+			writeText(w, http.StatusBadRequest, "Invalid height")
+			return
+		}
+		if h < height {
+			height = h
+		}
+	}
+	n := 15
+	if !strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		n = 10
+	}
+	blocks := []map[string]any{}
+	for h := height; h >= 0 && len(blocks) < n; h-- {
+		hash, _ := m.chain.BlockHash(h)
+		if blk, _, ok := m.chain.Block(hash); ok {
+			blocks = append(blocks, m.blockJSON(blk, h))
+		}
+	}
+	writeJSON(w, http.StatusOK, blocks)
+}
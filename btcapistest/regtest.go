@@ -0,0 +1,143 @@
+package btcapistest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis"
+)
+
+// Node regtest 节点: 出块, 打款, 等待交易进入内存池.
+// RegtestNode(本地启动的 bitcoind)与 MockNode(内存中的 Chain)实现相同的接口, 同一个场景脚本可以在两者上运行, 见 RunScenario.
+type Node interface {
+	// Config 连接到节点的 btcapis 配置
+	Config() *btcapis.Config
+	// Watch 让 btcapis 可以查询地址的余额与 UTXO; 真实节点上将地址导入只读钱包, 需要在查询前调用
+	Watch(ctx context.Context, addresses ...string) error
+	// GenerateToAddress 出 n 个区块, coinbase 支付给 address; 真实节点上 coinbase 需要 100 个确认才能花费
+	GenerateToAddress(ctx context.Context, n int, address string) ([]string, error)
+	// Mine 出 n 个区块, 确认内存池中的交易
+	Mine(ctx context.Context, n int) ([]string, error)
+	// Fund 向地址打款 sats, 交易留在内存池中, 返回 txid
+	Fund(ctx context.Context, address string, sats int64) (string, error)
+	// WaitForMempool 等待交易被节点接受(在内存池中或已确认), ctx 结束时返回错误
+	WaitForMempool(ctx context.Context, txid string) error
+	// BlockCount 链高度
+	BlockCount(ctx context.Context) (int, error)
+}
+
+// MockNode 基于 Env 的 Node 实现, 不需要 bitcoind, 适用于 CI
+type MockNode struct {
+	*Env
+}
+
+// NewMockNode 创建 Env 并包装为 Node, 测试结束时自动关闭
+func NewMockNode(t testing.TB) *MockNode {
+	t.Helper()
+	return &MockNode{Env: NewEnv(t)}
+}
+
+// Watch 假服务可以查询任意地址, 不需要导入
+func (m *MockNode) Watch(ctx context.Context, addresses ...string) error {
+	for _, address := range addresses {
+		if _, err := btcutil.DecodeAddress(address, m.Chain.Params()); err != nil {
+			return fmt.Errorf("btcapistest: watch %s: %w", address, err)
+		}
+	}
+	return nil
+}
+
+func (m *MockNode) GenerateToAddress(ctx context.Context, n int, address string) ([]string, error) {
+	addr, err := btcutil.DecodeAddress(address, m.Chain.Params())
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	return hashStrings(m.Chain.MineTo(pkScript, n)), nil
+}
+
+func (m *MockNode) Mine(ctx context.Context, n int) ([]string, error) {
+	return hashStrings(m.Chain.Mine(n)), nil
+}
+
+func (m *MockNode) Fund(ctx context.Context, address string, sats int64) (string, error) {
+	op, err := m.Chain.Fund(address, sats)
+	if err != nil {
+		return "", err
+	}
+	return op.Hash.String(), nil
+}
+
+func (m *MockNode) WaitForMempool(ctx context.Context, txid string) error {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return err
+	}
+	return poll(ctx, txid+" in mempool", func() (bool, error) {
+		_, _, ok := m.Chain.Tx(*hash)
+		return ok, nil
+	})
+}
+
+func (m *MockNode) BlockCount(ctx context.Context) (int, error) {
+	return m.Chain.Height(), nil
+}
+
+func hashStrings(hashes []chainhash.Hash) []string {
+	ret := make([]string, len(hashes))
+	for i, h := range hashes {
+		ret[i] = h.String()
+	}
+	return ret
+}
+
+// 每 100ms 检查一次, 直到 done 返回 true, 出错或 ctx 结束; what 用于错误信息
+func poll(ctx context.Context, what string, done func() (bool, error)) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		ok, err := done()
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("btcapistest: wait for %s: %w", what, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scenario 可以在任意 Node 上运行的测试脚本, client 已使用 node.Config() 创建
+type Scenario func(t *testing.T, node Node, client *btcapis.Client)
+
+// RunScenario 依次在 MockNode 与 RegtestNode 上运行场景, 分别为子测试 "mock" 与 "bitcoind";
+// 找不到 bitcoind 可执行文件时跳过后者(见 StartRegtest).
+// btcapis.New 会设置全局状态, 场景不能并行运行.
+func RunScenario(t *testing.T, scenario Scenario) {
+	t.Run("mock", func(t *testing.T) {
+		node := NewMockNode(t)
+		scenario(t, node, newClient(t, node))
+	})
+	t.Run("bitcoind", func(t *testing.T) {
+		node := StartRegtest(t)
+		scenario(t, node, newClient(t, node))
+	})
+}
+
+func newClient(t *testing.T, node Node) *btcapis.Client {
+	t.Helper()
+	client, err := btcapis.NewE(node.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
@@ -0,0 +1,286 @@
+package btcapistest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/crazycloudcc/btcapis"
+)
+
+const (
+	regtestUser   = "btcapistest"
+	regtestPass   = "btcapistest"
+	minerWallet   = "miner" // 挖矿与打款钱包
+	watchWallet   = "watch" // 只读钱包, Config.RPCWallet
+	startTimeout  = 30 * time.Second
+	stopTimeout   = 10 * time.Second
+	coinbaseDepth = 101 // 初始出块数, 使第一个 coinbase 成熟
+)
+
+// RegtestNode 本地启动的 regtest bitcoind(-txindex -rest), 数据目录为临时目录.
+// 启动后创建 miner 钱包并出 101 个块, Fund 从 miner 钱包打款;
+// Watch 将地址导入只读的 watch 钱包, Config 通过 RPCWallet 从该钱包查询余额与 UTXO.
+type RegtestNode struct {
+	cmd    *exec.Cmd
+	exited chan struct{}
+	url    string
+	miner  string // miner 钱包的收款地址
+	id     atomic.Int64
+}
+
+// StartRegtest 启动 bitcoind, 测试结束时停止.
+// 可执行文件为环境变量 BITCOIND, 未设置时从 PATH 查找 bitcoind; 找不到时跳过测试.
+func StartRegtest(t testing.TB) *RegtestNode {
+	t.Helper()
+	bin := os.Getenv("BITCOIND")
+	if bin == "" {
+		var err error
+		if bin, err = exec.LookPath("bitcoind"); err != nil {
+			t.Skip("btcapistest: bitcoind not found, set BITCOIND or add it to PATH")
+		}
+	}
+	ports, err := freePorts(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &RegtestNode{
+		url:    fmt.Sprintf("http://127.0.0.1:%d", ports[0]),
+		exited: make(chan struct{}),
+	}
+	n.cmd = exec.Command(bin,
+		"-regtest",
+		"-datadir="+t.TempDir(),
+		"-rpcport="+strconv.Itoa(ports[0]),
+		"-port="+strconv.Itoa(ports[1]),
+		"-rpcbind=127.0.0.1",
+		"-rpcallowip=127.0.0.1",
+		"-rpcuser="+regtestUser,
+		"-rpcpassword="+regtestPass,
+		"-server", "-rest", "-txindex", "-listen=0",
+		"-fallbackfee=0.0002",
+		"-printtoconsole=0",
+	)
+	if err := n.cmd.Start(); err != nil {
+		t.Fatalf("btcapistest: start bitcoind: %v", err)
+	}
+	go func() {
+		n.cmd.Wait()
+		close(n.exited)
+	}()
+	// TempDir 的清理先注册, 在节点停止后执行
+	t.Cleanup(func() { n.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	if err := n.setup(ctx); err != nil {
+		t.Fatalf("btcapistest: setup bitcoind: %v", err)
+	}
+	return n
+}
+
+// 等待 RPC 可用, 创建钱包并出块
+func (n *RegtestNode) setup(ctx context.Context) error {
+	if err := poll(ctx, "bitcoind rpc", func() (bool, error) {
+		select {
+		case <-n.exited:
+			return false, errors.New("bitcoind exited")
+		default:
+		}
+		err := n.call(ctx, "", "getblockchaininfo", nil)
+		var rpcErr *rpcError
+		if err == nil {
+			return true, nil
+		}
+		if errors.As(err, &rpcErr) && rpcErr.Code != -28 { // -28 RPC_IN_WARMUP
+			return false, err
+		}
+		return false, nil // 未监听或仍在启动
+	}); err != nil {
+		return err
+	}
+	if err := n.call(ctx, "", "createwallet", nil, minerWallet, false, false, "", false, true); err != nil {
+		return err
+	}
+	if err := n.call(ctx, "", "createwallet", nil, watchWallet, true, true, "", false, true); err != nil {
+		return err
+	}
+	if err := n.call(ctx, minerWallet, "getnewaddress", &n.miner, "", "bech32"); err != nil {
+		return err
+	}
+	_, err := n.GenerateToAddress(ctx, coinbaseDepth, n.miner)
+	return err
+}
+
+// Close 停止节点并等待进程退出, 超时后强制结束
+func (n *RegtestNode) Close() error {
+	select {
+	case <-n.exited:
+		return nil
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := n.call(ctx, "", "stop", nil); err != nil {
+		n.cmd.Process.Kill()
+	}
+	select {
+	case <-n.exited:
+	case <-ctx.Done():
+		n.cmd.Process.Kill()
+		<-n.exited
+	}
+	return nil
+}
+
+// Config 只配置 bitcoind, 地址查询使用 watch 钱包
+func (n *RegtestNode) Config() *btcapis.Config {
+	return &btcapis.Config{
+		Network:    "regtest",
+		Timeout:    10,
+		RPCUrl:     n.url,
+		RPCUser:    regtestUser,
+		RPCPass:    regtestPass,
+		RPCUseREST: true,
+		RPCWallet:  watchWallet,
+	}
+}
+
+// Watch 以 addr() 描述符导入 watch 钱包, 从创世区块开始重新扫描
+func (n *RegtestNode) Watch(ctx context.Context, addresses ...string) error {
+	for _, address := range addresses {
+		var info struct {
+			Descriptor string `json:"descriptor"`
+		}
+		if err := n.call(ctx, "", "getdescriptorinfo", &info, "addr("+address+")"); err != nil {
+			return err
+		}
+		var results []struct {
+			Success bool      `json:"success"`
+			Error   *rpcError `json:"error"`
+		}
+		req := []map[string]any{{"desc": info.Descriptor, "timestamp": 0}}
+		if err := n.call(ctx, watchWallet, "importdescriptors", &results, req); err != nil {
+			return err
+		}
+		if len(results) != 1 || !results[0].Success {
+			if len(results) == 1 && results[0].Error != nil {
+				return results[0].Error
+			}
+			return fmt.Errorf("btcapistest: import %s failed", address)
+		}
+	}
+	return nil
+}
+
+func (n *RegtestNode) GenerateToAddress(ctx context.Context, count int, address string) ([]string, error) {
+	var hashes []string
+	err := n.call(ctx, "", "generatetoaddress", &hashes, count, address)
+	return hashes, err
+}
+
+// Mine 出块给 miner 钱包
+func (n *RegtestNode) Mine(ctx context.Context, count int) ([]string, error) {
+	return n.GenerateToAddress(ctx, count, n.miner)
+}
+
+func (n *RegtestNode) Fund(ctx context.Context, address string, sats int64) (string, error) {
+	var txid string
+	err := n.call(ctx, minerWallet, "sendtoaddress", &txid, address, btcutil.Amount(sats).ToBTC())
+	return txid, err
+}
+
+// WaitForMempool 轮询 getrawtransaction(-txindex 同时覆盖内存池与区块)
+func (n *RegtestNode) WaitForMempool(ctx context.Context, txid string) error {
+	return poll(ctx, txid+" in mempool", func() (bool, error) {
+		err := n.call(ctx, "", "getrawtransaction", nil, txid)
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) && rpcErr.Code == -5 { // -5 RPC_INVALID_ADDRESS_OR_KEY: 尚未收到
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+func (n *RegtestNode) BlockCount(ctx context.Context) (int, error) {
+	var count int
+	err := n.call(ctx, "", "getblockcount", &count)
+	return count, err
+}
+
+// 作为客户端时, bitcoind 返回的 RPC 错误
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("bitcoind rpc error %d: %s", e.Code, e.Message)
+}
+
+// 按位置传参的 JSON-RPC 调用, wallet 非空时发送到 /wallet/<wallet>; out 为 nil 时忽略结果
+func (n *RegtestNode) call(ctx context.Context, wallet, method string, out any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "1.0",
+		"id":      n.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	url := n.url
+	if wallet != "" {
+		url += "/wallet/" + wallet
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(regtestUser, regtestPass)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// bitcoind 出错时 HTTP 状态码为 500/404, 响应体仍是 JSON-RPC 格式
+	var ret struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return fmt.Errorf("btcapistest: %s: http %d: %w", method, resp.StatusCode, err)
+	}
+	if ret.Error != nil {
+		return ret.Error
+	}
+	if out != nil {
+		return json.Unmarshal(ret.Result, out)
+	}
+	return nil
+}
+
+// 从系统获取 n 个空闲端口
+func freePorts(n int) ([]int, error) {
+	ports := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		defer l.Close()
+		ports = append(ports, l.Addr().(*net.TCPAddr).Port)
+	}
+	return ports, nil
+}
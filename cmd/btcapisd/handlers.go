@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// GET /v1/tx/{txid} -> types.Tx
+func (s *server) getTx(w http.ResponseWriter, r *http.Request) {
+	txid := r.PathValue("txid")
+	if err := s.client.CheckFormatTxid(txid); err != nil {
+		badRequest(w, "txid: %v", err)
+		return
+	}
+	tx, err := s.client.GetTx(r.Context(), txid)
+	if err != nil {
+		s.writeFacadeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tx)
+}
+
+// GET /v1/address/{address}/utxos -> []types.TxUTXO
+func (s *server) getAddressUTXOs(w http.ResponseWriter, r *http.Request) {
+	address := r.PathValue("address")
+	if err := s.client.CheckFormatAddress(address); err != nil {
+		badRequest(w, "address: %v", err)
+		return
+	}
+	utxos, err := s.client.GetAddressUTXOs(r.Context(), address)
+	if err != nil {
+		s.writeFacadeError(w, err)
+		return
+	}
+	if utxos == nil {
+		utxos = []types.TxUTXO{}
+	}
+	writeJSON(w, http.StatusOK, utxos)
+}
+
+// 地址余额(sats)
+type balanceResponse struct {
+	Address     string  `json:"address"`
+	Confirmed   float64 `json:"confirmed"`
+	Unconfirmed float64 `json:"unconfirmed"`
+}
+
+// GET /v1/address/{address}/balance
+func (s *server) getAddressBalance(w http.ResponseWriter, r *http.Request) {
+	address := r.PathValue("address")
+	if err := s.client.CheckFormatAddress(address); err != nil {
+		badRequest(w, "address: %v", err)
+		return
+	}
+	confirmed, unconfirmed, err := s.client.GetAddressBalance(r.Context(), address)
+	if err != nil {
+		s.writeFacadeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balanceResponse{Address: address, Confirmed: confirmed, Unconfirmed: unconfirmed})
+}
+
+// POST /v1/psbt, 请求为 types.TxInputParams -> types.TxUnsignedPSBT
+func (s *server) createPSBT(w http.ResponseWriter, r *http.Request) {
+	var params types.TxInputParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	if len(params.FromAddress) == 0 || len(params.ToAddress) == 0 {
+		badRequest(w, "from_address and to_address are required")
+		return
+	}
+	if len(params.ToAddress) != len(params.AmountBTC) {
+		badRequest(w, "to_address and amount must have the same length")
+		return
+	}
+	for _, addr := range append(append([]string{}, params.FromAddress...), params.ToAddress...) {
+		if err := s.client.CheckFormatAddress(addr); err != nil {
+			badRequest(w, "address %q: %v", addr, err)
+			return
+		}
+	}
+	if params.ChangeAddress != "" {
+		if err := s.client.CheckFormatAddress(params.ChangeAddress); err != nil {
+			badRequest(w, "change_address: %v", err)
+			return
+		}
+	}
+	for i, amount := range params.AmountBTC {
+		if amount <= 0 {
+			badRequest(w, "amount[%d] must be positive", i)
+			return
+		}
+	}
+	if params.FeeRate < 0 {
+		badRequest(w, "fee_rate must not be negative")
+		return
+	}
+	if params.Data != "" {
+		if err := s.client.CheckFormatHex(params.Data); err != nil {
+			badRequest(w, "data: %v", err)
+			return
+		}
+	}
+
+	psbtBase64, err := s.client.CreatePSBT(r.Context(), &params)
+	if err != nil {
+		s.writeFacadeError(w, err)
+		return
+	}
+	ret := types.TxUnsignedPSBT{PSBTBase64: psbtBase64}
+	if p, err := psbt.NewFromRawBytes(bytes.NewReader([]byte(psbtBase64)), true); err == nil {
+		var buf bytes.Buffer
+		if err := p.UnsignedTx.Serialize(&buf); err == nil {
+			ret.UnsignedTx = hex.EncodeToString(buf.Bytes())
+		}
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
+
+type psbtRequest struct {
+	PSBTBase64 string `json:"psbt_base64"` // 已签名的 PSBT
+}
+
+type txidResponse struct {
+	TxID string `json:"txid"`
+}
+
+// POST /v1/psbt/broadcast
+func (s *server) broadcastPSBT(w http.ResponseWriter, r *http.Request) {
+	var req psbtRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := s.client.CheckFormatBase64(req.PSBTBase64); err != nil {
+		badRequest(w, "psbt_base64: %v", err)
+		return
+	}
+	txid, err := s.client.FinalizePSBTAndBroadcast(r.Context(), req.PSBTBase64)
+	if err != nil {
+		s.writeFacadeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, txidResponse{TxID: txid})
+}
+
+// 手续费估算, 0 表示对应数据源未配置或估算失败
+type feeRateResponse struct {
+	TargetBlocks int     `json:"target_blocks"`
+	Bitcoind     float64 `json:"bitcoind"`      // estimatesmartfee 结果
+	MempoolSpace float64 `json:"mempool_space"` // mempool.space fastestFee(sat/vB)
+}
+
+// GET /v1/fees/estimate?target=6
+func (s *server) estimateFeeRate(w http.ResponseWriter, r *http.Request) {
+	target := 6
+	if v := r.URL.Query().Get("target"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1008 {
+			badRequest(w, "target must be an integer between 1 and 1008")
+			return
+		}
+		target = n
+	}
+	fee1, fee2, err := s.client.EstimateFeeRate(r.Context(), target)
+	if err != nil {
+		s.writeFacadeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, feeRateResponse{TargetBlocks: target, Bitcoind: fee1, MempoolSpace: fee2})
+}
+
+// GET /v1/decode/address/{address} -> types.AddressScriptInfo
+func (s *server) decodeAddress(w http.ResponseWriter, r *http.Request) {
+	address := r.PathValue("address")
+	if err := s.client.CheckFormatAddress(address); err != nil {
+		badRequest(w, "address: %v", err)
+		return
+	}
+	info, err := s.client.DecodeAddressToScriptInfo(address)
+	if err != nil {
+		badRequest(w, "address: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+type hexRequest struct {
+	Hex string `json:"hex"`
+}
+
+// 脚本解析结果
+type scriptResponse struct {
+	Info *types.AddressInfo `json:"info"`
+	Asm  string             `json:"asm"`
+	Ops  []types.ScriptOp   `json:"ops"`
+}
+
+// POST /v1/decode/script
+func (s *server) decodeScript(w http.ResponseWriter, r *http.Request) {
+	var req hexRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := s.client.CheckFormatHex(req.Hex); err != nil {
+		badRequest(w, "hex: %v", err)
+		return
+	}
+	pkScript, _ := hex.DecodeString(req.Hex)
+	info, err := s.client.DecodePkScriptToAddressInfo(pkScript)
+	if err != nil {
+		badRequest(w, "script: %v", err)
+		return
+	}
+	ops, asm, err := s.client.DecodePkScriptToAsmString(pkScript)
+	if err != nil {
+		badRequest(w, "script: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scriptResponse{Info: info, Asm: asm, Ops: ops})
+}
+
+// POST /v1/decode/tx -> types.Tx
+func (s *server) decodeTx(w http.ResponseWriter, r *http.Request) {
+	var req hexRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := s.client.CheckFormatHex(req.Hex); err != nil {
+		badRequest(w, "hex: %v", err)
+		return
+	}
+	tx, err := s.client.DecodeRawTxString(req.Hex)
+	if err != nil {
+		badRequest(w, "tx: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tx)
+}
+
+type checkRequest struct {
+	Kind  string `json:"kind"` // address, txid, hex, base64
+	Value string `json:"value"`
+}
+
+type checkResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// POST /v1/check, 格式不合法时仍返回 200, valid 为 false
+func (s *server) check(w http.ResponseWriter, r *http.Request) {
+	var req checkRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	checkers := map[string]func(string) error{
+		"address": s.client.CheckFormatAddress,
+		"txid":    s.client.CheckFormatTxid,
+		"hex":     s.client.CheckFormatHex,
+		"base64":  s.client.CheckFormatBase64,
+	}
+	fn, ok := checkers[req.Kind]
+	if !ok {
+		badRequest(w, "kind must be one of address, txid, hex, base64")
+		return
+	}
+	if err := fn(req.Value); err != nil {
+		writeJSON(w, http.StatusOK, checkResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, checkResponse{Valid: true})
+}
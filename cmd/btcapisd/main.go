@@ -0,0 +1,146 @@
+// Command btcapisd 以 HTTP/JSON 服务的形式提供 btcapis.Client 的能力(/v1 REST API),
+// 供非 Go 服务使用. 接口说明见 GET /v1/openapi.json.
+//
+// 示例:
+//
+//	BTCAPISD_TOKENS=secret1,secret2 BTCAPISD_RPC_PASS=pass btcapisd \
+//		-listen :8080 -network mainnet -rpc-url http://127.0.0.1:8332 -rpc-user user \
+//		-mempool-url https://mempool.space
+//
+// 除 /healthz 与 /v1/openapi.json 外, 请求需要带 Authorization: Bearer <token>.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/crazycloudcc/btcapis"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "btcapisd:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		listen     = flag.String("listen", ":8080", "监听地址")
+		network    = flag.String("network", "mainnet", "网络: mainnet, testnet, signet, regtest")
+		timeout    = flag.Int("timeout", 30, "数据源超时(秒)")
+		rpcURL     = flag.String("rpc-url", "", "bitcoind RPC 地址")
+		rpcUser    = flag.String("rpc-user", "", "bitcoind RPC 用户名")
+		rpcPass    = flag.String("rpc-pass", "", "bitcoind RPC 密码, 建议使用环境变量 BTCAPISD_RPC_PASS")
+		rpcWallet  = flag.String("rpc-wallet", "", "bitcoind 钱包名称, 见 btcapis.Config.RPCWallet")
+		rpcREST    = flag.Bool("rpc-rest", false, "通过 bitcoind REST 获取原始区块/交易")
+		mempoolURL = flag.String("mempool-url", "", "mempool.space API 地址")
+		esploraURL = flag.String("esplora-url", "", "Esplora API 地址")
+		electrumx  = flag.String("electrumx-url", "", "ElectrumX 地址")
+		ordURL     = flag.String("ord-url", "", "ord server 地址")
+		tokensFile = flag.String("tokens-file", "", "认证令牌文件, 每行一个, # 开头为注释; 也可以使用环境变量 BTCAPISD_TOKENS(逗号分隔)")
+		noAuth     = flag.Bool("no-auth", false, "不校验令牌, 仅用于本地调试")
+	)
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	tokens, err := loadTokens(*tokensFile, os.Getenv("BTCAPISD_TOKENS"))
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 && !*noAuth {
+		return errors.New("no auth tokens configured, use -tokens-file or BTCAPISD_TOKENS (or -no-auth for local testing)")
+	}
+	if *noAuth {
+		tokens = nil
+		log.Warn("authentication disabled")
+	}
+	if *rpcPass == "" {
+		*rpcPass = os.Getenv("BTCAPISD_RPC_PASS")
+	}
+
+	client, err := btcapis.NewE(&btcapis.Config{
+		Network:         *network,
+		Timeout:         *timeout,
+		RPCUrl:          *rpcURL,
+		RPCUser:         *rpcUser,
+		RPCPass:         *rpcPass,
+		RPCUseREST:      *rpcREST,
+		RPCWallet:       *rpcWallet,
+		MempoolSpaceUrl: *mempoolURL,
+		EsploraUrl:      *esploraURL,
+		ElectrumXUrl:    *electrumx,
+		OrdServerUrl:    *ordURL,
+		Logger:          log,
+	})
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           newServer(client, tokens, log),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      time.Duration(*timeout+30) * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		log.Info("listening", "addr", *listen, "network", *network)
+		errc <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// 从文件(每行一个)与逗号分隔的环境变量读取令牌, 忽略空行与 # 注释
+func loadTokens(path, env string) ([]string, error) {
+	var tokens []string
+	for _, t := range strings.Split(env, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	if path == "" {
+		return tokens, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tokens: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			tokens = append(tokens, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read tokens: %w", err)
+	}
+	return tokens, nil
+}
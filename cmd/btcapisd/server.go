@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crazycloudcc/btcapis"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+//go:embed openapi.json
+var openapiSpec []byte
+
+// 请求体上限
+const maxBodyBytes = 1 << 20
+
+// server 将 btcapis.Client 暴露为 /v1 REST API
+type server struct {
+	client *btcapis.Client
+	tokens [][sha256.Size]byte // 令牌的 sha256, 为空时不校验
+	log    *slog.Logger
+}
+
+// newServer 创建 HTTP handler; tokens 为空时不做认证(仅用于本地调试)
+func newServer(client *btcapis.Client, tokens []string, log *slog.Logger) http.Handler {
+	s := &server{client: client, log: log}
+	for _, t := range tokens {
+		s.tokens = append(s.tokens, sha256.Sum256([]byte(t)))
+	}
+
+	api := http.NewServeMux()
+	api.HandleFunc("GET /v1/tx/{txid}", s.getTx)
+	api.HandleFunc("GET /v1/address/{address}/utxos", s.getAddressUTXOs)
+	api.HandleFunc("GET /v1/address/{address}/balance", s.getAddressBalance)
+	api.HandleFunc("POST /v1/psbt", s.createPSBT)
+	api.HandleFunc("POST /v1/psbt/broadcast", s.broadcastPSBT)
+	api.HandleFunc("GET /v1/fees/estimate", s.estimateFeeRate)
+	api.HandleFunc("GET /v1/decode/address/{address}", s.decodeAddress)
+	api.HandleFunc("POST /v1/decode/script", s.decodeScript)
+	api.HandleFunc("POST /v1/decode/tx", s.decodeTx)
+	api.HandleFunc("POST /v1/check", s.check)
+	api.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found", "no such endpoint")
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openapiSpec)
+	})
+	mux.Handle("/v1/", s.auth(api))
+	return s.logRequests(mux)
+}
+
+// 校验 Authorization: Bearer <token>
+func (s *server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.tokens) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok {
+			sum := sha256.Sum256([]byte(token))
+			for _, t := range s.tokens {
+				if subtle.ConstantTimeCompare(sum[:], t[:]) == 1 {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="btcapisd"`)
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// 访问日志, 只记录方法/路径/状态码/耗时, 不记录请求体
+func (s *server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.log.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start))
+	})
+}
+
+// errorBody 错误响应 {"error": {...}}
+type errorBody struct {
+	Code    string `json:"code"`    // invalid_request, unauthorized, not_found, insufficient_funds, rejected, rate_limited, backend_unavailable, internal
+	Message string `json:"message"` // 错误信息
+	// 交易被拒绝时节点返回的错误码与原因, 见 types.ErrRejected
+	RejectCode   int    `json:"reject_code,omitempty"`
+	RejectReason string `json:"reject_reason,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]errorBody{"error": {Code: code, Message: message}})
+}
+
+// 参数校验失败, 400
+func badRequest(w http.ResponseWriter, format string, args ...any) {
+	writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf(format, args...))
+}
+
+// 按 types 的错误分类映射 HTTP 状态码
+func (s *server) writeFacadeError(w http.ResponseWriter, err error) {
+	body := errorBody{Message: err.Error()}
+	status := http.StatusInternalServerError
+	var rejected *types.ErrRejected
+	switch {
+	case errors.As(err, &rejected):
+		status, body.Code = http.StatusUnprocessableEntity, "rejected"
+		body.RejectCode, body.RejectReason = rejected.Code, rejected.Reason
+	case errors.Is(err, types.ErrTxRejected):
+		status, body.Code = http.StatusUnprocessableEntity, "rejected"
+	case errors.Is(err, types.ErrInvalidRequest):
+		status, body.Code = http.StatusBadRequest, "invalid_request"
+	case errors.Is(err, types.ErrNotFound):
+		status, body.Code = http.StatusNotFound, "not_found"
+	case errors.Is(err, types.ErrInsufficientFunds):
+		status, body.Code = http.StatusUnprocessableEntity, "insufficient_funds"
+	case errors.Is(err, types.ErrRateLimited):
+		status, body.Code = http.StatusTooManyRequests, "rate_limited"
+	case errors.Is(err, types.ErrBackendUnavailable):
+		status, body.Code = http.StatusServiceUnavailable, "backend_unavailable"
+	default:
+		body.Code = "internal"
+		s.log.Error("facade error", "error", btcapis.RedactLog(err.Error()))
+	}
+	writeJSON(w, status, map[string]errorBody{"error": body})
+}
+
+// 解析 JSON 请求体, 不允许未知字段; 失败时已写入 400
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		badRequest(w, "invalid json body: %v", err)
+		return false
+	}
+	return true
+}
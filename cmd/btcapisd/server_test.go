@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/btcapistest"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+const testToken = "test-token"
+
+func newTestServer(t *testing.T) (*btcapistest.Env, *httptest.Server) {
+	env := btcapistest.NewEnv(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(newServer(env.Client(), []string{testToken}, log))
+	t.Cleanup(srv.Close)
+	return env, srv
+}
+
+// 发送请求并解析 JSON 响应, 返回状态码
+func do(t *testing.T, srv *httptest.Server, method, path string, body, out any) int {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		r = bytes.NewReader(b)
+	}
+	req, _ := http.NewRequest(method, srv.URL+path, r)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAuth(t *testing.T) {
+	_, srv := newTestServer(t)
+	for _, tc := range []struct {
+		path, auth string
+		want       int
+	}{
+		{"/v1/fees/estimate", "", http.StatusUnauthorized},
+		{"/v1/fees/estimate", "Bearer wrong", http.StatusUnauthorized},
+		{"/v1/fees/estimate", "Bearer " + testToken, http.StatusOK},
+		{"/v1/openapi.json", "", http.StatusOK},
+		{"/healthz", "", http.StatusOK},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+tc.path, nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("GET %s (%q) = %d, want %d", tc.path, tc.auth, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestValidation(t *testing.T) {
+	_, srv := newTestServer(t)
+	for _, tc := range []struct {
+		method, path string
+		body         any
+	}{
+		{http.MethodGet, "/v1/tx/xyz", nil},
+		{http.MethodGet, "/v1/address/bad%20addr/utxos", nil},
+		{http.MethodGet, "/v1/fees/estimate?target=0", nil},
+		{http.MethodPost, "/v1/psbt", map[string]any{"from_address": []string{"x"}, "to_address": []string{}}},
+		{http.MethodPost, "/v1/psbt", map[string]any{"unknown": 1}},
+		{http.MethodPost, "/v1/psbt/broadcast", map[string]string{"psbt_base64": "!!"}},
+		{http.MethodPost, "/v1/decode/tx", map[string]string{"hex": "abc"}},
+		{http.MethodPost, "/v1/check", map[string]string{"kind": "pubkey", "value": "00"}},
+	} {
+		var ret struct {
+			Error errorBody `json:"error"`
+		}
+		if code := do(t, srv, tc.method, tc.path, tc.body, &ret); code != http.StatusBadRequest || ret.Error.Code != "invalid_request" {
+			t.Errorf("%s %s = %d %+v, want 400 invalid_request", tc.method, tc.path, code, ret.Error)
+		}
+	}
+
+	var check checkResponse
+	if code := do(t, srv, http.MethodPost, "/v1/check", checkRequest{Kind: "txid", Value: "00"}, &check); code != http.StatusOK || check.Valid {
+		t.Errorf("check txid = %d %+v", code, check)
+	}
+}
+
+// CreatePSBT -> 签名 -> 广播 -> GetTx; 重复花费返回 422 rejected
+func TestPSBTFlow(t *testing.T) {
+	env, srv := newTestServer(t)
+	alice := btcapistest.NewKey("alice", nil)
+	from := alice.P2WPKH()
+	env.Chain.Fund(from, 50_000_000)
+	env.Chain.Mine(1)
+
+	var utxos []types.TxUTXO
+	if code := do(t, srv, http.MethodGet, "/v1/address/"+from+"/utxos", nil, &utxos); code != http.StatusOK || len(utxos) != 1 || utxos[0].Value != 50_000_000 {
+		t.Fatalf("utxos = %d %+v", code, utxos)
+	}
+
+	var signed []string
+	for _, to := range []string{btcapistest.NewKey("bob", nil).P2WPKH(), btcapistest.NewKey("carol", nil).P2TR()} {
+		var unsigned types.TxUnsignedPSBT
+		code := do(t, srv, http.MethodPost, "/v1/psbt", types.TxInputParams{
+			FromAddress:   []string{from},
+			ToAddress:     []string{to},
+			AmountBTC:     []float64{0.1},
+			FeeRate:       2,
+			ChangeAddress: from,
+		}, &unsigned)
+		if code != http.StatusOK || unsigned.PSBTBase64 == "" || unsigned.UnsignedTx == "" {
+			t.Fatalf("create psbt = %d %+v", code, unsigned)
+		}
+		s, err := btcapistest.SignPSBT(unsigned.PSBTBase64, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed = append(signed, s)
+	}
+
+	var sent txidResponse
+	if code := do(t, srv, http.MethodPost, "/v1/psbt/broadcast", psbtRequest{PSBTBase64: signed[0]}, &sent); code != http.StatusOK {
+		t.Fatalf("broadcast = %d", code)
+	}
+	var tx struct { // types.Tx 的 JSON 视图, 见 types/json_hex.go
+		TxID  string
+		TxOut []struct{ Value int64 }
+	}
+	if code := do(t, srv, http.MethodGet, "/v1/tx/"+sent.TxID, nil, &tx); code != http.StatusOK || tx.TxID != sent.TxID || len(tx.TxOut) != 2 {
+		t.Fatalf("get tx = %d %+v", code, tx)
+	}
+
+	var ret struct {
+		Error errorBody `json:"error"`
+	}
+	code := do(t, srv, http.MethodPost, "/v1/psbt/broadcast", psbtRequest{PSBTBase64: signed[1]}, &ret)
+	if code != http.StatusUnprocessableEntity || ret.Error.Code != "rejected" || ret.Error.RejectReason != "txn-mempool-conflict" {
+		t.Fatalf("double spend = %d %+v", code, ret.Error)
+	}
+}
@@ -0,0 +1,99 @@
+// Package adaptertest REST 数据源客户端的共用测试: 按表在同一个假服务上并发请求多个 GET 接口,
+// 参数部分重复, 校验请求合并/限流/重试不会把响应交给错误的调用方, 以及 404 映射为 types.ErrNotFound.
+package adaptertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+const (
+	workers  = 32
+	requests = 50 // 每个 goroutine
+	keys     = 97 // 参数取值个数, 小于请求数以产生重复请求
+)
+
+// Endpoint 一个 GET 接口
+type Endpoint struct {
+	Name    string
+	Pattern string // http.ServeMux 模式, 通配符 {key} 为参数, 例如 "GET /block-height/{key}"
+	// 按参数生成响应体, ok 为 false 时返回 404
+	Respond func(r *http.Request, key string) (body string, ok bool)
+	// 以第 n 个参数调用客户端并校验结果; 服务端返回 404 时应返回 types.ErrNotFound
+	Call func(ctx context.Context, n int) error
+	// 第 n 个参数是否不存在(服务端返回 404), 为 nil 时都存在
+	Missing func(n int) bool
+}
+
+// Run 启动假服务, 用服务地址创建接口表(通常先用地址创建客户端), 然后逐个接口并发请求
+func Run(t *testing.T, endpoints func(url string) []Endpoint) {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	eps := endpoints(srv.URL)
+	for _, ep := range eps {
+		ep := ep
+		mux.HandleFunc(ep.Pattern, func(w http.ResponseWriter, r *http.Request) {
+			body, ok := ep.Respond(r, r.PathValue("key"))
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, body)
+		})
+	}
+	for _, ep := range eps {
+		t.Run(ep.Name, func(t *testing.T) { concurrent(t, ep) })
+	}
+}
+
+func concurrent(t *testing.T, ep Endpoint) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*requests)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < requests; i++ {
+				n := (w*requests + i) % keys
+				err := ep.Call(ctx, n)
+				switch missing := ep.Missing != nil && ep.Missing(n); {
+				case missing && !errors.Is(err, types.ErrNotFound):
+					errs <- fmt.Errorf("%d: got %v, want ErrNotFound", n, err)
+				case !missing && err != nil:
+					errs <- fmt.Errorf("%d: %w", n, err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+// Hash 第 n 个参数对应的 txid/区块哈希
+func Hash(n int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// ParseHash Hash 的逆运算
+func ParseHash(s string) (int, bool) {
+	if len(s) != 64 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 16, 64)
+	return int(n), err == nil
+}
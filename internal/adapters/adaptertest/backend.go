@@ -0,0 +1,96 @@
+package adaptertest
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+)
+
+// Missing 个位为 9 的参数不存在, 假服务返回 404
+func Missing(n int) bool { return n%10 == 9 }
+
+// BackendEndpoints mempool.space 与 Esplora 共有的接口, prefix 为路径前缀(mempool.space 为 /api, Esplora 为空)
+func BackendEndpoints(prefix string, b mempoolapis.Backend) []Endpoint {
+	rawTx := func(n int) []byte { return binary.BigEndian.AppendUint32([]byte{2, 0, 0, 0}, uint32(n)) }
+	byHash := func(respond func(n int) string) func(*http.Request, string) (string, bool) {
+		return func(r *http.Request, key string) (string, bool) {
+			n, ok := ParseHash(key)
+			if !ok || Missing(n) {
+				return "", false
+			}
+			return respond(n), true
+		}
+	}
+	return []Endpoint{
+		{
+			Name:    "block-height",
+			Pattern: "GET " + prefix + "/block-height/{key}",
+			Respond: func(r *http.Request, key string) (string, bool) {
+				n, err := strconv.Atoi(key)
+				if err != nil || Missing(n) {
+					return "", false
+				}
+				return Hash(n), true
+			},
+			Call: func(ctx context.Context, n int) error {
+				hash, err := b.BlockHeightGetHash(ctx, n)
+				if err == nil && hash != Hash(n) {
+					err = fmt.Errorf("hash = %s", hash)
+				}
+				return err
+			},
+			Missing: Missing,
+		},
+		{
+			Name:    "tx hex",
+			Pattern: "GET " + prefix + "/tx/{key}/hex",
+			Respond: byHash(func(n int) string { return hex.EncodeToString(rawTx(n)) }),
+			Call: func(ctx context.Context, n int) error {
+				raw, err := b.TxGetRaw(ctx, Hash(n))
+				if err == nil && string(raw) != string(rawTx(n)) {
+					err = fmt.Errorf("raw = %x", raw)
+				}
+				return err
+			},
+			Missing: Missing,
+		},
+		{
+			Name:    "tx status",
+			Pattern: "GET " + prefix + "/tx/{key}/status",
+			Respond: byHash(func(n int) string {
+				return fmt.Sprintf(`{"confirmed":true,"block_height":%d,"block_hash":"%s"}`, n, Hash(n))
+			}),
+			Call: func(ctx context.Context, n int) error {
+				st, err := b.TxGetStatus(ctx, Hash(n))
+				if err == nil && (!st.Confirmed || st.BlockHeight != n || st.BlockHash != Hash(n)) {
+					err = fmt.Errorf("status = %+v", st)
+				}
+				return err
+			},
+			Missing: Missing,
+		},
+		{
+			Name:    "tx outspend",
+			Pattern: "GET " + prefix + "/tx/{txid}/outspend/{key}",
+			Respond: func(r *http.Request, key string) (string, bool) {
+				n, ok := ParseHash(r.PathValue("txid"))
+				if !ok || key != strconv.Itoa(n%4) {
+					return "", false
+				}
+				return fmt.Sprintf(`{"spent":true,"txid":"%s","vin":%d}`, Hash(n+1), n), true
+			},
+			Call: func(ctx context.Context, n int) error {
+				out, err := b.TxGetOutspend(ctx, Hash(n), uint32(n%4))
+				if err == nil && (out.Txid != Hash(n+1) || out.Vin != uint32(n)) {
+					err = fmt.Errorf("outspend = %+v", out)
+				}
+				return err
+			},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+// 缓存: 原始交易达到确认数后缓存, 按哈希查询的原始区块/区块头不可变, 直接缓存
+package bitcoindrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/crazycloudcc/btcapis/internal/cache"
+)
+
+// SetCache 设置缓存, 对 Wallet() 之后派生的客户端同样生效
+func (c *Client) SetCache(store cache.Cache, policy cache.Policy) {
+	c.cache = store
+	c.cachePolicy = policy
+}
+
+// 未命中时使用 verbose 查询, 同时得到确认数; 未达到确认数的交易(可能被重组或替换)不缓存
+func (c *Client) txGetRawCached(ctx context.Context, txid string) ([]byte, error) {
+	key := cache.KeyRawTx(txid)
+	if raw, ok := cache.Get(ctx, c.cache, key); ok {
+		return raw, nil
+	}
+	var dto struct {
+		Hex           string `json:"hex"`
+		Confirmations int    `json:"confirmations"`
+	}
+	if err := c.rpcCall(ctx, "getrawtransaction", []any{txid, true}, &dto); err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(dto.Hex)
+	if err != nil {
+		return nil, err
+	}
+	if dto.Confirmations > 0 && dto.Confirmations >= c.cachePolicy.MinConfirmations {
+		cache.Set(ctx, c.cache, key, raw, c.cachePolicy.ConfirmedTTL)
+	}
+	return raw, nil
+}
+
+// 链高度, 用于判断高度->区块哈希能否缓存; 按 VolatileTTL 缓存
+func (c *Client) cachedTip(ctx context.Context) (int, error) {
+	key := cache.Key("bitcoind", "tip")
+	if v, ok := cache.Get(ctx, c.cache, key); ok {
+		if tip, err := strconv.Atoi(string(v)); err == nil {
+			return tip, nil
+		}
+	}
+	tip, err := c.ChainGetBlockCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if c.cachePolicy.VolatileTTL > 0 {
+		cache.Set(ctx, c.cache, key, []byte(strconv.Itoa(tip)), c.cachePolicy.VolatileTTL)
+	}
+	return tip, nil
+}
+
+// 按 VolatileTTL 缓存的 JSON 数据(手续费等)
+func (c *Client) getVolatileJSON(ctx context.Context, key string, v any) bool {
+	if c.cache == nil || c.cachePolicy.VolatileTTL <= 0 {
+		return false
+	}
+	b, ok := cache.Get(ctx, c.cache, key)
+	return ok && json.Unmarshal(b, v) == nil
+}
+
+func (c *Client) setVolatileJSON(ctx context.Context, key string, v any) {
+	if c.cache == nil || c.cachePolicy.VolatileTTL <= 0 {
+		return
+	}
+	if b, err := json.Marshal(v); err == nil {
+		cache.Set(ctx, c.cache, key, b, c.cachePolicy.VolatileTTL)
+	}
+}
@@ -6,146 +6,89 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/crazycloudcc/btcapis/internal/cache"
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+	"github.com/crazycloudcc/btcapis/internal/transport"
 	"github.com/crazycloudcc/btcapis/pkg/logger"
 )
 
 type Client struct {
-	url    string
-	user   string
-	pass   string
-	http   *http.Client
-	idSeed int
+	url     string
+	auth    *credentials
+	headers http.Header
+	http    *http.Client
+	rest    bool             // 节点开启 -rest 时, 原始区块/交易优先通过 REST 获取
+	flight  *transport.Group // 合并并发的相同查询, Wallet() 派生的客户端共享
+
+	cache       cache.Cache // 可选, 见 SetCache
+	cachePolicy cache.Policy
 }
 
+// New 使用固定用户名密码创建客户端, 其他连接选项见 NewWithOptions
 func New(url, user, pass string, timeout int) *Client {
 	return &Client{
-		url:  url,
-		user: user,
-		pass: pass,
-		http: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		url:     url,
+		auth:    &credentials{user: user, pass: pass},
+		headers: make(http.Header),
+		http:    &http.Client{Transport: transport.New("bitcoind", nil, DefaultRetryPolicy(time.Duration(timeout)*time.Second))},
+		flight:  &transport.Group{},
 	}
 }
 
-func (c *Client) rpcCall(ctx context.Context, method string, params []any, out any) error {
-	// startTime := time.Now()
-	c.idSeed++
-
-	// 记录请求开始
-	// logger.Debug("[DEBUG] Bitcoin RPC 请求开始 - Method: %s, ID: %d, Params: %+v", method, c.idSeed, params)
-
-	req := struct {
-		JSONRPC string `json:"jsonrpc"`
-		ID      int    `json:"id"`
-		Method  string `json:"method"`
-		Params  []any  `json:"params"`
-	}{
-		JSONRPC: "2.0",
-		ID:      c.idSeed,
-		Method:  method,
-		Params:  params,
-	}
-
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
-		logger.Error("[ERROR] JSON 编码失败: %v", err)
-		return err
+// Wallet 返回指定钱包的客户端, 请求发送到 /wallet/<name>; 节点加载了多个钱包时, 钱包类接口必须指定钱包
+func (c *Client) Wallet(name string) *Client {
+	w := *c
+	if u, err := url.Parse(c.url); err == nil {
+		u.Path = "/wallet/" + name
+		w.url = u.String()
 	}
+	return &w
+}
 
-	// 记录请求体内容
-	// logger.Debug("[DEBUG] RPC 请求体: %s", buf.String())
+// 请求 id, 所有客户端共享, 并发安全
+var idSeed atomic.Int64
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
-	if err != nil {
-		logger.Error("[ERROR] 创建 HTTP 请求失败: %v", err)
-		return err
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if c.user != "" {
-		httpReq.SetBasicAuth(c.user, c.pass)
-		// logger.Debug("[DEBUG] 使用认证 - User: %s", c.user)
-	}
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
 
-	// logger.Debug("[DEBUG] 发送 HTTP 请求到: %s", c.url)
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	ID *int64 `json:"id"`
+}
 
-	resp, err := c.http.Do(httpReq)
-	if err != nil {
-		logger.Error("[ERROR] HTTP 请求执行失败: %v", err)
-		return err
+// 发送请求并返回 result; 可重试的查询按 钱包地址+方法+参数 合并, 并发的相同调用共享同一个响应
+func (c *Client) send(ctx context.Context, version, method string, params any) ([]byte, error) {
+	if nonIdempotentMethods[method] {
+		return c.exchange(transport.WithIdempotent(ctx, false), version, method, params)
 	}
-	defer resp.Body.Close()
-
-	// 记录响应状态
-	// logger.Debug("[DEBUG] HTTP 响应状态: %s, StatusCode: %d", resp.Status, resp.StatusCode)
-
-	var rpcResp struct {
-		Result json.RawMessage `json:"result"`
-		Error  *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-		ID int `json:"id"`
-	}
-
-	// 读取响应体用于日志记录
-	respBody, err := io.ReadAll(resp.Body)
+	key, err := json.Marshal(params)
 	if err != nil {
-		logger.Error("[ERROR] 读取响应体失败: %v", err)
-		return err
-	}
-
-	// logger.Debug("[DEBUG] RPC 响应体: %s", string(respBody))
-
-	// 重新创建 reader 用于 JSON 解码
-	reader := bytes.NewReader(respBody)
-	if err := json.NewDecoder(reader).Decode(&rpcResp); err != nil {
-		logger.Error("[ERROR] JSON 响应解码失败: %v", err)
-		return err
-	}
-
-	// 记录 RPC 响应详情
-	// logger.Debug("[DEBUG] RPC 响应 - ID: %d, HasError: %v, HasResult: %v",
-	// 	rpcResp.ID, rpcResp.Error != nil, len(rpcResp.Result) > 0)
-
-	if rpcResp.Error != nil {
-		logger.Error("[ERROR] Bitcoin RPC 错误 - Code: %d, Message: %s",
-			rpcResp.Error.Code, rpcResp.Error.Message)
-		return fmt.Errorf("bitcoind rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
-	}
-
-	if out != nil {
-		if err := json.Unmarshal(rpcResp.Result, out); err != nil {
-			logger.Error("[ERROR] 结果反序列化失败: %v", err)
-			return err
-		}
-		// logger.Debug("[DEBUG] 结果反序列化成功")
+		return nil, err
 	}
-
-	// duration := time.Since(startTime)
-	// logger.Debug("[DEBUG] Bitcoin RPC 请求完成 - Method: %s, 耗时: %v", method, duration)
-
-	return nil
+	return c.flight.Do(ctx, c.url+" "+method+" "+string(key), func(ctx context.Context) ([]byte, error) {
+		return c.exchange(transport.WithIdempotent(ctx, true), version, method, params)
+	})
 }
 
-// createrawtransaction接口必须使用any, 不能使用[]any.
-func (c *Client) rpcCallWithAny(ctx context.Context, method string, params any, out any) error {
-	// startTime := time.Now()
-	c.idSeed++
-
-	// 记录请求开始
-	// logger.Debug("[DEBUG] Bitcoin RPC 请求开始 - Method: %s, ID: %d, Params: %+v", method, c.idSeed, params)
-
-	req := struct {
-		JSONRPC string `json:"jsonrpc"`
-		ID      int    `json:"id"`
-		Method  string `json:"method"`
-		Params  any    `json:"params"`
-	}{
-		JSONRPC: "1.0",
-		ID:      c.idSeed,
+// 一次 JSON-RPC 请求/响应, 校验响应 id 与请求一致
+func (c *Client) exchange(ctx context.Context, version, method string, params any) ([]byte, error) {
+	req := rpcRequest{
+		JSONRPC: version,
+		ID:      idSeed.Add(1),
 		Method:  method,
 		Params:  params,
 	}
@@ -153,80 +96,73 @@ func (c *Client) rpcCallWithAny(ctx context.Context, method string, params any,
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
 		logger.Error("[ERROR] JSON 编码失败: %v", err)
-		return err
+		return nil, err
 	}
 
-	// 记录请求体内容
-	// logger.Debug("[DEBUG] RPC 请求体: %s", buf.String())
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	respBody, err := c.post(ctx, buf.Bytes())
 	if err != nil {
-		logger.Error("[ERROR] 创建 HTTP 请求失败: %v", err)
-		return err
+		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if c.user != "" {
-		httpReq.SetBasicAuth(c.user, c.pass)
-		// logger.Debug("[DEBUG] 使用认证 - User: %s", c.user)
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		logger.Error("[ERROR] JSON 响应解码失败: %v", err)
+		return nil, err
 	}
 
-	// logger.Debug("[DEBUG] 发送 HTTP 请求到: %s", c.url)
+	// 请求无法解析时节点返回的 id 为 null, 此时以错误信息为准
+	if (rpcResp.ID == nil && rpcResp.Error == nil) || (rpcResp.ID != nil && *rpcResp.ID != req.ID) {
+		return nil, fmt.Errorf("bitcoind %s: response id %s does not match request id %d", method, idString(rpcResp.ID), req.ID)
+	}
 
-	resp, err := c.http.Do(httpReq)
-	if err != nil {
-		logger.Error("[ERROR] HTTP 请求执行失败: %v", err)
-		return err
+	if rpcResp.Error != nil {
+		logger.Error("[ERROR] Bitcoin RPC 错误 - Code: %d, Message: %s",
+			rpcResp.Error.Code, rpcResp.Error.Message)
+		return nil, transport.RPCError("bitcoind", rpcResp.Error.Code, rpcResp.Error.Message)
 	}
-	defer resp.Body.Close()
-
-	// 记录响应状态
-	// logger.Debug("[DEBUG] HTTP 响应状态: %s, StatusCode: %d", resp.Status, resp.StatusCode)
-
-	var rpcResp struct {
-		Result json.RawMessage `json:"result"`
-		Error  *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-		ID int `json:"id"`
+	return rpcResp.Result, nil
+}
+
+func idString(id *int64) string {
+	if id == nil {
+		return "null"
 	}
+	return strconv.FormatInt(*id, 10)
+}
 
-	// 读取响应体用于日志记录
-	respBody, err := io.ReadAll(resp.Body)
+func (c *Client) rpcCall(ctx context.Context, method string, params []any, out any) (err error) {
+	ctx, done := telemetry.Start(ctx, "bitcoind", method)
+	defer func() { done(err) }()
+
+	result, err := c.send(ctx, "2.0", method, params)
 	if err != nil {
-		logger.Error("[ERROR] 读取响应体失败: %v", err)
 		return err
 	}
 
-	// logger.Debug("[DEBUG] RPC 响应体: %s", string(respBody))
-
-	// 重新创建 reader 用于 JSON 解码
-	reader := bytes.NewReader(respBody)
-	if err := json.NewDecoder(reader).Decode(&rpcResp); err != nil {
-		logger.Error("[ERROR] JSON 响应解码失败: %v", err)
-		return err
+	if out != nil {
+		if err := json.Unmarshal(result, out); err != nil {
+			logger.Error("[ERROR] 结果反序列化失败: %v", err)
+			return err
+		}
 	}
+	return nil
+}
 
-	// 记录 RPC 响应详情
-	// logger.Debug("[DEBUG] RPC 响应 - ID: %d, HasError: %v, HasResult: %v",
-	// 	rpcResp.ID, rpcResp.Error != nil, len(rpcResp.Result) > 0)
+// createrawtransaction接口必须使用any, 不能使用[]any.
+func (c *Client) rpcCallWithAny(ctx context.Context, method string, params any, out any) (err error) {
+	ctx, done := telemetry.Start(ctx, "bitcoind", method)
+	defer func() { done(err) }()
 
-	if rpcResp.Error != nil {
-		logger.Error("[ERROR] Bitcoin RPC 错误 - Code: %d, Message: %s",
-			rpcResp.Error.Code, rpcResp.Error.Message)
-		return fmt.Errorf("bitcoind rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	result, err := c.send(ctx, "1.0", method, params)
+	if err != nil {
+		return err
 	}
 
 	if out != nil {
-		if err := json.Unmarshal(rpcResp.Result, out); err != nil {
+		if err := json.Unmarshal(result, out); err != nil {
 			logger.Error("[ERROR] 结果反序列化失败: %v", err)
 			return err
 		}
-		// logger.Debug("[DEBUG] 结果反序列化成功")
 	}
-
-	// duration := time.Since(startTime)
-	// logger.Debug("[DEBUG] Bitcoin RPC 请求完成 - Method: %s, 耗时: %v", method, duration)
-
 	return nil
 }
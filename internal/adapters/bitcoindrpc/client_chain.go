@@ -5,14 +5,24 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"strconv"
+
+	"github.com/crazycloudcc/btcapis/internal/cache"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
 )
 
 // 估算交易费率 Confirmation target in blocks (1 - 1008)
 func (c *Client) ChainEstimateSmartFeeRate(ctx context.Context, targetBlocks int) (*FeeRateSmartDTO, error) {
+	key := cache.Key("bitcoind", "fee", strconv.Itoa(targetBlocks))
 	var resp *FeeRateSmartDTO
+	if c.getVolatileJSON(ctx, key, &resp) && resp != nil {
+		return resp, nil
+	}
 	if err := c.rpcCall(ctx, "estimatesmartfee", []any{targetBlocks}, &resp); err != nil {
 		return nil, err
 	}
+	c.setVolatileJSON(ctx, key, resp)
 	return resp, nil
 }
 
@@ -31,7 +41,7 @@ func (c *Client) ChainGetUTXO(ctx context.Context, hash [32]byte, index uint32)
 
 	// 如果 scriptPubKey 为空，则返回错误
 	if dto.ScriptPubKey.Hex == "" {
-		return nil, 0, fmt.Errorf("bitcoind: utxo not found")
+		return nil, 0, fmt.Errorf("bitcoind: utxo: %w", types.ErrNotFound)
 	}
 	spk, _ := hex.DecodeString(dto.ScriptPubKey.Hex)
 	value := int64(dto.Value * 1e8)
@@ -59,11 +69,21 @@ func (c *Client) ChainGetBestBlockHash(ctx context.Context) (string, error) {
 }
 
 // 使用区块高度 查询区块哈希
+// 设置缓存时, 达到确认数的高度会被缓存
 func (c *Client) ChainGetBlockHash(ctx context.Context, height int64) (string, error) {
+	key := cache.KeyBlockHash(int(height))
+	if v, ok := cache.Get(ctx, c.cache, key); ok {
+		return string(v), nil
+	}
 	var hash string
 	if err := c.rpcCall(ctx, "getblockhash", []any{height}, &hash); err != nil {
 		return "", err
 	}
+	if c.cache != nil {
+		if tip, err := c.cachedTip(ctx); err == nil && c.cachePolicy.Deep(int(height), tip) {
+			cache.Set(ctx, c.cache, key, []byte(hash), c.cachePolicy.ConfirmedTTL)
+		}
+	}
 	return hash, nil
 }
 
@@ -86,3 +106,60 @@ func (c *Client) ChainGetBlock(ctx context.Context, hash string) (*BlockDTO, err
 	}
 	return dto, nil
 }
+
+// 使用区块block hash 查询原始区块数据
+// 区块内容由哈希确定, 设置缓存时直接缓存
+func (c *Client) ChainGetBlockRaw(ctx context.Context, hash string) ([]byte, error) {
+	key := cache.KeyRawBlock(hash)
+	if raw, ok := cache.Get(ctx, c.cache, key); ok {
+		return raw, nil
+	}
+	raw, err := c.getBlockRaw(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(ctx, c.cache, key, raw, c.cachePolicy.ConfirmedTTL)
+	return raw, nil
+}
+
+func (c *Client) getBlockRaw(ctx context.Context, hash string) ([]byte, error) {
+	if c.rest {
+		raw, err := c.RestGetBlock(ctx, hash)
+		if err == nil {
+			return raw, nil
+		}
+		logger.Warn("bitcoind rest: %v, fallback to rpc", err)
+	}
+	var hexStr string
+	if err := c.rpcCall(ctx, "getblock", []any{hash, 0}, &hexStr); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(hexStr)
+}
+
+// 使用区块block hash 查询原始区块头(80 字节)
+func (c *Client) ChainGetBlockHeaderRaw(ctx context.Context, hash string) ([]byte, error) {
+	key := cache.KeyRawHeader(hash)
+	if raw, ok := cache.Get(ctx, c.cache, key); ok {
+		return raw, nil
+	}
+	var hexStr string
+	if err := c.rpcCall(ctx, "getblockheader", []any{hash, false}, &hexStr); err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(ctx, c.cache, key, raw, c.cachePolicy.ConfirmedTTL)
+	return raw, nil
+}
+
+// 使用区块block hash 查询 BIP158 basic 过滤器, 需要节点开启 blockfilterindex
+func (c *Client) ChainGetBlockFilter(ctx context.Context, hash string) (*BlockFilterDTO, error) {
+	var dto *BlockFilterDTO
+	if err := c.rpcCall(ctx, "getblockfilter", []any{hash, "basic"}, &dto); err != nil {
+		return nil, err
+	}
+	return dto, nil
+}
@@ -0,0 +1,103 @@
+// REST 接口(/rest/*), 需要节点开启 -rest; 与 RPC 使用同一端口, 不需要认证
+package bitcoindrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+	"github.com/crazycloudcc/btcapis/internal/transport"
+)
+
+// 单次 getutxos 最多查询的输出数量(bitcoind MAX_GETUTXOS_OUTPOINTS)
+const RestMaxUTXOOutpoints = 15
+
+// EnableREST 原始区块/交易优先通过 REST 获取, 失败时回退到 RPC
+func (c *Client) EnableREST() {
+	c.rest = true
+}
+
+// REST 地址: 复用 RPC 的 scheme 与 host, 去掉认证信息及 /wallet/<name> 等路径
+func (c *Client) restURL(p string) (string, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return "", fmt.Errorf("invalid rpc url: %w", err)
+	}
+	u.User = nil
+	u.Path = "/rest/" + p
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+func (c *Client) restGet(ctx context.Context, p string) (_ []byte, err error) {
+	u, err := c.restURL(p)
+	if err != nil {
+		return nil, err
+	}
+	ctx, done := telemetry.StartHTTP(ctx, "bitcoind", http.MethodGet, u)
+	defer func() { done(err) }()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, transport.StatusError("bitcoind rest", http.MethodGet, u, resp.StatusCode, string(b))
+	}
+	return b, nil
+}
+
+// 使用区块哈希 查询原始区块数据
+func (c *Client) RestGetBlock(ctx context.Context, hash string) ([]byte, error) {
+	return c.restGet(ctx, "block/"+hash+".bin")
+}
+
+// 查询原始交易数据, 已确认交易需要节点开启 txindex
+func (c *Client) RestGetTx(ctx context.Context, txid string) ([]byte, error) {
+	return c.restGet(ctx, "tx/"+txid+".bin")
+}
+
+// 批量查询输出是否未花费, outpoints 格式为 txid:vout, 最多 RestMaxUTXOOutpoints 个;
+// checkMempool 为 true 时计入内存池中的花费和新输出
+func (c *Client) RestGetUTXOs(ctx context.Context, outpoints []string, checkMempool bool) (*RestUTXOsDTO, error) {
+	if len(outpoints) == 0 || len(outpoints) > RestMaxUTXOOutpoints {
+		return nil, fmt.Errorf("getutxos: outpoints count must be 1-%d, got %d", RestMaxUTXOOutpoints, len(outpoints))
+	}
+	p := "getutxos/"
+	if checkMempool {
+		p += "checkmempool/"
+	}
+	for i, op := range outpoints {
+		txid, vout, ok := strings.Cut(op, ":")
+		if !ok {
+			return nil, fmt.Errorf("getutxos: invalid outpoint %s", op)
+		}
+		if i > 0 {
+			p += "/"
+		}
+		p += txid + "-" + vout
+	}
+	b, err := c.restGet(ctx, p+".json")
+	if err != nil {
+		return nil, err
+	}
+	var dto *RestUTXOsDTO
+	if err := json.Unmarshal(b, &dto); err != nil {
+		return nil, fmt.Errorf("getutxos: %w", err)
+	}
+	return dto, nil
+}
@@ -0,0 +1,136 @@
+package bitcoindrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/internal/cache"
+)
+
+// 模拟 bitcoind: getblockhash 返回由高度生成的哈希, getblockcount 返回 800000; idOffset 不为 0 时返回错误的 id
+func fakeNode(t *testing.T, idOffset int64) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		var req struct {
+			ID     int64             `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result any
+		switch req.Method {
+		case "getblockhash":
+			var height int64
+			json.Unmarshal(req.Params[0], &height)
+			result = fmt.Sprintf("%064x", height)
+		case "getblockcount":
+			result = 800000
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "result": nil, "error": map[string]any{"code": -32601, "message": "Method not found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": req.ID + idOffset, "result": result, "error": nil})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func hammer(t *testing.T, workers, calls int, fn func(i int) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*calls)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < calls; i++ {
+				if err := fn(w*calls + i); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentRPC(t *testing.T) {
+	srv, _ := fakeNode(t, 0)
+	c := New(srv.URL, "user", "pass", 5)
+	w := c.Wallet("test")
+
+	ctx := context.Background()
+	hammer(t, 32, 50, func(i int) error {
+		cli := c
+		if i%2 == 1 {
+			cli = w
+		}
+		// 部分高度重复, 同时覆盖合并与不合并的请求
+		height := int64(i % 97)
+		hash, err := cli.ChainGetBlockHash(ctx, height)
+		if err != nil {
+			return err
+		}
+		if want := fmt.Sprintf("%064x", height); hash != want {
+			return fmt.Errorf("getblockhash %d = %s, want %s", height, hash, want)
+		}
+		if _, err := cli.ChainGetBlockCount(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func TestConcurrentRPCWithCache(t *testing.T) {
+	srv, hits := fakeNode(t, 0)
+	c := New(srv.URL, "user", "pass", 5)
+	c.SetCache(cache.NewMemory(1000, 0), cache.DefaultPolicy())
+
+	ctx := context.Background()
+	hammer(t, 32, 50, func(i int) error {
+		height := int64(i%10) + 1
+		hash, err := c.ChainGetBlockHash(ctx, height)
+		if err != nil {
+			return err
+		}
+		if want := fmt.Sprintf("%064x", height); hash != want {
+			return fmt.Errorf("getblockhash %d = %s, want %s", height, hash, want)
+		}
+		return nil
+	})
+	// 10 个高度都已足够深, 缓存后不再访问节点
+	before := hits.Load()
+	for h := int64(1); h <= 10; h++ {
+		if _, err := c.ChainGetBlockHash(ctx, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits.Load() != before {
+		t.Fatalf("cached getblockhash hit the node %d times", hits.Load()-before)
+	}
+}
+
+func TestResponseIDMismatch(t *testing.T) {
+	srv, _ := fakeNode(t, 1)
+	c := New(srv.URL, "user", "pass", 5)
+	_, err := c.ChainGetBlockCount(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "does not match request id") {
+		t.Fatalf("err = %v, want id mismatch", err)
+	}
+}
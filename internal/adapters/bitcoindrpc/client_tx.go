@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 )
 
 // 获取交易元数据
@@ -12,6 +14,16 @@ import (
 // 目前使用btcd库统一解析交易数据的hex.
 // decodeFlag: false-返回hex字符串; true-返回json;
 func (c *Client) TxGetRaw(ctx context.Context, txid string, decodeFlag bool) ([]byte, error) {
+	if c.cache != nil && !decodeFlag {
+		return c.txGetRawCached(ctx, txid)
+	}
+	if c.rest && !decodeFlag {
+		raw, err := c.RestGetTx(ctx, txid)
+		if err == nil {
+			return raw, nil
+		}
+		logger.Warn("bitcoind rest: %v, fallback to rpc", err)
+	}
 	var hexStr string
 	if err := c.rpcCall(ctx, "getrawtransaction", []any{txid, decodeFlag}, &hexStr); err != nil {
 		return nil, err
@@ -88,3 +100,14 @@ func (c *Client) TxTestMempoolAccept(ctx context.Context, rawtx []byte) (string,
 	}
 	return txid, nil
 }
+
+// 查询交易所在区块哈希, 未确认交易返回空字符串
+func (c *Client) TxGetBlockHash(ctx context.Context, txid string) (string, error) {
+	var dto struct {
+		BlockHash string `json:"blockhash"`
+	}
+	if err := c.rpcCall(ctx, "getrawtransaction", []any{txid, true}, &dto); err != nil {
+		return "", err
+	}
+	return dto.BlockHash, nil
+}
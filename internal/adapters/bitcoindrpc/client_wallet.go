@@ -2,6 +2,8 @@
 // 封装只能对本地钱包操作的rpc接口
 package bitcoindrpc
 
+import "context"
+
 // 4. 钱包（生成/加载/余额/地址/UTXO）
 // createwallet <name>、
 // loadwallet <name>、
@@ -33,3 +35,162 @@ package bitcoindrpc
 // PSBT流：walletcreatefundedpsbt →（外部签名或 walletprocesspsbt）→ finalizepsbt → sendrawtransaction
 // utxoupdatepsbt、
 // decodepsbt（调试/补全）
+
+// 创建钱包
+func (c *Client) WalletCreate(ctx context.Context, dto *WalletCreateDTO) (*WalletLoadResultDTO, error) {
+	var res *WalletLoadResultDTO
+	if err := c.rpcCallWithAny(ctx, "createwallet", dto, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 加载钱包
+func (c *Client) WalletLoad(ctx context.Context, name string) (*WalletLoadResultDTO, error) {
+	var res *WalletLoadResultDTO
+	if err := c.rpcCall(ctx, "loadwallet", []any{name}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 卸载钱包
+func (c *Client) WalletUnload(ctx context.Context, name string) (*WalletLoadResultDTO, error) {
+	var res *WalletLoadResultDTO
+	if err := c.rpcCall(ctx, "unloadwallet", []any{name}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 已加载的钱包
+func (c *Client) WalletList(ctx context.Context) ([]string, error) {
+	var res []string
+	if err := c.rpcCall(ctx, "listwallets", []any{}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 查询描述符校验和等信息
+func (c *Client) WalletGetDescriptorInfo(ctx context.Context, desc string) (*DescriptorInfoDTO, error) {
+	var res *DescriptorInfoDTO
+	if err := c.rpcCall(ctx, "getdescriptorinfo", []any{desc}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 导入描述符, 只读钱包用于导入 xpub 描述符
+func (c *Client) WalletImportDescriptors(ctx context.Context, reqs []ImportDescriptorDTO) ([]ImportDescriptorResultDTO, error) {
+	var res []ImportDescriptorResultDTO
+	if err := c.rpcCall(ctx, "importdescriptors", []any{reqs}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 列出钱包描述符, private 为 true 时包含私钥
+func (c *Client) WalletListDescriptors(ctx context.Context, private bool) (*ListDescriptorsDTO, error) {
+	var res *ListDescriptorsDTO
+	if err := c.rpcCall(ctx, "listdescriptors", []any{private}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 生成新地址, addrType: legacy/p2sh-segwit/bech32/bech32m, 为空时使用节点默认值
+func (c *Client) WalletGetNewAddress(ctx context.Context, label, addrType string) (string, error) {
+	params := []any{label}
+	if addrType != "" {
+		params = append(params, addrType)
+	}
+	var res string
+	if err := c.rpcCall(ctx, "getnewaddress", params, &res); err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
+// 查询钱包未花费输出, addresses 为空时返回全部; includeUnsafe 包含他人发送的未确认输出
+func (c *Client) WalletListUnspent(ctx context.Context, minconf, maxconf int, addresses []string, includeUnsafe bool) ([]WalletUnspentDTO, error) {
+	if addresses == nil {
+		addresses = []string{}
+	}
+	var res []WalletUnspentDTO
+	if err := c.rpcCall(ctx, "listunspent", []any{minconf, maxconf, addresses, includeUnsafe}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 查询钱包交易记录, label 为 "*" 时返回全部; 按时间正序, 最新的在最后
+func (c *Client) WalletListTransactions(ctx context.Context, label string, count, skip int, includeWatchOnly bool) ([]WalletTxDTO, error) {
+	var res []WalletTxDTO
+	if err := c.rpcCall(ctx, "listtransactions", []any{label, count, skip, includeWatchOnly}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 使用钱包补全 PSBT 信息并签名; sign 为 false 时只补全 UTXO 与派生路径
+func (c *Client) WalletProcessPsbt(ctx context.Context, psbtBase64 string, sign bool, sighashType string, finalize bool) (*WalletProcessPsbtDTO, error) {
+	if sighashType == "" {
+		sighashType = "DEFAULT"
+	}
+	var res *WalletProcessPsbtDTO
+	if err := c.rpcCall(ctx, "walletprocesspsbt", []any{psbtBase64, sign, sighashType, true, finalize}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 使用钱包选币创建 PSBT, outputs 格式为 [{address: BTC}] 或 [{"data": hex}]
+func (c *Client) WalletCreateFundedPsbt(ctx context.Context, inputs []OutpointDTO, outputs []map[string]any, locktime int64, options *WalletFundedPsbtOptionsDTO) (*WalletFundedPsbtDTO, error) {
+	if inputs == nil {
+		inputs = []OutpointDTO{}
+	}
+	var res *WalletFundedPsbtDTO
+	if err := c.rpcCall(ctx, "walletcreatefundedpsbt", []any{inputs, outputs, locktime, options, true}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 提高交易手续费(RBF); usePsbt 为 true 时调用 psbtbumpfee 返回待签名 PSBT, 用于只读钱包
+func (c *Client) WalletBumpFee(ctx context.Context, txid string, options *BumpFeeOptionsDTO, usePsbt bool) (*BumpFeeDTO, error) {
+	method := "bumpfee"
+	if usePsbt {
+		method = "psbtbumpfee"
+	}
+	var res *BumpFeeDTO
+	if err := c.rpcCall(ctx, method, []any{txid, options}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 锁定/解锁输出, unlock 为 true 且 outpoints 为空时解锁全部
+func (c *Client) WalletLockUnspent(ctx context.Context, unlock bool, outpoints []OutpointDTO) (bool, error) {
+	if outpoints == nil {
+		outpoints = []OutpointDTO{}
+	}
+	var res bool
+	if err := c.rpcCall(ctx, "lockunspent", []any{unlock, outpoints}, &res); err != nil {
+		return false, err
+	}
+	return res, nil
+}
+
+// 发送交易, feeRate(sat/vB) 为 0 时使用节点估算; 钱包无法完成签名时返回 PSBT
+func (c *Client) WalletSend(ctx context.Context, outputs []map[string]any, feeRate float64, options *SendOptionsDTO) (*SendResultDTO, error) {
+	var rate any
+	if feeRate > 0 {
+		rate = feeRate
+	}
+	var res *SendResultDTO
+	if err := c.rpcCall(ctx, "send", []any{outputs, nil, "unset", rate, options}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -100,3 +100,27 @@ type SignedTxDTO struct {
 	Hex      string `json:"hex"`      // 交易十六进制
 	Complete bool   `json:"complete"` // 是否完成
 }
+
+// BIP158 区块过滤器数据结构
+type BlockFilterDTO struct {
+	Filter string `json:"filter"` // 过滤器(hex)
+	Header string `json:"header"` // 过滤器头
+}
+
+// REST getutxos 查询结果
+type RestUTXOsDTO struct {
+	ChainHeight  int64         `json:"chainHeight"`  // 节点最新高度
+	ChaintipHash string        `json:"chaintipHash"` // 节点最新区块哈希
+	Bitmap       string        `json:"bitmap"`       // 按查询顺序, "1" 表示未花费
+	UTXOs        []RestUTXODTO `json:"utxos"`        // 只包含未花费的输出, 与 bitmap 中的 "1" 依次对应
+}
+
+// REST getutxos 单个未花费输出
+type RestUTXODTO struct {
+	Height       int64   `json:"height"` // 内存池中的输出为 0x7fffffff
+	Value        float64 `json:"value"`  // BTC
+	ScriptPubKey struct {
+		Hex     string `json:"hex"`
+		Address string `json:"address"`
+	} `json:"scriptPubKey"`
+}
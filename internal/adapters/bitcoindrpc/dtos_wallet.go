@@ -0,0 +1,175 @@
+// 钱包rpc数据结构定义
+package bitcoindrpc
+
+// createwallet 参数(按名称传参)
+type WalletCreateDTO struct {
+	WalletName         string `json:"wallet_name"`                    // 钱包名称
+	DisablePrivateKeys bool   `json:"disable_private_keys,omitempty"` // 只读钱包
+	Blank              bool   `json:"blank,omitempty"`                // 不生成默认描述符
+	Passphrase         string `json:"passphrase,omitempty"`           // 加密密码
+	AvoidReuse         bool   `json:"avoid_reuse,omitempty"`          // 避免地址重用
+	Descriptors        bool   `json:"descriptors"`                    // 描述符钱包
+	LoadOnStartup      *bool  `json:"load_on_startup,omitempty"`      // 节点启动时是否自动加载, nil 为不修改
+}
+
+// createwallet/loadwallet/unloadwallet 返回
+type WalletLoadResultDTO struct {
+	Name     string   `json:"name"`     // 钱包名称
+	Warnings []string `json:"warnings"` // 警告(v25+)
+	Warning  string   `json:"warning"`  // 警告(旧版本)
+}
+
+// importdescriptors 单个请求
+type ImportDescriptorDTO struct {
+	Desc      string `json:"desc"`                 // 带校验和的描述符
+	Active    bool   `json:"active,omitempty"`     // 作为 getnewaddress 的地址来源
+	Range     []int  `json:"range,omitempty"`      // 派生范围 [begin, end]
+	NextIndex *int   `json:"next_index,omitempty"` // 下一个派生序号
+	Timestamp any    `json:"timestamp"`            // 创建时间(Unix 秒)或 "now"
+	Internal  bool   `json:"internal,omitempty"`   // 找零描述符
+	Label     string `json:"label,omitempty"`      // 标签, 仅非 active 描述符
+}
+
+// importdescriptors 单个结果
+type ImportDescriptorResultDTO struct {
+	Success  bool     `json:"success"`
+	Warnings []string `json:"warnings"`
+	Error    *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// listdescriptors 返回
+type ListDescriptorsDTO struct {
+	WalletName  string          `json:"wallet_name"`
+	Descriptors []DescriptorDTO `json:"descriptors"`
+}
+
+// 钱包中的描述符
+type DescriptorDTO struct {
+	Desc      string `json:"desc"`       // 描述符
+	Timestamp int64  `json:"timestamp"`  // 创建时间
+	Active    bool   `json:"active"`     // 是否为地址来源
+	Internal  *bool  `json:"internal"`   // 是否为找零描述符, 非 active 时为空
+	Range     []int  `json:"range"`      // 派生范围
+	Next      *int   `json:"next"`       // 下一个派生序号(旧字段)
+	NextIndex *int   `json:"next_index"` // 下一个派生序号(v27+)
+}
+
+// getdescriptorinfo 返回
+type DescriptorInfoDTO struct {
+	Descriptor     string `json:"descriptor"`     // 规范化描述符(不含私钥)
+	Checksum       string `json:"checksum"`       // 输入描述符的校验和
+	IsRange        bool   `json:"isrange"`        // 是否带派生范围
+	IsSolvable     bool   `json:"issolvable"`     // 是否可解
+	HasPrivateKeys bool   `json:"hasprivatekeys"` // 是否包含私钥
+}
+
+// listunspent 单个输出
+type WalletUnspentDTO struct {
+	TxID          string  `json:"txid"`          // 交易ID
+	Vout          uint32  `json:"vout"`          // 输出索引
+	Address       string  `json:"address"`       // 地址
+	Label         string  `json:"label"`         // 标签
+	ScriptPubKey  string  `json:"scriptPubKey"`  // 脚本公钥
+	AmountBTC     float64 `json:"amount"`        // 金额
+	Confirmations int64   `json:"confirmations"` // 确认数, 内存池中为 0
+	Spendable     bool    `json:"spendable"`     // 钱包是否可签名
+	Solvable      bool    `json:"solvable"`      // 是否可解
+	Desc          string  `json:"desc"`          // 描述符
+	Safe          bool    `json:"safe"`          // 是否可安全花费
+}
+
+// listtransactions 单条记录
+type WalletTxDTO struct {
+	Address         string   `json:"address"`            // 地址
+	Category        string   `json:"category"`           // send/receive/generate/immature/orphan
+	AmountBTC       float64  `json:"amount"`             // 金额, 转出为负
+	Label           string   `json:"label"`              // 标签
+	Vout            uint32   `json:"vout"`               // 输出索引
+	FeeBTC          float64  `json:"fee"`                // 手续费, 仅 send, 为负
+	Confirmations   int64    `json:"confirmations"`      // 确认数, 冲突交易为负
+	BlockHash       string   `json:"blockhash"`          // 区块哈希
+	BlockHeight     int64    `json:"blockheight"`        // 区块高度
+	BlockTime       int64    `json:"blocktime"`          // 区块时间
+	TxID            string   `json:"txid"`               // 交易ID
+	Time            int64    `json:"time"`               // 交易时间
+	TimeReceived    int64    `json:"timereceived"`       // 钱包收到时间
+	Replaceable     string   `json:"bip125-replaceable"` // yes/no/unknown
+	Abandoned       bool     `json:"abandoned"`          // 是否已放弃
+	WalletConflicts []string `json:"walletconflicts"`    // 冲突交易
+	ReplacedBy      string   `json:"replaced_by_txid"`   // 替换交易
+	Replaces        string   `json:"replaces_txid"`      // 被替换交易
+	Trusted         *bool    `json:"trusted"`            // 未确认交易是否可信
+	InvolvesWatch   bool     `json:"involvesWatchonly"`  // 是否涉及只读地址(旧钱包)
+	ParentDescs     []string `json:"parent_descs"`       // 输出所属描述符
+}
+
+// 输入引用
+type OutpointDTO struct {
+	TxID string `json:"txid"`
+	Vout uint32 `json:"vout"`
+}
+
+// walletcreatefundedpsbt 选项
+type WalletFundedPsbtOptionsDTO struct {
+	AddInputs              *bool   `json:"add_inputs,omitempty"`             // 指定输入不足时是否自动添加
+	ChangeAddress          string  `json:"changeAddress,omitempty"`          // 找零地址
+	IncludeWatching        bool    `json:"includeWatching,omitempty"`        // 选用只读输出
+	LockUnspents           bool    `json:"lockUnspents,omitempty"`           // 锁定选中的输出
+	FeeRate                float64 `json:"fee_rate,omitempty"`               // 费率(sat/vB)
+	SubtractFeeFromOutputs []int   `json:"subtractFeeFromOutputs,omitempty"` // 从这些输出中扣除手续费
+	Replaceable            *bool   `json:"replaceable,omitempty"`            // BIP125 可替换
+}
+
+// walletcreatefundedpsbt 返回
+type WalletFundedPsbtDTO struct {
+	Psbt      string  `json:"psbt"`      // base64
+	FeeBTC    float64 `json:"fee"`       // 手续费
+	ChangePos int     `json:"changepos"` // 找零输出位置, 没有找零为 -1
+}
+
+// walletprocesspsbt 返回
+type WalletProcessPsbtDTO struct {
+	Psbt     string `json:"psbt"`     // base64
+	Complete bool   `json:"complete"` // 是否已完成签名
+	Hex      string `json:"hex"`      // 完成签名且 finalize 时的交易
+}
+
+// bumpfee/psbtbumpfee 选项
+type BumpFeeOptionsDTO struct {
+	FeeRate     float64 `json:"fee_rate,omitempty"`    // 新费率(sat/vB)
+	Replaceable *bool   `json:"replaceable,omitempty"` // 新交易是否可再次替换
+}
+
+// bumpfee/psbtbumpfee 返回
+type BumpFeeDTO struct {
+	TxID       string   `json:"txid"`    // bumpfee: 新交易ID
+	Psbt       string   `json:"psbt"`    // psbtbumpfee: 待签名 PSBT
+	OrigFeeBTC float64  `json:"origfee"` // 原手续费
+	FeeBTC     float64  `json:"fee"`     // 新手续费
+	Errors     []string `json:"errors"`  // 错误
+}
+
+// send 选项
+type SendOptionsDTO struct {
+	AddInputs              *bool         `json:"add_inputs,omitempty"`                // 指定输入不足时是否自动添加
+	AddToWallet            *bool         `json:"add_to_wallet,omitempty"`             // false 时只返回交易不广播
+	ChangeAddress          string        `json:"change_address,omitempty"`            // 找零地址
+	IncludeWatching        bool          `json:"include_watching,omitempty"`          // 选用只读输出
+	Inputs                 []OutpointDTO `json:"inputs,omitempty"`                    // 指定输入
+	LockUnspents           bool          `json:"lock_unspents,omitempty"`             // 锁定选中的输出
+	Locktime               int64         `json:"locktime,omitempty"`                  // 锁定时间
+	Psbt                   bool          `json:"psbt,omitempty"`                      // 总是返回 PSBT
+	SubtractFeeFromOutputs []int         `json:"subtract_fee_from_outputs,omitempty"` // 从这些输出中扣除手续费
+	Replaceable            *bool         `json:"replaceable,omitempty"`               // BIP125 可替换
+}
+
+// send 返回
+type SendResultDTO struct {
+	Complete bool   `json:"complete"` // 是否已完成签名
+	TxID     string `json:"txid"`     // 已广播时的交易ID
+	Hex      string `json:"hex"`      // add_to_wallet=false 时的交易
+	Psbt     string `json:"psbt"`     // 未完成签名或要求返回 PSBT 时
+}
@@ -0,0 +1,225 @@
+// 连接选项: cookie/rpcauth 认证, TLS(经反向代理), HTTP/SOCKS5 代理, 自定义请求头
+package bitcoindrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/transport"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+)
+
+// Options bitcoind 连接选项
+type Options struct {
+	// 认证: 设置 CookieFile 时优先使用 cookie, 否则使用 User/Pass(rpcuser/rpcpassword 或 rpcauth 对应的明文密码)
+	User       string
+	Pass       string
+	CookieFile string // 例如 ~/.bitcoin/.cookie; 节点重启后 cookie 变化, 收到 401 时自动重新读取
+
+	Timeout time.Duration // 单次请求超时, 0 为不限制; 重试的总时长由调用方 ctx 控制
+
+	// TLS: bitcoind 本身不支持 TLS, 需要通过 nginx 等反向代理, 此时使用 https:// 地址
+	TLSCAFile             string      // 可选 校验服务端证书的 CA
+	TLSCertFile           string      // 可选 mTLS 客户端证书
+	TLSKeyFile            string      // 可选 mTLS 客户端私钥
+	TLSServerName         string      // 可选 覆盖 SNI/证书校验的主机名
+	TLSInsecureSkipVerify bool        // 不校验服务端证书, 仅用于测试
+	TLSConfig             *tls.Config // 可选 完整 TLS 配置, 设置后忽略以上 TLS 字段
+
+	// 代理: http://host:port 或 socks5://host:port(Tor 一般为 socks5://127.0.0.1:9050, 可访问 .onion 节点)
+	Proxy string
+
+	Headers map[string]string // 每个请求附加的请求头, 例如反向代理的鉴权头
+}
+
+// 认证信息, Wallet() 派生的客户端共享同一份, cookie 重新读取后全部生效
+type credentials struct {
+	mu         sync.RWMutex
+	user       string
+	pass       string
+	cookieFile string
+}
+
+// NewWithOptions 使用连接选项创建客户端
+func NewWithOptions(rpcURL string, opts *Options) (*Client, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		base.TLSClientConfig = tlsConfig
+	}
+
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %w", opts.Proxy, err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %s", proxyURL.Scheme)
+		}
+		base.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	c := &Client{
+		url:     rpcURL,
+		http:    &http.Client{Transport: transport.New("bitcoind", base, DefaultRetryPolicy(opts.Timeout))},
+		auth:    &credentials{user: opts.User, pass: opts.Pass, cookieFile: opts.CookieFile},
+		headers: make(http.Header),
+		flight:  &transport.Group{},
+	}
+	for k, v := range opts.Headers {
+		c.headers.Set(k, v)
+	}
+	if opts.CookieFile != "" {
+		// 节点未启动时 cookie 文件可能还不存在, 第一次请求时再读取
+		if err := c.auth.reloadCookie(); err != nil {
+			logger.Error("bitcoind rpc: %v", err)
+		}
+	}
+	return c, nil
+}
+
+func (o *Options) tlsConfig() (*tls.Config, error) {
+	if o.TLSConfig != nil {
+		return o.TLSConfig, nil
+	}
+	if o.TLSCAFile == "" && o.TLSCertFile == "" && o.TLSServerName == "" && !o.TLSInsecureSkipVerify {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		ServerName:         o.TLSServerName,
+		InsecureSkipVerify: o.TLSInsecureSkipVerify,
+	}
+	if o.TLSCAFile != "" {
+		pem, err := os.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", o.TLSCAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if o.TLSCertFile != "" || o.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls client cert: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// 读取 cookie 文件, 内容为 __cookie__:<password>
+func (a *credentials) reloadCookie() error {
+	b, err := os.ReadFile(a.cookieFile)
+	if err != nil {
+		return fmt.Errorf("read cookie: %w", err)
+	}
+	user, pass, ok := strings.Cut(strings.TrimSpace(string(b)), ":")
+	if !ok {
+		return fmt.Errorf("invalid cookie file %s", a.cookieFile)
+	}
+	a.mu.Lock()
+	a.user, a.pass = user, pass
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *credentials) get() (string, string) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.user, a.pass
+}
+
+// 发送 RPC 请求并读取响应体; 使用 cookie 认证时, 收到 401 会重新读取 cookie 并重试一次
+func (c *Client) post(ctx context.Context, body []byte) ([]byte, error) {
+	if c.auth.cookieFile != "" {
+		if user, _ := c.auth.get(); user == "" {
+			if err := c.auth.reloadCookie(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+		if err != nil {
+			logger.Error("[ERROR] 创建 HTTP 请求失败: %v", err)
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		c.setHeaders(httpReq)
+		if user, pass := c.auth.get(); user != "" {
+			httpReq.SetBasicAuth(user, pass)
+		}
+
+		resp, err := c.http.Do(httpReq)
+		if err != nil {
+			logger.Error("[ERROR] HTTP 请求执行失败: %v", err)
+			return nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			logger.Error("[ERROR] 读取响应体失败: %v", err)
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized {
+			if c.auth.cookieFile != "" && attempt == 0 {
+				if err := c.auth.reloadCookie(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, errors.New("bitcoind rpc: 401 unauthorized")
+		}
+		return respBody, nil
+	}
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+}
+
+// GenerateRPCAuth 生成 bitcoin.conf 的 rpcauth 配置行(与 share/rpcauth/rpcauth.py 相同), 客户端使用 user 与明文 pass 连接
+func GenerateRPCAuth(user, pass string) (string, error) {
+	if user == "" || strings.Contains(user, ":") {
+		return "", fmt.Errorf("invalid rpc user %q", user)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	saltHex := hex.EncodeToString(salt)
+	mac := hmac.New(sha256.New, []byte(saltHex))
+	mac.Write([]byte(pass))
+	return fmt.Sprintf("rpcauth=%s:%s$%s", user, saltHex, hex.EncodeToString(mac.Sum(nil))), nil
+}
@@ -0,0 +1,55 @@
+// 重试策略: 哪些 RPC 可以安全重试(同时决定能否合并并发的相同调用)
+package bitcoindrpc
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/transport"
+)
+
+// 有副作用且重复调用结果不同的 RPC, 网络错误时不重试.
+// sendrawtransaction 按 txid 幂等(重复广播同一笔交易没有副作用), 可以重试.
+// 注资类 RPC 可能锁定所选 UTXO(lockUnspents), 重试会选到另一组输入; lockunspent 的结果依赖
+// 钱包当前的锁定状态; importdescriptors 会触发重新扫描.
+var nonIdempotentMethods = map[string]bool{
+	"send":                   true,
+	"sendtoaddress":          true,
+	"sendmany":               true,
+	"sendall":                true,
+	"bumpfee":                true,
+	"psbtbumpfee":            true,
+	"walletcreatefundedpsbt": true,
+	"fundrawtransaction":     true,
+	"lockunspent":            true,
+	"importdescriptors":      true,
+	"getnewaddress":          true,
+	"createwallet":           true,
+	"generatetoaddress":      true,
+	"generateblock":          true,
+}
+
+// DefaultRetryPolicy bitcoind 默认重试策略. bitcoind 用 HTTP 500 返回 RPC 错误(如区块不存在),
+// 这类错误重试无意义, 也不代表节点故障, 因此不在可重试状态码中.
+func DefaultRetryPolicy(attemptTimeout time.Duration) transport.Policy {
+	p := transport.DefaultPolicy(attemptTimeout)
+	p.RetryStatuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	return p
+}
+
+// SetRetryPolicy 替换重试与熔断策略, 对 Wallet() 派生的客户端同样生效; 500 不会被重试, 原因见 DefaultRetryPolicy
+func (c *Client) SetRetryPolicy(policy transport.Policy) {
+	statuses := make([]int, 0, len(policy.RetryStatuses))
+	for _, s := range policy.RetryStatuses {
+		if s != http.StatusInternalServerError {
+			statuses = append(statuses, s)
+		}
+	}
+	policy.RetryStatuses = statuses
+	transport.Apply(c.http, "bitcoind", policy)
+}
+
+// SetRateLimit 设置限流(每秒请求数, 突发数), rate <= 0 为不限流; 对 Wallet() 派生的客户端同样生效
+func (c *Client) SetRateLimit(rate float64, burst int) {
+	transport.SetRateLimit(c.http, "bitcoind", rate, burst)
+}
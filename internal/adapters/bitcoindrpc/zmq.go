@@ -0,0 +1,353 @@
+// ZMQ 通知订阅(-zmqpub*), 内置最小 ZMTP 3.0 SUB 实现(NULL 认证, 仅 tcp), 不依赖 libzmq
+package bitcoindrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// bitcoind 发布的主题
+const (
+	ZMQTopicRawTx     = "rawtx"
+	ZMQTopicHashTx    = "hashtx"
+	ZMQTopicRawBlock  = "rawblock"
+	ZMQTopicHashBlock = "hashblock"
+	ZMQTopicSequence  = "sequence"
+)
+
+const (
+	zmqDialTimeout  = 10 * time.Second
+	zmqMaxBackoff   = 30 * time.Second
+	zmqMaxFrameSize = 64 << 20
+)
+
+// ZMQSubscriber bitcoind ZMQ 订阅者. 通过 Subscribe 添加端点与主题后调用 Run,
+// 每个端点独立连接, 断线后自动重连, 事件统一从 Events 输出.
+type ZMQSubscriber struct {
+	events chan types.NodeEvent
+
+	mu        sync.Mutex
+	endpoints map[string][]string // 端点 => 主题
+	lastSeq   map[string]uint32   // 主题 => 最近的消息序号
+}
+
+// NewZMQSubscriber 创建订阅者
+func NewZMQSubscriber() *ZMQSubscriber {
+	return &ZMQSubscriber{
+		events:    make(chan types.NodeEvent, 1024),
+		endpoints: make(map[string][]string),
+		lastSeq:   make(map[string]uint32),
+	}
+}
+
+// Subscribe 订阅端点上的主题, endpoint 与 bitcoind 配置一致, 例如 tcp://127.0.0.1:28332.
+// 需要在 Run 之前调用.
+func (z *ZMQSubscriber) Subscribe(endpoint string, topics ...string) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.endpoints[endpoint] = append(z.endpoints[endpoint], topics...)
+}
+
+// Events 事件通道, Run 返回后关闭
+func (z *ZMQSubscriber) Events() <-chan types.NodeEvent {
+	return z.events
+}
+
+// Run 连接全部端点并分发事件, 直到 ctx 取消; 返回时关闭事件通道
+func (z *ZMQSubscriber) Run(ctx context.Context) error {
+	z.mu.Lock()
+	endpoints := make(map[string][]string, len(z.endpoints))
+	for ep, topics := range z.endpoints {
+		endpoints[ep] = append([]string(nil), topics...)
+	}
+	z.mu.Unlock()
+	if len(endpoints) == 0 {
+		close(z.events)
+		return errors.New("zmq: no endpoint subscribed")
+	}
+
+	var wg sync.WaitGroup
+	for ep, topics := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			z.runEndpoint(ctx, ep, topics)
+		}()
+	}
+	wg.Wait()
+	close(z.events)
+	return ctx.Err()
+}
+
+// 单个端点: 断线后按退避时间重连
+func (z *ZMQSubscriber) runEndpoint(ctx context.Context, endpoint string, topics []string) {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := z.runOnce(ctx, endpoint, topics)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > zmqMaxBackoff {
+			backoff = time.Second
+		}
+		logger.Warn("bitcoind zmq %s: %v, reconnect in %s", endpoint, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, zmqMaxBackoff)
+	}
+}
+
+// 单次连接: 握手, 发送订阅, 读取消息直到出错
+func (z *ZMQSubscriber) runOnce(ctx context.Context, endpoint string, topics []string) error {
+	addr, ok := strings.CutPrefix(endpoint, "tcp://")
+	if !ok {
+		return fmt.Errorf("unsupported endpoint %s, only tcp:// is supported", endpoint)
+	}
+	dialer := net.Dialer{Timeout: zmqDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// ctx 取消时关闭连接以结束读取
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(zmqDialTimeout))
+	if err := zmtpHandshake(conn, r); err != nil {
+		return fmt.Errorf("handshake: %w", err)
+	}
+	for _, t := range topics {
+		// ZMTP 3.0 的订阅是一条以 0x01 开头的普通消息
+		if err := zmtpWriteFrame(conn, 0, append([]byte{1}, t...)); err != nil {
+			return fmt.Errorf("subscribe %s: %w", t, err)
+		}
+	}
+	// 通知的间隔可能很长, 读取不设超时, 依赖 TCP keepalive 发现断线
+	conn.SetDeadline(time.Time{})
+
+	for {
+		parts, err := zmtpReadMessage(r)
+		if err != nil {
+			return err
+		}
+		ev, err := z.decodeMessage(parts)
+		if err != nil {
+			logger.Warn("bitcoind zmq %s: %v", endpoint, err)
+			continue
+		}
+		select {
+		case z.events <- *ev:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// bitcoind 消息由三部分组成: 主题, 内容, 4 字节小端序号
+func (z *ZMQSubscriber) decodeMessage(parts [][]byte) (*types.NodeEvent, error) {
+	if len(parts) != 3 || len(parts[2]) != 4 {
+		return nil, fmt.Errorf("unexpected message with %d parts", len(parts))
+	}
+	topic, body := string(parts[0]), parts[1]
+	ev := &types.NodeEvent{Seq: binary.LittleEndian.Uint32(parts[2])}
+
+	z.mu.Lock()
+	// 序号变小说明节点重启, 不计为丢失
+	if last, ok := z.lastSeq[topic]; ok && ev.Seq > last+1 {
+		ev.Missed = ev.Seq - last - 1
+	}
+	z.lastSeq[topic] = ev.Seq
+	z.mu.Unlock()
+	if ev.Missed > 0 {
+		logger.Warn("bitcoind zmq: %d %s messages missed", ev.Missed, topic)
+	}
+
+	switch topic {
+	case ZMQTopicRawTx:
+		tx, err := decoders.DecodeRawTx(body)
+		if err != nil {
+			return nil, fmt.Errorf("decode rawtx: %w", err)
+		}
+		ev.Type, ev.Hash, ev.Tx = types.NodeEventTx, tx.TxID, tx
+	case ZMQTopicRawBlock:
+		blk, err := decoders.DecodeRawBlock(body, 0)
+		if err != nil {
+			return nil, fmt.Errorf("decode rawblock: %w", err)
+		}
+		ev.Type, ev.Hash, ev.Block = types.NodeEventBlock, blk.Hash, blk
+	case ZMQTopicHashTx, ZMQTopicHashBlock:
+		if len(body) != 32 {
+			return nil, fmt.Errorf("%s: invalid hash length %d", topic, len(body))
+		}
+		// 哈希已是展示顺序
+		ev.Type, ev.Hash = types.NodeEventTxHash, hex.EncodeToString(body)
+		if topic == ZMQTopicHashBlock {
+			ev.Type = types.NodeEventBlockHash
+		}
+	case ZMQTopicSequence:
+		// <32 字节哈希><1 字节标记>[<8 字节小端内存池序号>, 仅 A/R]
+		if len(body) < 33 {
+			return nil, fmt.Errorf("sequence: invalid length %d", len(body))
+		}
+		ev.Hash = hex.EncodeToString(body[:32])
+		switch body[32] {
+		case 'C':
+			ev.Type = types.NodeEventBlockConnected
+		case 'D':
+			ev.Type = types.NodeEventBlockDisconnected
+		case 'A', 'R':
+			if len(body) != 41 {
+				return nil, fmt.Errorf("sequence: invalid length %d", len(body))
+			}
+			ev.Type = types.NodeEventMempoolAdd
+			if body[32] == 'R' {
+				ev.Type = types.NodeEventMempoolRemove
+			}
+			ev.MempoolSeq = binary.LittleEndian.Uint64(body[33:])
+		default:
+			return nil, fmt.Errorf("sequence: unknown label %q", body[32])
+		}
+	default:
+		return nil, fmt.Errorf("unknown topic %s", topic)
+	}
+	return ev, nil
+}
+
+// ===== ZMTP 3.0 =====
+
+const (
+	zmtpFlagMore    = 0x01
+	zmtpFlagLong    = 0x02
+	zmtpFlagCommand = 0x04
+)
+
+// 问候(64 字节) + NULL 认证的 READY 命令
+func zmtpHandshake(w io.Writer, r *bufio.Reader) error {
+	greeting := make([]byte, 64)
+	greeting[0], greeting[9] = 0xff, 0x7f
+	greeting[10], greeting[11] = 3, 0
+	copy(greeting[12:32], "NULL")
+	if _, err := w.Write(greeting); err != nil {
+		return err
+	}
+
+	peer := make([]byte, 64)
+	if _, err := io.ReadFull(r, peer); err != nil {
+		return err
+	}
+	if peer[0] != 0xff || peer[9] != 0x7f || peer[10] < 3 {
+		return errors.New("peer is not a ZMTP 3.x endpoint")
+	}
+	if mech := string(bytes.TrimRight(peer[12:32], "\x00")); mech != "NULL" {
+		return fmt.Errorf("unsupported security mechanism %s", mech)
+	}
+
+	var ready bytes.Buffer
+	ready.WriteByte(5)
+	ready.WriteString("READY")
+	ready.WriteByte(byte(len("Socket-Type")))
+	ready.WriteString("Socket-Type")
+	binary.Write(&ready, binary.BigEndian, uint32(len("SUB")))
+	ready.WriteString("SUB")
+	if err := zmtpWriteFrame(w, zmtpFlagCommand, ready.Bytes()); err != nil {
+		return err
+	}
+
+	flags, body, err := zmtpReadFrame(r)
+	if err != nil {
+		return err
+	}
+	if flags&zmtpFlagCommand == 0 || len(body) < 1 || len(body) < 1+int(body[0]) {
+		return errors.New("expected READY command")
+	}
+	if name := string(body[1 : 1+body[0]]); name != "READY" {
+		return fmt.Errorf("peer sent %s command", name)
+	}
+	return nil
+}
+
+func zmtpWriteFrame(w io.Writer, flags byte, body []byte) error {
+	var hdr []byte
+	if len(body) > 255 {
+		hdr = binary.BigEndian.AppendUint64([]byte{flags | zmtpFlagLong}, uint64(len(body)))
+	} else {
+		hdr = []byte{flags, byte(len(body))}
+	}
+	_, err := w.Write(append(hdr, body...))
+	return err
+}
+
+func zmtpReadFrame(r *bufio.Reader) (byte, []byte, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&zmtpFlagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > zmqMaxFrameSize {
+		return 0, nil, fmt.Errorf("frame too large: %d", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// 读取一条完整的多帧消息, 跳过命令帧
+func zmtpReadMessage(r *bufio.Reader) ([][]byte, error) {
+	var parts [][]byte
+	for {
+		flags, body, err := zmtpReadFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		if flags&zmtpFlagCommand != 0 {
+			continue
+		}
+		parts = append(parts, body)
+		if flags&zmtpFlagMore == 0 {
+			return parts, nil
+		}
+	}
+}
@@ -8,40 +8,75 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+	"github.com/crazycloudcc/btcapis/internal/transport"
 	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
 )
 
 type Client struct {
 	url    string
 	http   *http.Client
-	idSeed int
+	flight transport.Group
 }
 
+// 请求 id, 所有客户端共享, 并发安全
+var idSeed atomic.Int64
+
 // New 创建ElectrumX客户端实例
 // baseURL: ElectrumX服务器地址，例如 "http://localhost:50001"
 // timeout: 请求超时时间（秒）
 func New(baseURL string, timeout int) *Client {
 	return &Client{
 		url:  baseURL,
-		http: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		http: transport.NewClient("electrumx", nil, time.Duration(timeout)*time.Second),
 	}
 }
 
-// rpcCall 执行ElectrumX JSON-RPC调用
-func (c *Client) rpcCall(ctx context.Context, method string, params []interface{}, out interface{}) error {
-	c.idSeed++
+// rpcCall 执行ElectrumX JSON-RPC调用, 并发的相同调用(方法与参数相同)合并为一次
+func (c *Client) rpcCall(ctx context.Context, method string, params []interface{}, out interface{}) (err error) {
+	ctx, done := telemetry.Start(ctx, "electrumx", method)
+	defer func() { done(err) }()
+	key, err := json.Marshal(params)
+	if err != nil {
+		logger.Error("[ERROR] ElectrumX JSON 编码失败: %v", err)
+		return err
+	}
+	result, err := c.flight.Do(ctx, method+string(key), func(ctx context.Context) ([]byte, error) {
+		return c.call(ctx, method, params)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 解析结果
+	if out != nil {
+		if err := json.Unmarshal(result, out); err != nil {
+			logger.Error("[ERROR] 结果反序列化失败: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
 
+// 发送一次 JSON-RPC 请求, 返回 result 原始数据
+func (c *Client) call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
 	// 构建JSON-RPC请求
 	req := struct {
 		JSONRPC string        `json:"jsonrpc"`
-		ID      int           `json:"id"`
+		ID      int64         `json:"id"`
 		Method  string        `json:"method"`
 		Params  []interface{} `json:"params"`
 	}{
 		JSONRPC: "2.0",
-		ID:      c.idSeed,
+		ID:      idSeed.Add(1),
 		Method:  method,
 		Params:  params,
 	}
@@ -49,14 +84,15 @@ func (c *Client) rpcCall(ctx context.Context, method string, params []interface{
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
 		logger.Error("[ERROR] ElectrumX JSON 编码失败: %v", err)
-		return err
+		return nil, err
 	}
 
 	// 创建HTTP请求
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	// ElectrumX 接口均为查询或按 txid 幂等的广播, 允许重试
+	httpReq, err := http.NewRequestWithContext(transport.WithIdempotent(ctx, true), http.MethodPost, c.url, &buf)
 	if err != nil {
 		logger.Error("[ERROR] 创建 HTTP 请求失败: %v", err)
-		return err
+		return nil, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
@@ -64,7 +100,7 @@ func (c *Client) rpcCall(ctx context.Context, method string, params []interface{
 	resp, err := c.http.Do(httpReq)
 	if err != nil {
 		logger.Error("[ERROR] HTTP 请求执行失败: %v", err)
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -75,37 +111,67 @@ func (c *Client) rpcCall(ctx context.Context, method string, params []interface{
 			Code    int    `json:"code"`
 			Message string `json:"message"`
 		} `json:"error"`
-		ID int `json:"id"`
+		ID *int64 `json:"id"`
 	}
 
 	// 读取响应体
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logger.Error("[ERROR] 读取响应体失败: %v", err)
-		return err
+		return nil, err
 	}
 
 	// 解码JSON响应
 	reader := bytes.NewReader(respBody)
 	if err := json.NewDecoder(reader).Decode(&rpcResp); err != nil {
 		logger.Error("[ERROR] JSON 响应解码失败: %v", err)
-		return err
+		return nil, err
+	}
+
+	// 校验响应与请求对应; 请求无法解析时 id 为 null, 以错误信息为准
+	if (rpcResp.ID == nil && rpcResp.Error == nil) || (rpcResp.ID != nil && *rpcResp.ID != req.ID) {
+		got := "null"
+		if rpcResp.ID != nil {
+			got = strconv.FormatInt(*rpcResp.ID, 10)
+		}
+		return nil, fmt.Errorf("electrumx %s: response id %s does not match request id %d", method, got, req.ID)
 	}
 
 	// 检查RPC错误
 	if rpcResp.Error != nil {
 		logger.Error("[ERROR] ElectrumX RPC 错误 - Code: %d, Message: %s",
 			rpcResp.Error.Code, rpcResp.Error.Message)
-		return fmt.Errorf("electrumx rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return nil, rpcError(rpcResp.Error.Code, rpcResp.Error.Message)
 	}
 
-	// 解析结果
-	if out != nil {
-		if err := json.Unmarshal(rpcResp.Result, out); err != nil {
-			logger.Error("[ERROR] 结果反序列化失败: %v", err)
-			return err
+	return rpcResp.Result, nil
+}
+
+// SetRetryPolicy 替换重试与熔断策略
+func (c *Client) SetRetryPolicy(policy transport.Policy) {
+	transport.Apply(c.http, "electrumx", policy)
+}
+
+// SetRateLimit 设置限流(每秒请求数, 突发数), rate <= 0 为不限流
+func (c *Client) SetRateLimit(rate float64, burst int) {
+	transport.SetRateLimit(c.http, "electrumx", rate, burst)
+}
+
+// ElectrumX 转发的 bitcoind 错误, 例如 daemon error: DaemonError({'code': -5, 'message': 'No such mempool or blockchain transaction. ...'})
+var daemonError = regexp.MustCompile(`['"]code['"]:\s*(-?\d+),\s*['"]message['"]:\s*['"](.*?)['"]\}`)
+
+// 错误映射: 优先使用转发的 bitcoind 错误码; 广播被拒绝时消息为 "the transaction was rejected by network rules.\n\n<原因>\n[<rawtx>]"
+func rpcError(code int, message string) error {
+	if m := daemonError.FindStringSubmatch(message); m != nil {
+		daemonCode, _ := strconv.Atoi(m[1])
+		return transport.RPCError("electrumx", daemonCode, m[2])
+	}
+	if strings.Contains(message, "rejected by network rules") {
+		reason := message
+		if parts := strings.Split(message, "\n\n"); len(parts) > 1 {
+			reason, _, _ = strings.Cut(parts[1], "\n")
 		}
+		return &types.ErrRejected{Reason: strings.TrimSpace(reason)}
 	}
-
-	return nil
+	return &types.BackendError{Backend: "electrumx", Code: code, Message: message}
 }
@@ -0,0 +1,93 @@
+package electrumx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// 模拟 ElectrumX: 余额由 scripthash 前 4 字节生成; idOffset 不为 0 时返回错误的 id
+func fakeServer(t *testing.T, idOffset int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int64    `json:"id"`
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Method != "blockchain.scripthash.get_balance" || len(req.Params) != 1 {
+			json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "error": map[string]any{"code": -32601, "message": "unknown method"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": req.ID + idOffset, "result": fakeBalance(req.Params[0])})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func fakeBalance(scriptHash string) BalanceDTO {
+	v, _ := strconv.ParseInt(scriptHash[:8], 16, 64)
+	return BalanceDTO{Confirmed: v, Unconfirmed: v % 1000}
+}
+
+func testAddresses(t *testing.T, n int) []string {
+	t.Helper()
+	addrs := make([]string, n)
+	for i := range addrs {
+		var hash [20]byte
+		hash[0], hash[1] = byte(i), byte(i>>8)
+		a, err := btcutil.NewAddressWitnessPubKeyHash(hash[:], &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs[i] = a.EncodeAddress()
+	}
+	return addrs
+}
+
+func TestConcurrentBatchGetBalances(t *testing.T) {
+	srv := fakeServer(t, 0)
+	c := New(srv.URL, 5)
+
+	// 地址有重复, 同时覆盖合并与不合并的请求
+	addrs := testAddresses(t, 200)
+	addrs = append(addrs, addrs[:50]...)
+
+	results, err := c.BatchGetBalances(context.Background(), addrs, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.Error != nil {
+			t.Fatalf("%s: %v", addrs[i], r.Error)
+		}
+		scriptHash, err := addressToScriptHash(addrs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := fakeBalance(scriptHash)
+		if r.Address != addrs[i] || r.Confirmed != want.Confirmed || r.Unconfirmed != want.Unconfirmed {
+			t.Fatalf("result %d = %+v, want %s %+v", i, r, addrs[i], want)
+		}
+	}
+}
+
+func TestResponseIDMismatch(t *testing.T) {
+	srv := fakeServer(t, 1)
+	c := New(srv.URL, 5)
+	_, _, err := c.AddressGetBalance(context.Background(), testAddresses(t, 1)[0])
+	if err == nil || !strings.Contains(err.Error(), "does not match request id") {
+		t.Fatalf("err = %v, want id mismatch", err)
+	}
+}
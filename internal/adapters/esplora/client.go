@@ -0,0 +1,51 @@
+// Package esplora 提供 Esplora(Blockstream electrs) REST API 客户端.
+// 接口与 mempool.space 基本一致, 数据结构复用 mempoolapis, 路径不带 /api 前缀(由 baseURL 决定).
+package esplora
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/internal/transport"
+)
+
+var _ mempoolapis.Backend = (*Client)(nil)
+
+type Client struct {
+	base *url.URL
+	http *http.Client
+	get  *transport.Getter
+}
+
+// baseURL 例如 https://blockstream.info/api, 自建 electrs 为 http://localhost:3000
+func New(baseURL string, timeout int) *Client {
+	u, _ := url.Parse(baseURL)
+	c := &Client{
+		base: u,
+		http: transport.NewClient("esplora", nil, time.Duration(timeout)*time.Second),
+	}
+	c.get = transport.NewGetter("esplora", c.http)
+	if u != nil {
+		// 公共服务默认限流, 避免批量查询被封禁
+		rate, burst := transport.DefaultRateLimit(u.Hostname())
+		transport.SetRateLimit(c.http, "esplora", rate, burst)
+	}
+	return c
+}
+
+// 拼接 baseURL 与路径
+func (c *Client) url(elem ...string) string {
+	return c.base.JoinPath(elem...).String()
+}
+
+// SetRetryPolicy 替换重试与熔断策略
+func (c *Client) SetRetryPolicy(policy transport.Policy) {
+	transport.Apply(c.http, "esplora", policy)
+}
+
+// SetRateLimit 设置限流(每秒请求数, 突发数), rate <= 0 为不限流
+func (c *Client) SetRateLimit(rate float64, burst int) {
+	transport.SetRateLimit(c.http, "esplora", rate, burst)
+}
@@ -0,0 +1,62 @@
+package esplora
+
+import (
+	"context"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+)
+
+// 地址/脚本哈希统计
+type statsDTO struct {
+	ChainStats struct {
+		Funded int64 `json:"funded_txo_sum"`
+		Spent  int64 `json:"spent_txo_sum"`
+	} `json:"chain_stats"`
+	MempoolStats struct {
+		Funded int64 `json:"funded_txo_sum"`
+		Spent  int64 `json:"spent_txo_sum"`
+	} `json:"mempool_stats"`
+}
+
+func (d *statsDTO) balance() (float64, float64) {
+	confirmed := d.ChainStats.Funded - d.ChainStats.Spent
+	mempool := d.MempoolStats.Funded - d.MempoolStats.Spent
+	return float64(confirmed), float64(mempool)
+}
+
+// 获取地址余额
+func (c *Client) AddressGetBalance(ctx context.Context, addr string) (float64, float64, error) {
+	var dto statsDTO
+	if err := c.get.JSON(ctx, c.url("address", addr), &dto); err != nil {
+		return 0, 0, err
+	}
+	confirmed, mempool := dto.balance()
+	return confirmed, mempool, nil
+}
+
+// 获取地址 UTXO
+func (c *Client) AddressGetUTXOs(ctx context.Context, addr string) ([]mempoolapis.UTXODTO, error) {
+	var dtos []mempoolapis.UTXODTO
+	if err := c.get.JSON(ctx, c.url("address", addr, "utxo"), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取地址已确认交易历史, 每页 25 条; afterTxid 为上一页最后一个 txid
+func (c *Client) AddressGetTxsChain(ctx context.Context, addr string, afterTxid string) ([]mempoolapis.TxDTO, error) {
+	var dtos []mempoolapis.TxDTO
+	if err := c.get.JSON(ctx, c.url("address", addr, "txs", "chain", afterTxid), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取地址未确认交易, 最多 50 条
+func (c *Client) AddressGetTxsMempool(ctx context.Context, addr string) ([]mempoolapis.TxDTO, error) {
+	var dtos []mempoolapis.TxDTO
+	if err := c.get.JSON(ctx, c.url("address", addr, "txs", "mempool"), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
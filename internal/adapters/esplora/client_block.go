@@ -0,0 +1,86 @@
+package esplora
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+)
+
+// 获取最近的 10 个区块; startHeight >= 0 时从该高度向下分页
+func (c *Client) BlocksGet(ctx context.Context, startHeight int) ([]mempoolapis.BlockDTO, error) {
+	u := c.url("blocks")
+	if startHeight >= 0 {
+		u = c.url("blocks", strconv.Itoa(startHeight))
+	}
+	var dtos []mempoolapis.BlockDTO
+	if err := c.get.JSON(ctx, u, &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取最新区块高度
+func (c *Client) BlocksTipHeight(ctx context.Context) (int, error) {
+	s, err := c.get.Text(ctx, c.url("blocks", "tip", "height"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(s)
+}
+
+// 获取最新区块哈希
+func (c *Client) BlocksTipHash(ctx context.Context) (string, error) {
+	return c.get.Text(ctx, c.url("blocks", "tip", "hash"))
+}
+
+// 根据高度获取区块哈希
+func (c *Client) BlockHeightGetHash(ctx context.Context, height int) (string, error) {
+	return c.get.Text(ctx, c.url("block-height", strconv.Itoa(height)))
+}
+
+// 获取区块详情
+func (c *Client) BlockGet(ctx context.Context, hash string) (*mempoolapis.BlockDTO, error) {
+	var dto mempoolapis.BlockDTO
+	if err := c.get.JSON(ctx, c.url("block", hash), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取 80 字节的原始区块头
+func (c *Client) BlockGetHeader(ctx context.Context, hash string) ([]byte, error) {
+	s, err := c.get.Text(ctx, c.url("block", hash, "header"))
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(s)
+}
+
+// 获取区块状态
+func (c *Client) BlockGetStatus(ctx context.Context, hash string) (*mempoolapis.BlockStatusDTO, error) {
+	var dto mempoolapis.BlockStatusDTO
+	if err := c.get.JSON(ctx, c.url("block", hash, "status"), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取区块内全部 txid
+func (c *Client) BlockGetTxids(ctx context.Context, hash string) ([]string, error) {
+	var txids []string
+	if err := c.get.JSON(ctx, c.url("block", hash, "txids"), &txids); err != nil {
+		return nil, err
+	}
+	return txids, nil
+}
+
+// 获取区块内的交易, 每页 25 条, startIndex 需要是 25 的倍数
+func (c *Client) BlockGetTxs(ctx context.Context, hash string, startIndex int) ([]mempoolapis.TxDTO, error) {
+	var dtos []mempoolapis.TxDTO
+	if err := c.get.JSON(ctx, c.url("block", hash, "txs", strconv.Itoa(startIndex)), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
@@ -0,0 +1,46 @@
+package esplora
+
+import (
+	"context"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+)
+
+// scripthash 为 sha256(scriptPubKey) 的 hex, 自然字节序(Electrum 协议为字节逆序), 用于没有地址的脚本
+
+// 获取脚本哈希余额
+func (c *Client) ScripthashGetBalance(ctx context.Context, scripthash string) (float64, float64, error) {
+	var dto statsDTO
+	if err := c.get.JSON(ctx, c.url("scripthash", scripthash), &dto); err != nil {
+		return 0, 0, err
+	}
+	confirmed, mempool := dto.balance()
+	return confirmed, mempool, nil
+}
+
+// 获取脚本哈希 UTXO
+func (c *Client) ScripthashGetUTXOs(ctx context.Context, scripthash string) ([]mempoolapis.UTXODTO, error) {
+	var dtos []mempoolapis.UTXODTO
+	if err := c.get.JSON(ctx, c.url("scripthash", scripthash, "utxo"), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取脚本哈希已确认交易历史, 每页 25 条; afterTxid 为上一页最后一个 txid
+func (c *Client) ScripthashGetTxsChain(ctx context.Context, scripthash string, afterTxid string) ([]mempoolapis.TxDTO, error) {
+	var dtos []mempoolapis.TxDTO
+	if err := c.get.JSON(ctx, c.url("scripthash", scripthash, "txs", "chain", afterTxid), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取脚本哈希未确认交易
+func (c *Client) ScripthashGetTxsMempool(ctx context.Context, scripthash string) ([]mempoolapis.TxDTO, error) {
+	var dtos []mempoolapis.TxDTO
+	if err := c.get.JSON(ctx, c.url("scripthash", scripthash, "txs", "mempool"), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
@@ -0,0 +1,14 @@
+package esplora_test
+
+import (
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/adaptertest"
+	"github.com/crazycloudcc/btcapis/internal/adapters/esplora"
+)
+
+func TestConcurrentRequests(t *testing.T) {
+	adaptertest.Run(t, func(url string) []adaptertest.Endpoint {
+		return adaptertest.BackendEndpoints("", esplora.New(url, 5))
+	})
+}
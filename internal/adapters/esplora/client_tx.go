@@ -0,0 +1,147 @@
+package esplora
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+	"github.com/crazycloudcc/btcapis/internal/transport"
+)
+
+// 获取交易的原始数据，返回二进制格式
+func (c *Client) TxGetRaw(ctx context.Context, txid string) ([]byte, error) {
+	s, err := c.get.Text(ctx, c.url("tx", txid, "hex"))
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(s)
+}
+
+// 广播交易，返回交易ID
+func (c *Client) TxBroadcast(ctx context.Context, rawtx []byte) (_ string, err error) {
+	u := c.url("tx")
+	ctx, done := telemetry.StartHTTP(ctx, "esplora", http.MethodPost, u)
+	defer func() { done(err) }()
+	// 重复广播同一笔交易没有副作用, 允许重试
+	req, err := http.NewRequestWithContext(transport.WithIdempotent(ctx, true), http.MethodPost, u, strings.NewReader(hex.EncodeToString(rawtx)))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return "", transport.StatusError("esplora", http.MethodPost, u, resp.StatusCode, string(body))
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// 获取交易详情
+func (c *Client) TxGet(ctx context.Context, txid string) (*mempoolapis.TxDTO, error) {
+	var dto mempoolapis.TxDTO
+	if err := c.get.JSON(ctx, c.url("tx", txid), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易确认状态
+func (c *Client) TxGetStatus(ctx context.Context, txid string) (*mempoolapis.TxStatusDTO, error) {
+	var dto mempoolapis.TxStatusDTO
+	if err := c.get.JSON(ctx, c.url("tx", txid, "status"), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易全部输出的花费情况
+func (c *Client) TxGetOutspends(ctx context.Context, txid string) ([]mempoolapis.OutspendDTO, error) {
+	var dtos []mempoolapis.OutspendDTO
+	if err := c.get.JSON(ctx, c.url("tx", txid, "outspends"), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取单个输出的花费情况
+func (c *Client) TxGetOutspend(ctx context.Context, txid string, vout uint32) (*mempoolapis.OutspendDTO, error) {
+	var dto mempoolapis.OutspendDTO
+	if err := c.get.JSON(ctx, c.url("tx", txid, "outspend", strconv.FormatUint(uint64(vout), 10)), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易的 merkle 证明(Electrum 格式)
+func (c *Client) TxGetMerkleProof(ctx context.Context, txid string) (*mempoolapis.MerkleProofDTO, error) {
+	var dto mempoolapis.MerkleProofDTO
+	if err := c.get.JSON(ctx, c.url("tx", txid, "merkle-proof"), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易的 merkleblock 证明(hex)
+func (c *Client) TxGetMerkleBlockProof(ctx context.Context, txid string) (string, error) {
+	return c.get.Text(ctx, c.url("tx", txid, "merkleblock-proof"))
+}
+
+// 获取费率预估: key 为确认目标区块数, value 为费率(sat/vB)
+func (c *Client) FeeEstimates(ctx context.Context) (map[int]float64, error) {
+	var raw map[string]float64
+	if err := c.get.JSON(ctx, c.url("fee-estimates"), &raw); err != nil {
+		return nil, err
+	}
+	ret := make(map[int]float64, len(raw))
+	for k, v := range raw {
+		target, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("esplora fee-estimates: invalid target %q", k)
+		}
+		ret[target] = v
+	}
+	return ret, nil
+}
+
+// 估算交易费率: 按 mempool.space 推荐费率的口径, 分别取 1/3/6 个区块内确认的费率
+func (c *Client) EstimateFeeRate(ctx context.Context, targetBlocks int) (*mempoolapis.FeeRateDTO, error) {
+	est, err := c.FeeEstimates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(est) == 0 {
+		return nil, fmt.Errorf("esplora fee-estimates: empty result")
+	}
+	return &mempoolapis.FeeRateDTO{
+		FastestFee:  feeForTarget(est, 1),
+		HalfHourFee: feeForTarget(est, 3),
+		HourFee:     feeForTarget(est, 6),
+	}, nil
+}
+
+// 不超过 target 的最大目标对应的费率; 没有时取最小目标
+func feeForTarget(est map[int]float64, target int) float64 {
+	targets := make([]int, 0, len(est))
+	for t := range est {
+		targets = append(targets, t)
+	}
+	sort.Ints(targets)
+	best := targets[0]
+	for _, t := range targets {
+		if t <= target {
+			best = t
+		}
+	}
+	return est[best]
+}
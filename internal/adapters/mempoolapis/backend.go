@@ -0,0 +1,44 @@
+package mempoolapis
+
+import "context"
+
+// Backend esplora 风格的 REST 索引服务, mempool.space 与 Esplora 都实现该接口
+type Backend interface {
+	AddressGetBalance(ctx context.Context, addr string) (float64, float64, error)
+	AddressGetUTXOs(ctx context.Context, addr string) ([]UTXODTO, error)
+	AddressGetTxsChain(ctx context.Context, addr string, afterTxid string) ([]TxDTO, error)
+	AddressGetTxsMempool(ctx context.Context, addr string) ([]TxDTO, error)
+
+	TxGetRaw(ctx context.Context, txid string) ([]byte, error)
+	TxBroadcast(ctx context.Context, rawtx []byte) (string, error)
+	TxGet(ctx context.Context, txid string) (*TxDTO, error)
+	TxGetStatus(ctx context.Context, txid string) (*TxStatusDTO, error)
+	TxGetOutspends(ctx context.Context, txid string) ([]OutspendDTO, error)
+	TxGetOutspend(ctx context.Context, txid string, vout uint32) (*OutspendDTO, error)
+	TxGetMerkleProof(ctx context.Context, txid string) (*MerkleProofDTO, error)
+	TxGetMerkleBlockProof(ctx context.Context, txid string) (string, error)
+	EstimateFeeRate(ctx context.Context, targetBlocks int) (*FeeRateDTO, error)
+
+	BlocksGet(ctx context.Context, startHeight int) ([]BlockDTO, error)
+	BlocksTipHeight(ctx context.Context) (int, error)
+	BlockHeightGetHash(ctx context.Context, height int) (string, error)
+	BlockGet(ctx context.Context, hash string) (*BlockDTO, error)
+	BlockGetHeader(ctx context.Context, hash string) ([]byte, error)
+	BlockGetStatus(ctx context.Context, hash string) (*BlockStatusDTO, error)
+	BlockGetTxids(ctx context.Context, hash string) ([]string, error)
+	BlockGetTxs(ctx context.Context, hash string, startIndex int) ([]TxDTO, error)
+}
+
+// Extras 只有 mempool.space 提供的接口, 使用前需要类型断言
+type Extras interface {
+	FeesMempoolBlocks(ctx context.Context) ([]MempoolBlockDTO, error)
+	Replacements(ctx context.Context, fullRBF bool) ([]*ReplacementDTO, error)
+	TxGetCPFP(ctx context.Context, txid string) (*CPFPDTO, error)
+	TxGetRBF(ctx context.Context, txid string) (*TxRBFDTO, error)
+	NewWSClient() *WSClient
+}
+
+var (
+	_ Backend = (*Client)(nil)
+	_ Extras  = (*Client)(nil)
+)
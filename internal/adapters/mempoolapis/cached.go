@@ -0,0 +1,221 @@
+package mempoolapis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/cache"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Cached 带缓存的 Backend: 交易/高度->区块哈希 达到确认数后缓存, 按哈希查询的区块数据直接缓存,
+// 手续费按 VolatileTTL 缓存; 其他接口直接访问数据源
+type Cached struct {
+	Backend
+	name   string
+	store  cache.Cache
+	policy cache.Policy
+}
+
+// NewCached 包装 b, name 用于区分各数据源的变化数据(如 mempool.space, esplora)
+func NewCached(b Backend, name string, store cache.Cache, policy cache.Policy) *Cached {
+	return &Cached{Backend: b, name: name, store: store, policy: policy}
+}
+
+// Unwrap 返回被包装的数据源, 用于类型断言 Extras 等可选接口
+func Unwrap(b Backend) Backend {
+	if c, ok := b.(*Cached); ok {
+		return c.Backend
+	}
+	return b
+}
+
+// ErrNotConfigured 未配置 mempool.space/Esplora 数据源
+var ErrNotConfigured = fmt.Errorf("%w: mempool.space or esplora client not configured", types.ErrBackendUnavailable)
+
+// AsExtras 返回 mempool.space 独有的接口(Esplora 不支持); b 为 nil 或不支持时返回 ErrBackendUnavailable
+func AsExtras(b Backend) (Extras, error) {
+	if b == nil {
+		return nil, ErrNotConfigured
+	}
+	ex, ok := Unwrap(b).(Extras)
+	if !ok {
+		return nil, fmt.Errorf("%w: not supported by the configured backend, requires mempool.space", types.ErrBackendUnavailable)
+	}
+	return ex, nil
+}
+
+func (c *Cached) TxGetRaw(ctx context.Context, txid string) ([]byte, error) {
+	key := cache.KeyRawTx(txid)
+	if raw, ok := cache.Get(ctx, c.store, key); ok {
+		return raw, nil
+	}
+	raw, err := c.Backend.TxGetRaw(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	// 判断确认数要查询交易状态与链高度; 变化数据不缓存时每次未命中都会多出这两个请求, 此时不缓存原始交易
+	if c.policy.VolatileTTL <= 0 {
+		return raw, nil
+	}
+	if status, err := c.TxGetStatus(ctx, txid); err == nil && c.deep(ctx, status) {
+		cache.Set(ctx, c.store, key, raw, c.policy.ConfirmedTTL)
+	}
+	return raw, nil
+}
+
+func (c *Cached) TxGet(ctx context.Context, txid string) (*TxDTO, error) {
+	key := cache.Key(c.name, "tx", txid)
+	var dto *TxDTO
+	if c.getJSON(ctx, key, &dto) && dto != nil {
+		return dto, nil
+	}
+	dto, err := c.Backend.TxGet(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	c.setJSON(ctx, key, dto, c.statusTTL(ctx, &dto.Status))
+	return dto, nil
+}
+
+func (c *Cached) TxGetStatus(ctx context.Context, txid string) (*TxStatusDTO, error) {
+	key := cache.Key(c.name, "txstatus", txid)
+	var dto *TxStatusDTO
+	if c.getJSON(ctx, key, &dto) && dto != nil {
+		return dto, nil
+	}
+	dto, err := c.Backend.TxGetStatus(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	c.setJSON(ctx, key, dto, c.statusTTL(ctx, dto))
+	return dto, nil
+}
+
+func (c *Cached) EstimateFeeRate(ctx context.Context, targetBlocks int) (*FeeRateDTO, error) {
+	key := cache.Key(c.name, "fee", strconv.Itoa(targetBlocks))
+	var dto *FeeRateDTO
+	if c.policy.VolatileTTL > 0 && c.getJSON(ctx, key, &dto) && dto != nil {
+		return dto, nil
+	}
+	dto, err := c.Backend.EstimateFeeRate(ctx, targetBlocks)
+	if err != nil {
+		return nil, err
+	}
+	if c.policy.VolatileTTL > 0 {
+		c.setJSON(ctx, key, dto, c.policy.VolatileTTL)
+	}
+	return dto, nil
+}
+
+func (c *Cached) BlockHeightGetHash(ctx context.Context, height int) (string, error) {
+	key := cache.KeyBlockHash(height)
+	if v, ok := cache.Get(ctx, c.store, key); ok {
+		return string(v), nil
+	}
+	hash, err := c.Backend.BlockHeightGetHash(ctx, height)
+	if err != nil {
+		return "", err
+	}
+	if tip, err := c.tip(ctx); err == nil && c.policy.Deep(height, tip) {
+		cache.Set(ctx, c.store, key, []byte(hash), c.policy.ConfirmedTTL)
+	}
+	return hash, nil
+}
+
+// 区块内容由哈希确定, 直接缓存
+func (c *Cached) BlockGetHeader(ctx context.Context, hash string) ([]byte, error) {
+	key := cache.KeyRawHeader(hash)
+	if raw, ok := cache.Get(ctx, c.store, key); ok {
+		return raw, nil
+	}
+	raw, err := c.Backend.BlockGetHeader(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(ctx, c.store, key, raw, c.policy.ConfirmedTTL)
+	return raw, nil
+}
+
+func (c *Cached) BlockGet(ctx context.Context, hash string) (*BlockDTO, error) {
+	key := cache.Key(c.name, "block", hash)
+	var dto *BlockDTO
+	if c.getJSON(ctx, key, &dto) && dto != nil {
+		return dto, nil
+	}
+	dto, err := c.Backend.BlockGet(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	c.setJSON(ctx, key, dto, c.policy.ConfirmedTTL)
+	return dto, nil
+}
+
+func (c *Cached) BlockGetTxids(ctx context.Context, hash string) ([]string, error) {
+	key := cache.Key(c.name, "txids", hash)
+	var txids []string
+	if c.getJSON(ctx, key, &txids) && txids != nil {
+		return txids, nil
+	}
+	txids, err := c.Backend.BlockGetTxids(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	c.setJSON(ctx, key, txids, c.policy.ConfirmedTTL)
+	return txids, nil
+}
+
+// 交易所在区块是否已有足够确认数
+func (c *Cached) deep(ctx context.Context, status *TxStatusDTO) bool {
+	if status == nil || !status.Confirmed {
+		return false
+	}
+	tip, err := c.tip(ctx)
+	return err == nil && c.policy.Deep(status.BlockHeight, tip)
+}
+
+// 确认数足够的交易不再变化, 按 ConfirmedTTL 缓存; 未确认或确认数不足的按 VolatileTTL 缓存, 返回 -1 表示不缓存
+func (c *Cached) statusTTL(ctx context.Context, status *TxStatusDTO) time.Duration {
+	if c.deep(ctx, status) {
+		return c.policy.ConfirmedTTL
+	}
+	if c.policy.VolatileTTL > 0 {
+		return c.policy.VolatileTTL
+	}
+	return -1
+}
+
+// 链高度按 VolatileTTL 缓存, 只用于判断确认数
+func (c *Cached) tip(ctx context.Context) (int, error) {
+	key := cache.Key(c.name, "tip")
+	if v, ok := cache.Get(ctx, c.store, key); ok {
+		if tip, err := strconv.Atoi(string(v)); err == nil {
+			return tip, nil
+		}
+	}
+	tip, err := c.Backend.BlocksTipHeight(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if c.policy.VolatileTTL > 0 {
+		cache.Set(ctx, c.store, key, []byte(strconv.Itoa(tip)), c.policy.VolatileTTL)
+	}
+	return tip, nil
+}
+
+func (c *Cached) getJSON(ctx context.Context, key string, v any) bool {
+	b, ok := cache.Get(ctx, c.store, key)
+	return ok && json.Unmarshal(b, v) == nil
+}
+
+func (c *Cached) setJSON(ctx context.Context, key string, v any, ttl time.Duration) {
+	if ttl < 0 {
+		return
+	}
+	if b, err := json.Marshal(v); err == nil {
+		cache.Set(ctx, c.store, key, b, ttl)
+	}
+}
@@ -0,0 +1,62 @@
+package mempoolapis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/internal/cache"
+)
+
+// 只实现 TxGetRaw 用到的接口, 记录请求次数
+type countingBackend struct {
+	mempoolapis.Backend
+	calls map[string]int
+}
+
+func (b *countingBackend) TxGetRaw(ctx context.Context, txid string) ([]byte, error) {
+	b.calls["raw"]++
+	return []byte{2, 0, 0, 0}, nil
+}
+
+func (b *countingBackend) TxGetStatus(ctx context.Context, txid string) (*mempoolapis.TxStatusDTO, error) {
+	b.calls["status"]++
+	return &mempoolapis.TxStatusDTO{Confirmed: true, BlockHeight: 100}, nil
+}
+
+func (b *countingBackend) BlocksTipHeight(ctx context.Context) (int, error) {
+	b.calls["tip"]++
+	return 200, nil
+}
+
+func TestCachedTxGetRaw(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name     string
+		volatile time.Duration
+		want     map[string]int // 两次 TxGetRaw 之后的请求次数
+	}{
+		// 确认数足够, 第二次命中缓存
+		{"cached", 30 * time.Second, map[string]int{"raw": 1, "status": 1, "tip": 1}},
+		// 状态与链高度不缓存时不额外查询, 也不缓存原始交易
+		{"no volatile ttl", 0, map[string]int{"raw": 2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &countingBackend{calls: map[string]int{}}
+			policy := cache.DefaultPolicy()
+			policy.VolatileTTL = tc.volatile
+			c := mempoolapis.NewCached(b, "test", cache.NewMemory(0, 0), policy)
+			for i := 0; i < 2; i++ {
+				if _, err := c.TxGetRaw(ctx, "txid"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, k := range []string{"raw", "status", "tip"} {
+				if b.calls[k] != tc.want[k] {
+					t.Errorf("%s requests = %d, want %d", k, b.calls[k], tc.want[k])
+				}
+			}
+		})
+	}
+}
@@ -2,60 +2,40 @@
 package mempoolapis
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/transport"
 )
 
 type Client struct {
 	base *url.URL
 	http *http.Client
+	get  *transport.Getter
 }
 
 func New(baseURL string, timeout int) *Client {
 	u, _ := url.Parse(baseURL)
-	return &Client{
+	c := &Client{
 		base: u,
-		http: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		http: transport.NewClient("mempool.space", nil, time.Duration(timeout)*time.Second),
+	}
+	c.get = transport.NewGetter("mempool.space", c.http)
+	if u != nil {
+		// 公共服务默认限流, 避免批量查询被封禁
+		rate, burst := transport.DefaultRateLimit(u.Hostname())
+		transport.SetRateLimit(c.http, "mempool.space", rate, burst)
 	}
+	return c
 }
 
-// ===== HTTP helpers =====
-func (c *Client) getJSON(ctx context.Context, url string, v any) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return err
-	}
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("GET %s status=%d body=%s", url, resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-	return json.NewDecoder(resp.Body).Decode(v)
+// SetRetryPolicy 替换重试与熔断策略
+func (c *Client) SetRetryPolicy(policy transport.Policy) {
+	transport.Apply(c.http, "mempool.space", policy)
 }
 
-func (c *Client) getBytes(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GET %s status=%d body=%s", url, resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-	return io.ReadAll(resp.Body)
+// SetRateLimit 设置限流(每秒请求数, 突发数), rate <= 0 为不限流
+func (c *Client) SetRateLimit(rate float64, burst int) {
+	transport.SetRateLimit(c.http, "mempool.space", rate, burst)
 }
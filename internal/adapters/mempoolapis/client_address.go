@@ -19,7 +19,7 @@ func (c *Client) AddressGetBalance(ctx context.Context, addr string) (float64, f
 			Spent  int64 `json:"spent_txo_sum"`
 		} `json:"mempool_stats"`
 	}
-	if err := c.getJSON(ctx, u.String(), &dto); err != nil {
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
 		return 0, 0, err
 	}
 	confirmed := dto.ChainStats.Funded - dto.ChainStats.Spent
@@ -32,7 +32,29 @@ func (c *Client) AddressGetUTXOs(ctx context.Context, addr string) ([]UTXODTO, e
 	u := *c.base
 	u.Path = path.Join(u.Path, "/api/address/", addr, "/utxo")
 	var dtos []UTXODTO
-	if err := c.getJSON(ctx, u.String(), &dtos); err != nil {
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取地址已确认交易历史, 每页 25 条, 按时间倒序; afterTxid 为上一页最后一个 txid, 为空时返回第一页
+func (c *Client) AddressGetTxsChain(ctx context.Context, addr string, afterTxid string) ([]TxDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/address/", addr, "/txs/chain", afterTxid)
+	var dtos []TxDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取地址未确认交易, 最多 50 条
+func (c *Client) AddressGetTxsMempool(ctx context.Context, addr string) ([]TxDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/address/", addr, "/txs/mempool")
+	var dtos []TxDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
 		return nil, err
 	}
 	return dtos, nil
@@ -0,0 +1,100 @@
+package mempoolapis
+
+import (
+	"context"
+	"encoding/hex"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 获取最近的 15 个区块; startHeight >= 0 时从该高度向下分页
+func (c *Client) BlocksGet(ctx context.Context, startHeight int) ([]BlockDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/v1/blocks")
+	if startHeight >= 0 {
+		u.Path = path.Join(u.Path, strconv.Itoa(startHeight))
+	}
+	var dtos []BlockDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 根据高度获取区块哈希
+func (c *Client) BlockHeightGetHash(ctx context.Context, height int) (string, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/block-height/", strconv.Itoa(height))
+	b, err := c.get.Bytes(ctx, u.String())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// 获取区块详情
+func (c *Client) BlockGet(ctx context.Context, hash string) (*BlockDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/block/", hash)
+	var dto BlockDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取 80 字节的原始区块头
+func (c *Client) BlockGetHeader(ctx context.Context, hash string) ([]byte, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/block/", hash, "header")
+	b, err := c.get.Bytes(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(b)))
+}
+
+// 获取区块状态
+func (c *Client) BlockGetStatus(ctx context.Context, hash string) (*BlockStatusDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/block/", hash, "status")
+	var dto BlockStatusDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取区块内全部 txid
+func (c *Client) BlockGetTxids(ctx context.Context, hash string) ([]string, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/block/", hash, "txids")
+	var txids []string
+	if err := c.get.JSON(ctx, u.String(), &txids); err != nil {
+		return nil, err
+	}
+	return txids, nil
+}
+
+// 获取区块内的交易, 每页 25 条, startIndex 需要是 25 的倍数
+func (c *Client) BlockGetTxs(ctx context.Context, hash string, startIndex int) ([]TxDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/block/", hash, "txs", strconv.Itoa(startIndex))
+	var dtos []TxDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取最新区块高度
+func (c *Client) BlocksTipHeight(ctx context.Context) (int, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/blocks/tip/height")
+	b, err := c.get.Bytes(ctx, u.String())
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,32 @@
+package mempoolapis
+
+import (
+	"context"
+	"path"
+)
+
+// 获取按费率预估的内存池区块
+func (c *Client) FeesMempoolBlocks(ctx context.Context) ([]MempoolBlockDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/v1/fees/mempool-blocks")
+	var dtos []MempoolBlockDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取最近的 RBF 替换树; fullRBF 为 true 时只返回包含未声明 BIP125 交易的替换
+func (c *Client) Replacements(ctx context.Context, fullRBF bool) ([]*ReplacementDTO, error) {
+	u := *c.base
+	if fullRBF {
+		u.Path = path.Join(u.Path, "/api/v1/fullrbf/replacements")
+	} else {
+		u.Path = path.Join(u.Path, "/api/v1/replacements")
+	}
+	var dtos []*ReplacementDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
@@ -0,0 +1,14 @@
+package mempoolapis_test
+
+import (
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/adaptertest"
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+)
+
+func TestConcurrentRequests(t *testing.T) {
+	adaptertest.Run(t, func(url string) []adaptertest.Endpoint {
+		return adaptertest.BackendEndpoints("/api", mempoolapis.New(url, 5))
+	})
+}
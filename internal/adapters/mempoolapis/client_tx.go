@@ -3,18 +3,22 @@ package mempoolapis
 import (
 	"context"
 	"encoding/hex"
-	"fmt"
 	"io"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
+
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+	"github.com/crazycloudcc/btcapis/internal/transport"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 )
 
 // 获取交易的原始数据，返回二进制格式
 func (c *Client) TxGetRaw(ctx context.Context, txid string) ([]byte, error) {
 	u := *c.base
 	u.Path = path.Join(u.Path, "/api/tx/", txid, "hex")
-	b, err := c.getBytes(ctx, u.String())
+	b, err := c.get.Bytes(ctx, u.String())
 	if err != nil {
 		return nil, err
 	}
@@ -22,11 +26,14 @@ func (c *Client) TxGetRaw(ctx context.Context, txid string) ([]byte, error) {
 }
 
 // 广播交易，返回交易ID
-func (c *Client) TxBroadcast(ctx context.Context, rawtx []byte) (string, error) {
+func (c *Client) TxBroadcast(ctx context.Context, rawtx []byte) (_ string, err error) {
 	// mempool.space 支持 POST /api/tx，body 为 hex
 	u := *c.base
 	u.Path = path.Join(u.Path, "/api/tx")
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(hex.EncodeToString(rawtx)))
+	ctx, done := telemetry.StartHTTP(ctx, "mempool.space", http.MethodPost, u.String())
+	defer func() { done(err) }()
+	// 重复广播同一笔交易没有副作用, 允许重试
+	req, err := http.NewRequestWithContext(transport.WithIdempotent(ctx, true), http.MethodPost, u.String(), strings.NewReader(hex.EncodeToString(rawtx)))
 	if err != nil {
 		return "", err
 	}
@@ -38,7 +45,7 @@ func (c *Client) TxBroadcast(ctx context.Context, rawtx []byte) (string, error)
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("mempool POST /api/tx status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return "", transport.StatusError("mempool.space", http.MethodPost, u.String(), resp.StatusCode, string(body))
 	}
 	txid, _ := io.ReadAll(resp.Body)
 	return strings.TrimSpace(string(txid)), nil
@@ -49,11 +56,99 @@ func (c *Client) EstimateFeeRate(ctx context.Context, targetBlocks int) (*FeeRat
 	u := *c.base
 	u.Path = path.Join(u.Path, "/api/v1/fees/recommended")
 	var dto FeeRateDTO
-	if err := c.getJSON(ctx, u.String(), &dto); err != nil {
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("mempooldto: %+v", dto)
+
+	return &dto, nil
+}
+
+// 获取交易详情
+func (c *Client) TxGet(ctx context.Context, txid string) (*TxDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/tx/", txid)
+	var dto TxDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易确认状态
+func (c *Client) TxGetStatus(ctx context.Context, txid string) (*TxStatusDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/tx/", txid, "status")
+	var dto TxStatusDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易全部输出的花费情况, 与输出一一对应
+func (c *Client) TxGetOutspends(ctx context.Context, txid string) ([]OutspendDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/tx/", txid, "outspends")
+	var dtos []OutspendDTO
+	if err := c.get.JSON(ctx, u.String(), &dtos); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// 获取单个输出的花费情况
+func (c *Client) TxGetOutspend(ctx context.Context, txid string, vout uint32) (*OutspendDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/tx/", txid, "outspend", strconv.FormatUint(uint64(vout), 10))
+	var dto OutspendDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// 获取交易的 merkle 证明(Electrum 格式), 交易需要已确认
+func (c *Client) TxGetMerkleProof(ctx context.Context, txid string) (*MerkleProofDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/tx/", txid, "merkle-proof")
+	var dto MerkleProofDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
 		return nil, err
 	}
+	return &dto, nil
+}
+
+// 获取交易的 merkleblock 证明(bitcoind merkleblock 格式的 hex)
+func (c *Client) TxGetMerkleBlockProof(ctx context.Context, txid string) (string, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/tx/", txid, "merkleblock-proof")
+	b, err := c.get.Bytes(ctx, u.String())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
 
-	fmt.Printf("mempooldto: %+v\n", dto)
+// 获取未确认交易的 CPFP 分析
+func (c *Client) TxGetCPFP(ctx context.Context, txid string) (*CPFPDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/v1/cpfp/", txid)
+	var dto CPFPDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
 
+// 获取交易的 RBF 替换历史
+func (c *Client) TxGetRBF(ctx context.Context, txid string) (*TxRBFDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/api/v1/tx/", txid, "rbf")
+	var dto TxRBFDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
 	return &dto, nil
 }
@@ -0,0 +1,195 @@
+// mempool.space 数据结构到 types 的转换
+package mempoolapis
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// ToTxDetail 转换为 types.TxDetail, 重新计算 txid 校验数据完整性
+func (d *TxDTO) ToTxDetail() (*types.TxDetail, error) {
+	m := wire.NewMsgTx(d.Version)
+	m.LockTime = d.Locktime
+	prevouts := make([]*types.TxOut, len(d.Vin))
+	for i, in := range d.Vin {
+		h, err := chainhash.NewHashFromStr(in.Txid)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s vin %d: invalid txid: %w", d.Txid, i, err)
+		}
+		sigScript, err := hex.DecodeString(in.Scriptsig)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s vin %d: invalid scriptsig: %w", d.Txid, i, err)
+		}
+		witness := make(wire.TxWitness, len(in.Witness))
+		for j, w := range in.Witness {
+			if witness[j], err = hex.DecodeString(w); err != nil {
+				return nil, fmt.Errorf("tx %s vin %d: invalid witness: %w", d.Txid, i, err)
+			}
+		}
+		m.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Hash: *h, Index: in.Vout},
+			SignatureScript:  sigScript,
+			Witness:          witness,
+			Sequence:         in.Sequence,
+		})
+		if in.Prevout != nil {
+			if prevouts[i], err = in.Prevout.toTxOut(); err != nil {
+				return nil, fmt.Errorf("tx %s vin %d prevout: %w", d.Txid, i, err)
+			}
+		}
+	}
+	for i, o := range d.Vout {
+		pkScript, err := hex.DecodeString(o.ScriptPubKey)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s vout %d: invalid scriptpubkey: %w", d.Txid, i, err)
+		}
+		m.AddTxOut(wire.NewTxOut(o.Value, pkScript))
+	}
+
+	tx := decoders.DecodeMsgTx(m)
+	if tx.TxID != d.Txid {
+		return nil, fmt.Errorf("tx %s: txid mismatch after decoding: %s", d.Txid, tx.TxID)
+	}
+	return &types.TxDetail{
+		Tx:       tx,
+		Status:   d.Status.ToTypes(),
+		Fee:      d.Fee,
+		Size:     d.Size,
+		Weight:   d.Weight,
+		Prevouts: prevouts,
+	}, nil
+}
+
+func (o *TxVoutDTO) toTxOut() (*types.TxOut, error) {
+	pkScript, err := hex.DecodeString(o.ScriptPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scriptpubkey: %w", err)
+	}
+	out := decoders.DecodeTxOut(o.Value, pkScript)
+	return &out, nil
+}
+
+// TxDTOsToDetails 批量转换交易列表
+func TxDTOsToDetails(dtos []TxDTO) ([]*types.TxDetail, error) {
+	ret := make([]*types.TxDetail, 0, len(dtos))
+	for i := range dtos {
+		d, err := dtos[i].ToTxDetail()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, d)
+	}
+	return ret, nil
+}
+
+func (s TxStatusDTO) ToTypes() types.TxStatus {
+	return types.TxStatus{
+		Confirmed:   s.Confirmed,
+		BlockHeight: s.BlockHeight,
+		BlockHash:   s.BlockHash,
+		BlockTime:   s.BlockTime,
+	}
+}
+
+func (o OutspendDTO) ToTypes() types.TxOutspend {
+	return types.TxOutspend{
+		Spent:  o.Spent,
+		TxID:   o.Txid,
+		Vin:    o.Vin,
+		Status: o.Status.ToTypes(),
+	}
+}
+
+func (p *MerkleProofDTO) ToTypes() *types.TxMerkleProof {
+	return &types.TxMerkleProof{BlockHeight: p.BlockHeight, Merkle: p.Merkle, Pos: p.Pos}
+}
+
+func (c *CPFPDTO) ToTypes() *types.TxCPFPInfo {
+	conv := func(in []CPFPTxDTO) []types.CPFPTx {
+		out := make([]types.CPFPTx, len(in))
+		for i, t := range in {
+			out[i] = t.toTypes()
+		}
+		return out
+	}
+	ret := &types.TxCPFPInfo{
+		Ancestors:            conv(c.Ancestors),
+		Descendants:          conv(c.Descendants),
+		EffectiveFeePerVsize: c.EffectiveFeePerVsize,
+		AdjustedVsize:        c.AdjustedVsize,
+	}
+	if c.BestDescendant != nil {
+		best := c.BestDescendant.toTypes()
+		ret.BestDescendant = &best
+	}
+	return ret
+}
+
+func (t CPFPTxDTO) toTypes() types.CPFPTx {
+	return types.CPFPTx{TxID: t.Txid, Fee: t.Fee, Weight: t.Weight, Rate: t.Rate}
+}
+
+func (b MempoolBlockDTO) ToTypes() types.MempoolBlockFees {
+	return types.MempoolBlockFees{
+		BlockSize:  b.BlockSize,
+		BlockVSize: b.BlockVSize,
+		NTx:        b.NTx,
+		TotalFees:  b.TotalFees,
+		MedianFee:  b.MedianFee,
+		FeeRange:   b.FeeRange,
+	}
+}
+
+// ToTypes 转换为 types.ChainBlock; mempool.space 不返回确认数, 工作量与下一区块哈希
+func (b *BlockDTO) ToTypes() *types.ChainBlock {
+	return &types.ChainBlock{
+		Hash:              b.ID,
+		Height:            b.Height,
+		Version:           b.Version,
+		VersionHex:        fmt.Sprintf("%08x", uint32(b.Version)),
+		MerkleRoot:        b.MerkleRoot,
+		Time:              b.Timestamp,
+		MedianTime:        b.MedianTime,
+		Nonce:             int(b.Nonce),
+		Bits:              fmt.Sprintf("%08x", b.Bits),
+		Difficulty:        b.Difficulty,
+		NTx:               b.TxCount,
+		PreviousBlockHash: b.PreviousBlockHash,
+		Size:              b.Size,
+		Weight:            b.Weight,
+	}
+}
+
+func (s *BlockStatusDTO) ToTypes() *types.BlockStatus {
+	return &types.BlockStatus{InBestChain: s.InBestChain, Height: s.Height, NextBest: s.NextBest}
+}
+
+func (r *ReplacementDTO) ToTypes() *types.RBFReplacement {
+	if r == nil {
+		return nil
+	}
+	ret := &types.RBFReplacement{
+		TxID:    r.Tx.Txid,
+		Fee:     r.Tx.Fee,
+		Vsize:   r.Tx.Vsize,
+		Value:   r.Tx.Value,
+		Rate:    r.Tx.Rate,
+		RBF:     r.Tx.RBF,
+		FullRBF: r.FullRBF || r.Tx.FullRBF,
+		Mined:   r.Mined || r.Tx.Mined,
+		Time:    r.Time,
+	}
+	for _, child := range r.Replaces {
+		ret.Replaces = append(ret.Replaces, child.ToTypes())
+	}
+	return ret
+}
+
+func (r *TxRBFDTO) ToTypes() *types.TxRBFHistory {
+	return &types.TxRBFHistory{Replacements: r.Replacements.ToTypes(), Replaces: r.Replaces}
+}
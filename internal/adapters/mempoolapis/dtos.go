@@ -3,26 +3,130 @@ package mempoolapis
 
 // 从mempool.space获取到的交易数据结构
 type TxDTO struct {
-	Txid     string `json:"txid"`
-	Version  int32  `json:"version"`
-	Locktime uint32 `json:"locktime"`
-	Weight   int64  `json:"weight"`
-	Size     int64  `json:"size"`
+	Txid     string      `json:"txid"`
+	Version  int32       `json:"version"`
+	Locktime uint32      `json:"locktime"`
+	Weight   int64       `json:"weight"`
+	Size     int64       `json:"size"`
+	Fee      int64       `json:"fee"`
+	Status   TxStatusDTO `json:"status"`
 
 	Vin []struct {
-		Txid      string   `json:"txid"`
-		Vout      uint32   `json:"vout"`
-		Sequence  uint32   `json:"sequence"`
-		Scriptsig string   `json:"scriptsig"`
-		Witness   []string `json:"witness"`
+		Txid       string     `json:"txid"`
+		Vout       uint32     `json:"vout"`
+		Prevout    *TxVoutDTO `json:"prevout"` // coinbase 输入为 null
+		Sequence   uint32     `json:"sequence"`
+		Scriptsig  string     `json:"scriptsig"`
+		Witness    []string   `json:"witness"`
+		IsCoinbase bool       `json:"is_coinbase"`
 	} `json:"vin"`
 
-	Vout []struct {
-		Value        int64  `json:"value"` // sats
-		ScriptPubKey string `json:"scriptpubkey"`
-		ScriptType   string `json:"scriptpubkey_type"`
-		Address      string `json:"scriptpubkey_address"`
-	} `json:"vout"`
+	Vout []TxVoutDTO `json:"vout"`
+}
+
+// 交易输出
+type TxVoutDTO struct {
+	Value        int64  `json:"value"` // sats
+	ScriptPubKey string `json:"scriptpubkey"`
+	ScriptType   string `json:"scriptpubkey_type"`
+	Address      string `json:"scriptpubkey_address"`
+}
+
+// 交易确认状态
+type TxStatusDTO struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int    `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   int64  `json:"block_time"`
+}
+
+// 输出花费情况
+type OutspendDTO struct {
+	Spent  bool        `json:"spent"`
+	Txid   string      `json:"txid"`
+	Vin    uint32      `json:"vin"`
+	Status TxStatusDTO `json:"status"`
+}
+
+// merkle 证明
+type MerkleProofDTO struct {
+	BlockHeight int      `json:"block_height"`
+	Merkle      []string `json:"merkle"`
+	Pos         int      `json:"pos"`
+}
+
+// CPFP 分析
+type CPFPDTO struct {
+	Ancestors            []CPFPTxDTO `json:"ancestors"`
+	Descendants          []CPFPTxDTO `json:"descendants"`
+	BestDescendant       *CPFPTxDTO  `json:"bestDescendant"`
+	EffectiveFeePerVsize float64     `json:"effectiveFeePerVsize"`
+	AdjustedVsize        float64     `json:"adjustedVsize"`
+}
+
+type CPFPTxDTO struct {
+	Txid   string  `json:"txid"`
+	Fee    int64   `json:"fee"`
+	Weight int64   `json:"weight"`
+	Rate   float64 `json:"rate"`
+}
+
+// 内存池预估区块
+type MempoolBlockDTO struct {
+	BlockSize  int64     `json:"blockSize"`
+	BlockVSize float64   `json:"blockVSize"`
+	NTx        int       `json:"nTx"`
+	TotalFees  int64     `json:"totalFees"`
+	MedianFee  float64   `json:"medianFee"`
+	FeeRange   []float64 `json:"feeRange"`
+}
+
+// 区块信息(/api/block/:hash 与 /api/v1/blocks 共用)
+type BlockDTO struct {
+	ID                string  `json:"id"`
+	Height            int     `json:"height"`
+	Version           int     `json:"version"`
+	Timestamp         int     `json:"timestamp"`
+	TxCount           int     `json:"tx_count"`
+	Size              int     `json:"size"`
+	Weight            int     `json:"weight"`
+	MerkleRoot        string  `json:"merkle_root"`
+	PreviousBlockHash string  `json:"previousblockhash"`
+	MedianTime        int     `json:"mediantime"`
+	Nonce             uint32  `json:"nonce"`
+	Bits              uint32  `json:"bits"`
+	Difficulty        float64 `json:"difficulty"`
+}
+
+// 区块状态
+type BlockStatusDTO struct {
+	InBestChain bool   `json:"in_best_chain"`
+	Height      int    `json:"height"`
+	NextBest    string `json:"next_best"`
+}
+
+// RBF 替换树节点
+type ReplacementDTO struct {
+	Tx struct {
+		Txid    string  `json:"txid"`
+		Fee     int64   `json:"fee"`
+		Vsize   float64 `json:"vsize"`
+		Value   int64   `json:"value"`
+		Rate    float64 `json:"rate"`
+		RBF     bool    `json:"rbf"`
+		FullRBF bool    `json:"fullRbf"`
+		Mined   bool    `json:"mined"`
+	} `json:"tx"`
+	Time     int64             `json:"time"`
+	FullRBF  bool              `json:"fullRbf"`
+	Mined    bool              `json:"mined"`
+	Replaces []*ReplacementDTO `json:"replaces"`
+}
+
+// 单个交易的 RBF 历史
+type TxRBFDTO struct {
+	Replacements *ReplacementDTO `json:"replacements"`
+	Replaces     []string        `json:"replaces"`
 }
 
 // 从mempool.space获取到的UTXO数据结构
@@ -0,0 +1,333 @@
+package mempoolapis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsReadTimeout  = 90 * time.Second
+	wsWriteTimeout = 10 * time.Second
+	wsMaxBackoff   = 30 * time.Second
+)
+
+// WSClient mempool.space WebSocket(/api/v1/ws) 客户端.
+// 断线后自动重连并重新发送订阅; 订阅可以在连接前后随时修改.
+type WSClient struct {
+	url    string
+	dialer *websocket.Dialer
+	events chan types.MempoolEvent
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	addresses     map[string]bool
+	txids         map[string]bool
+	blocks        bool
+	mempoolBlocks bool
+}
+
+// NewWSClient 使用 REST 接口的地址创建 WebSocket 客户端, http(s) 自动转换为 ws(s)
+func (c *Client) NewWSClient() *WSClient {
+	u := *c.base
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	u.Path = path.Join(u.Path, "/api/v1/ws")
+	return NewWSClient(u.String())
+}
+
+// NewWSClient 创建 WebSocket 客户端, wsURL 为完整地址, 例如 wss://mempool.space/api/v1/ws
+func NewWSClient(wsURL string) *WSClient {
+	return &WSClient{
+		url:       wsURL,
+		dialer:    &websocket.Dialer{HandshakeTimeout: wsWriteTimeout},
+		events:    make(chan types.MempoolEvent, 256),
+		addresses: make(map[string]bool),
+		txids:     make(map[string]bool),
+	}
+}
+
+// Events 事件通道, Run 返回后关闭
+func (w *WSClient) Events() <-chan types.MempoolEvent {
+	return w.events
+}
+
+// SubscribeAddresses 订阅地址: 相关交易进入内存池, 被打包, 被移出内存池时推送
+func (w *WSClient) SubscribeAddresses(addrs ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, a := range addrs {
+		w.addresses[a] = true
+	}
+	return w.sendAddressesLocked()
+}
+
+// UnsubscribeAddresses 取消订阅地址
+func (w *WSClient) UnsubscribeAddresses(addrs ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, a := range addrs {
+		delete(w.addresses, a)
+	}
+	return w.sendAddressesLocked()
+}
+
+// SubscribeTxs 订阅交易: 被打包或被替换时推送, 推送后自动取消订阅
+func (w *WSClient) SubscribeTxs(txids ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range txids {
+		w.txids[id] = true
+	}
+	return w.sendTxidsLocked()
+}
+
+// UnsubscribeTxs 取消订阅交易
+func (w *WSClient) UnsubscribeTxs(txids ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range txids {
+		delete(w.txids, id)
+	}
+	return w.sendTxidsLocked()
+}
+
+// WantBlocks 是否推送新区块与内存池预估区块
+func (w *WSClient) WantBlocks(blocks, mempoolBlocks bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.blocks = blocks
+	w.mempoolBlocks = mempoolBlocks
+	return w.sendWantLocked()
+}
+
+// Run 保持连接并分发事件, 直到 ctx 取消; 返回时关闭事件通道
+func (w *WSClient) Run(ctx context.Context) error {
+	defer close(w.events)
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := w.runOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if time.Since(start) > wsMaxBackoff {
+			backoff = time.Second
+		}
+		logger.Warn("mempool ws: %v, reconnect in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+// 单次连接: 建立连接, 重新发送订阅, 读取消息直到出错
+func (w *WSClient) runOnce(ctx context.Context) error {
+	conn, _, err := w.dialer.DialContext(ctx, w.url, nil)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", w.url, err)
+	}
+	defer conn.Close()
+
+	w.mu.Lock()
+	w.conn = conn
+	err = w.sendWantLocked()
+	if err == nil {
+		err = w.sendAddressesLocked()
+	}
+	if err == nil {
+		err = w.sendTxidsLocked()
+	}
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.conn = nil
+		w.mu.Unlock()
+	}()
+	if err != nil {
+		return err
+	}
+
+	// ctx 取消时关闭连接以结束读取; 定时发送 ping 保持连接
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				w.mu.Lock()
+				err := w.writeLocked(map[string]any{"action": "ping"})
+				w.mu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if err := w.handleMessage(ctx, data); err != nil {
+			logger.Warn("mempool ws: %v", err)
+		}
+	}
+}
+
+// 推送消息, 一条消息可能同时包含多个字段
+type wsMessage struct {
+	Block         *BlockDTO                  `json:"block"`
+	MempoolBlocks []MempoolBlockDTO          `json:"mempool-blocks"`
+	MultiAddress  map[string]wsAddressTxsDTO `json:"multi-address-transactions"`
+	TrackedTxs    map[string]wsTrackedTxDTO  `json:"tracked-txs"`
+}
+
+type wsAddressTxsDTO struct {
+	Mempool   []TxDTO `json:"mempool"`
+	Confirmed []TxDTO `json:"confirmed"`
+	Removed   []TxDTO `json:"removed"`
+}
+
+type wsTrackedTxDTO struct {
+	Confirmed  json.RawMessage `json:"confirmed"` // true 或区块信息
+	ReplacedBy string          `json:"replacedBy"`
+}
+
+func (w *WSClient) handleMessage(ctx context.Context, data []byte) error {
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("decode message: %w", err)
+	}
+
+	var events []types.MempoolEvent
+	var blk *types.ChainBlock
+	if msg.Block != nil {
+		blk = msg.Block.ToTypes()
+		events = append(events, types.MempoolEvent{Type: types.MempoolEventBlock, Block: blk})
+	}
+	if len(msg.MempoolBlocks) > 0 {
+		ev := types.MempoolEvent{Type: types.MempoolEventMempoolBlocks}
+		for _, b := range msg.MempoolBlocks {
+			ev.MempoolBlocks = append(ev.MempoolBlocks, b.ToTypes())
+		}
+		events = append(events, ev)
+	}
+
+	for _, a := range sortedKeys(msg.MultiAddress) {
+		txs := msg.MultiAddress[a]
+		for _, group := range []struct {
+			typ types.MempoolEventType
+			txs []TxDTO
+		}{
+			{types.MempoolEventSeen, txs.Mempool},
+			{types.MempoolEventConfirmed, txs.Confirmed},
+			{types.MempoolEventReplaced, txs.Removed},
+		} {
+			for i := range group.txs {
+				ev := types.MempoolEvent{Type: group.typ, Address: a, TxID: group.txs[i].Txid}
+				detail, err := group.txs[i].ToTxDetail()
+				if err != nil {
+					logger.Warn("mempool ws: %v", err)
+				} else {
+					ev.Tx = detail
+				}
+				if group.typ == types.MempoolEventConfirmed {
+					ev.Block = blk
+				}
+				events = append(events, ev)
+			}
+		}
+	}
+
+	var finished []string
+	for _, txid := range sortedKeys(msg.TrackedTxs) {
+		st := msg.TrackedTxs[txid]
+		switch {
+		case st.ReplacedBy != "":
+			events = append(events, types.MempoolEvent{Type: types.MempoolEventReplaced, TxID: txid, ReplacedBy: st.ReplacedBy})
+		case len(st.Confirmed) > 0 && string(st.Confirmed) != "false" && string(st.Confirmed) != "null":
+			events = append(events, types.MempoolEvent{Type: types.MempoolEventConfirmed, TxID: txid, Block: blk})
+		default:
+			continue
+		}
+		finished = append(finished, txid)
+	}
+	if len(finished) > 0 {
+		if err := w.UnsubscribeTxs(finished...); err != nil {
+			logger.Warn("mempool ws: unsubscribe txs: %v", err)
+		}
+	}
+
+	for _, ev := range events {
+		select {
+		case w.events <- ev:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (w *WSClient) sendWantLocked() error {
+	data := []string{}
+	if w.blocks {
+		data = append(data, "blocks")
+	}
+	if w.mempoolBlocks {
+		data = append(data, "mempool-blocks")
+	}
+	return w.writeLocked(map[string]any{"action": "want", "data": data})
+}
+
+// track-addresses / track-txs 每次发送完整列表, 覆盖之前的订阅
+func (w *WSClient) sendAddressesLocked() error {
+	return w.writeLocked(map[string]any{"track-addresses": sortedKeys(w.addresses)})
+}
+
+func (w *WSClient) sendTxidsLocked() error {
+	return w.writeLocked(map[string]any{"track-txs": sortedKeys(w.txids)})
+}
+
+// 未连接时只记录订阅, 连接建立后统一发送
+func (w *WSClient) writeLocked(v any) error {
+	if w.conn == nil {
+		return nil
+	}
+	w.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return w.conn.WriteJSON(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,38 @@
+// Package ordserver 提供 ord server(ord --index-runes server) JSON API 客户端
+package ordserver
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/transport"
+)
+
+type Client struct {
+	base *url.URL
+	http *http.Client
+	get  *transport.Getter
+}
+
+func New(baseURL string, timeout int) *Client {
+	u, _ := url.Parse(baseURL)
+	c := &Client{
+		base: u,
+		http: transport.NewClient("ord server", nil, time.Duration(timeout)*time.Second),
+	}
+	// ord server 根据 Accept 头返回 JSON 或 HTML
+	c.get = transport.NewGetter("ord server", c.http)
+	c.get.Header = http.Header{"Accept": {"application/json"}}
+	return c
+}
+
+// SetRetryPolicy 替换重试与熔断策略
+func (c *Client) SetRetryPolicy(policy transport.Policy) {
+	transport.Apply(c.http, "ord server", policy)
+}
+
+// SetRateLimit 设置限流(每秒请求数, 突发数), rate <= 0 为不限流
+func (c *Client) SetRateLimit(rate float64, burst int) {
+	transport.SetRateLimit(c.http, "ord server", rate, burst)
+}
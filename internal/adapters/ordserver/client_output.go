@@ -0,0 +1,17 @@
+package ordserver
+
+import (
+	"context"
+	"path"
+)
+
+// 查询输出上的铭文与 rune 余额, outpoint 格式为 txid:vout
+func (c *Client) OutputGet(ctx context.Context, outpoint string) (*OutputDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/output/", outpoint)
+	var dto OutputDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
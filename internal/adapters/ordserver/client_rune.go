@@ -0,0 +1,17 @@
+package ordserver
+
+import (
+	"context"
+	"path"
+)
+
+// 查询 rune 信息, id 可以是 block:tx 或名称
+func (c *Client) RuneGet(ctx context.Context, id string) (*RuneDTO, error) {
+	u := *c.base
+	u.Path = path.Join(u.Path, "/rune/", id)
+	var dto RuneDTO
+	if err := c.get.JSON(ctx, u.String(), &dto); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
@@ -0,0 +1,72 @@
+package ordserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/adaptertest"
+)
+
+// ord server 根据 Accept 头返回 JSON 或 HTML, 假服务只响应 JSON 请求
+func jsonOnly(respond func(key string) (string, bool)) func(*http.Request, string) (string, bool) {
+	return func(r *http.Request, key string) (string, bool) {
+		if r.Header.Get("Accept") != "application/json" {
+			return "", false
+		}
+		return respond(key)
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	adaptertest.Run(t, func(url string) []adaptertest.Endpoint {
+		c := New(url, 5)
+		return []adaptertest.Endpoint{
+			{
+				Name:    "output",
+				Pattern: "GET /output/{key}",
+				// value 为 vout
+				Respond: jsonOnly(func(key string) (string, bool) {
+					txid, vout, _ := strings.Cut(key, ":")
+					n, ok := adaptertest.ParseHash(txid)
+					if !ok || adaptertest.Missing(n) || vout != strconv.Itoa(n) {
+						return "", false
+					}
+					return fmt.Sprintf(`{"value":%d,"indexed":true,"inscriptions":["%si0"]}`, n, txid), true
+				}),
+				Call: func(ctx context.Context, n int) error {
+					out, err := c.OutputGet(ctx, fmt.Sprintf("%s:%d", adaptertest.Hash(n), n))
+					if err == nil && (out.Value != int64(n) || len(out.Inscriptions) != 1 || out.Inscriptions[0] != adaptertest.Hash(n)+"i0") {
+						err = fmt.Errorf("output = %+v", out)
+					}
+					return err
+				},
+				Missing: adaptertest.Missing,
+			},
+			{
+				Name:    "rune",
+				Pattern: "GET /rune/{key}",
+				// id 为 <n>:1
+				Respond: jsonOnly(func(key string) (string, bool) {
+					block, _, _ := strings.Cut(key, ":")
+					n, err := strconv.Atoi(block)
+					if err != nil || adaptertest.Missing(n) {
+						return "", false
+					}
+					return fmt.Sprintf(`{"id":"%s","entry":{"block":%d,"spaced_rune":"RUNE•%d"}}`, key, n, n), true
+				}),
+				Call: func(ctx context.Context, n int) error {
+					dto, err := c.RuneGet(ctx, fmt.Sprintf("%d:1", n))
+					if err == nil && (dto.ID != fmt.Sprintf("%d:1", n) || dto.Entry.Block != uint64(n) || dto.Entry.SpacedRune != fmt.Sprintf("RUNE•%d", n)) {
+						err = fmt.Errorf("rune = %+v", dto)
+					}
+					return err
+				},
+				Missing: adaptertest.Missing,
+			},
+		}
+	})
+}
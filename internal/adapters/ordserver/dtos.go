@@ -0,0 +1,86 @@
+package ordserver
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OutputDTO GET /output/<txid:vout>
+type OutputDTO struct {
+	Address      string          `json:"address"`
+	Inscriptions []string        `json:"inscriptions"`
+	Runes        json.RawMessage `json:"runes"` // 不同版本格式不同, 使用 ParseRunes 解析
+	Spent        bool            `json:"spent"`
+	Value        int64           `json:"value"`
+	Indexed      bool            `json:"indexed"`
+}
+
+// RuneBalanceDTO 输出中的 rune 余额
+type RuneBalanceDTO struct {
+	SpacedRune   string      `json:"-"`
+	Amount       json.Number `json:"amount"` // 最小单位, 可能超过 uint64
+	Divisibility uint8       `json:"divisibility"`
+	Symbol       string      `json:"symbol"`
+}
+
+// ParseRunes 解析 runes 字段.
+// 新版本为 {"SPACED•RUNE": {...}}, 0.18/0.19 为 [["SPACED•RUNE", {...}], ...].
+func (o *OutputDTO) ParseRunes() ([]RuneBalanceDTO, error) {
+	if len(o.Runes) == 0 || string(o.Runes) == "null" {
+		return nil, nil
+	}
+	var out []RuneBalanceDTO
+	var m map[string]RuneBalanceDTO
+	if err := json.Unmarshal(o.Runes, &m); err == nil {
+		for name, b := range m {
+			b.SpacedRune = name
+			out = append(out, b)
+		}
+		return out, nil
+	}
+	var pairs [][2]json.RawMessage
+	if err := json.Unmarshal(o.Runes, &pairs); err != nil {
+		return nil, fmt.Errorf("unexpected runes format: %s", o.Runes)
+	}
+	for _, p := range pairs {
+		var b RuneBalanceDTO
+		if err := json.Unmarshal(p[0], &b.SpacedRune); err != nil {
+			return nil, fmt.Errorf("unexpected runes format: %s", o.Runes)
+		}
+		if err := json.Unmarshal(p[1], &b); err != nil {
+			return nil, fmt.Errorf("unexpected runes format: %s", o.Runes)
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// RuneDTO GET /rune/<id 或 名称>
+type RuneDTO struct {
+	Entry    RuneEntryDTO `json:"entry"`
+	ID       string       `json:"id"`
+	Mintable bool         `json:"mintable"`
+}
+
+type RuneEntryDTO struct {
+	Block        uint64        `json:"block"`
+	Burned       json.Number   `json:"burned"`
+	Divisibility uint8         `json:"divisibility"`
+	Etching      string        `json:"etching"`
+	Mints        json.Number   `json:"mints"`
+	Number       uint64        `json:"number"`
+	Premine      json.Number   `json:"premine"`
+	SpacedRune   string        `json:"spaced_rune"`
+	Symbol       *string       `json:"symbol"`
+	Terms        *RuneTermsDTO `json:"terms"`
+	Timestamp    int64         `json:"timestamp"`
+	Turbo        bool          `json:"turbo"`
+}
+
+// RuneTermsDTO 铸造条款, height/offset 为 [start, end], 未设置的一端为 null
+type RuneTermsDTO struct {
+	Amount json.Number `json:"amount"`
+	Cap    json.Number `json:"cap"`
+	Height [2]*uint64  `json:"height"`
+	Offset [2]*uint64  `json:"offset"`
+}
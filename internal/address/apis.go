@@ -2,9 +2,11 @@ package address
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
-	"errors"
+	"fmt"
 
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -17,7 +19,7 @@ func (c *Client) GetAddressBalanceWithElectrumX(ctx context.Context, addr string
 		}
 		return float64(confirmedSats), float64(unconfirmedSats), nil
 	}
-	return 0, 0, errors.New("btcapis: no client available")
+	return 0, 0, fmt.Errorf("%w: no client available", types.ErrBackendUnavailable)
 }
 
 // 通过扩展私钥查询余额
@@ -25,7 +27,7 @@ func (c *Client) GetAddressBalanceWithElectrumXByXPRV(ctx context.Context, xprv
 	if c.electrumxClient != nil {
 		return c.electrumxClient.GetBalancesByXPRV(ctx, xprv, numAddresses)
 	}
-	return nil, errors.New("btcapis: not implemented yet")
+	return nil, fmt.Errorf("%w: no electrumx client available", types.ErrBackendUnavailable)
 }
 
 // 通过私钥查询余额
@@ -33,7 +35,7 @@ func (c *Client) GetAddressBalanceWithElectrumXByPrivateKey(ctx context.Context,
 	if c.electrumxClient != nil {
 		return c.electrumxClient.GetBalancesByPrivateKey(ctx, privateKeyWIF)
 	}
-	return nil, errors.New("btcapis: not implemented yet")
+	return nil, fmt.Errorf("%w: no electrumx client available", types.ErrBackendUnavailable)
 }
 
 // 批量查询并过滤有余额的地址
@@ -41,7 +43,7 @@ func (c *Client) FilterAddressesWithBalanceWithElectrumX(ctx context.Context, ad
 	if c.electrumxClient != nil {
 		return c.electrumxClient.FilterAddressesWithBalance(ctx, addresses, 0, concurrent)
 	}
-	return nil, errors.New("btcapis: no electrumx client available")
+	return nil, fmt.Errorf("%w: no electrumx client available", types.ErrBackendUnavailable)
 }
 
 // 批量查询所有地址余额
@@ -49,7 +51,7 @@ func (c *Client) BatchGetBalancesWithElectrumX(ctx context.Context, addresses []
 	if c.electrumxClient != nil {
 		return c.electrumxClient.BatchGetBalances(ctx, addresses, concurrent)
 	}
-	return nil, errors.New("btcapis: no electrumx client available")
+	return nil, fmt.Errorf("%w: no electrumx client available", types.ErrBackendUnavailable)
 }
 
 // // GetAddressUTXOsWithElectrumX 通过ElectrumX获取地址的UTXO
@@ -78,16 +80,25 @@ func (c *Client) BatchGetBalancesWithElectrumX(ctx context.Context, addresses []
 // }
 
 // GetAddressBalance 通过地址, 获取地址的确认余额和未确认余额.
+// 设置了 bitcoind 钱包且地址由钱包关注时, 从钱包查询.
 func (c *Client) GetAddressBalance(ctx context.Context, addr string) (float64, float64, error) {
+	if c.walletWatches(ctx, addr) {
+		return c.walletClient.GetAddressBalance(ctx, addr)
+	}
 	if c.mempoolapisClient != nil {
 		return c.mempoolapisClient.AddressGetBalance(ctx, addr)
 	}
-	return 0, 0, errors.New("btcapis: no client available")
+	return 0, 0, fmt.Errorf("%w: no client available", types.ErrBackendUnavailable)
 }
 
 // GetAddressUTXOs 通过地址, 获取地址拥有的UTXO.
+// 设置了 bitcoind 钱包且地址由钱包关注时, 从钱包查询.
 func (c *Client) GetAddressUTXOs(ctx context.Context, addr string) ([]types.TxUTXO, error) {
-	errRet := errors.New("btcapis: no client available or no utxos")
+	if c.walletWatches(ctx, addr) {
+		return c.walletClient.ListUnspent(ctx, addr)
+	}
+
+	errRet := fmt.Errorf("%w: no client available or no utxos", types.ErrBackendUnavailable)
 
 	// 全量扫UTXO耗时太长, 暂时使用mempool.space的API
 	// if bitcoindrpc.IsInited() {
@@ -127,3 +138,92 @@ func (c *Client) GetAddressUTXOs(ctx context.Context, addr string) ([]types.TxUT
 
 	return nil, errRet
 }
+
+// GetAddressTxsChain 地址已确认交易历史, 每页 25 条, 按时间倒序; afterTxid 为上一页最后一个 txid, 为空时返回第一页.
+func (c *Client) GetAddressTxsChain(ctx context.Context, addr string, afterTxid string) ([]*types.TxDetail, error) {
+	if c.mempoolapisClient == nil {
+		return nil, fmt.Errorf("%w: no mempool.space client available", types.ErrBackendUnavailable)
+	}
+	dtos, err := c.mempoolapisClient.AddressGetTxsChain(ctx, addr, afterTxid)
+	if err != nil {
+		return nil, err
+	}
+	return mempoolapis.TxDTOsToDetails(dtos)
+}
+
+// GetAddressTxsMempool 地址未确认交易, 最多 50 条.
+func (c *Client) GetAddressTxsMempool(ctx context.Context, addr string) ([]*types.TxDetail, error) {
+	if c.mempoolapisClient == nil {
+		return nil, fmt.Errorf("%w: no mempool.space client available", types.ErrBackendUnavailable)
+	}
+	dtos, err := c.mempoolapisClient.AddressGetTxsMempool(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return mempoolapis.TxDTOsToDetails(dtos)
+}
+
+// 按脚本哈希查询的接口, 目前只有 Esplora 提供
+type scripthashBackend interface {
+	ScripthashGetBalance(ctx context.Context, scripthash string) (float64, float64, error)
+	ScripthashGetUTXOs(ctx context.Context, scripthash string) ([]mempoolapis.UTXODTO, error)
+	ScripthashGetTxsChain(ctx context.Context, scripthash string, afterTxid string) ([]mempoolapis.TxDTO, error)
+}
+
+func (c *Client) scripthashBackend(pkScript []byte) (scripthashBackend, string, error) {
+	sb, ok := mempoolapis.Unwrap(c.mempoolapisClient).(scripthashBackend)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: scripthash queries require an esplora client", types.ErrBackendUnavailable)
+	}
+	// Esplora 的 scripthash 为 sha256(pkScript) 的自然字节序 hex, 与 Electrum 协议的逆序不同
+	h := sha256.Sum256(pkScript)
+	return sb, hex.EncodeToString(h[:]), nil
+}
+
+// GetScriptBalance 通过锁定脚本, 获取确认余额和未确认余额(适用于没有地址的脚本).
+func (c *Client) GetScriptBalance(ctx context.Context, pkScript []byte) (float64, float64, error) {
+	sb, scripthash, err := c.scripthashBackend(pkScript)
+	if err != nil {
+		return 0, 0, err
+	}
+	return sb.ScripthashGetBalance(ctx, scripthash)
+}
+
+// GetScriptUTXOs 通过锁定脚本, 获取脚本拥有的UTXO.
+func (c *Client) GetScriptUTXOs(ctx context.Context, pkScript []byte) ([]types.TxUTXO, error) {
+	sb, scripthash, err := c.scripthashBackend(pkScript)
+	if err != nil {
+		return nil, err
+	}
+	dtos, err := sb.ScripthashGetUTXOs(ctx, scripthash)
+	if err != nil {
+		return nil, err
+	}
+	utxos := make([]types.TxUTXO, 0, len(dtos))
+	for _, dto := range dtos {
+		txidBytes, _ := hex.DecodeString(dto.Txid)
+		u := types.TxUTXO{
+			OutPoint: types.TxOutPoint{Hash: types.Hash32(txidBytes), Index: dto.Vout},
+			Value:    dto.Value,
+			PkScript: pkScript,
+		}
+		if dto.Status.Confirmed {
+			u.Height = uint32(dto.Status.BlockHeight)
+		}
+		utxos = append(utxos, u)
+	}
+	return utxos, nil
+}
+
+// GetScriptTxsChain 通过锁定脚本, 获取已确认交易历史, 分页方式同 GetAddressTxsChain.
+func (c *Client) GetScriptTxsChain(ctx context.Context, pkScript []byte, afterTxid string) ([]*types.TxDetail, error) {
+	sb, scripthash, err := c.scripthashBackend(pkScript)
+	if err != nil {
+		return nil, err
+	}
+	dtos, err := sb.ScripthashGetTxsChain(ctx, scripthash, afterTxid)
+	if err != nil {
+		return nil, err
+	}
+	return mempoolapis.TxDTOsToDetails(dtos)
+}
@@ -1,21 +1,44 @@
 package address
 
 import (
+	"context"
+
 	"github.com/crazycloudcc/btcapis/internal/adapters/bitcoindrpc"
 	"github.com/crazycloudcc/btcapis/internal/adapters/electrumx"
 	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/internal/wallet"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 )
 
 type Client struct {
 	bitcoindrpcClient *bitcoindrpc.Client
-	mempoolapisClient *mempoolapis.Client
+	mempoolapisClient mempoolapis.Backend // mempool.space 或 Esplora
 	electrumxClient   *electrumx.Client
+	walletClient      *wallet.Client // 可选, bitcoind 只读描述符钱包, 钱包关注的地址优先从钱包查询
 }
 
-func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient *mempoolapis.Client, electrumxClient *electrumx.Client) *Client {
+func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient mempoolapis.Backend, electrumxClient *electrumx.Client) *Client {
 	return &Client{
 		bitcoindrpcClient: bitcoindrpcClient,
 		mempoolapisClient: mempoolapisClient,
 		electrumxClient:   electrumxClient,
 	}
 }
+
+// 设置 bitcoind 钱包作为地址余额/UTXO 的数据来源
+func (c *Client) SetWallet(w *wallet.Client) {
+	c.walletClient = w
+}
+
+// 地址是否由钱包关注; 查询失败时视为不关注, 回退到其他数据源
+func (c *Client) walletWatches(ctx context.Context, addr string) bool {
+	if c.walletClient == nil {
+		return false
+	}
+	ok, err := c.walletClient.IsWatching(ctx, addr)
+	if err != nil {
+		logger.Warn("bitcoind wallet %s: %v", c.walletClient.Name(), err)
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,80 @@
+// Package cache 链上数据缓存: 原始交易/区块/区块头等不可变数据只在达到足够确认数(不会被重组)后长期缓存,
+// 手续费等变化的数据按 TTL 缓存. 内置内存 LRU, 本地文件与 Redis 三种存储, 其他存储(BoltDB, Badger 等)实现 Cache 接口即可.
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Cache 缓存存储; ttl 为 0 表示不过期. 读写失败时调用方直接访问数据源, 缓存不影响结果正确性.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Policy 缓存策略
+type Policy struct {
+	MinConfirmations int           // 交易/高度->区块哈希 达到该确认数后才缓存, 避免重组后返回旧数据
+	ConfirmedTTL     time.Duration // 已确认数据的缓存时间, 0 为不过期
+	VolatileTTL      time.Duration // 手续费, 链高度, 交易确认状态等变化数据的缓存时间, 0 为不缓存(此时 REST 数据源的原始交易也不缓存)
+}
+
+// DefaultPolicy 默认策略: 6 个确认后永久缓存, 变化数据缓存 30 秒
+func DefaultPolicy() Policy {
+	return Policy{
+		MinConfirmations: 6,
+		VolatileTTL:      30 * time.Second,
+	}
+}
+
+// Deep 高度为 height 的区块在链高度为 tip 时是否已有足够确认数
+func (p Policy) Deep(height, tip int) bool {
+	return height > 0 && tip-height+1 >= p.MinConfirmations
+}
+
+// 缓存 key 都以当前网络开头(types.CurrentNetwork), 不同网络的服务共用同一个 Redis/目录时不会互相覆盖.
+// 不可变数据(按哈希索引的原始数据)各数据源共享, 其他数据按数据源区分
+func KeyRawTx(txid string) string     { return Key("rawtx", txid) }
+func KeyRawBlock(hash string) string  { return Key("rawblock", hash) }
+func KeyRawHeader(hash string) string { return Key("rawheader", hash) }
+func KeyBlockHash(height int) string  { return Key("blockhash", strconv.Itoa(height)) }
+
+// Key 数据源相关的 key, 例如 Key("mempool.space", "tx", txid) -> "mainnet:mempool.space:tx:<txid>"
+func Key(backend string, parts ...string) string {
+	k := string(types.CurrentNetwork) + ":" + backend
+	for _, p := range parts {
+		k += ":" + p
+	}
+	return k
+}
+
+// Get 读取缓存, c 为 nil 或读取失败时视为未命中
+func Get(ctx context.Context, c Cache, key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok, err := c.Get(ctx, key)
+	if err != nil {
+		logger.Warn("cache get %s: %v", key, err)
+		ok = false
+	}
+	telemetry.CacheResult(key, ok)
+	return v, ok
+}
+
+// Set 写入缓存, c 为 nil 时忽略, 失败只打印
+func Set(ctx context.Context, c Cache, key string, value []byte, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		logger.Warn("cache set %s: %v", key, err)
+	}
+}
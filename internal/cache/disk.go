@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+)
+
+// Disk 本地文件缓存, 每个 key 一个文件(文件名为 key 的 sha256), 进程重启后仍然有效.
+// 文件内容为 8 字节过期时间(UnixNano, 0 为不过期) + 数据; 写入先写临时文件再改名, 多进程共享目录是安全的.
+// 总大小超过 maxBytes 时清理: 先删除过期文件, 再按修改时间删除最久未使用的文件(命中时更新修改时间),
+// 直到低于上限的 90%. 多进程共享目录时各进程分别统计写入量, 清理时以目录的实际大小为准.
+type Disk struct {
+	dir      string
+	maxBytes int64
+
+	mu       sync.Mutex
+	size     int64 // 估算的目录大小, 清理时重新统计
+	sweeping bool
+}
+
+// NewDisk 使用目录 dir 创建文件缓存, 目录不存在时创建; maxBytes 为 0 时不限制大小
+func NewDisk(dir string, maxBytes int64) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cache dir: %w", err)
+	}
+	d := &Disk{dir: dir, maxBytes: maxBytes}
+	if maxBytes > 0 {
+		size, err := d.Sweep()
+		if err != nil {
+			return nil, fmt.Errorf("cache dir: %w", err)
+		}
+		d.size = size
+	}
+	return d, nil
+}
+
+// 两级目录, 避免单个目录下文件过多
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(d.dir, name[:2], name)
+}
+
+func (d *Disk) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := os.ReadFile(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) < 8 {
+		// 损坏的文件按未命中处理
+		os.Remove(d.path(key))
+		return nil, false, nil
+	}
+	if exp := int64(binary.BigEndian.Uint64(b)); exp != 0 && time.Now().UnixNano() > exp {
+		os.Remove(d.path(key))
+		return nil, false, nil
+	}
+	if d.maxBytes > 0 {
+		now := time.Now()
+		os.Chtimes(d.path(key), now, now)
+	}
+	return b[8:], true, nil
+}
+
+func (d *Disk) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	p := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b := make([]byte, 8+len(value))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(b, uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	copy(b[8:], value)
+
+	f, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), p); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	d.grow(int64(len(b)))
+	return nil
+}
+
+// 记录写入量, 超过上限时在后台清理
+func (d *Disk) grow(n int64) {
+	if d.maxBytes <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.size += n
+	if d.size <= d.maxBytes || d.sweeping {
+		return
+	}
+	d.sweeping = true
+	go func() {
+		size, err := d.Sweep()
+		if err != nil {
+			logger.Warn("cache sweep %s: %v", d.dir, err)
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if err == nil {
+			d.size = size
+		}
+		d.sweeping = false
+	}()
+}
+
+type diskFile struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+// Sweep 删除过期文件与遗留的临时文件, 总大小超过上限时按修改时间删除最旧的文件, 返回清理后的总大小.
+// 目录中不符合缓存布局的文件不统计也不删除.
+func (d *Disk) Sweep() (int64, error) {
+	now := time.Now()
+	var files []diskFile
+	var total int64
+	err := filepath.WalkDir(d.dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // 其他进程同时删除
+			}
+			return err
+		}
+		if e.IsDir() {
+			if p != d.dir && (filepath.Dir(p) != d.dir || !isHex(e.Name(), 2)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// 只处理 path() 生成的 <2 位 hex>/<64 位 hex> 文件及其临时文件, 目录中的其他文件不属于缓存
+		if filepath.Dir(filepath.Dir(p)) != d.dir {
+			return nil
+		}
+		name := e.Name()
+		if !strings.HasPrefix(name, ".tmp-") && (!isHex(name, 64) || name[:2] != filepath.Base(filepath.Dir(p))) {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(name, ".tmp-") {
+			// 写入中断留下的临时文件; 正在写入的文件很新, 不删除
+			if now.Sub(info.ModTime()) > time.Hour {
+				os.Remove(p)
+			}
+			return nil
+		}
+		if expired(p, now) {
+			os.Remove(p)
+			return nil
+		}
+		files = append(files, diskFile{path: p, size: info.Size(), mtime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if d.maxBytes <= 0 || total <= d.maxBytes {
+		return total, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].mtime.Before(files[j].mtime) })
+	target := d.maxBytes / 10 * 9
+	for _, f := range files {
+		if total <= target {
+			break
+		}
+		if err := os.Remove(f.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+			total -= f.size
+		}
+	}
+	return total, nil
+}
+
+// s 是否为 n 位小写 hex(与 hex.EncodeToString 一致)
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// 读取文件头判断是否过期
+func expired(path string, now time.Time) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	var head [8]byte
+	if _, err := io.ReadFull(f, head[:]); err != nil {
+		return true // 损坏的文件
+	}
+	exp := int64(binary.BigEndian.Uint64(head[:]))
+	return exp != 0 && now.UnixNano() > exp
+}
+
+func (d *Disk) Delete(ctx context.Context, key string) error {
+	err := os.Remove(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskSweep(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDisk(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := make([]byte, 92) // 加上 8 字节文件头共 100 字节
+	base := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b", "c", "d"} {
+		if err := d.Set(ctx, key, value, 0); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(d.path(key), mtime, mtime)
+	}
+	if err := d.Set(ctx, "expired", value, time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	// 不属于缓存的文件: 不统计, 也不删除
+	name := filepath.Base(d.path("a"))
+	foreign := []string{
+		filepath.Join(d.dir, "notes.txt"),
+		filepath.Join(d.dir, name[:2], "notes.txt"),
+		filepath.Join(d.dir, "zz", name),
+		filepath.Join(d.dir, name[:2], "x", name),
+	}
+	for _, p := range foreign {
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(p, base, base)
+	}
+
+	// 命中后 a 成为最近使用的文件
+	d.maxBytes = 250
+	if _, ok, _ := d.Get(ctx, "a"); !ok {
+		t.Fatal("a not found")
+	}
+	size, err := d.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 200 {
+		t.Errorf("size after sweep = %d, want 200", size)
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": false, "d": true, "expired": false} {
+		if _, err := os.Stat(d.path(key)); (err == nil) != want {
+			t.Errorf("%s kept = %v, want %v", key, err == nil, want)
+		}
+	}
+	for _, p := range foreign {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s removed", p)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+var errNoBitcoind = fmt.Errorf("%w: bitcoind rpc client not configured", types.ErrBackendUnavailable)
+
+// 获取节点区块数量(最新高度): 优先使用bitcoindrpc, 没有时使用mempoolapis
+func (c *Client) GetBlockCount(ctx context.Context) (int, error) {
+	if c.bitcoindrpcClient != nil {
+		return c.bitcoindrpcClient.ChainGetBlockCount(ctx)
+	}
+	if c.mempoolapisClient != nil {
+		return c.mempoolapisClient.BlocksTipHeight(ctx)
+	}
+	return 0, errNoBitcoind
+}
+
+// 使用区块高度 查询区块哈希: 优先使用bitcoindrpc, 没有时使用mempoolapis
+func (c *Client) GetBlockHash(ctx context.Context, height int) (string, error) {
+	if c.bitcoindrpcClient != nil {
+		return c.bitcoindrpcClient.ChainGetBlockHash(ctx, int64(height))
+	}
+	if c.mempoolapisClient != nil {
+		return c.mempoolapisClient.BlockHeightGetHash(ctx, height)
+	}
+	return "", errNoBitcoind
+}
+
+// 使用区块哈希 查询完整区块(含交易)
+func (c *Client) GetBlock(ctx context.Context, hash string) (*types.Block, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, errNoBitcoind
+	}
+	header, err := c.bitcoindrpcClient.ChainGetBlockHeader(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.bitcoindrpcClient.ChainGetBlockRaw(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return decoders.DecodeRawBlock(raw, header.Height)
+}
+
+// 查询交易输出的锁定脚本及交易所在区块高度, 未确认时高度为 -1
+func (c *Client) GetTxOut(ctx context.Context, txid string, vout uint32) ([]byte, int, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, 0, errNoBitcoind
+	}
+	out, err := c.getRawTxOut(ctx, txid, vout)
+	if err != nil {
+		return nil, 0, err
+	}
+	pkScript := out.PkScript
+
+	blockHash, err := c.bitcoindrpcClient.TxGetBlockHash(ctx, txid)
+	if err != nil {
+		return nil, 0, err
+	}
+	if blockHash == "" {
+		return pkScript, -1, nil
+	}
+	header, err := c.bitcoindrpcClient.ChainGetBlockHeader(ctx, blockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pkScript, header.Height, nil
+}
+
+// 查询交易输出金额(sats), 已花费的输出同样可以查询(需要节点开启 txindex)
+func (c *Client) GetTxOutValue(ctx context.Context, txid string, vout uint32) (int64, error) {
+	if c.bitcoindrpcClient == nil {
+		return 0, errNoBitcoind
+	}
+	out, err := c.getRawTxOut(ctx, txid, vout)
+	if err != nil {
+		return 0, err
+	}
+	return out.Value, nil
+}
+
+func (c *Client) getRawTxOut(ctx context.Context, txid string, vout uint32) (*wire.TxOut, error) {
+	raw, err := c.bitcoindrpcClient.TxGetRaw(ctx, txid, false)
+	if err != nil {
+		return nil, err
+	}
+	var m wire.MsgTx
+	if err := m.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	if int(vout) >= len(m.TxOut) {
+		return nil, fmt.Errorf("vout %d out of range for %s", vout, txid)
+	}
+	return m.TxOut[vout], nil
+}
+
+// 使用区块哈希 查询原始区块头(80 字节)
+func (c *Client) GetBlockHeaderRaw(ctx context.Context, hash string) ([]byte, error) {
+	if c.bitcoindrpcClient != nil {
+		return c.bitcoindrpcClient.ChainGetBlockHeaderRaw(ctx, hash)
+	}
+	if c.mempoolapisClient != nil {
+		return c.mempoolapisClient.BlockGetHeader(ctx, hash)
+	}
+	return nil, errNoBitcoind
+}
+
+// 使用区块哈希 查询原始区块数据
+func (c *Client) GetBlockRaw(ctx context.Context, hash string) ([]byte, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, errNoBitcoind
+	}
+	return c.bitcoindrpcClient.ChainGetBlockRaw(ctx, hash)
+}
+
+// 使用区块哈希 查询 BIP158 basic 过滤器及过滤器头, 需要节点开启 blockfilterindex
+func (c *Client) GetBlockFilter(ctx context.Context, hash string) ([]byte, string, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, "", errNoBitcoind
+	}
+	dto, err := c.bitcoindrpcClient.ChainGetBlockFilter(ctx, hash)
+	if err != nil {
+		return nil, "", err
+	}
+	filter, err := hex.DecodeString(dto.Filter)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid block filter: %w", err)
+	}
+	return filter, dto.Header, nil
+}
@@ -7,10 +7,10 @@ import (
 
 type Client struct {
 	bitcoindrpcClient *bitcoindrpc.Client
-	mempoolapisClient *mempoolapis.Client
+	mempoolapisClient mempoolapis.Backend // mempool.space 或 Esplora
 }
 
-func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient *mempoolapis.Client) *Client {
+func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient mempoolapis.Backend) *Client {
 	return &Client{
 		bitcoindrpcClient: bitcoindrpcClient,
 		mempoolapisClient: mempoolapisClient,
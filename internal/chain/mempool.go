@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 按费率预估的内存池区块, 第一个为下一个区块
+func (c *Client) GetMempoolBlocks(ctx context.Context) ([]types.MempoolBlockFees, error) {
+	ex, err := mempoolapis.AsExtras(c.mempoolapisClient)
+	if err != nil {
+		return nil, err
+	}
+	dtos, err := ex.FeesMempoolBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]types.MempoolBlockFees, len(dtos))
+	for i, d := range dtos {
+		ret[i] = d.ToTypes()
+	}
+	return ret, nil
+}
+
+// 最近的 15 个区块; startHeight >= 0 时从该高度向下分页
+func (c *Client) GetRecentBlocks(ctx context.Context, startHeight int) ([]*types.ChainBlock, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dtos, err := c.mempoolapisClient.BlocksGet(ctx, startHeight)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*types.ChainBlock, len(dtos))
+	for i := range dtos {
+		ret[i] = dtos[i].ToTypes()
+	}
+	return ret, nil
+}
+
+// 区块摘要(不含交易列表)
+func (c *Client) GetBlockInfo(ctx context.Context, hash string) (*types.ChainBlock, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dto, err := c.mempoolapisClient.BlockGet(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTypes(), nil
+}
+
+// 区块头, 只包含 80 字节区块头中的字段
+func (c *Client) GetBlockHeader(ctx context.Context, hash string) (*types.ChainBlock, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	raw, err := c.mempoolapisClient.BlockGetHeader(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return decoders.DecodeBlockHeader(raw)
+}
+
+// 区块是否在主链上
+func (c *Client) GetBlockStatus(ctx context.Context, hash string) (*types.BlockStatus, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dto, err := c.mempoolapisClient.BlockGetStatus(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTypes(), nil
+}
+
+// 区块内全部 txid
+func (c *Client) GetBlockTxids(ctx context.Context, hash string) ([]string, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	return c.mempoolapisClient.BlockGetTxids(ctx, hash)
+}
+
+// 区块内的交易, 每页 25 条, startIndex 需要是 25 的倍数
+func (c *Client) GetBlockTxs(ctx context.Context, hash string, startIndex int) ([]*types.TxDetail, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dtos, err := c.mempoolapisClient.BlockGetTxs(ctx, hash, startIndex)
+	if err != nil {
+		return nil, err
+	}
+	return mempoolapis.TxDTOsToDetails(dtos)
+}
+
+// 创建 mempool.space WebSocket 客户端, 用于实时跟踪地址与交易
+func (c *Client) NewMempoolWatcher() (*mempoolapis.WSClient, error) {
+	ex, err := mempoolapis.AsExtras(c.mempoolapisClient)
+	if err != nil {
+		return nil, err
+	}
+	return ex.NewWSClient(), nil
+}
@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/bitcoindrpc"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// bitcoind REST getutxos 中内存池输出的高度
+const restMempoolHeight = 0x7fffffff
+
+// 批量查询输出是否未花费(bitcoind REST getutxos), outpoints 格式为 txid:vout.
+// 返回结果与 outpoints 一一对应, 已花费或不存在时为 nil; includeMempool 为 true 时计入内存池, 内存池中的输出高度为 0.
+func (c *Client) GetOutpointUTXOs(ctx context.Context, outpoints []string, includeMempool bool) ([]*types.TxUTXO, error) {
+	if c.bitcoindrpcClient == nil {
+		return nil, errNoBitcoind
+	}
+	ret := make([]*types.TxUTXO, 0, len(outpoints))
+	for start := 0; start < len(outpoints); start += bitcoindrpc.RestMaxUTXOOutpoints {
+		batch := outpoints[start:min(start+bitcoindrpc.RestMaxUTXOOutpoints, len(outpoints))]
+		dto, err := c.bitcoindrpcClient.RestGetUTXOs(ctx, batch, includeMempool)
+		if err != nil {
+			return nil, err
+		}
+		if len(dto.Bitmap) != len(batch) {
+			return nil, fmt.Errorf("getutxos: bitmap length %d != %d", len(dto.Bitmap), len(batch))
+		}
+		next := 0
+		for i, op := range batch {
+			if dto.Bitmap[i] != '1' {
+				ret = append(ret, nil)
+				continue
+			}
+			if next >= len(dto.UTXOs) {
+				return nil, fmt.Errorf("getutxos: missing utxo for %s", op)
+			}
+			u, err := restUTXO(op, &dto.UTXOs[next])
+			if err != nil {
+				return nil, err
+			}
+			next++
+			ret = append(ret, u)
+		}
+	}
+	return ret, nil
+}
+
+func restUTXO(outpoint string, dto *bitcoindrpc.RestUTXODTO) (*types.TxUTXO, error) {
+	txid, voutStr, _ := strings.Cut(outpoint, ":")
+	txidBytes, err := hex.DecodeString(txid)
+	if err != nil || len(txidBytes) != 32 {
+		return nil, fmt.Errorf("invalid outpoint %s", outpoint)
+	}
+	vout, err := strconv.ParseUint(voutStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outpoint %s", outpoint)
+	}
+	pkScript, err := hex.DecodeString(dto.ScriptPubKey.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scriptPubKey for %s: %w", outpoint, err)
+	}
+	u := &types.TxUTXO{
+		// 与地址查询得到的 UTXO 一致, Hash 中保存的是展示顺序的 txid
+		OutPoint: types.TxOutPoint{Hash: types.Hash32(txidBytes), Index: uint32(vout)},
+		Value:    int64(dto.Value*1e8 + 0.5),
+		PkScript: pkScript,
+		Address:  dto.ScriptPubKey.Address,
+	}
+	if dto.Height != restMempoolHeight {
+		u.Height = uint32(dto.Height)
+	}
+	return u, nil
+}
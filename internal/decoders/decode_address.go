@@ -5,6 +5,7 @@ import (
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -56,16 +57,21 @@ func DecodeAddress(addr string) (*types.AddressScriptInfo, error) {
 		return nil, fmt.Errorf("make scriptPubKey: %w", err)
 	}
 
-	// 2) 分类（模板识别）
+	// 2) 分类（模板识别）; Typ 使用 types.Addr* 常量, 无法识别时保留 btcd 的脚本类别名称.
+	// 早期版本直接使用 btcd 的名称: pubkeyhash, scripthash, witness_v0_keyhash, witness_v0_scripthash,
+	// 对应现在的 p2pkh, p2sh, p2wpkh, p2wsh(psbt_builder 按 types.Addr* 判断输入类型)
 	cls, _, _, _ := txscript.ExtractPkScriptAddrs(pkScript, types.CurrentNetworkParams)
-	stype := cls.String()
+	stype, err := PKScriptToType(pkScript)
+	if err != nil {
+		stype = types.AddressType(cls.String())
+	}
 
 	// 3) 反汇编
 	asm, _ := txscript.DisasmString(pkScript)
 
 	info := &types.AddressScriptInfo{
 		Address:         addr,
-		Typ:             types.AddressType(stype),
+		Typ:             stype,
 		Cls:             cls,
 		ScriptPubKeyHex: pkScript,
 		ScriptAsm:       asm,
@@ -149,31 +155,16 @@ func printDecodeAddress(addr string, info *types.AddressScriptInfo) {
 
 	ops, asm, err := DecodeAsmScript(pkScript)
 	if err != nil {
-		fmt.Printf("[DisasmScript] %s\n", err)
+		logger.Debug("[DisasmScript] %s", err)
 	}
 
 	// 打印详细的解析结果，便于调试和验证
-	fmt.Printf("Addr2ScriptHash ===================================\n")
-	fmt.Printf("[pkScript %d] %x\n", len(pkScript), pkScript)
-	fmt.Printf("[DisasmScriptOps] %v\n", ops)
-	fmt.Printf("[DisasmScript] %s\n", asm)
-
-	fmt.Printf("[Network] %s\n", types.CurrentNetwork)
-	fmt.Printf("[Address] %s\n", addr)
-	fmt.Printf("[AddressType] %s\n", info.Typ)
-	fmt.Printf("[ScriptClass] %s\n", info.Cls.String())
-	fmt.Printf("[PubKeyHash %d] %x\n", len(info.PubKeyHashHex), info.PubKeyHashHex)
-	fmt.Printf("[RedeemScript %d] %x\n", len(info.RedeemScriptHashHex), info.RedeemScriptHashHex)
-
-	fmt.Printf("[ScriptPubKeyHex(pkScript) %d] %x\n", len(info.ScriptPubKeyHex), info.ScriptPubKeyHex)
-	fmt.Printf("[ScriptAsm] %s\n", info.ScriptAsm)
-
-	fmt.Printf("[IsWitness] %t\n", info.IsWitness)
-	fmt.Printf("[WitnessVersion] %d\n", info.WitnessVersion)
-	fmt.Printf("[WitnessScript %d] %x\n", len(info.WitnessProgramHex), info.WitnessProgramHex)
-	fmt.Printf("[WitnessProgramLen] %d\n", info.WitnessProgramLen)
-	fmt.Printf("[BechEncoding] %s\n", info.BechEncoding)
-
-	fmt.Printf("[TaprootKey %d] %x\n", len(info.TaprootOutputKeyHex), info.TaprootOutputKeyHex)
-	fmt.Printf("Addr2ScriptHash ===================================\n")
+	logger.Debug("Addr2ScriptHash [pkScript %d] %x [DisasmScriptOps] %v [DisasmScript] %s [Network] %s [Address] %s [AddressType] %s [ScriptClass] %s",
+		len(pkScript), pkScript, ops, asm, types.CurrentNetwork, addr, info.Typ, info.Cls.String())
+	logger.Debug("Addr2ScriptHash [PubKeyHash %d] %x [RedeemScript %d] %x [ScriptPubKeyHex(pkScript) %d] %x [ScriptAsm] %s",
+		len(info.PubKeyHashHex), info.PubKeyHashHex, len(info.RedeemScriptHashHex), info.RedeemScriptHashHex,
+		len(info.ScriptPubKeyHex), info.ScriptPubKeyHex, info.ScriptAsm)
+	logger.Debug("Addr2ScriptHash [IsWitness] %t [WitnessVersion] %d [WitnessScript %d] %x [WitnessProgramLen] %d [BechEncoding] %s [TaprootKey %d] %x",
+		info.IsWitness, info.WitnessVersion, len(info.WitnessProgramHex), info.WitnessProgramHex, info.WitnessProgramLen,
+		info.BechEncoding, len(info.TaprootOutputKeyHex), info.TaprootOutputKeyHex)
 }
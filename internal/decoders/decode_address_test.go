@@ -0,0 +1,27 @@
+package decoders
+
+import (
+	"testing"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Typ 使用 types.Addr* 常量, 不再是 btcd 的脚本类别名称(pubkeyhash, witness_v0_keyhash 等)
+func TestDecodeAddressTyp(t *testing.T) {
+	for addr, want := range map[string]types.AddressType{
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa":                             types.AddrP2PKH,
+		"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy":                             types.AddrP2SH,
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4":                     types.AddrP2WPKH,
+		"bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3": types.AddrP2WSH,
+		"bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297": types.AddrP2TR,
+	} {
+		info, err := DecodeAddress(addr)
+		if err != nil {
+			t.Errorf("%s: %v", addr, err)
+			continue
+		}
+		if info.Typ != want {
+			t.Errorf("%s: Typ = %q, want %q", addr, info.Typ, want)
+		}
+	}
+}
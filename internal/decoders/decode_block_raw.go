@@ -0,0 +1,47 @@
+package decoders
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// DecodeRawBlock 解析原始区块数据, 原始数据不包含高度, 需要调用方传入
+func DecodeRawBlock(raw []byte, height int) (*types.Block, error) {
+	var m wire.MsgBlock
+	if err := m.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+
+	b := &types.Block{
+		Hash:     m.BlockHash().String(),
+		PrevHash: m.Header.PrevBlock.String(),
+		Height:   height,
+		Time:     m.Header.Timestamp.Unix(),
+		Txs:      make([]*types.Tx, len(m.Transactions)),
+	}
+	for i, tx := range m.Transactions {
+		b.Txs[i] = DecodeMsgTx(tx)
+	}
+	return b, nil
+}
+
+// DecodeBlockHeader 解析 80 字节区块头, 高度等链上下文字段为空
+func DecodeBlockHeader(raw []byte) (*types.ChainBlock, error) {
+	var h wire.BlockHeader
+	if err := h.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return &types.ChainBlock{
+		Hash:              h.BlockHash().String(),
+		Version:           int(h.Version),
+		VersionHex:        fmt.Sprintf("%08x", uint32(h.Version)),
+		MerkleRoot:        h.MerkleRoot.String(),
+		Time:              int(h.Timestamp.Unix()),
+		Nonce:             int(h.Nonce),
+		Bits:              fmt.Sprintf("%08x", h.Bits),
+		PreviousBlockHash: h.PrevBlock.String(),
+	}, nil
+}
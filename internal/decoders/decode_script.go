@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -42,7 +43,7 @@ func DecodePkScript(pkScript []byte) (*types.AddressInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("DecodePkScript cls: %s\n", cls.String())
+	logger.Debug("DecodePkScript cls: %s", cls.String())
 	typ, err := PKScriptToType(pkScript)
 	if err != nil {
 		return nil, err
@@ -61,19 +62,12 @@ func printDecodePkScript(info *types.AddressInfo) {
 
 	ops, asm, err := DecodeAsmScript(info.PKScript)
 	if err != nil {
-		fmt.Printf("[DecodeAsmScript] %s\n", err)
+		logger.Debug("[DecodeAsmScript] %s", err)
 	}
 
 	// 打印详细的解析结果，便于调试和验证
-	fmt.Printf("PKScript2Address ===================================\n")
-	fmt.Printf("[Network] %s\n", types.CurrentNetwork)
-	fmt.Printf("[PKScript] %x\n", info.PKScript)
-	fmt.Printf("[AsmScriptOps] %v\n", ops)
-	fmt.Printf("[AsmScript] %s\n", asm)
-	fmt.Printf("[AddressType] %s\n", info.Typ)
-	fmt.Printf("[ReqSigs] %d\n", info.ReqSigs)
-	fmt.Printf("[Addresses] %v\n", info.Addresses)
-	fmt.Printf("PKScript2Address ===================================\n")
+	logger.Debug("PKScript2Address [Network] %s [PKScript] %x [AsmScriptOps] %v [AsmScript] %s [AddressType] %s [ReqSigs] %d [Addresses] %v",
+		types.CurrentNetwork, info.PKScript, ops, asm, info.Typ, info.ReqSigs, info.Addresses)
 }
 
 // // Script2Addr 仅做最小化识别：p2pkh/p2sh/p2wpkh/p2wsh/p2tr，其余返回 "unknown"。
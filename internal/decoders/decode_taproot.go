@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -47,6 +48,36 @@ func ExtractTapScriptPath(w [][]byte) (stack [][]byte, script []byte, control []
 	return stack, script, cb, true
 }
 
+// DecodeTapscript 解析脚本路径花费的 witness, 仅接受 tapscript(leaf version 0xc0).
+// 用 leaf version 过滤可以排除 P2WPKH 的 [sig, pubkey] 这类长度恰好满足控制块规则的 witness.
+func DecodeTapscript(w [][]byte) (*types.TapscriptInfo, bool) {
+	stack, script, control, ok := ExtractTapScriptPath(w)
+	if !ok {
+		return nil, false
+	}
+	cb, err := ParseControlBlock(control)
+	if err != nil || cb.LeafVersion != byte(txscript.BaseLeafVersion) {
+		return nil, false
+	}
+	ops, asm, err := DecodeAsmScript(script)
+	if err != nil {
+		// 无法反汇编的脚本仍然保留原文, 便于上层继续解析
+		ops, asm = nil, ""
+	}
+	stackHex := make([]string, len(stack))
+	for i, item := range stack {
+		stackHex[i] = hex.EncodeToString(item)
+	}
+	return &types.TapscriptInfo{
+		ScriptHex: hex.EncodeToString(script),
+		ASM:       asm,
+		Ops:       ops,
+		Control:   cb,
+		StackHex:  stackHex,
+		Path:      "p2tr-script",
+	}, true
+}
+
 // TapLeafHash computes the tagged hash for a tapscript leaf per BIP-342.
 // H_TapLeaf(leafVer || varint(len(script)) || script)
 func TapLeafHash(leafVersion byte, script []byte) [32]byte {
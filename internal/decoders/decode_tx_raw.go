@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/crazycloudcc/btcapis/types"
 )
@@ -21,8 +22,13 @@ func DecodeRawTx(raw []byte) (*types.Tx, error) {
 	if err := m.Deserialize(bytes.NewReader(raw)); err != nil {
 		return nil, err
 	}
+	return DecodeMsgTx(&m), nil
+}
 
+// DecodeMsgTx 把 btcd 的交易结构转换为 types.Tx
+func DecodeMsgTx(m *wire.MsgTx) *types.Tx {
 	t := &types.Tx{
+		TxID:     m.TxHash().String(),
 		Version:  m.Version,
 		LockTime: m.LockTime,
 		TxIn:     make([]types.TxIn, len(m.TxIn)),
@@ -41,21 +47,34 @@ func DecodeRawTx(raw []byte) (*types.Tx, error) {
 			ScriptSig: append([]byte(nil), in.SignatureScript...),
 			Witness:   w,
 		}
+		if info, ok := DecodeTapscript(w); ok {
+			t.TxIn[i].Tapscript = info
+		}
 	}
 
 	for i, o := range m.TxOut {
-		spk := append([]byte(nil), o.PkScript...)
-		addrInfo, err := DecodePkScript(spk)
-		if err != nil {
-			return nil, err
-		}
-		t.TxOut[i] = types.TxOut{
-			Value:      o.Value,
-			PkScript:   spk,
-			ScriptType: string(addrInfo.Typ),
-			Address:    addrInfo.Addresses[0],
-		}
+		t.TxOut[i] = DecodeTxOut(o.Value, o.PkScript)
 	}
 
-	return t, nil
+	return t
+}
+
+// DecodeTxOut 解析交易输出的脚本类别与地址
+func DecodeTxOut(value int64, pkScript []byte) types.TxOut {
+	spk := append([]byte(nil), pkScript...)
+	out := types.TxOut{
+		Value:    value,
+		PkScript: spk,
+	}
+	addrInfo, err := DecodePkScript(spk)
+	if err != nil {
+		// OP_RETURN 等非标准脚本没有地址, 只记录脚本类别
+		out.ScriptType = txscript.GetScriptClass(spk).String()
+		return out
+	}
+	out.ScriptType = string(addrInfo.Typ)
+	if len(addrInfo.Addresses) > 0 {
+		out.Address = addrInfo.Addresses[0]
+	}
+	return out
 }
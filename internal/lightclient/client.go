@@ -0,0 +1,244 @@
+// Package lightclient BIP157/158 轻客户端: 下载区块过滤器并在本地匹配关注的脚本, 只获取命中的区块,
+// 由此维护关注地址的 UTXO 与交易历史, 不依赖索引服务, 也不向第三方暴露地址.
+package lightclient
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 保留撤销记录的区块数, 更深的分叉需要 Rescan
+const MaxReorgDepth = 100
+
+// 交易历史分页大小, 与 mempool.space 一致
+const txsPageSize = 25
+
+// FilterSource 区块头, 过滤器与区块的数据来源(bitcoind RPC/REST 或 P2P 节点)
+type FilterSource interface {
+	// 最新区块高度
+	GetBlockCount(ctx context.Context) (int, error)
+	// 指定高度的区块哈希
+	GetBlockHash(ctx context.Context, height int) (string, error)
+	// 80 字节原始区块头
+	GetBlockHeaderRaw(ctx context.Context, hash string) ([]byte, error)
+	// BIP158 basic 过滤器及过滤器头; 来源不提供过滤器头时返回空字符串, 此时不校验过滤器头链
+	GetBlockFilter(ctx context.Context, hash string) ([]byte, string, error)
+	// 原始区块, 只在过滤器命中时获取
+	GetBlockRaw(ctx context.Context, hash string) ([]byte, error)
+}
+
+// Client 轻客户端. 先通过 Watch* 添加关注的地址/脚本, 再调用 Sync 同步到最新区块.
+type Client struct {
+	src         FilterSource
+	startHeight int
+
+	syncMu    sync.Mutex // 串行化 Sync/Rescan, 持有者才能修改 tip/headers/undo
+	mu        sync.RWMutex
+	watched   map[string][]byte // key: 脚本 hex
+	tip       int
+	headers   map[int]*blockHeader
+	undo      map[int][]func()
+	utxos     map[string]*types.TxUTXO   // key: txid:vout
+	txs       map[string]*txRecord       // key: txid
+	scriptTxs map[string]map[string]bool // 脚本 hex => 相关 txid
+}
+
+// 已同步区块的头信息
+type blockHeader struct {
+	hash         string
+	prevHash     string
+	filterHeader string
+	bits         uint32 // 难度, 用于校验下一个区块
+	timestamp    int64
+}
+
+func newBlockHeader(hash string, h *wire.BlockHeader, filterHeader string) *blockHeader {
+	return &blockHeader{hash: hash, prevHash: h.PrevBlock.String(), filterHeader: filterHeader, bits: h.Bits, timestamp: h.Timestamp.Unix()}
+}
+
+// 关注脚本相关的交易
+type txRecord struct {
+	detail *types.TxDetail
+	index  int // 在区块内的位置
+}
+
+// New 创建轻客户端, startHeight 为钱包创建高度, 之前的区块不扫描
+func New(src FilterSource, startHeight int) *Client {
+	c := &Client{src: src}
+	c.reset(startHeight)
+	return c
+}
+
+func (c *Client) reset(startHeight int) {
+	c.startHeight = startHeight
+	c.tip = startHeight - 1
+	if c.watched == nil {
+		c.watched = make(map[string][]byte)
+	}
+	c.headers = make(map[int]*blockHeader)
+	c.undo = make(map[int][]func())
+	c.utxos = make(map[string]*types.TxUTXO)
+	c.txs = make(map[string]*txRecord)
+	c.scriptTxs = make(map[string]map[string]bool)
+}
+
+// WatchAddresses 关注地址. 已同步过的区块不会重新扫描, 地址在这些区块中有交易时需要调用 Rescan.
+func (c *Client) WatchAddresses(addrs ...string) error {
+	scripts := make([][]byte, 0, len(addrs))
+	for _, a := range addrs {
+		pkScript, err := decoders.AddressToPkScript(a)
+		if err != nil {
+			return fmt.Errorf("invalid address %s: %w", a, err)
+		}
+		scripts = append(scripts, pkScript)
+	}
+	c.WatchScripts(scripts...)
+	return nil
+}
+
+// WatchScripts 关注锁定脚本, 说明同 WatchAddresses
+func (c *Client) WatchScripts(scripts ...[]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range scripts {
+		c.watched[hex.EncodeToString(s)] = append([]byte(nil), s...)
+	}
+}
+
+// Rescan 清空已同步的数据, 从 fromHeight 开始重新同步
+func (c *Client) Rescan(ctx context.Context, fromHeight int) (int, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.mu.Lock()
+	c.reset(fromHeight)
+	c.mu.Unlock()
+	return c.sync(ctx)
+}
+
+// Tip 最新已同步高度
+func (c *Client) Tip() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tip
+}
+
+// GetAddressBalance 地址余额(sats). 轻客户端看不到内存池, 未确认余额始终为 0.
+func (c *Client) GetAddressBalance(ctx context.Context, addr string) (float64, float64, error) {
+	key, err := scriptKey(addr)
+	if err != nil {
+		return 0, 0, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.watched[key]; !ok {
+		return 0, 0, fmt.Errorf("address %s is not watched", addr)
+	}
+	var confirmed int64
+	for _, u := range c.utxos {
+		if hex.EncodeToString(u.PkScript) == key {
+			confirmed += u.Value
+		}
+	}
+	return float64(confirmed), 0, nil
+}
+
+// GetAddressUTXOs 地址的已确认 UTXO, 按高度排序
+func (c *Client) GetAddressUTXOs(ctx context.Context, addr string) ([]types.TxUTXO, error) {
+	key, err := scriptKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.watched[key]; !ok {
+		return nil, fmt.Errorf("address %s is not watched", addr)
+	}
+	var ret []types.TxUTXO
+	for _, u := range c.utxos {
+		if hex.EncodeToString(u.PkScript) == key {
+			ret = append(ret, *u)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Height != ret[j].Height {
+			return ret[i].Height < ret[j].Height
+		}
+		return utxoKey(ret[i]) < utxoKey(ret[j])
+	})
+	return ret, nil
+}
+
+// GetAddressTxsChain 地址已确认交易历史, 每页 25 条, 按时间倒序; afterTxid 为上一页最后一个 txid, 为空时返回第一页.
+// 只有所有输入都属于关注脚本时才能计算手续费, 否则 Fee 为 0.
+func (c *Client) GetAddressTxsChain(ctx context.Context, addr string, afterTxid string) ([]*types.TxDetail, error) {
+	key, err := scriptKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.watched[key]; !ok {
+		return nil, fmt.Errorf("address %s is not watched", addr)
+	}
+	recs := make([]*txRecord, 0, len(c.scriptTxs[key]))
+	for txid := range c.scriptTxs[key] {
+		recs = append(recs, c.txs[txid])
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		hi, hj := recs[i].detail.Status.BlockHeight, recs[j].detail.Status.BlockHeight
+		if hi != hj {
+			return hi > hj
+		}
+		return recs[i].index > recs[j].index
+	})
+	start := 0
+	if afterTxid != "" {
+		start = -1
+		for i, r := range recs {
+			if r.detail.Tx.TxID == afterTxid {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("tx %s in history of %s: %w", afterTxid, addr, types.ErrNotFound)
+		}
+	}
+	end := min(start+txsPageSize, len(recs))
+	ret := make([]*types.TxDetail, 0, end-start)
+	for _, r := range recs[start:end] {
+		ret = append(ret, r.detail)
+	}
+	return ret, nil
+}
+
+// GetAddressTxsMempool 轻客户端不同步内存池, 始终返回空列表
+func (c *Client) GetAddressTxsMempool(ctx context.Context, addr string) ([]*types.TxDetail, error) {
+	if _, err := scriptKey(addr); err != nil {
+		return nil, err
+	}
+	return []*types.TxDetail{}, nil
+}
+
+func scriptKey(addr string) (string, error) {
+	pkScript, err := decoders.AddressToPkScript(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	return hex.EncodeToString(pkScript), nil
+}
+
+func utxoKey(u types.TxUTXO) string {
+	// 与地址查询得到的 UTXO 一致, Hash 中保存的是展示顺序的 txid
+	return fmt.Sprintf("%s:%d", u.OutPoint.Hash.String(), u.OutPoint.Index)
+}
+
+var errReorgTooDeep = errors.New("lightclient: reorg deeper than undo history, rescan required")
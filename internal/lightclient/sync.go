@@ -0,0 +1,349 @@
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Sync 同步到数据源的最新区块, 遇到分叉时回滚到共同祖先后继续. 返回最新已同步高度.
+// 同一时间只有一个 Sync 在运行; 网络请求期间不持有读写锁, 查询不会被同步阻塞.
+func (c *Client) Sync(ctx context.Context) (int, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	return c.sync(ctx)
+}
+
+// 调用方持有 syncMu. tip/headers/undo 只在持有 syncMu 时修改, 这里读取不需要 mu, 修改时加写锁.
+func (c *Client) sync(ctx context.Context) (int, error) {
+	best, err := c.src.GetBlockCount(ctx)
+	if err != nil {
+		return c.tip, err
+	}
+
+	for height := c.tip + 1; height <= best; height = c.tip + 1 {
+		if err := ctx.Err(); err != nil {
+			return c.tip, err
+		}
+		hash, err := c.src.GetBlockHash(ctx, height)
+		if err != nil {
+			return c.tip, err
+		}
+		header, err := c.fetchHeader(ctx, hash)
+		if err != nil {
+			return c.tip, fmt.Errorf("block %d: %w", height, err)
+		}
+		prev, ok := c.headers[height-1]
+		if ok && prev.hash != header.PrevBlock.String() {
+			if err := c.rollbackToCommonAncestor(ctx); err != nil {
+				return c.tip, err
+			}
+			continue
+		}
+		if err := c.checkDifficulty(ctx, height, prev, header); err != nil {
+			return c.tip, fmt.Errorf("block %d: %w", height, err)
+		}
+		if err := c.syncBlock(ctx, height, hash, header); err != nil {
+			return c.tip, fmt.Errorf("block %d: %w", height, err)
+		}
+	}
+	return c.tip, nil
+}
+
+// 获取并校验区块头: 哈希一致且满足区块头自身声明的工作量, 难度是否为期望值由 checkDifficulty 校验
+func (c *Client) fetchHeader(ctx context.Context, hash string) (*wire.BlockHeader, error) {
+	raw, err := c.src.GetBlockHeaderRaw(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	var h wire.BlockHeader
+	if err := h.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+	blockHash := h.BlockHash()
+	if blockHash.String() != hash {
+		return nil, fmt.Errorf("header hash mismatch: %s != %s", blockHash, hash)
+	}
+	target := blockchain.CompactToBig(h.Bits)
+	if target.Sign() <= 0 || blockchain.HashToBig(&blockHash).Cmp(target) > 0 {
+		return nil, fmt.Errorf("header %s fails proof of work", hash)
+	}
+	return &h, nil
+}
+
+// 校验区块头的难度为期望值(与 bitcoind 的 GetNextWorkRequired 一致): 调整周期内与上一个区块相同,
+// 周期开始时按上一周期的实际用时调整. prev 为 nil(起始区块)时从数据源获取上一个区块头作为基准.
+// testnet 允许按出块间隔使用最低难度, 只凭相邻区块无法确定期望值, 只检查不低于最低难度.
+func (c *Client) checkDifficulty(ctx context.Context, height int, prev *blockHeader, h *wire.BlockHeader) error {
+	params := types.CurrentNetworkParams
+	if height == 0 {
+		if h.BlockHash() != *params.GenesisHash {
+			return fmt.Errorf("genesis block mismatch: %s", h.BlockHash())
+		}
+		return nil
+	}
+	if blockchain.CompactToBig(h.Bits).Cmp(params.PowLimit) > 0 {
+		return fmt.Errorf("header %s target exceeds proof of work limit", h.BlockHash())
+	}
+	if params.ReduceMinDifficulty && !params.PoWNoRetargeting {
+		return nil
+	}
+	if prev == nil {
+		ph, err := c.fetchHeader(ctx, h.PrevBlock.String())
+		if err != nil {
+			return fmt.Errorf("previous header: %w", err)
+		}
+		prev = newBlockHeader(h.PrevBlock.String(), ph, "")
+	}
+
+	expect := prev.bits
+	interval := int(params.TargetTimespan / params.TargetTimePerBlock)
+	if !params.PoWNoRetargeting && height%interval == 0 {
+		first, err := c.headerAt(ctx, height-interval)
+		if err != nil {
+			return fmt.Errorf("retarget header: %w", err)
+		}
+		expect = retarget(params, prev, first.Timestamp.Unix())
+	}
+	if h.Bits != expect {
+		return fmt.Errorf("header %s has bits %08x, expected %08x", h.BlockHash(), h.Bits, expect)
+	}
+	return nil
+}
+
+func (c *Client) headerAt(ctx context.Context, height int) (*wire.BlockHeader, error) {
+	hash, err := c.src.GetBlockHash(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetchHeader(ctx, hash)
+}
+
+// 新周期的难度: 上一周期用时限制在目标的 1/4 到 4 倍之间, 按比例调整 prev 的目标值
+func retarget(params *chaincfg.Params, prev *blockHeader, firstTime int64) uint32 {
+	timespan := int64(params.TargetTimespan / time.Second)
+	actual := prev.timestamp - firstTime
+	actual = max(actual, timespan/params.RetargetAdjustmentFactor)
+	actual = min(actual, timespan*params.RetargetAdjustmentFactor)
+
+	target := blockchain.CompactToBig(prev.bits)
+	target.Mul(target, big.NewInt(actual))
+	target.Div(target, big.NewInt(timespan))
+	if target.Cmp(params.PowLimit) > 0 {
+		target.Set(params.PowLimit)
+	}
+	return blockchain.BigToCompact(target)
+}
+
+// 从当前高度向下寻找与数据源一致的区块, 撤销其后的全部区块
+func (c *Client) rollbackToCommonAncestor(ctx context.Context) error {
+	for c.tip >= c.startHeight {
+		srcHash, err := c.src.GetBlockHash(ctx, c.tip)
+		if err != nil {
+			return err
+		}
+		if srcHash == c.headers[c.tip].hash {
+			break
+		}
+		if err := c.undoTip(); err != nil {
+			return err
+		}
+	}
+	logger.Warn("lightclient: reorg detected, rollback to height %d", c.tip)
+	return nil
+}
+
+// 撤销最新区块
+func (c *Client) undoTip() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	undo, ok := c.undo[c.tip]
+	if !ok {
+		return errReorgTooDeep
+	}
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+	delete(c.undo, c.tip)
+	delete(c.headers, c.tip)
+	c.tip--
+	return nil
+}
+
+// 处理单个区块: 校验过滤器头链, 匹配过滤器, 命中时下载区块; 网络请求完成后再加锁更新状态
+func (c *Client) syncBlock(ctx context.Context, height int, hash string, header *wire.BlockHeader) error {
+	rawFilter, filterHeader, err := c.src.GetBlockFilter(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("get filter: %w", err)
+	}
+	filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, rawFilter)
+	if err != nil {
+		return fmt.Errorf("decode filter: %w", err)
+	}
+	if prev, ok := c.headers[height-1]; ok && prev.filterHeader != "" && filterHeader != "" {
+		prevHeader, err := chainhash.NewHashFromStr(prev.filterHeader)
+		if err != nil {
+			return err
+		}
+		expect, err := builder.MakeHeaderForFilter(filter, *prevHeader)
+		if err != nil {
+			return err
+		}
+		if expect.String() != filterHeader {
+			return fmt.Errorf("filter header mismatch: %s != %s", expect, filterHeader)
+		}
+	}
+
+	c.mu.RLock()
+	scripts := make([][]byte, 0, len(c.watched))
+	for _, s := range c.watched {
+		scripts = append(scripts, s)
+	}
+	c.mu.RUnlock()
+
+	var blk *wire.MsgBlock
+	if len(scripts) > 0 {
+		blockHash := header.BlockHash()
+		match, err := filter.MatchAny(builder.DeriveKey(&blockHash), scripts)
+		if err != nil {
+			return fmt.Errorf("match filter: %w", err)
+		}
+		if match {
+			if blk, err = c.fetchBlock(ctx, hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var undo []func()
+	if blk != nil {
+		undo = c.scanBlock(blk, height, hash, header)
+	}
+	c.tip = height
+	c.headers[height] = newBlockHeader(hash, header, filterHeader)
+	c.undo[height] = undo
+	delete(c.undo, height-MaxReorgDepth)
+	delete(c.headers, height-MaxReorgDepth-1)
+	return nil
+}
+
+func (c *Client) fetchBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
+	raw, err := c.src.GetBlockRaw(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get block: %w", err)
+	}
+	var blk wire.MsgBlock
+	if err := blk.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("decode block: %w", err)
+	}
+	if blk.BlockHash().String() != hash {
+		return nil, fmt.Errorf("block hash mismatch: %s != %s", blk.BlockHash(), hash)
+	}
+	return &blk, nil
+}
+
+// 更新关注脚本的 UTXO 与交易历史, 返回撤销操作; 调用方持有写锁
+func (c *Client) scanBlock(blk *wire.MsgBlock, height int, hash string, header *wire.BlockHeader) []func() {
+	var undo []func()
+	for idx, m := range blk.Transactions {
+		involved := make(map[string]bool)
+		prevouts := make([]*types.TxOut, len(m.TxIn))
+
+		for i, in := range m.TxIn {
+			op := in.PreviousOutPoint.String()
+			u, ok := c.utxos[op]
+			if !ok {
+				continue
+			}
+			delete(c.utxos, op)
+			undo = append(undo, func() { c.utxos[op] = u })
+			involved[hex.EncodeToString(u.PkScript)] = true
+			out := decoders.DecodeTxOut(u.Value, u.PkScript)
+			prevouts[i] = &out
+		}
+
+		txHash := m.TxHash()
+		txidBytes, _ := hex.DecodeString(txHash.String())
+		for vout, out := range m.TxOut {
+			key := hex.EncodeToString(out.PkScript)
+			if _, ok := c.watched[key]; !ok {
+				continue
+			}
+			decoded := decoders.DecodeTxOut(out.Value, out.PkScript)
+			u := &types.TxUTXO{
+				OutPoint: types.TxOutPoint{Hash: types.Hash32(txidBytes), Index: uint32(vout)},
+				Value:    out.Value,
+				PkScript: decoded.PkScript,
+				Height:   uint32(height),
+				Coinbase: idx == 0,
+				Address:  decoded.Address,
+			}
+			op := utxoKey(*u)
+			c.utxos[op] = u
+			undo = append(undo, func() { delete(c.utxos, op) })
+			involved[key] = true
+		}
+
+		if len(involved) == 0 {
+			continue
+		}
+		txid := txHash.String()
+		c.txs[txid] = &txRecord{detail: txDetail(m, prevouts, height, hash, header), index: idx}
+		undo = append(undo, func() { delete(c.txs, txid) })
+		for key := range involved {
+			set, ok := c.scriptTxs[key]
+			if !ok {
+				set = make(map[string]bool)
+				c.scriptTxs[key] = set
+			}
+			set[txid] = true
+			undo = append(undo, func() { delete(set, txid) })
+		}
+	}
+	return undo
+}
+
+// 构建交易详情, 只有所有输入金额都已知时才计算手续费
+func txDetail(m *wire.MsgTx, prevouts []*types.TxOut, height int, hash string, header *wire.BlockHeader) *types.TxDetail {
+	d := &types.TxDetail{
+		Tx: decoders.DecodeMsgTx(m),
+		Status: types.TxStatus{
+			Confirmed:   true,
+			BlockHeight: height,
+			BlockHash:   hash,
+			BlockTime:   header.Timestamp.Unix(),
+		},
+		Size:     int64(m.SerializeSize()),
+		Weight:   blockchain.GetTransactionWeight(btcutil.NewTx(m)),
+		Prevouts: prevouts,
+	}
+	var in int64
+	for _, p := range prevouts {
+		if p == nil {
+			return d
+		}
+		in += p.Value
+	}
+	var out int64
+	for _, o := range m.TxOut {
+		out += o.Value
+	}
+	d.Fee = in - out
+	return d
+}
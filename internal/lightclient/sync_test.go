@@ -0,0 +1,204 @@
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 内存中的区块链, 替换 blocks 模拟重组; 记录下载区块的次数
+type memFilterSource struct {
+	blocks    []*wire.MsgBlock
+	blockRaws int
+}
+
+func (m *memFilterSource) GetBlockCount(ctx context.Context) (int, error) {
+	return len(m.blocks) - 1, nil
+}
+
+func (m *memFilterSource) GetBlockHash(ctx context.Context, height int) (string, error) {
+	return m.blocks[height].BlockHash().String(), nil
+}
+
+func (m *memFilterSource) find(hash string) (int, error) {
+	for i, b := range m.blocks {
+		if b.BlockHash().String() == hash {
+			return i, nil
+		}
+	}
+	return 0, types.ErrNotFound
+}
+
+func (m *memFilterSource) GetBlockHeaderRaw(ctx context.Context, hash string) ([]byte, error) {
+	i, err := m.find(hash)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = m.blocks[i].Header.Serialize(&buf)
+	return buf.Bytes(), err
+}
+
+func (m *memFilterSource) GetBlockFilter(ctx context.Context, hash string) ([]byte, string, error) {
+	height, err := m.find(hash)
+	if err != nil {
+		return nil, "", err
+	}
+	var header chainhash.Hash
+	var raw []byte
+	for i := 0; i <= height; i++ {
+		filter, err := builder.BuildBasicFilter(m.blocks[i], nil)
+		if err != nil {
+			return nil, "", err
+		}
+		if header, err = builder.MakeHeaderForFilter(filter, header); err != nil {
+			return nil, "", err
+		}
+		if raw, err = filter.NBytes(); err != nil {
+			return nil, "", err
+		}
+	}
+	return raw, header.String(), nil
+}
+
+func (m *memFilterSource) GetBlockRaw(ctx context.Context, hash string) ([]byte, error) {
+	i, err := m.find(hash)
+	if err != nil {
+		return nil, err
+	}
+	m.blockRaws++
+	var buf bytes.Buffer
+	err = m.blocks[i].Serialize(&buf)
+	return buf.Bytes(), err
+}
+
+// 在链尾追加区块, coinbase 支付给 pkScript; tag 区分不同分支的区块
+func (m *memFilterSource) mine(t *testing.T, tag string, bits uint32, pkScript []byte) {
+	t.Helper()
+	prev := m.blocks[len(m.blocks)-1]
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex),
+		[]byte(fmt.Sprintf("%d/%s", len(m.blocks), tag)), nil))
+	coinbase.AddTxOut(wire.NewTxOut(50_0000_0000, pkScript))
+	blk := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    4,
+			PrevBlock:  prev.BlockHash(),
+			MerkleRoot: blockchain.CalcMerkleRoot([]*btcutil.Tx{btcutil.NewTx(coinbase)}, false),
+			Timestamp:  prev.Header.Timestamp.Add(10 * time.Minute),
+			Bits:       bits,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+	target := blockchain.CompactToBig(bits)
+	for {
+		hash := blk.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+		blk.Header.Nonce++
+	}
+	m.blocks = append(m.blocks, blk)
+}
+
+func p2wpkh(t *testing.T, b byte) (string, []byte) {
+	t.Helper()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(bytes.Repeat([]byte{b}, 20), &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, _ := txscript.PayToAddrScript(addr)
+	return addr.EncodeAddress(), pkScript
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	types.SetCurrentNetwork(string(types.Regtest))
+	const bits = 0x207fffff
+	alice, aliceScript := p2wpkh(t, 1)
+	_, otherScript := p2wpkh(t, 2)
+	newChain := func() *memFilterSource {
+		return &memFilterSource{blocks: []*wire.MsgBlock{chaincfg.RegressionNetParams.GenesisBlock}}
+	}
+	balance := func(c *Client) float64 {
+		t.Helper()
+		b, _, err := c.GetAddressBalance(ctx, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	t.Run("filter match and reorg", func(t *testing.T) {
+		src := newChain()
+		src.mine(t, "a", bits, otherScript)
+		src.mine(t, "a", bits, aliceScript)
+		src.mine(t, "a", bits, otherScript)
+		c := New(src, 0)
+		if err := c.WatchAddresses(alice); err != nil {
+			t.Fatal(err)
+		}
+		if tip, err := c.Sync(ctx); err != nil || tip != 3 {
+			t.Fatalf("sync = %d, %v", tip, err)
+		}
+		// 只有区块 2 命中过滤器
+		if b := balance(c); b != 50_0000_0000 || src.blockRaws != 1 {
+			t.Errorf("balance = %v, block downloads = %d", b, src.blockRaws)
+		}
+
+		// 区块 2 之后被更长的分支替换
+		src.blocks = src.blocks[:2]
+		for i := 0; i < 3; i++ {
+			src.mine(t, "b", bits, otherScript)
+		}
+		if tip, err := c.Sync(ctx); err != nil || tip != 4 {
+			t.Fatalf("sync after reorg = %d, %v", tip, err)
+		}
+		if b := balance(c); b != 0 {
+			t.Errorf("balance after reorg = %v", b)
+		}
+		if utxos, _ := c.GetAddressUTXOs(ctx, alice); len(utxos) != 0 {
+			t.Errorf("utxos after reorg = %+v", utxos)
+		}
+	})
+
+	t.Run("unexpected difficulty", func(t *testing.T) {
+		src := newChain()
+		src.mine(t, "a", bits, otherScript)
+		src.mine(t, "a", bits-1, otherScript) // 工作量满足自身声明, 但难度与上一个区块不同
+		c := New(src, 0)
+		if tip, err := c.Sync(ctx); err == nil || tip != 1 {
+			t.Errorf("sync = %d, %v, want error at block 2", tip, err)
+		}
+	})
+
+	t.Run("reorg too deep", func(t *testing.T) {
+		src := newChain()
+		for i := 0; i < MaxReorgDepth+2; i++ {
+			src.mine(t, "a", bits, otherScript)
+		}
+		c := New(src, 0)
+		if _, err := c.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+		src.blocks = src.blocks[:1]
+		for i := 0; i < MaxReorgDepth+3; i++ {
+			src.mine(t, "b", bits, otherScript)
+		}
+		if _, err := c.Sync(ctx); !errors.Is(err, errReorgTooDeep) {
+			t.Errorf("sync = %v, want errReorgTooDeep", err)
+		}
+	})
+}
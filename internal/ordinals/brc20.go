@@ -1 +1,183 @@
 package ordinals
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// BRC-20 协议标识
+const BRC20Protocol = "brc-20"
+
+// BRC-20 默认及最大小数位
+const (
+	BRC20DefaultDecimals = 18
+	BRC20MaxDecimals     = 18
+)
+
+// 数值上限: 整数部分不超过 uint64 最大值
+var brc20MaxAmount = decimal.NewFromUint64(math.MaxUint64)
+
+// 数值格式: 只允许十进制数字和一个小数点, 小数点两侧都必须有数字
+var brc20NumberRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ParseBRC20 从铭文解析 BRC-20 操作, 不是 BRC-20 或格式不合法时返回错误.
+// 只做与状态无关的校验(字段格式, tick 长度, 部署参数), 余额和供给由状态机校验.
+func ParseBRC20(ins *types.Inscription) (*types.BRC20Operation, error) {
+	if ins == nil || !ins.HasBody {
+		return nil, errors.New("brc-20: inscription has no body")
+	}
+	if ins.ContentEncoding != "" {
+		return nil, fmt.Errorf("brc-20: unsupported content encoding %q", ins.ContentEncoding)
+	}
+	mime := strings.TrimSpace(strings.SplitN(ins.ContentType, ";", 2)[0])
+	if mime != "text/plain" && mime != "application/json" {
+		return nil, fmt.Errorf("brc-20: unsupported content type %q", ins.ContentType)
+	}
+	return ParseBRC20JSON(ins.Body)
+}
+
+// ParseBRC20JSON 解析 BRC-20 JSON 内容
+func ParseBRC20JSON(body []byte) (*types.BRC20Operation, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("brc-20: invalid json: %w", err)
+	}
+	str := func(key string) (string, bool, error) {
+		raw, ok := fields[key]
+		if !ok {
+			return "", false, nil
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", true, fmt.Errorf("brc-20: field %q must be a string", key)
+		}
+		return s, true, nil
+	}
+
+	op := &types.BRC20Operation{}
+	var err error
+	var ok bool
+	if op.P, _, err = str("p"); err != nil {
+		return nil, err
+	}
+	if op.P != BRC20Protocol {
+		return nil, fmt.Errorf("brc-20: unknown protocol %q", op.P)
+	}
+	if op.Op, _, err = str("op"); err != nil {
+		return nil, err
+	}
+	if op.Tick, _, err = str("tick"); err != nil {
+		return nil, err
+	}
+	if n := len(op.Tick); n != 4 && n != 5 {
+		return nil, fmt.Errorf("brc-20: tick must be 4 or 5 bytes: %q", op.Tick)
+	}
+
+	switch op.Op {
+	case types.BRC20OpDeploy:
+		selfMint, _, err := str("self_mint")
+		if err != nil {
+			return nil, err
+		}
+		op.SelfMint = selfMint == "true"
+		if len(op.Tick) == 5 && !op.SelfMint {
+			return nil, fmt.Errorf("brc-20: 5-byte tick %q requires self_mint", op.Tick)
+		}
+		if op.Dec, ok, err = str("dec"); err != nil {
+			return nil, err
+		}
+		dec := BRC20DefaultDecimals
+		if ok {
+			if dec, err = parseBRC20Decimals(op.Dec); err != nil {
+				return nil, err
+			}
+		}
+		if op.Max, ok, err = str("max"); err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("brc-20: deploy requires max")
+		}
+		supply, err := ParseBRC20Amount(op.Max, dec)
+		if err != nil {
+			return nil, err
+		}
+		// self_mint 允许 max 为 0, 表示不限量(uint64 上限)
+		if supply.IsZero() && !op.SelfMint {
+			return nil, errors.New("brc-20: max must be positive")
+		}
+		if op.Lim, ok, err = str("lim"); err != nil {
+			return nil, err
+		}
+		if ok {
+			lim, err := ParseBRC20Amount(op.Lim, dec)
+			if err != nil {
+				return nil, err
+			}
+			if lim.IsZero() {
+				return nil, errors.New("brc-20: lim must be positive")
+			}
+		}
+	case types.BRC20OpMint, types.BRC20OpTransfer:
+		if op.Amt, ok, err = str("amt"); err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("brc-20: %s requires amt", op.Op)
+		}
+		// 小数位要结合部署参数校验, 这里只检查格式与上限
+		amt, err := ParseBRC20Amount(op.Amt, BRC20MaxDecimals)
+		if err != nil {
+			return nil, err
+		}
+		if amt.IsZero() {
+			return nil, errors.New("brc-20: amt must be positive")
+		}
+	default:
+		return nil, fmt.Errorf("brc-20: unknown op %q", op.Op)
+	}
+	return op, nil
+}
+
+// ParseBRC20Amount 解析数值字符串, 小数位不能超过 dec, 整数部分不能超过 uint64 上限
+func ParseBRC20Amount(s string, dec int) (decimal.Decimal, error) {
+	if !brc20NumberRe.MatchString(s) {
+		return decimal.Zero, fmt.Errorf("brc-20: invalid number %q", s)
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > dec {
+		return decimal.Zero, fmt.Errorf("brc-20: number %q has more than %d decimals", s, dec)
+	}
+	v, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("brc-20: invalid number %q", s)
+	}
+	if v.GreaterThan(brc20MaxAmount) {
+		return decimal.Zero, fmt.Errorf("brc-20: number %q overflows", s)
+	}
+	return v, nil
+}
+
+// 小数位: 0-18 的整数
+func parseBRC20Decimals(s string) (int, error) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, fmt.Errorf("brc-20: invalid dec %q", s)
+	}
+	dec, err := strconv.Atoi(s)
+	if err != nil || dec > BRC20MaxDecimals {
+		return 0, fmt.Errorf("brc-20: invalid dec %q", s)
+	}
+	return dec, nil
+}
+
+// BRC20TickKey tick 比较时不区分大小写
+func BRC20TickKey(tick string) string {
+	return strings.ToLower(tick)
+}
@@ -0,0 +1,219 @@
+package ordinals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 保留撤销记录的区块数, 更深的分叉无法回滚
+const BRC20MaxReorgDepth = 144
+
+// BRC20BlockSource BRC-20 索引器的数据来源
+type BRC20BlockSource interface {
+	// 最新区块高度
+	GetBlockCount(ctx context.Context) (int, error)
+	// 指定高度的区块哈希
+	GetBlockHash(ctx context.Context, height int) (string, error)
+	// 完整区块
+	GetBlock(ctx context.Context, hash string) (*types.Block, error)
+	// 交易输出金额(含已花费输出), 用于计算铭文所在 sat 的偏移
+	GetTxOutValue(ctx context.Context, txid string, vout uint32) (int64, error)
+}
+
+// BRC20FirstHeight 首个 BRC-20 部署所在高度, 之前的区块无需索引
+func BRC20FirstHeight(net types.Network) int {
+	if net == types.Mainnet {
+		return 779832
+	}
+	return 0
+}
+
+// BRC20Indexer 逐块驱动 BRC-20 状态机
+// 只跟踪已生效且尚未转移的 transfer 铭文所在的 sat, 不维护完整的 sat 索引,
+// 因此无法识别落在已有铭文 sat 上的 reinscription.
+type BRC20Indexer struct {
+	f       *blockFollower
+	src     BRC20BlockSource
+	state   *BRC20State
+	network types.Network
+	tracked trackedOutputs
+}
+
+// NewBRC20Indexer 创建索引器, 默认从首个 BRC-20 部署高度开始
+func NewBRC20Indexer(src BRC20BlockSource, network types.Network) *BRC20Indexer {
+	ix := &BRC20Indexer{
+		src:     src,
+		state:   NewBRC20State(network),
+		network: network,
+		tracked: make(trackedOutputs),
+	}
+	ix.f = newBlockFollower("brc-20 indexer", src, BRC20FirstHeight(network), ix.applyBlock)
+	return ix
+}
+
+// SetStartHeight 修改起始高度(仅在尚未索引任何区块时有效)
+func (ix *BRC20Indexer) SetStartHeight(height int) {
+	ix.f.startHeight = height
+}
+
+// State 余额状态
+func (ix *BRC20Indexer) State() *BRC20State {
+	return ix.state
+}
+
+// Tip 最新已索引区块, 尚未索引时为 -1
+func (ix *BRC20Indexer) Tip() int {
+	return ix.f.tip
+}
+
+// Sync 索引到数据源的最新区块, 遇到分叉时回滚到共同祖先后继续. 返回最新已索引高度.
+func (ix *BRC20Indexer) Sync(ctx context.Context) (int, error) {
+	return ix.f.sync(ctx)
+}
+
+// IndexBlock 处理单个区块, 调用方需要保证按高度顺序调用
+func (ix *BRC20Indexer) IndexBlock(ctx context.Context, blk *types.Block) error {
+	return ix.f.indexBlock(ctx, blk)
+}
+
+func (ix *BRC20Indexer) applyBlock(ctx context.Context, blk *types.Block) (func(), error) {
+	st := &brc20Block{ix: ix, ctx: ctx, blk: blk, outputs: make(map[string][]types.TxOut)}
+	for _, tx := range blk.Txs {
+		st.outputs[tx.TxID] = tx.TxOut
+	}
+	revert := func(state []func()) {
+		ix.state.revert(state)
+		for j := len(st.undo) - 1; j >= 0; j-- {
+			st.undo[j]()
+		}
+	}
+	for i, tx := range blk.Txs {
+		if err := st.indexTx(i == 0, tx); err != nil {
+			// 中途失败时撤销本区块已做的修改, 保证可以重试
+			revert(ix.state.takeUndo())
+			return nil, fmt.Errorf("tx %s: %w", tx.TxID, err)
+		}
+	}
+	state := ix.state.takeUndo()
+	return func() { revert(state) }, nil
+}
+
+// 区块内的处理上下文
+type brc20Block struct {
+	ix      *BRC20Indexer
+	ctx     context.Context
+	blk     *types.Block
+	outputs map[string][]types.TxOut // 本区块交易的输出, 避免重复查询
+	undo    []func()
+}
+
+func (st *brc20Block) indexTx(coinbase bool, tx *types.Tx) error {
+	ix := st.ix
+
+	offsetOf := inputOffsets(tx, st.inputValue)
+
+	// 1. 转移已有的 transfer 铭文
+	if !coinbase {
+		for i, in := range tx.TxIn {
+			op := outpointKey(in.PreviousOutPoint)
+			if _, ok := ix.tracked[op]; !ok {
+				continue
+			}
+			base, err := offsetOf(i)
+			if err != nil {
+				return err
+			}
+			out, _ := ix.tracked.remove(op, &st.undo)
+			for _, ins := range out.inscriptions {
+				vout, _, toFee := locate(tx.TxOut, base+ins.offset)
+				to := ""
+				if !toFee {
+					to = receiverAddress(tx.TxOut[vout])
+				}
+				ix.state.Move(ins.id, tx.TxID, st.blk.Height, to, toFee)
+			}
+		}
+	}
+
+	// 2. 新铭文, 忽略 cursed 铭文; 未被诅咒的铭文位于第一个输入的第一个 sat
+	first := true
+	for _, ins := range ParseTx(tx) {
+		if cursed(&ins, first) {
+			first = false
+			continue
+		}
+		first = false
+		op, err := ParseBRC20(&ins)
+		if err != nil {
+			continue
+		}
+
+		vout, inner, toFee := locate(tx.TxOut, 0)
+		owner := ""
+		if !toFee {
+			owner = receiverAddress(tx.TxOut[vout])
+		}
+
+		ev, err := ix.state.Inscribe(op, BRC20Inscribe{
+			InscriptionID: ins.ID,
+			TxID:          tx.TxID,
+			Height:        st.blk.Height,
+			Owner:         owner,
+			Parents:       ins.Parents,
+		})
+		if err != nil || ev.Type != types.BRC20EventInscribeTransfer {
+			continue
+		}
+		op2 := fmt.Sprintf("%s:%d", tx.TxID, vout)
+		ix.state.setLocation(ins.ID, fmt.Sprintf("%s:%d", op2, inner))
+		ix.tracked.add(op2, tx.TxOut[vout].Value, trackedInscription{id: ins.ID, offset: inner}, &st.undo)
+	}
+	return nil
+}
+
+// ord 的 curse: 不是交易中的第一个信封(NotInFirstInput, NotAtOffsetZero), 带 pointer,
+// 以及信封格式问题. BRC-20 不处理 cursed 铭文. Reinscription 需要 sat 索引, 这里不检查.
+func cursed(ins *types.Inscription, first bool) bool {
+	return !first || ins.Input != 0 || ins.Pointer != nil ||
+		ins.Pushnum || ins.Stutter || ins.DuplicateField || ins.IncompleteField || ins.UnrecognizedEvenField
+}
+
+func (st *brc20Block) inputValue(prev types.TxOutPoint) (int64, error) {
+	return spentValue(st.ctx, st.ix.src, st.ix.tracked, st.outputs, prev)
+}
+
+func outpointKey(op types.TxOutPoint) string {
+	return fmt.Sprintf("%s:%d", chainhash.Hash(op.Hash).String(), op.Index)
+}
+
+// 按 sat 偏移定位输出, 返回输出索引, 输出内偏移, 以及是否落入手续费
+func locate(outs []types.TxOut, offset int64) (int, int64, bool) {
+	var start int64
+	for i, o := range outs {
+		if offset < start+o.Value {
+			return i, offset - start, false
+		}
+		start += o.Value
+	}
+	return -1, 0, true
+}
+
+func totalValue(outs []types.TxOut) int64 {
+	var total int64
+	for _, o := range outs {
+		total += o.Value
+	}
+	return total
+}
+
+// 接收地址, OP_RETURN 及无法解析地址的脚本返回空(视为销毁)
+func receiverAddress(out types.TxOut) string {
+	if len(out.PkScript) > 0 && out.PkScript[0] == txscript.OP_RETURN {
+		return ""
+	}
+	return out.Address
+}
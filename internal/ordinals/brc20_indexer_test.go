@@ -0,0 +1,50 @@
+package ordinals
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// cursed 铭文(不在第一个输入, 带 pointer)不参与 BRC-20
+func TestBRC20IndexerSkipsCursed(t *testing.T) {
+	control := append([]byte{byte(txscript.BaseLeafVersion)}, make([]byte, 32)...)
+	deploy := func(tick string, field EnvelopeField) types.TxWitness {
+		t.Helper()
+		body := fmt.Sprintf(`{"p":"brc-20","op":"deploy","tick":"%s","max":"21000000","lim":"1000"}`, tick)
+		script, err := BuildRevealScript(make([]byte, 32),
+			[]types.InscriptionData{{ContentType: "text/plain;charset=utf-8", Body: []byte(body)}},
+			[]EnvelopeField{field})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.TxWitness{make([]byte, 64), script, control}
+	}
+
+	src := &memBlockSource{values: map[string]int64{}}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		src.values[testHash(name).String()+":0"] = 10_000
+	}
+	pointer := uint64(100)
+	first := testTx("first", []types.TxIn{spend("a", 0)}, 546)
+	first.TxIn[0].Witness = deploy("frst", EnvelopeField{})
+	second := testTx("second", []types.TxIn{spend("b", 0), spend("c", 0)}, 546)
+	second.TxIn[1].Witness = deploy("scnd", EnvelopeField{})
+	pointed := testTx("pointed", []types.TxIn{spend("d", 0)}, 546)
+	pointed.TxIn[0].Witness = deploy("pntr", EnvelopeField{Pointer: &pointer})
+	testBlock(src, "0", first, second, pointed)
+
+	ix := NewBRC20Indexer(src, types.Regtest)
+	ix.SetStartHeight(0)
+	if _, err := ix.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for tick, want := range map[string]bool{"frst": true, "scnd": false, "pntr": false} {
+		if got := ix.State().Token(tick) != nil; got != want {
+			t.Errorf("%s deployed = %v, want %v", tick, got, want)
+		}
+	}
+}
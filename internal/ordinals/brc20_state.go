@@ -0,0 +1,391 @@
+package ordinals
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// BRC20SelfMintHeight 5 字节 tick(self_mint) 的启用高度
+func BRC20SelfMintHeight(net types.Network) int {
+	if net == types.Mainnet {
+		return 837090
+	}
+	return 0
+}
+
+// BRC20Inscribe 铭文首次出现(reveal)时的上下文
+type BRC20Inscribe struct {
+	InscriptionID string
+	TxID          string
+	Height        int
+	Owner         string   // 铭文落入的输出地址, 作为手续费或非标准输出时为空
+	Parents       []string // 父铭文ID, self_mint 代币的 mint 需要部署铭文作为父铭文
+}
+
+// BRC20State BRC-20 余额状态机
+// 每个地址的余额分为 available 与 transferable:
+//   - mint 直接增加 available;
+//   - 铭刻 transfer 铭文时从 available 锁定到 transferable;
+//   - transfer 铭文第一次被转移时, 从发送方 transferable 转入接收方 available,
+//     转入手续费时退回发送方 available, 转入 OP_RETURN 时销毁.
+//
+// 所有修改都记录撤销操作, 由索引器按区块回滚.
+type BRC20State struct {
+	mu        sync.RWMutex
+	network   types.Network
+	tokens    map[string]*types.BRC20Token    // key: 小写 tick
+	balances  map[string]*types.BRC20Balance  // key: 小写 tick|address
+	transfers map[string]*types.BRC20Transfer // 待转移的 transfer 铭文, key: 铭文ID
+	events    []types.BRC20Event
+	undo      []func()
+}
+
+// NewBRC20State 创建空状态
+func NewBRC20State(network types.Network) *BRC20State {
+	return &BRC20State{
+		network:   network,
+		tokens:    make(map[string]*types.BRC20Token),
+		balances:  make(map[string]*types.BRC20Balance),
+		transfers: make(map[string]*types.BRC20Transfer),
+	}
+}
+
+// Inscribe 处理新铭文中的 BRC-20 操作, 操作无效时返回错误且状态不变
+func (s *BRC20State) Inscribe(op *types.BRC20Operation, in BRC20Inscribe) (*types.BRC20Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch op.Op {
+	case types.BRC20OpDeploy:
+		return s.deploy(op, in)
+	case types.BRC20OpMint:
+		return s.mint(op, in)
+	case types.BRC20OpTransfer:
+		return s.inscribeTransfer(op, in)
+	default:
+		return nil, fmt.Errorf("brc-20: unknown op %q", op.Op)
+	}
+}
+
+func (s *BRC20State) deploy(op *types.BRC20Operation, in BRC20Inscribe) (*types.BRC20Event, error) {
+	key := BRC20TickKey(op.Tick)
+	if _, ok := s.tokens[key]; ok {
+		return nil, fmt.Errorf("brc-20: tick %q already deployed", op.Tick)
+	}
+	if len(op.Tick) == 5 && in.Height < BRC20SelfMintHeight(s.network) {
+		return nil, fmt.Errorf("brc-20: 5-byte tick %q not activated at height %d", op.Tick, in.Height)
+	}
+
+	dec := BRC20DefaultDecimals
+	if op.Dec != "" {
+		var err error
+		if dec, err = parseBRC20Decimals(op.Dec); err != nil {
+			return nil, err
+		}
+	}
+	supply, err := ParseBRC20Amount(op.Max, dec)
+	if err != nil {
+		return nil, err
+	}
+	if supply.IsZero() {
+		supply = brc20MaxAmount
+	}
+	lim := supply
+	if op.Lim != "" {
+		if lim, err = ParseBRC20Amount(op.Lim, dec); err != nil {
+			return nil, err
+		}
+	}
+
+	tok := &types.BRC20Token{
+		Tick:          op.Tick,
+		InscriptionID: in.InscriptionID,
+		Max:           supply,
+		Lim:           lim,
+		Dec:           dec,
+		SelfMint:      op.SelfMint,
+		Minted:        decimal.Zero,
+		Deployer:      in.Owner,
+		DeployHeight:  in.Height,
+		DeployTxID:    in.TxID,
+	}
+	s.tokens[key] = tok
+	s.record(func() { delete(s.tokens, key) })
+	return s.emit(types.BRC20Event{
+		Type:          types.BRC20EventDeploy,
+		Tick:          tok.Tick,
+		InscriptionID: in.InscriptionID,
+		TxID:          in.TxID,
+		BlockHeight:   in.Height,
+		To:            in.Owner,
+		Amount:        decimal.Zero,
+	}), nil
+}
+
+func (s *BRC20State) mint(op *types.BRC20Operation, in BRC20Inscribe) (*types.BRC20Event, error) {
+	tok, amt, err := s.tokenAmount(op)
+	if err != nil {
+		return nil, err
+	}
+	if in.Owner == "" {
+		return nil, errors.New("brc-20: mint has no owner")
+	}
+	if amt.GreaterThan(tok.Lim) {
+		return nil, fmt.Errorf("brc-20: mint amount %s exceeds limit %s", amt, tok.Lim)
+	}
+	if tok.SelfMint && !slices.Contains(in.Parents, tok.InscriptionID) {
+		return nil, fmt.Errorf("brc-20: self_mint tick %q requires deploy inscription as parent", tok.Tick)
+	}
+	left := tok.Max.Sub(tok.Minted)
+	if !left.IsPositive() {
+		return nil, fmt.Errorf("brc-20: tick %q fully minted", tok.Tick)
+	}
+	// 剩余不足时只铸造剩余部分
+	amt = decimal.Min(amt, left)
+
+	minted := tok.Minted
+	tok.Minted = tok.Minted.Add(amt)
+	s.record(func() { tok.Minted = minted })
+
+	bal := s.balance(tok.Tick, in.Owner)
+	bal.Available = bal.Available.Add(amt)
+	return s.emit(types.BRC20Event{
+		Type:          types.BRC20EventMint,
+		Tick:          tok.Tick,
+		InscriptionID: in.InscriptionID,
+		TxID:          in.TxID,
+		BlockHeight:   in.Height,
+		To:            in.Owner,
+		Amount:        amt,
+	}), nil
+}
+
+func (s *BRC20State) inscribeTransfer(op *types.BRC20Operation, in BRC20Inscribe) (*types.BRC20Event, error) {
+	tok, amt, err := s.tokenAmount(op)
+	if err != nil {
+		return nil, err
+	}
+	if in.Owner == "" {
+		return nil, errors.New("brc-20: transfer has no owner")
+	}
+	if cur := s.balances[balanceKey(tok.Tick, in.Owner)]; cur == nil || cur.Available.LessThan(amt) {
+		return nil, fmt.Errorf("brc-20: insufficient available balance of %q for %s", tok.Tick, in.Owner)
+	}
+
+	bal := s.balance(tok.Tick, in.Owner)
+	bal.Available = bal.Available.Sub(amt)
+	bal.Transferable = bal.Transferable.Add(amt)
+
+	id := in.InscriptionID
+	s.transfers[id] = &types.BRC20Transfer{InscriptionID: id, Tick: tok.Tick, Amount: amt, From: in.Owner}
+	s.record(func() { delete(s.transfers, id) })
+	return s.emit(types.BRC20Event{
+		Type:          types.BRC20EventInscribeTransfer,
+		Tick:          tok.Tick,
+		InscriptionID: id,
+		TxID:          in.TxID,
+		BlockHeight:   in.Height,
+		From:          in.Owner,
+		Amount:        amt,
+	}), nil
+}
+
+// Move 处理 transfer 铭文的首次转移. to 为接收地址(OP_RETURN 时为空表示销毁), toFee 表示铭文作为手续费被矿工获得.
+// 铭文不是待转移的 transfer 铭文时返回 nil.
+func (s *BRC20State) Move(inscriptionID, txid string, height int, to string, toFee bool) *types.BRC20Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transfers[inscriptionID]
+	if !ok {
+		return nil
+	}
+	delete(s.transfers, inscriptionID)
+	s.record(func() { s.transfers[inscriptionID] = t })
+
+	if toFee {
+		to = t.From
+	}
+	from := s.balance(t.Tick, t.From)
+	from.Transferable = from.Transferable.Sub(t.Amount)
+	if to != "" {
+		recv := s.balance(t.Tick, to)
+		recv.Available = recv.Available.Add(t.Amount)
+	}
+	return s.emit(types.BRC20Event{
+		Type:          types.BRC20EventTransfer,
+		Tick:          t.Tick,
+		InscriptionID: inscriptionID,
+		TxID:          txid,
+		BlockHeight:   height,
+		From:          t.From,
+		To:            to,
+		Amount:        t.Amount,
+	})
+}
+
+// 查找代币并按代币小数位解析数量
+func (s *BRC20State) tokenAmount(op *types.BRC20Operation) (*types.BRC20Token, decimal.Decimal, error) {
+	tok, ok := s.tokens[BRC20TickKey(op.Tick)]
+	if !ok {
+		return nil, decimal.Zero, fmt.Errorf("brc-20: tick %q not deployed", op.Tick)
+	}
+	amt, err := ParseBRC20Amount(op.Amt, tok.Dec)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	if amt.IsZero() {
+		return nil, decimal.Zero, errors.New("brc-20: amt must be positive")
+	}
+	return tok, amt, nil
+}
+
+func balanceKey(tick, address string) string {
+	return BRC20TickKey(tick) + "|" + address
+}
+
+// 获取(不存在时创建)余额记录, 并记录修改前的值用于撤销
+func (s *BRC20State) balance(tick, address string) *types.BRC20Balance {
+	key := balanceKey(tick, address)
+	bal, ok := s.balances[key]
+	if !ok {
+		bal = &types.BRC20Balance{Tick: s.tokens[BRC20TickKey(tick)].Tick, Address: address}
+		s.balances[key] = bal
+		s.record(func() { delete(s.balances, key) })
+		return bal
+	}
+	old := *bal
+	s.record(func() { *bal = old })
+	return bal
+}
+
+func (s *BRC20State) emit(ev types.BRC20Event) *types.BRC20Event {
+	n := len(s.events)
+	s.events = append(s.events, ev)
+	s.record(func() { s.events = s.events[:n] })
+	return &ev
+}
+
+func (s *BRC20State) record(fn func()) {
+	s.undo = append(s.undo, fn)
+}
+
+// 取出自上次调用以来的撤销操作
+func (s *BRC20State) takeUndo() []func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	undo := s.undo
+	s.undo = nil
+	return undo
+}
+
+// 倒序执行撤销操作
+func (s *BRC20State) revert(undo []func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+}
+
+// Token 查询代币, 不存在时返回 nil
+func (s *BRC20State) Token(tick string) *types.BRC20Token {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tok, ok := s.tokens[BRC20TickKey(tick)]
+	if !ok {
+		return nil
+	}
+	cp := *tok
+	return &cp
+}
+
+// Tokens 全部已部署代币
+func (s *BRC20State) Tokens() []types.BRC20Token {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]types.BRC20Token, 0, len(s.tokens))
+	for _, tok := range s.tokens {
+		out = append(out, *tok)
+	}
+	slices.SortFunc(out, func(a, b types.BRC20Token) int { return a.DeployHeight - b.DeployHeight })
+	return out
+}
+
+// Balance 查询地址在某个代币上的余额
+func (s *BRC20State) Balance(tick, address string) types.BRC20Balance {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if bal, ok := s.balances[balanceKey(tick, address)]; ok {
+		return *bal
+	}
+	if tok, ok := s.tokens[BRC20TickKey(tick)]; ok {
+		tick = tok.Tick
+	}
+	return types.BRC20Balance{Tick: tick, Address: address}
+}
+
+// Balances 查询地址的全部代币余额
+func (s *BRC20State) Balances(address string) []types.BRC20Balance {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []types.BRC20Balance
+	for _, bal := range s.balances {
+		if bal.Address == address {
+			out = append(out, *bal)
+		}
+	}
+	slices.SortFunc(out, func(a, b types.BRC20Balance) int {
+		switch {
+		case a.Tick < b.Tick:
+			return -1
+		case a.Tick > b.Tick:
+			return 1
+		}
+		return 0
+	})
+	return out
+}
+
+// Transfer 查询待转移的 transfer 铭文, 不存在或已转移时返回 nil
+func (s *BRC20State) Transfer(inscriptionID string) *types.BRC20Transfer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.transfers[inscriptionID]
+	if !ok {
+		return nil
+	}
+	cp := *t
+	return &cp
+}
+
+// Events 返回 [from, to] 高度区间内的事件
+func (s *BRC20State) Events(from, to int) []types.BRC20Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []types.BRC20Event
+	for _, ev := range s.events {
+		if ev.BlockHeight >= from && ev.BlockHeight <= to {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// 更新待转移铭文的位置
+func (s *BRC20State) setLocation(inscriptionID, location string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.transfers[inscriptionID]
+	if !ok {
+		return
+	}
+	old := t.Location
+	t.Location = location
+	s.record(func() { t.Location = old })
+}
@@ -0,0 +1,232 @@
+package ordinals
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// 铭文 metadata 使用 CBOR 编码, 这里只实现解码到通用 JSON 结构所需的最小子集:
+// 整数/字节串/文本/数组/映射/tag/浮点/简单值, 支持不定长编码.
+// 字节串以十六进制字符串输出, 映射的非字符串 key 会格式化为字符串, 便于直接 JSON 序列化.
+
+const cborMaxDepth = 64
+
+var errCBORShort = errors.New("cbor: unexpected end of data")
+
+// DecodeCBOR 解码单个 CBOR 数据项, 数据末尾不允许有多余字节
+func DecodeCBOR(b []byte) (any, error) {
+	d := &cborDecoder{buf: b}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.buf) {
+		return nil, fmt.Errorf("cbor: %d trailing bytes", len(d.buf)-d.pos)
+	}
+	return v, nil
+}
+
+type cborDecoder struct {
+	buf []byte
+	pos int
+}
+
+// 读取头部: 主类型, 附加信息, 参数值
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, err error) {
+	if d.pos >= len(d.buf) {
+		return 0, 0, 0, errCBORShort
+	}
+	ib := d.buf[d.pos]
+	d.pos++
+	major, info = ib>>5, ib&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24:
+		if d.pos+1 > len(d.buf) {
+			return 0, 0, 0, errCBORShort
+		}
+		arg = uint64(d.buf[d.pos])
+		d.pos++
+	case info == 25:
+		if d.pos+2 > len(d.buf) {
+			return 0, 0, 0, errCBORShort
+		}
+		arg = uint64(binary.BigEndian.Uint16(d.buf[d.pos:]))
+		d.pos += 2
+	case info == 26:
+		if d.pos+4 > len(d.buf) {
+			return 0, 0, 0, errCBORShort
+		}
+		arg = uint64(binary.BigEndian.Uint32(d.buf[d.pos:]))
+		d.pos += 4
+	case info == 27:
+		if d.pos+8 > len(d.buf) {
+			return 0, 0, 0, errCBORShort
+		}
+		arg = binary.BigEndian.Uint64(d.buf[d.pos:])
+		d.pos += 8
+	case info == 31:
+		// 不定长, 由调用方处理
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: reserved additional info %d", info)
+	}
+	return major, info, arg, nil
+}
+
+func (d *cborDecoder) isBreak() bool {
+	return d.pos < len(d.buf) && d.buf[d.pos] == 0xff
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.buf)-d.pos) {
+		return nil, errCBORShort
+	}
+	b := d.buf[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// 读取字节串/文本串(支持不定长分段)
+func (d *cborDecoder) str(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		return d.bytes(arg)
+	}
+	var out []byte
+	for !d.isBreak() {
+		m, i, a, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || i == 31 {
+			return nil, errors.New("cbor: invalid indefinite string chunk")
+		}
+		chunk, err := d.bytes(a)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+	d.pos++ // break
+	return out, nil
+}
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0: // 无符号整数
+		return arg, nil
+	case 1: // 负整数: -1 - arg
+		if arg > math.MaxInt64 {
+			// 超出 int64 时用字符串表示
+			n := new(big.Int).SetUint64(arg)
+			return "-" + n.Add(n, big.NewInt(1)).String(), nil
+		}
+		return -1 - int64(arg), nil
+	case 2: // 字节串
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(b), nil
+	case 3: // 文本
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 4: // 数组
+		var out []any
+		for i := uint64(0); info == 31 || i < arg; i++ {
+			if info == 31 && d.isBreak() {
+				d.pos++
+				break
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		if out == nil {
+			out = []any{}
+		}
+		return out, nil
+	case 5: // 映射
+		out := make(map[string]any)
+		for i := uint64(0); info == 31 || i < arg; i++ {
+			if info == 31 && d.isBreak() {
+				d.pos++
+				break
+			}
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			out[key] = v
+		}
+		return out, nil
+	case 6: // tag: 忽略 tag 编号, 直接返回内容
+		return d.decode(depth + 1)
+	default: // 7: 浮点与简单值
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return halfToFloat(uint16(arg)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		case 27:
+			return math.Float64frombits(arg), nil
+		case 31:
+			return nil, errors.New("cbor: unexpected break")
+		default:
+			return arg, nil
+		}
+	}
+}
+
+// IEEE 754 半精度转 float64
+func halfToFloat(h uint16) float64 {
+	exp := (h >> 10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, int(exp)-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
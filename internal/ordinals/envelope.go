@@ -0,0 +1,148 @@
+package ordinals
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 单次 push 的最大字节数(tapscript 的 MAX_SCRIPT_ELEMENT_SIZE)
+const maxPushSize = txscript.MaxScriptElementSize
+
+// EnvelopeField 构建信封时的附加字段
+type EnvelopeField struct {
+	Pointer *uint64 // tag 2: 铭文落在 reveal 输出中的 sat 偏移
+	Parent  string  // tag 3: 父铭文ID
+}
+
+// BuildRevealScript 构建 reveal 使用的 tapscript: <xonly pubkey> OP_CHECKSIG 之后依次追加每个铭文的信封.
+// 不使用 txscript.ScriptBuilder, 因为它限制脚本总长度为 10000 字节, 而 tapscript 没有这个限制.
+func BuildRevealScript(xOnlyPubKey []byte, inscriptions []types.InscriptionData, fields []EnvelopeField) ([]byte, error) {
+	if len(xOnlyPubKey) != 32 {
+		return nil, fmt.Errorf("invalid x-only pubkey length: %d", len(xOnlyPubKey))
+	}
+	if len(fields) != 0 && len(fields) != len(inscriptions) {
+		return nil, fmt.Errorf("envelope fields count mismatch: %d != %d", len(fields), len(inscriptions))
+	}
+
+	script := appendPush(nil, xOnlyPubKey)
+	script = append(script, txscript.OP_CHECKSIG)
+	for i := range inscriptions {
+		var f EnvelopeField
+		if len(fields) > 0 {
+			f = fields[i]
+		}
+		env, err := BuildEnvelope(&inscriptions[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("inscription %d: %w", i, err)
+		}
+		script = append(script, env...)
+	}
+	return script, nil
+}
+
+// BuildEnvelope 构建单个 OP_FALSE OP_IF "ord" ... OP_ENDIF 信封.
+// 字段按 tag 从小到大写入, body 与 metadata 按 520 字节分段.
+func BuildEnvelope(data *types.InscriptionData, f EnvelopeField) ([]byte, error) {
+	script := []byte{txscript.OP_FALSE, txscript.OP_IF}
+	script = appendPush(script, protocolID)
+
+	field := func(tag byte, value []byte) error {
+		if len(value) > maxPushSize {
+			return fmt.Errorf("tag %d value too large: %d bytes", tag, len(value))
+		}
+		script = appendPush(script, []byte{tag})
+		script = appendPush(script, value)
+		return nil
+	}
+
+	if data.ContentType != "" {
+		if err := field(tagContentType, []byte(data.ContentType)); err != nil {
+			return nil, err
+		}
+	}
+	if f.Pointer != nil && *f.Pointer > 0 {
+		if err := field(tagPointer, EncodePointer(*f.Pointer)); err != nil {
+			return nil, err
+		}
+	}
+	if f.Parent != "" {
+		id, err := EncodeInscriptionID(f.Parent)
+		if err != nil {
+			return nil, err
+		}
+		if err := field(tagParent, id); err != nil {
+			return nil, err
+		}
+	}
+	for _, chunk := range chunks(data.MetadataCBOR) {
+		if err := field(tagMetadata, chunk); err != nil {
+			return nil, err
+		}
+	}
+	if data.Metaprotocol != "" {
+		if err := field(tagMetaprotocol, []byte(data.Metaprotocol)); err != nil {
+			return nil, err
+		}
+	}
+	if data.ContentEncoding != "" {
+		if err := field(tagContentEncoding, []byte(data.ContentEncoding)); err != nil {
+			return nil, err
+		}
+	}
+	if data.Delegate != "" {
+		id, err := EncodeInscriptionID(data.Delegate)
+		if err != nil {
+			return nil, err
+		}
+		if err := field(tagDelegate, id); err != nil {
+			return nil, err
+		}
+	}
+	if len(data.Body) > 0 {
+		script = append(script, txscript.OP_0)
+		for _, chunk := range chunks(data.Body) {
+			script = appendPush(script, chunk)
+		}
+	}
+
+	return append(script, txscript.OP_ENDIF), nil
+}
+
+// 按最大 push 长度切分
+func chunks(b []byte) [][]byte {
+	var out [][]byte
+	for len(b) > 0 {
+		n := min(len(b), maxPushSize)
+		out = append(out, b[:n])
+		b = b[n:]
+	}
+	return out
+}
+
+// 追加一个数据 push. 与 ScriptBuilder.AddData 不同, 单字节数据不会被替换为 OP_1..OP_16,
+// 否则 ord 会把信封标记为 pushnum.
+func appendPush(script []byte, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n == 0:
+		return append(script, txscript.OP_0)
+	case n < txscript.OP_PUSHDATA1:
+		script = append(script, byte(n))
+	case n <= 0xff:
+		script = append(script, txscript.OP_PUSHDATA1, byte(n))
+	case n <= 0xffff:
+		var buf [2]byte
+		binary.LittleEndian.PutUint16(buf[:], uint16(n))
+		script = append(script, txscript.OP_PUSHDATA2)
+		script = append(script, buf[:]...)
+	default:
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(n))
+		script = append(script, txscript.OP_PUSHDATA4)
+		script = append(script, buf[:]...)
+	}
+	return append(script, data...)
+}
@@ -0,0 +1,194 @@
+package ordinals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// blockFollower 按高度顺序把数据源的区块交给 apply 处理, 遇到分叉时按撤销记录回滚到共同祖先.
+// BRC20Indexer 与 InscriptionIndex 共用.
+type blockFollower struct {
+	name        string // 用于日志与错误信息
+	src         BRC20BlockSource
+	startHeight int
+	tip         int
+	hashes      map[int]string
+	undo        map[int]func()
+
+	// 处理单个区块, 成功时返回撤销函数; 失败时需要自行撤销已做的修改, 保证可以重试
+	apply func(ctx context.Context, blk *types.Block) (func(), error)
+}
+
+func newBlockFollower(name string, src BRC20BlockSource, startHeight int, apply func(context.Context, *types.Block) (func(), error)) *blockFollower {
+	return &blockFollower{
+		name:        name,
+		src:         src,
+		startHeight: startHeight,
+		tip:         -1,
+		hashes:      make(map[int]string),
+		undo:        make(map[int]func()),
+		apply:       apply,
+	}
+}
+
+// 索引到数据源的最新区块, 遇到分叉时回滚到共同祖先后继续. 返回最新已索引高度.
+func (f *blockFollower) sync(ctx context.Context) (int, error) {
+	best, err := f.src.GetBlockCount(ctx)
+	if err != nil {
+		return f.tip, err
+	}
+	if f.tip >= 0 {
+		srcHash, err := f.src.GetBlockHash(ctx, f.tip)
+		if err != nil {
+			return f.tip, err
+		}
+		if srcHash != f.hashes[f.tip] {
+			if err := f.rollbackToCommonAncestor(ctx); err != nil {
+				return f.tip, err
+			}
+		}
+	}
+
+	height := max(f.tip+1, f.startHeight)
+	for height <= best {
+		if err := ctx.Err(); err != nil {
+			return f.tip, err
+		}
+		hash, err := f.src.GetBlockHash(ctx, height)
+		if err != nil {
+			return f.tip, err
+		}
+		blk, err := f.src.GetBlock(ctx, hash)
+		if err != nil {
+			return f.tip, err
+		}
+		blk.Height = height
+
+		if prev, ok := f.hashes[height-1]; ok && prev != blk.PrevHash {
+			if err := f.rollbackToCommonAncestor(ctx); err != nil {
+				return f.tip, err
+			}
+			height = max(f.tip+1, f.startHeight)
+			continue
+		}
+
+		if err := f.indexBlock(ctx, blk); err != nil {
+			return f.tip, fmt.Errorf("index block %d: %w", height, err)
+		}
+		height++
+	}
+	return f.tip, nil
+}
+
+// 从当前高度向下寻找与数据源一致的区块, 撤销其后的全部区块
+func (f *blockFollower) rollbackToCommonAncestor(ctx context.Context) error {
+	for f.tip >= f.startHeight {
+		srcHash, err := f.src.GetBlockHash(ctx, f.tip)
+		if err != nil {
+			return err
+		}
+		if srcHash == f.hashes[f.tip] {
+			break
+		}
+		undo, ok := f.undo[f.tip]
+		if !ok {
+			return fmt.Errorf("%s: reorg deeper than %d blocks at height %d", f.name, BRC20MaxReorgDepth, f.tip)
+		}
+		undo()
+		delete(f.undo, f.tip)
+		delete(f.hashes, f.tip)
+		f.tip--
+	}
+	logger.Warn("%s: reorg detected, rollback to height %d", f.name, f.tip)
+	return nil
+}
+
+// 处理单个区块并保存撤销记录, 调用方需要保证按高度顺序调用
+func (f *blockFollower) indexBlock(ctx context.Context, blk *types.Block) error {
+	undo, err := f.apply(ctx, blk)
+	if err != nil {
+		return err
+	}
+	f.tip = blk.Height
+	f.hashes[blk.Height] = blk.Hash
+	f.undo[blk.Height] = undo
+	delete(f.undo, blk.Height-BRC20MaxReorgDepth)
+	delete(f.hashes, blk.Height-BRC20MaxReorgDepth-1)
+	return nil
+}
+
+// 带有被跟踪铭文的输出
+type trackedOutput struct {
+	value        int64
+	inscriptions []trackedInscription
+}
+
+type trackedInscription struct {
+	id     string
+	offset int64 // 在输出内的 sat 偏移
+}
+
+// 被跟踪的输出, key: txid:vout; 修改时把撤销操作追加到 undo
+type trackedOutputs map[string]*trackedOutput
+
+// 在输出上记录铭文
+func (m trackedOutputs) add(op string, value int64, ins trackedInscription, undo *[]func()) {
+	old, ok := m[op]
+	out := &trackedOutput{value: value}
+	if ok {
+		out.inscriptions = append(out.inscriptions, old.inscriptions...)
+	}
+	out.inscriptions = append(out.inscriptions, ins)
+	m[op] = out
+	*undo = append(*undo, func() {
+		if ok {
+			m[op] = old
+		} else {
+			delete(m, op)
+		}
+	})
+}
+
+// 输出被花费, 返回其上的记录
+func (m trackedOutputs) remove(op string, undo *[]func()) (*trackedOutput, bool) {
+	old, ok := m[op]
+	if !ok {
+		return nil, false
+	}
+	delete(m, op)
+	*undo = append(*undo, func() { m[op] = old })
+	return old, true
+}
+
+// 交易输入的起始 sat 偏移, 按需查询被花费输出的金额(铭文不在第一个输入时才需要)
+func inputOffsets(tx *types.Tx, valueOf func(prev types.TxOutPoint) (int64, error)) func(input int) (int64, error) {
+	offsets := make([]int64, 0, len(tx.TxIn)+1)
+	offsets = append(offsets, 0)
+	return func(input int) (int64, error) {
+		for len(offsets) <= input {
+			i := len(offsets) - 1
+			v, err := valueOf(tx.TxIn[i].PreviousOutPoint)
+			if err != nil {
+				return 0, err
+			}
+			offsets = append(offsets, offsets[i]+v)
+		}
+		return offsets[input], nil
+	}
+}
+
+// 被花费输出的金额: 优先使用跟踪记录和本区块交易, 否则查询数据源
+func spentValue(ctx context.Context, src BRC20BlockSource, tracked trackedOutputs, outputs map[string][]types.TxOut, prev types.TxOutPoint) (int64, error) {
+	txid := chainhash.Hash(prev.Hash).String()
+	if out, ok := tracked[fmt.Sprintf("%s:%d", txid, prev.Index)]; ok {
+		return out.value, nil
+	}
+	if outs, ok := outputs[txid]; ok && int(prev.Index) < len(outs) {
+		return outs[prev.Index].Value, nil
+	}
+	return src.GetTxOutValue(ctx, txid, prev.Index)
+}
@@ -1 +1,321 @@
+// Package ordinals 提供 Ordinals 铭文(inscription)相关的解析工具
 package ordinals
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 信封协议标识: OP_FALSE OP_IF "ord" ... OP_ENDIF
+var protocolID = []byte("ord")
+
+// 信封字段 tag(单字节), 参考 ord 的 Tag 定义
+const (
+	tagBody            = 0
+	tagContentType     = 1
+	tagPointer         = 2
+	tagParent          = 3
+	tagMetadata        = 5
+	tagMetaprotocol    = 7
+	tagContentEncoding = 9
+	tagDelegate        = 11
+	tagRune            = 13
+	tagNote            = 15
+)
+
+// 可以分多段 push 的字段, 解析时拼接; 其余字段重复出现视为 duplicate
+func isChunkedTag(tag byte) bool {
+	return tag == tagMetadata || tag == tagNote
+}
+
+// 可以出现多次的数组字段
+func isArrayTag(tag byte) bool {
+	return tag == tagParent
+}
+
+// ParseTx 解析整笔交易所有输入中的铭文, 并把结果挂到 TxIn.Tapscript.Ord 上.
+// 铭文ID 需要交易ID, tx.TxID 为空时只返回序号不生成ID.
+func ParseTx(tx *types.Tx) []types.Inscription {
+	var all []types.Inscription
+	for i := range tx.TxIn {
+		in := &tx.TxIn[i]
+		_, script, _, ok := decoders.ExtractTapScriptPath(in.Witness)
+		if !ok {
+			continue
+		}
+		inscriptions := ParseTapscript(script, i)
+		if len(inscriptions) == 0 {
+			continue
+		}
+		for j := range inscriptions {
+			inscriptions[j].Index = len(all)
+			if tx.TxID != "" {
+				inscriptions[j].ID = fmt.Sprintf("%si%d", tx.TxID, len(all))
+			}
+			all = append(all, inscriptions[j])
+		}
+		if in.Tapscript != nil {
+			in.Tapscript.Ord = &types.OrdinalsEnvelope{Inscriptions: inscriptions}
+		}
+	}
+	return all
+}
+
+// ParseWitness 从单个输入的 witness 中解析铭文(必须是脚本路径花费).
+func ParseWitness(w [][]byte, input int) ([]types.Inscription, bool) {
+	_, script, _, ok := decoders.ExtractTapScriptPath(w)
+	if !ok {
+		return nil, false
+	}
+	inscriptions := ParseTapscript(script, input)
+	return inscriptions, len(inscriptions) > 0
+}
+
+// ParseTapscript 解析 tapscript 中的全部信封.
+// 脚本无法完整解析(例如 push 被截断)时返回 nil, 与 ord 一致, 该输入中的信封全部忽略.
+// stutter 只在信封不完整时更新, 之后解析成功的信封都沿用该值.
+func ParseTapscript(script []byte, input int) []types.Inscription {
+	var out []types.Inscription
+	stuttered := false
+
+	tok := txscript.MakeScriptTokenizer(0, script)
+	for tok.Next() {
+		if !isEmptyPush(tok) {
+			continue
+		}
+		stutter, payload, pushnum, ok := readEnvelope(&tok)
+		if !ok {
+			stuttered = stutter
+			continue
+		}
+		ins := parsePayload(payload)
+		ins.Input = input
+		ins.Index = len(out)
+		ins.Pushnum = pushnum
+		ins.Stutter = stuttered
+		out = append(out, ins)
+	}
+	if tok.Err() != nil {
+		return nil
+	}
+	return out
+}
+
+// 读取 OP_FALSE 之后的信封内容.
+// 返回: 是否 stutter(下一个操作码又是 OP_FALSE), 信封 push 列表, 是否用到 pushnum, 是否为完整信封.
+// 与 ord 相同, OP_IF 与 "ord" 只在匹配时才消耗, 不匹配的操作码留给调用方重新扫描,
+// 例如 OP_0 OP_0 OP_IF "ord" ... 中第二个 OP_0 会开始新的信封.
+func readEnvelope(tok *txscript.ScriptTokenizer) (bool, [][]byte, bool, bool) {
+	peek := *tok
+	if !peek.Next() || peek.Opcode() != txscript.OP_IF {
+		return nextIsEmptyPush(*tok), nil, false, false
+	}
+	*tok = peek
+	if !peek.Next() || !isDataPush(peek.Opcode()) || !bytes.Equal(peek.Data(), protocolID) {
+		return nextIsEmptyPush(*tok), nil, false, false
+	}
+	*tok = peek
+
+	var payload [][]byte
+	pushnum := false
+	for tok.Next() {
+		op := tok.Opcode()
+		switch {
+		case op == txscript.OP_ENDIF:
+			return false, payload, pushnum, true
+		case op == txscript.OP_1NEGATE:
+			pushnum = true
+			payload = append(payload, []byte{0x81})
+		case op >= txscript.OP_1 && op <= txscript.OP_16:
+			pushnum = true
+			payload = append(payload, []byte{op - txscript.OP_1 + 1})
+		case isDataPush(op):
+			payload = append(payload, append([]byte(nil), tok.Data()...))
+		default:
+			return false, nil, false, false
+		}
+	}
+	return false, nil, false, false
+}
+
+// 把信封 push 列表解析为铭文字段
+func parsePayload(payload [][]byte) types.Inscription {
+	var ins types.Inscription
+
+	// body 分隔符: 偶数位置上的空 push
+	bodyAt := -1
+	for i := 0; i < len(payload); i += 2 {
+		if len(payload[i]) == 0 {
+			bodyAt = i
+			break
+		}
+	}
+	fieldsEnd := len(payload)
+	if bodyAt >= 0 {
+		fieldsEnd = bodyAt
+	}
+
+	// tag 按原始字节区分, 多字节 tag 一定是未识别字段
+	fields := make(map[string][][]byte)
+	var order []string
+	for i := 0; i < fieldsEnd; i += 2 {
+		if i+1 >= fieldsEnd {
+			ins.IncompleteField = true
+			break
+		}
+		key := string(payload[i])
+		if _, ok := fields[key]; !ok {
+			order = append(order, key)
+		}
+		fields[key] = append(fields[key], payload[i+1])
+	}
+
+	for _, key := range order {
+		values := fields[key]
+		if len(values) > 1 && !(len(key) == 1 && (isChunkedTag(key[0]) || isArrayTag(key[0]))) {
+			ins.DuplicateField = true
+		}
+	}
+
+	take := func(tag byte) ([]byte, bool) {
+		values, ok := fields[string([]byte{tag})]
+		if !ok {
+			return nil, false
+		}
+		delete(fields, string([]byte{tag}))
+		return values[0], true
+	}
+
+	if v, ok := take(tagContentEncoding); ok {
+		ins.ContentEncoding = string(v)
+	}
+	if v, ok := take(tagContentType); ok {
+		ins.ContentType = string(v)
+	}
+	if v, ok := take(tagDelegate); ok {
+		if id, ok := decodeInscriptionID(v); ok {
+			ins.Delegate = id
+		}
+	}
+	if values, ok := fields[string([]byte{tagMetadata})]; ok {
+		delete(fields, string([]byte{tagMetadata}))
+		raw := bytes.Join(values, nil)
+		ins.MetadataHex = hex.EncodeToString(raw)
+		if v, err := DecodeCBOR(raw); err == nil {
+			ins.Metadata = v
+		}
+	}
+	if v, ok := take(tagMetaprotocol); ok {
+		ins.Metaprotocol = string(v)
+	}
+	if values, ok := fields[string([]byte{tagParent})]; ok {
+		delete(fields, string([]byte{tagParent}))
+		for _, v := range values {
+			if id, ok := decodeInscriptionID(v); ok {
+				ins.Parents = append(ins.Parents, id)
+			}
+		}
+	}
+	if v, ok := take(tagPointer); ok {
+		if p, ok := decodePointer(v); ok {
+			ins.Pointer = &p
+		}
+	}
+	// rune / note 目前只做识别, 不对外暴露
+	take(tagRune)
+	delete(fields, string([]byte{tagNote}))
+
+	for key := range fields {
+		if len(key) > 0 && key[0]%2 == 0 {
+			ins.UnrecognizedEvenField = true
+			break
+		}
+	}
+
+	if bodyAt >= 0 {
+		ins.HasBody = true
+		ins.Body = bytes.Join(payload[bodyAt+1:], nil)
+	}
+	return ins
+}
+
+// 铭文ID 二进制格式: 32字节 txid(内部字节序) + 最多4字节小端 index(去掉末尾的0)
+func decodeInscriptionID(b []byte) (string, bool) {
+	if len(b) < 32 || len(b) > 36 {
+		return "", false
+	}
+	var h chainhash.Hash
+	copy(h[:], b[:32])
+	var idx [4]byte
+	copy(idx[:], b[32:])
+	return fmt.Sprintf("%si%d", h.String(), binary.LittleEndian.Uint32(idx[:])), true
+}
+
+// EncodeInscriptionID 把 <txid>i<index> 编码为信封中的二进制格式
+func EncodeInscriptionID(id string) ([]byte, error) {
+	var txid string
+	var index uint32
+	if len(id) < 66 || id[64] != 'i' {
+		return nil, fmt.Errorf("invalid inscription id: %s", id)
+	}
+	txid = id[:64]
+	if _, err := fmt.Sscanf(id[65:], "%d", &index); err != nil {
+		return nil, fmt.Errorf("invalid inscription id index: %s", id)
+	}
+	h, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inscription id txid: %w", err)
+	}
+	out := append([]byte(nil), h[:]...)
+	var idx [4]byte
+	binary.LittleEndian.PutUint32(idx[:], index)
+	n := 4
+	for n > 0 && idx[n-1] == 0 {
+		n--
+	}
+	return append(out, idx[:n]...), nil
+}
+
+// pointer 为小端整数, 超过 8 字节的非零部分视为无效
+func decodePointer(b []byte) (uint64, bool) {
+	for i := 8; i < len(b); i++ {
+		if b[i] != 0 {
+			return 0, false
+		}
+	}
+	var buf [8]byte
+	copy(buf[:], b)
+	return binary.LittleEndian.Uint64(buf[:]), true
+}
+
+// EncodePointer 把 pointer 编码为最短小端字节
+func EncodePointer(p uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], p)
+	n := 8
+	for n > 0 && buf[n-1] == 0 {
+		n--
+	}
+	return buf[:n]
+}
+
+// 数据 push 操作码(含 OP_0 空 push)
+func isDataPush(op byte) bool {
+	return op <= txscript.OP_PUSHDATA4
+}
+
+func isEmptyPush(tok txscript.ScriptTokenizer) bool {
+	return isDataPush(tok.Opcode()) && len(tok.Data()) == 0
+}
+
+// 不移动 tok, 查看下一个操作码是否为空 push(tok 为值拷贝)
+func nextIsEmptyPush(tok txscript.ScriptTokenizer) bool {
+	return tok.Next() && isEmptyPush(tok)
+}
@@ -0,0 +1,188 @@
+package ordinals
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// InscriptionFirstHeight 首个铭文所在高度, 之前的区块无需索引
+func InscriptionFirstHeight(net types.Network) int {
+	if net == types.Mainnet {
+		return 767430
+	}
+	return 0
+}
+
+// InscriptionIndex 逐块跟踪全部铭文所在的输出与输出内偏移, 实现 tx.InscriptionLocator.
+// 铭文按 FIFO 规则随 sat 转移; 落入手续费的铭文无法确定在 coinbase 中的偏移,
+// 记在该区块 coinbase 的全部输出上, 这些输出被花费后不再跟踪. 未确认交易的输出不在索引中.
+// Sync 与 InscriptionsAt 可以并发调用.
+type InscriptionIndex struct {
+	f   *blockFollower
+	src BRC20BlockSource
+
+	mu        sync.RWMutex
+	height    int // 最新已处理区块, 与 f.tip 相同, 供并发读取
+	locations trackedOutputs
+}
+
+// NewInscriptionIndex 创建索引, 默认从首个铭文所在高度开始; 数据源与 BRC-20 索引器相同
+func NewInscriptionIndex(src BRC20BlockSource, network types.Network) *InscriptionIndex {
+	ix := &InscriptionIndex{
+		src:       src,
+		height:    -1,
+		locations: make(trackedOutputs),
+	}
+	ix.f = newBlockFollower("inscription index", src, InscriptionFirstHeight(network), ix.applyBlock)
+	return ix
+}
+
+// SetStartHeight 修改起始高度(仅在尚未索引任何区块时有效)
+func (ix *InscriptionIndex) SetStartHeight(height int) {
+	ix.f.startHeight = height
+}
+
+// Tip 最新已索引区块, 尚未索引时为 -1
+func (ix *InscriptionIndex) Tip() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.height
+}
+
+// Sync 索引到数据源的最新区块, 遇到分叉时回滚到共同祖先后继续. 返回最新已索引高度.
+func (ix *InscriptionIndex) Sync(ctx context.Context) (int, error) {
+	return ix.f.sync(ctx)
+}
+
+// IndexBlock 处理单个区块, 调用方需要保证按高度顺序调用
+func (ix *InscriptionIndex) IndexBlock(ctx context.Context, blk *types.Block) error {
+	return ix.f.indexBlock(ctx, blk)
+}
+
+// InscriptionsAt 输出(txid:vout)上的铭文ID; 尚未索引任何区块时返回错误, 避免把所有输出当作没有铭文
+func (ix *InscriptionIndex) InscriptionsAt(ctx context.Context, outpoint string) ([]string, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if ix.height < 0 {
+		return nil, fmt.Errorf("%w: inscription index not synced", types.ErrBackendUnavailable)
+	}
+	out, ok := ix.locations[outpoint]
+	if !ok {
+		return nil, nil
+	}
+	ids := make([]string, len(out.inscriptions))
+	for i, ins := range out.inscriptions {
+		ids[i] = ins.id
+	}
+	return ids, nil
+}
+
+// 区块内的处理上下文
+type inscriptionBlock struct {
+	ix      *InscriptionIndex
+	ctx     context.Context
+	outputs map[string][]types.TxOut // 本区块交易的输出, 避免重复查询
+	flotsam []string                 // 落入手续费的铭文
+	undo    []func()
+}
+
+// coinbase 最后处理, 以便接收本区块落入手续费的铭文
+func (ix *InscriptionIndex) applyBlock(ctx context.Context, blk *types.Block) (func(), error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	st := &inscriptionBlock{ix: ix, ctx: ctx, outputs: make(map[string][]types.TxOut)}
+	for _, tx := range blk.Txs {
+		st.outputs[tx.TxID] = tx.TxOut
+	}
+	revert := func() {
+		for j := len(st.undo) - 1; j >= 0; j-- {
+			st.undo[j]()
+		}
+	}
+	for i, tx := range blk.Txs {
+		if i == 0 {
+			continue
+		}
+		if err := st.indexTx(tx); err != nil {
+			revert()
+			return nil, fmt.Errorf("tx %s: %w", tx.TxID, err)
+		}
+	}
+	if len(st.flotsam) > 0 && len(blk.Txs) > 0 {
+		coinbase := blk.Txs[0]
+		for vout, out := range coinbase.TxOut {
+			for _, id := range st.flotsam {
+				ix.locations.add(fmt.Sprintf("%s:%d", coinbase.TxID, vout), out.Value, trackedInscription{id: id, offset: -1}, &st.undo)
+			}
+		}
+	}
+
+	prev := ix.height
+	ix.height = blk.Height
+	return func() {
+		ix.mu.Lock()
+		defer ix.mu.Unlock()
+		revert()
+		ix.height = prev
+	}, nil
+}
+
+func (st *inscriptionBlock) indexTx(tx *types.Tx) error {
+	ix := st.ix
+	offsetOf := inputOffsets(tx, func(prev types.TxOutPoint) (int64, error) {
+		return spentValue(st.ctx, ix.src, ix.locations, st.outputs, prev)
+	})
+
+	// 本交易中需要重新定位的铭文, offset 为在全部输入 sat 中的偏移
+	var moving []trackedInscription
+
+	// 1. 被花费输出上已有的铭文
+	for i, in := range tx.TxIn {
+		op := outpointKey(in.PreviousOutPoint)
+		out, ok := ix.locations[op]
+		if !ok {
+			continue
+		}
+		base, err := offsetOf(i)
+		if err != nil {
+			return err
+		}
+		ix.locations.remove(op, &st.undo)
+		for _, ins := range out.inscriptions {
+			if ins.offset < 0 {
+				logger.Warn("inscription index: %s in coinbase output %s has unknown offset, no longer tracked", ins.id, op)
+				continue
+			}
+			moving = append(moving, trackedInscription{id: ins.id, offset: base + ins.offset})
+		}
+	}
+
+	// 2. 新铭文, 位于所在输入的第一个 sat, 或 pointer 指定的位置
+	for _, ins := range ParseTx(tx) {
+		var offset int64
+		if ins.Pointer != nil && int64(*ins.Pointer) < totalValue(tx.TxOut) {
+			offset = int64(*ins.Pointer)
+		} else {
+			var err error
+			if offset, err = offsetOf(ins.Input); err != nil {
+				return err
+			}
+		}
+		moving = append(moving, trackedInscription{id: ins.ID, offset: offset})
+	}
+
+	for _, m := range moving {
+		vout, inner, toFee := locate(tx.TxOut, m.offset)
+		if toFee {
+			st.flotsam = append(st.flotsam, m.id)
+			continue
+		}
+		ix.locations.add(fmt.Sprintf("%s:%d", tx.TxID, vout), tx.TxOut[vout].Value, trackedInscription{id: m.id, offset: inner}, &st.undo)
+	}
+	return nil
+}
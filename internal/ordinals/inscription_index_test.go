@@ -0,0 +1,163 @@
+package ordinals
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 内存中的区块来源, 替换 blocks 模拟重组
+type memBlockSource struct {
+	blocks []*types.Block
+	values map[string]int64 // 链外资金输出的金额, key: txid:vout
+}
+
+func (m *memBlockSource) GetBlockCount(ctx context.Context) (int, error) {
+	return len(m.blocks) - 1, nil
+}
+
+func (m *memBlockSource) GetBlockHash(ctx context.Context, height int) (string, error) {
+	return m.blocks[height].Hash, nil
+}
+
+func (m *memBlockSource) GetBlock(ctx context.Context, hash string) (*types.Block, error) {
+	for _, b := range m.blocks {
+		if b.Hash == hash {
+			return b, nil
+		}
+	}
+	return nil, types.ErrNotFound
+}
+
+func (m *memBlockSource) GetTxOutValue(ctx context.Context, txid string, vout uint32) (int64, error) {
+	if v, ok := m.values[fmt.Sprintf("%s:%d", txid, vout)]; ok {
+		return v, nil
+	}
+	for _, b := range m.blocks {
+		for _, tx := range b.Txs {
+			if tx.TxID == txid {
+				return tx.TxOut[vout].Value, nil
+			}
+		}
+	}
+	return 0, types.ErrNotFound
+}
+
+func testHash(name string) chainhash.Hash {
+	return chainhash.HashH([]byte(name))
+}
+
+func testTx(name string, ins []types.TxIn, values ...int64) *types.Tx {
+	tx := &types.Tx{TxID: testHash(name).String(), TxIn: ins}
+	for _, v := range values {
+		tx.TxOut = append(tx.TxOut, types.TxOut{Value: v, PkScript: []byte{txscript.OP_TRUE}})
+	}
+	return tx
+}
+
+func spend(tx string, vout uint32) types.TxIn {
+	return types.TxIn{PreviousOutPoint: types.TxOutPoint{Hash: types.Hash32(testHash(tx)), Index: vout}}
+}
+
+func testBlock(src *memBlockSource, name string, txs ...*types.Tx) {
+	prev := ""
+	if n := len(src.blocks); n > 0 {
+		prev = src.blocks[n-1].Hash
+	}
+	coinbase := testTx(name+"/coinbase", []types.TxIn{{}}, 5000)
+	src.blocks = append(src.blocks, &types.Block{
+		Hash:     testHash(name).String(),
+		PrevHash: prev,
+		Txs:      append([]*types.Tx{coinbase}, txs...),
+	})
+}
+
+func TestInscriptionIndex(t *testing.T) {
+	ctx := context.Background()
+	envelope, err := txscript.NewScriptBuilder().
+		AddData(make([]byte, 32)).AddOp(txscript.OP_CHECKSIG).
+		AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData(protocolID).
+		AddOp(txscript.OP_0).AddData([]byte("hello")).
+		AddOp(txscript.OP_ENDIF).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	control := append([]byte{byte(txscript.BaseLeafVersion)}, make([]byte, 32)...)
+
+	src := &memBlockSource{values: map[string]int64{
+		testHash("commit").String() + ":0":  10_000,
+		testHash("funding").String() + ":0": 1_000,
+	}}
+	reveal := testTx("reveal", []types.TxIn{spend("commit", 0)}, 546, 9_000)
+	reveal.TxIn[0].Witness = types.TxWitness{make([]byte, 64), envelope, control}
+	id := reveal.TxID + "i0"
+
+	testBlock(src, "0")
+	testBlock(src, "1", reveal)
+	ix := NewInscriptionIndex(src, types.Regtest)
+	if _, err := ix.InscriptionsAt(ctx, "x:0"); err == nil {
+		t.Fatal("unsynced index should fail closed")
+	}
+	if tip, err := ix.Sync(ctx); err != nil || tip != 1 {
+		t.Fatalf("sync = %d, %v", tip, err)
+	}
+	at := func(tx string, vout int) []string {
+		t.Helper()
+		ids, err := ix.InscriptionsAt(ctx, fmt.Sprintf("%s:%d", testHash(tx).String(), vout))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ids
+	}
+
+	// funding 在前, 铭文随 FIFO 落在第二个输出
+	transfer := testTx("transfer", []types.TxIn{spend("funding", 0), spend("reveal", 0)}, 1_000, 546)
+	testBlock(src, "2", transfer)
+	if _, err := ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := at("transfer", 1); !reflect.DeepEqual(got, []string{id}) {
+		t.Errorf("transfer:1 = %v", got)
+	}
+	// 全部作为手续费, 铭文记在 coinbase 输出上
+	burn := testTx("burn", []types.TxIn{spend("transfer", 1)})
+	testBlock(src, "3", burn)
+
+	if _, err := ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		tx   string
+		vout int
+		want []string
+	}{
+		{"reveal", 0, nil},
+		{"transfer", 0, nil},
+		{"transfer", 1, nil},
+		{"3/coinbase", 0, []string{id}},
+	} {
+		if got := at(tc.tx, tc.vout); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:%d = %v, want %v", tc.tx, tc.vout, got, tc.want)
+		}
+	}
+
+	// 重组: 区块 2, 3 被替换, transfer 与 burn 都不在新链上
+	src.blocks = src.blocks[:2]
+	testBlock(src, "2b")
+	testBlock(src, "3b")
+	testBlock(src, "4b")
+	if tip, err := ix.Sync(ctx); err != nil || tip != 4 {
+		t.Fatalf("sync after reorg = %d, %v", tip, err)
+	}
+	if got := at("reveal", 0); !reflect.DeepEqual(got, []string{id}) {
+		t.Errorf("reveal:0 after reorg = %v", got)
+	}
+	if got := at("3/coinbase", 0); got != nil {
+		t.Errorf("3/coinbase:0 after reorg = %v", got)
+	}
+}
@@ -0,0 +1,107 @@
+package ordinals
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func TestParseTapscript(t *testing.T) {
+	// 构建以 OP_CHECKSIG 结尾的 tapscript, fn 追加信封部分
+	script := func(fn func(b *txscript.ScriptBuilder)) []byte {
+		b := txscript.NewScriptBuilder().AddData(make([]byte, 32)).AddOp(txscript.OP_CHECKSIG)
+		fn(b)
+		s, err := b.Script()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	// ScriptBuilder.AddData 会把单字节数据编码为 OP_1..OP_16, tag 需要按 ord 的方式用 OP_DATA_1 push
+	tag := []byte{txscript.OP_DATA_1, tagContentType}
+	envelope := func(b *txscript.ScriptBuilder) {
+		b.AddOp(txscript.OP_IF).AddData(protocolID).
+			AddOps(tag).AddData([]byte("text/plain")).
+			AddOp(txscript.OP_0).AddData([]byte("hello")).
+			AddOp(txscript.OP_ENDIF)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		script  []byte
+		want    int
+		stutter bool
+		pushnum bool
+	}{
+		{"normal", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE)
+			envelope(b)
+		}), 1, false, false},
+		{"stutter", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_FALSE)
+			envelope(b)
+		}), 1, true, false},
+		{"stutter after if", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddOp(txscript.OP_FALSE)
+			envelope(b)
+		}), 1, true, false},
+		{"pushnum", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData(protocolID).
+				AddOp(txscript.OP_1).AddData([]byte("text/plain")).
+				AddOp(txscript.OP_0).AddOp(txscript.OP_16).
+				AddOp(txscript.OP_ENDIF)
+		}), 1, false, true},
+		{"incomplete", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData(protocolID).
+				AddOps(tag).AddData([]byte("text/plain"))
+		}), 0, false, false},
+		{"wrong protocol", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData([]byte("orb")).AddOp(txscript.OP_ENDIF)
+		}), 0, false, false},
+		{"two envelopes", script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE)
+			envelope(b)
+			b.AddOp(txscript.OP_FALSE)
+			envelope(b)
+		}), 2, false, false},
+		// push 被截断时丢弃整个脚本中的信封
+		{"truncated", append(script(func(b *txscript.ScriptBuilder) {
+			b.AddOp(txscript.OP_FALSE)
+			envelope(b)
+		}), txscript.OP_DATA_4, 0), 0, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ParseTapscript(tc.script, 0)
+			if len(got) != tc.want {
+				t.Fatalf("got %d inscriptions, want %d", len(got), tc.want)
+			}
+			if tc.want == 0 {
+				return
+			}
+			ins := got[0]
+			if ins.Stutter != tc.stutter || ins.Pushnum != tc.pushnum {
+				t.Errorf("stutter=%v pushnum=%v, want %v/%v", ins.Stutter, ins.Pushnum, tc.stutter, tc.pushnum)
+			}
+			if ins.ContentType != "text/plain" {
+				t.Errorf("content type = %q", ins.ContentType)
+			}
+			if !tc.pushnum && string(ins.Body) != "hello" {
+				t.Errorf("body = %q", ins.Body)
+			}
+			if tc.pushnum && string(ins.Body) != "\x10" {
+				t.Errorf("body = %x", ins.Body)
+			}
+		})
+	}
+
+	// stutter 之后成功解析的信封不会重置 stutter 标记
+	got := ParseTapscript(script(func(b *txscript.ScriptBuilder) {
+		b.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_FALSE)
+		envelope(b)
+		b.AddOp(txscript.OP_FALSE)
+		envelope(b)
+	}), 0)
+	if len(got) != 2 || !got[0].Stutter || !got[1].Stutter {
+		t.Errorf("stutter then success = %+v", got)
+	}
+}
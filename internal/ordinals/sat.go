@@ -0,0 +1,179 @@
+package ordinals
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 序数理论常量
+const (
+	SubsidyHalvingInterval = 210000                // 减半周期(区块)
+	DiffChangeInterval     = 2016                  // 难度调整周期(区块)
+	CycleEpochs            = 6                     // 每个 cycle 包含的减半周期数
+	InitialSubsidy         = 50 * 100_000_000      // 初始区块奖励(sats)
+	SupplySats             = 2_099_999_997_690_000 // sat 总量
+)
+
+// sat 稀有度
+const (
+	RarityCommon    = "common"
+	RarityUncommon  = "uncommon"  // 区块的第一个 sat
+	RarityRare      = "rare"      // 难度调整周期的第一个 sat
+	RarityEpic      = "epic"      // 减半周期的第一个 sat
+	RarityLegendary = "legendary" // cycle 的第一个 sat
+	RarityMythic    = "mythic"    // 创世区块的第一个 sat
+)
+
+// Subsidy 区块奖励(sats)
+func Subsidy(height uint64) uint64 {
+	epoch := height / SubsidyHalvingInterval
+	if epoch >= 64 {
+		return 0
+	}
+	return InitialSubsidy >> epoch
+}
+
+// FirstSat 区块 coinbase 新产生的第一个 sat
+func FirstSat(height uint64) uint64 {
+	var sat uint64
+	epoch := height / SubsidyHalvingInterval
+	for e := uint64(0); e < epoch && e < 64; e++ {
+		sat += SubsidyHalvingInterval * (InitialSubsidy >> e)
+	}
+	return sat + (height%SubsidyHalvingInterval)*Subsidy(height)
+}
+
+// SatHeight sat 被挖出的区块高度
+func SatHeight(sat uint64) (uint64, error) {
+	if sat >= SupplySats {
+		return 0, fmt.Errorf("sat %d exceeds supply", sat)
+	}
+	var height uint64
+	for e := uint64(0); e < 64; e++ {
+		subsidy := uint64(InitialSubsidy >> e)
+		epochSats := SubsidyHalvingInterval * subsidy
+		if sat < epochSats {
+			return height + sat/subsidy, nil
+		}
+		sat -= epochSats
+		height += SubsidyHalvingInterval
+	}
+	return 0, fmt.Errorf("sat %d exceeds supply", sat)
+}
+
+// Rarity sat 的稀有度
+func Rarity(sat uint64) (string, error) {
+	height, err := SatHeight(sat)
+	if err != nil {
+		return "", err
+	}
+	if sat != FirstSat(height) {
+		return RarityCommon, nil
+	}
+	switch {
+	case sat == 0:
+		return RarityMythic, nil
+	case height%SubsidyHalvingInterval == 0 && height%DiffChangeInterval == 0:
+		return RarityLegendary, nil
+	case height%SubsidyHalvingInterval == 0:
+		return RarityEpic, nil
+	case height%DiffChangeInterval == 0:
+		return RarityRare, nil
+	default:
+		return RarityUncommon, nil
+	}
+}
+
+// RangesValue 区间列表包含的 sat 总数
+func RangesValue(ranges []types.SatRange) uint64 {
+	var total uint64
+	for _, r := range ranges {
+		total += r.Size()
+	}
+	return total
+}
+
+// SatOffset sat 在区间列表(即一个 UTXO)中的偏移
+func SatOffset(ranges []types.SatRange, sat uint64) (uint64, bool) {
+	var offset uint64
+	for _, r := range ranges {
+		if sat >= r.Start && sat < r.End {
+			return offset + sat - r.Start, true
+		}
+		offset += r.Size()
+	}
+	return 0, false
+}
+
+// SatAt 区间列表中指定偏移处的 sat
+func SatAt(ranges []types.SatRange, offset uint64) (uint64, bool) {
+	for _, r := range ranges {
+		if offset < r.Size() {
+			return r.Start + offset, true
+		}
+		offset -= r.Size()
+	}
+	return 0, false
+}
+
+// TxSatRanges 按 FIFO 规则把输入的 sat 分配到输出.
+// inputs[i] 为第 i 个输入所花费 UTXO 的区间列表, 需要与输入一一对应;
+// 返回每个输出的区间列表, 以及剩余作为手续费的区间.
+func TxSatRanges(tx *types.Tx, inputs [][]types.SatRange) ([][]types.SatRange, []types.SatRange, error) {
+	if len(inputs) != len(tx.TxIn) {
+		return nil, nil, fmt.Errorf("input ranges count mismatch: %d != %d", len(inputs), len(tx.TxIn))
+	}
+	var pool []types.SatRange
+	for _, rs := range inputs {
+		pool = append(pool, rs...)
+	}
+	return assignRanges(tx.TxOut, pool)
+}
+
+// CoinbaseSatRanges coinbase 交易的 sat 分配: 先是本区块新产生的 sat, 再依次是区块内各交易的手续费区间.
+// 未被领取的 sat 会永久丢失, 作为第二个返回值.
+func CoinbaseSatRanges(coinbase *types.Tx, height uint64, fees [][]types.SatRange) ([][]types.SatRange, []types.SatRange, error) {
+	var pool []types.SatRange
+	if subsidy := Subsidy(height); subsidy > 0 {
+		first := FirstSat(height)
+		pool = append(pool, types.SatRange{Start: first, End: first + subsidy})
+	}
+	for _, rs := range fees {
+		pool = append(pool, rs...)
+	}
+	return assignRanges(coinbase.TxOut, pool)
+}
+
+// 从区间池中依次切出每个输出需要的 sat
+func assignRanges(outs []types.TxOut, pool []types.SatRange) ([][]types.SatRange, []types.SatRange, error) {
+	var need uint64
+	for _, o := range outs {
+		if o.Value < 0 {
+			return nil, nil, errors.New("negative output value")
+		}
+		need += uint64(o.Value)
+	}
+	if need > RangesValue(pool) {
+		return nil, nil, fmt.Errorf("outputs spend %d sats, inputs only have %d", need, RangesValue(pool))
+	}
+
+	result := make([][]types.SatRange, len(outs))
+	for i, o := range outs {
+		remaining := uint64(o.Value)
+		for remaining > 0 {
+			r := pool[0]
+			if r.Size() <= remaining {
+				result[i] = append(result[i], r)
+				remaining -= r.Size()
+				pool = pool[1:]
+				continue
+			}
+			result[i] = append(result[i], types.SatRange{Start: r.Start, End: r.Start + remaining})
+			pool[0].Start += remaining
+			remaining = 0
+		}
+	}
+	return result, append([]types.SatRange(nil), pool...), nil
+}
@@ -0,0 +1,749 @@
+package runes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// 账本中没有对应输出的事件(send / mint / 非 OP_RETURN 销毁)的 Output 取值
+const NoOutput = -1
+
+// BlockSource 索引器的数据来源
+type BlockSource interface {
+	// 最新区块高度
+	GetBlockCount(ctx context.Context) (int, error)
+	// 指定高度的区块哈希
+	GetBlockHash(ctx context.Context, height int) (string, error)
+	// 完整区块
+	GetBlock(ctx context.Context, hash string) (*types.Block, error)
+	// 交易输出的锁定脚本与所在区块高度, 用于校验名称承诺
+	GetTxOut(ctx context.Context, txid string, vout uint32) ([]byte, int, error)
+}
+
+// Indexer Runes 逐块索引器
+type Indexer struct {
+	src         BlockSource
+	store       Store
+	network     types.Network
+	startHeight int
+}
+
+// NewIndexer 创建索引器, 默认从协议启用高度开始索引
+func NewIndexer(src BlockSource, store Store, network types.Network) *Indexer {
+	return &Indexer{
+		src:         src,
+		store:       store,
+		network:     network,
+		startHeight: FirstRuneHeight(network),
+	}
+}
+
+// SetStartHeight 修改起始高度(仅在尚未索引任何区块时有效), 早于协议启用高度的区块不会产生 rune
+func (ix *Indexer) SetStartHeight(height int) {
+	ix.startHeight = height
+}
+
+// Sync 索引到数据源的最新区块, 遇到分叉时回滚到共同祖先后继续. 返回最新已索引高度.
+func (ix *Indexer) Sync(ctx context.Context) (int, error) {
+	best, err := ix.src.GetBlockCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tip, tipHash, err := ix.store.Tip(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if tip >= 0 {
+		srcHash, err := ix.src.GetBlockHash(ctx, tip)
+		if err != nil {
+			return 0, err
+		}
+		if srcHash != tipHash {
+			if tip, err = ix.rollbackToCommonAncestor(ctx, tip); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	height := max(tip+1, ix.startHeight)
+	for height <= best {
+		if err := ctx.Err(); err != nil {
+			return height - 1, err
+		}
+		hash, err := ix.src.GetBlockHash(ctx, height)
+		if err != nil {
+			return height - 1, err
+		}
+		blk, err := ix.src.GetBlock(ctx, hash)
+		if err != nil {
+			return height - 1, err
+		}
+		blk.Height = height
+
+		if height > ix.startHeight {
+			prev, err := ix.store.BlockHash(ctx, height-1)
+			if err != nil {
+				return height - 1, err
+			}
+			if prev != "" && prev != blk.PrevHash {
+				tip, err := ix.rollbackToCommonAncestor(ctx, height-1)
+				if err != nil {
+					return height - 1, err
+				}
+				height = max(tip+1, ix.startHeight)
+				continue
+			}
+		}
+
+		if err := ix.IndexBlock(ctx, blk); err != nil {
+			return height - 1, fmt.Errorf("index block %d: %w", height, err)
+		}
+		height++
+	}
+	return height - 1, nil
+}
+
+// 从 from 向下寻找与数据源一致的区块并回滚其后的全部数据, 返回共同祖先高度
+func (ix *Indexer) rollbackToCommonAncestor(ctx context.Context, from int) (int, error) {
+	h := from
+	for ; h >= ix.startHeight; h-- {
+		stored, err := ix.store.BlockHash(ctx, h)
+		if err != nil {
+			return 0, err
+		}
+		srcHash, err := ix.src.GetBlockHash(ctx, h)
+		if err != nil {
+			return 0, err
+		}
+		if stored == srcHash {
+			break
+		}
+	}
+	logger.Warn("runes indexer: reorg detected, rollback to height %d", h)
+	if err := ix.store.Rollback(ctx, h+1); err != nil {
+		return 0, err
+	}
+	return h, nil
+}
+
+// IndexBlock 处理单个区块并写入存储, 调用方需要保证按高度顺序调用
+func (ix *Indexer) IndexBlock(ctx context.Context, blk *types.Block) error {
+	count, err := ix.store.RuneCount(ctx)
+	if err != nil {
+		return err
+	}
+	st := &blockState{
+		ix:        ix,
+		ctx:       ctx,
+		blk:       blk,
+		changes:   &BlockChanges{Height: blk.Height, Hash: blk.Hash},
+		runes:     make(map[string]*RuneState),
+		names:     make(map[string]string),
+		runeCount: count,
+		created:   make(map[string][]OutpointBalance),
+		supply:    make(map[string]*supplyDelta),
+		balances:  make(map[string]*balanceDelta),
+	}
+	if blk.Height >= FirstRuneHeight(ix.network) {
+		for i, tx := range blk.Txs {
+			if err := st.indexTx(uint32(i), tx); err != nil {
+				return fmt.Errorf("tx %s: %w", tx.TxID, err)
+			}
+		}
+	}
+	if err := st.finish(); err != nil {
+		return err
+	}
+	return ix.store.ApplyBlock(ctx, st.changes)
+}
+
+// 区块内的中间状态
+type blockState struct {
+	ix      *Indexer
+	ctx     context.Context
+	blk     *types.Block
+	changes *BlockChanges
+
+	runes     map[string]*RuneState // 本区块访问过的 rune(含新发行)
+	names     map[string]string     // 本区块新发行的名称
+	runeCount int64
+
+	created      map[string][]OutpointBalance // 本区块产生且尚未花费的输出
+	createdOrder []string
+
+	supply      map[string]*supplyDelta
+	supplyOrder []string
+	balances    map[string]*balanceDelta
+	balOrder    []string
+
+	txIndex    uint32
+	tx         *types.Tx
+	eventIndex int64
+}
+
+type supplyDelta struct {
+	minted *big.Int
+	burned *big.Int
+	ops    int64
+}
+
+type balanceDelta struct {
+	runeID  string
+	address string
+	delta   *big.Int
+	ops     int64
+}
+
+// 有序的 rune -> 数量表, 保证事件顺序稳定
+type lots struct {
+	keys   []string
+	values map[string]*big.Int
+}
+
+func newLots() *lots {
+	return &lots{values: make(map[string]*big.Int)}
+}
+
+func (l *lots) add(id string, amount *big.Int) {
+	v, ok := l.values[id]
+	if !ok {
+		v = new(big.Int)
+		l.values[id] = v
+		l.keys = append(l.keys, id)
+	}
+	v.Add(v, amount)
+}
+
+func (l *lots) get(id string) (*big.Int, bool) {
+	v, ok := l.values[id]
+	return v, ok
+}
+
+func (st *blockState) indexTx(txIndex uint32, tx *types.Tx) error {
+	st.txIndex = txIndex
+	st.tx = tx
+	st.eventIndex = 0
+
+	artifact := DecipherTx(tx)
+	unallocated, err := st.unallocated()
+	if err != nil {
+		return err
+	}
+	allocated := make([]*lots, len(tx.TxOut))
+	for i := range allocated {
+		allocated[i] = newLots()
+	}
+
+	var etchedID, etchedName string
+	if artifact != nil {
+		if artifact.Mint != nil {
+			id := artifact.Mint.String()
+			amount, ok, err := st.mint(id)
+			if err != nil {
+				return err
+			}
+			if ok {
+				unallocated.add(id, amount)
+			}
+		}
+
+		etchedID, etchedName, err = st.etched(artifact)
+		if err != nil {
+			return err
+		}
+
+		if !artifact.Cenotaph {
+			if etchedID != "" && artifact.Etching.Premine != nil {
+				unallocated.add(etchedID, artifact.Etching.Premine)
+				st.ledger(etchedID, NoOutput, "", "", artifact.Etching.Premine, OpEtching)
+				st.supplyOf(etchedID).minted.Add(st.supplyOf(etchedID).minted, artifact.Etching.Premine)
+			}
+			for _, edict := range artifact.Edicts {
+				st.applyEdict(edict, etchedID, unallocated, allocated)
+			}
+		}
+
+		if etchedID != "" {
+			st.createRune(artifact, etchedID, etchedName)
+		}
+	}
+
+	burned := newLots()
+	burnOutput := make(map[string]int)
+	if artifact != nil && artifact.Cenotaph {
+		for _, id := range unallocated.keys {
+			burned.add(id, unallocated.values[id])
+		}
+	} else {
+		vout := -1
+		if artifact != nil && artifact.Pointer != nil {
+			vout = int(*artifact.Pointer)
+		} else {
+			for i := range tx.TxOut {
+				if !isOpReturn(tx.TxOut[i].PkScript) {
+					vout = i
+					break
+				}
+			}
+		}
+		for _, id := range unallocated.keys {
+			balance := unallocated.values[id]
+			if balance.Sign() <= 0 {
+				continue
+			}
+			if vout >= 0 {
+				allocated[vout].add(id, balance)
+			} else {
+				burned.add(id, balance)
+			}
+		}
+	}
+
+	txid := tx.TxID
+	for vout, balances := range allocated {
+		if len(balances.keys) == 0 {
+			continue
+		}
+		if isOpReturn(tx.TxOut[vout].PkScript) {
+			for _, id := range balances.keys {
+				burned.add(id, balances.values[id])
+				burnOutput[id] = vout
+			}
+			continue
+		}
+		address := outputAddress(tx.TxOut[vout].PkScript)
+		outpoint := fmt.Sprintf("%s:%d", txid, vout)
+		for _, id := range balances.keys {
+			amount := balances.values[id]
+			if amount.Sign() <= 0 {
+				continue
+			}
+			if _, ok := st.created[outpoint]; !ok {
+				st.createdOrder = append(st.createdOrder, outpoint)
+			}
+			st.created[outpoint] = append(st.created[outpoint], OutpointBalance{
+				Outpoint:    outpoint,
+				RuneID:      id,
+				Amount:      decimal.NewFromBigInt(amount, 0),
+				Address:     address,
+				BlockHeight: st.blk.Height,
+			})
+			st.ledger(id, vout, address, address, amount, OpReceive)
+			st.balanceOf(id, address, amount)
+		}
+	}
+
+	for _, id := range burned.keys {
+		amount := burned.values[id]
+		if amount.Sign() <= 0 {
+			continue
+		}
+		out, ok := burnOutput[id]
+		if !ok {
+			out = NoOutput
+		}
+		st.ledger(id, out, "", "", amount, OpBurn)
+		s := st.supplyOf(id)
+		s.burned.Add(s.burned, amount)
+	}
+	return nil
+}
+
+// 收集输入中的 rune 余额, 同时记录 send 事件
+func (st *blockState) unallocated() (*lots, error) {
+	ret := newLots()
+	for _, in := range st.tx.TxIn {
+		if in.PreviousOutPoint.Index == math.MaxUint32 && in.PreviousOutPoint.Hash == (types.Hash32{}) {
+			continue // coinbase
+		}
+		outpoint := outpointString(in.PreviousOutPoint)
+		balances, ok := st.created[outpoint]
+		if ok {
+			delete(st.created, outpoint)
+		} else {
+			var err error
+			balances, err = st.ix.store.GetOutpointBalances(st.ctx, outpoint)
+			if err != nil {
+				return nil, err
+			}
+			if len(balances) > 0 {
+				st.changes.Spent = append(st.changes.Spent, outpoint)
+			}
+		}
+		for _, b := range balances {
+			amount := b.Amount.BigInt()
+			ret.add(b.RuneID, amount)
+			st.ledger(b.RuneID, NoOutput, b.Address, "", amount, OpSend)
+			st.balanceOf(b.RuneID, b.Address, new(big.Int).Neg(amount))
+		}
+	}
+	return ret, nil
+}
+
+func (st *blockState) applyEdict(edict types.RuneEdict, etchedID string, unallocated *lots, allocated []*lots) {
+	id := edict.ID.String()
+	if edict.ID == (types.RuneID{}) {
+		if etchedID == "" {
+			return
+		}
+		id = etchedID
+	}
+	balance, ok := unallocated.get(id)
+	if !ok {
+		return
+	}
+	allocate := func(amount *big.Int, output int) {
+		if amount.Sign() > 0 {
+			balance.Sub(balance, amount)
+			allocated[output].add(id, amount)
+		}
+	}
+
+	outputs := len(st.tx.TxOut)
+	if int(edict.Output) == outputs {
+		var destinations []int
+		for i := range st.tx.TxOut {
+			if !isOpReturn(st.tx.TxOut[i].PkScript) {
+				destinations = append(destinations, i)
+			}
+		}
+		if len(destinations) == 0 {
+			return
+		}
+		if edict.Amount.Sign() == 0 {
+			n := big.NewInt(int64(len(destinations)))
+			each, remainder := new(big.Int).QuoRem(balance, n, new(big.Int))
+			r := int(remainder.Int64())
+			for i, output := range destinations {
+				amount := new(big.Int).Set(each)
+				if i < r {
+					amount.Add(amount, big.NewInt(1))
+				}
+				allocate(amount, output)
+			}
+		} else {
+			for _, output := range destinations {
+				allocate(minBig(edict.Amount, balance), output)
+			}
+		}
+		return
+	}
+
+	amount := new(big.Int).Set(balance)
+	if edict.Amount.Sign() != 0 {
+		amount = minBig(edict.Amount, balance)
+	}
+	allocate(amount, int(edict.Output))
+}
+
+// 铸造: 满足条款时返回本次铸造数量
+func (st *blockState) mint(id string) (*big.Int, bool, error) {
+	r, err := st.getRune(id)
+	if err != nil || r == nil {
+		return nil, false, err
+	}
+	amount, ok := mintable(r, uint64(st.blk.Height))
+	if !ok {
+		return nil, false, nil
+	}
+	r.Mints++
+	st.ledger(id, NoOutput, "", "", amount, OpMint)
+	s := st.supplyOf(id)
+	s.minted.Add(s.minted, amount)
+	return amount, true, nil
+}
+
+func mintable(r *RuneState, height uint64) (*big.Int, bool) {
+	amount, err := CheckMint(r.Terms, uint64(r.Rune.BlockHeight), r.Mints, height)
+	return amount, err == nil
+}
+
+// 判断本交易发行的 rune, 返回 ID 与名称; 不发行时返回空字符串
+func (st *blockState) etched(artifact *types.Runestone) (string, string, error) {
+	if artifact.Etching == nil {
+		return "", "", nil
+	}
+	id := types.RuneID{Block: uint64(st.blk.Height), Tx: st.txIndex}.String()
+
+	if artifact.Etching.Rune == nil {
+		if artifact.Cenotaph {
+			return "", "", nil
+		}
+		return id, ReservedName(uint64(st.blk.Height), st.txIndex), nil
+	}
+
+	name := *artifact.Etching.Rune
+	value, err := NameToValue(name)
+	if err != nil {
+		return "", "", nil
+	}
+	if value.Cmp(MinimumAtHeight(st.ix.network, st.blk.Height)) < 0 || IsReserved(value) {
+		return "", "", nil
+	}
+	if _, ok := st.names[name]; ok {
+		return "", "", nil
+	}
+	existing, err := st.ix.store.GetRuneIDByName(st.ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+	if existing != "" {
+		return "", "", nil
+	}
+	ok, err := st.commitsToRune(value)
+	if err != nil || !ok {
+		return "", "", err
+	}
+	return id, name, nil
+}
+
+// 名称承诺: 某个输入花费的是至少 6 个确认的 P2TR 输出, 且其 tapscript 中 push 了名称承诺
+func (st *blockState) commitsToRune(value *big.Int) (bool, error) {
+	commitment := Commitment(value)
+	for _, i := range commitmentInputs(st.tx, commitment) {
+		prev := st.tx.TxIn[i].PreviousOutPoint
+		pkScript, height, err := st.ix.src.GetTxOut(st.ctx, chainhash.Hash(prev.Hash).String(), prev.Index)
+		if err != nil {
+			return false, err
+		}
+		if !txscript.IsPayToTaproot(pkScript) || height < 0 {
+			continue
+		}
+		if st.blk.Height-height+1 >= CommitConfirmations {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (st *blockState) createRune(artifact *types.Runestone, id, name string) {
+	r := types.Runes{
+		ID:          id,
+		Number:      st.runeCount,
+		Name:        name,
+		SpacedName:  name,
+		BlockHash:   st.blk.Hash,
+		BlockHeight: st.blk.Height,
+		TxIndex:     int64(st.txIndex),
+		TxID:        st.tx.TxID,
+		Premine:     decimal.Zero,
+		TermsAmount: decimal.Zero,
+		TermsCap:    decimal.Zero,
+		Cenotaph:    artifact.Cenotaph,
+		Timestamp:   st.blk.Time,
+	}
+	var terms *types.RuneTerms
+	if e := artifact.Etching; !artifact.Cenotaph {
+		if e.Divisibility != nil {
+			r.Divisibility = int16(*e.Divisibility)
+		}
+		if e.Premine != nil {
+			r.Premine = decimal.NewFromBigInt(e.Premine, 0)
+		}
+		if e.Spacers != nil {
+			r.SpacedName = SpacedName(name, *e.Spacers)
+		}
+		if e.Symbol != nil {
+			r.Symbol = *e.Symbol
+		}
+		if t := e.Terms; t != nil {
+			terms = t
+			if t.Amount != nil {
+				r.TermsAmount = decimal.NewFromBigInt(t.Amount, 0)
+			}
+			if t.Cap != nil {
+				r.TermsCap = decimal.NewFromBigInt(t.Cap, 0)
+			}
+			r.TermsHeightStart = clampInt(t.HeightStart)
+			r.TermsHeightEnd = clampInt(t.HeightEnd)
+			r.TermsOffsetStart = clampInt(t.OffsetStart)
+			r.TermsOffsetEnd = clampInt(t.OffsetEnd)
+		}
+		r.Turbo = e.Turbo
+	}
+
+	entry := RuneEntry{Rune: r, Terms: terms}
+	st.changes.Runes = append(st.changes.Runes, entry)
+	st.runes[id] = &RuneState{RuneEntry: entry, TotalMints: decimal.Zero, TotalBurns: decimal.Zero}
+	st.names[name] = id
+	st.runeCount++
+	st.supplyOf(id)
+}
+
+func (st *blockState) getRune(id string) (*RuneState, error) {
+	if r, ok := st.runes[id]; ok {
+		return r, nil
+	}
+	r, err := st.ix.store.GetRune(st.ctx, id)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	st.runes[id] = r
+	return r, nil
+}
+
+func (st *blockState) ledger(runeID string, output int, address, receiver string, amount *big.Int, op string) {
+	st.changes.Ledger = append(st.changes.Ledger, types.RunesLedger{
+		RuneID:          runeID,
+		BlockHash:       st.blk.Hash,
+		BlockHeight:     st.blk.Height,
+		TxIndex:         int64(st.txIndex),
+		EventIndex:      st.eventIndex,
+		TxID:            st.tx.TxID,
+		Output:          output,
+		Address:         address,
+		ReceiverAddress: receiver,
+		Amount:          decimal.NewFromBigInt(amount, 0),
+		Operation:       op,
+		Timestamp:       st.blk.Time,
+	})
+	st.eventIndex++
+	st.supplyOf(runeID).ops++
+}
+
+func (st *blockState) supplyOf(id string) *supplyDelta {
+	s, ok := st.supply[id]
+	if !ok {
+		s = &supplyDelta{minted: new(big.Int), burned: new(big.Int)}
+		st.supply[id] = s
+		st.supplyOrder = append(st.supplyOrder, id)
+	}
+	return s
+}
+
+// 地址余额变化, 没有地址的输出不统计
+func (st *blockState) balanceOf(id, address string, delta *big.Int) {
+	if address == "" {
+		return
+	}
+	key := id + "|" + address
+	b, ok := st.balances[key]
+	if !ok {
+		b = &balanceDelta{runeID: id, address: address, delta: new(big.Int)}
+		st.balances[key] = b
+		st.balOrder = append(st.balOrder, key)
+	}
+	b.delta.Add(b.delta, delta)
+	b.ops++
+}
+
+// 汇总区块级别的行
+func (st *blockState) finish() error {
+	for _, op := range st.createdOrder {
+		st.changes.Created = append(st.changes.Created, st.created[op]...)
+	}
+
+	for _, id := range st.supplyOrder {
+		d := st.supply[id]
+		r, err := st.getRune(id)
+		if err != nil {
+			return err
+		}
+		row := types.RunesSupplyChanges{
+			RuneID:          id,
+			BlockHeight:     st.blk.Height,
+			Minted:          decimal.NewFromBigInt(d.minted, 0),
+			Burned:          decimal.NewFromBigInt(d.burned, 0),
+			TotalMints:      decimal.NewFromBigInt(d.minted, 0),
+			TotalBurns:      decimal.NewFromBigInt(d.burned, 0),
+			TotalOperations: d.ops,
+		}
+		if r != nil {
+			row.TotalMints = r.TotalMints.Add(row.Minted)
+			row.TotalBurns = r.TotalBurns.Add(row.Burned)
+			row.TotalOperations += r.TotalOperations
+		}
+		st.changes.SupplyChanges = append(st.changes.SupplyChanges, row)
+	}
+
+	for _, key := range st.balOrder {
+		b := st.balances[key]
+		prev, ops, err := st.ix.store.GetAddressBalance(st.ctx, b.runeID, b.address)
+		if err != nil {
+			return err
+		}
+		st.changes.BalanceChanges = append(st.changes.BalanceChanges, types.RunesBalanceChanges{
+			RuneID:          b.runeID,
+			BlockHeight:     st.blk.Height,
+			Address:         b.address,
+			Balance:         prev.Add(decimal.NewFromBigInt(b.delta, 0)),
+			TotalOperations: ops + b.ops,
+		})
+	}
+	return nil
+}
+
+// types.Hash32.String 不翻转字节序, 这里按 txid 的显示顺序格式化
+func outpointString(op types.TxOutPoint) string {
+	return fmt.Sprintf("%s:%d", chainhash.Hash(op.Hash).String(), op.Index)
+}
+
+func isOpReturn(pkScript []byte) bool {
+	return len(pkScript) > 0 && pkScript[0] == txscript.OP_RETURN
+}
+
+func outputAddress(pkScript []byte) string {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, types.CurrentNetworkParams)
+	if err != nil || len(addrs) != 1 {
+		return ""
+	}
+	return addrs[0].EncodeAddress()
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
+
+// 相对高度: block + offset, 溢出时取最大值
+func relative(block uint64, offset *uint64) *uint64 {
+	if offset == nil {
+		return nil
+	}
+	v := block + *offset
+	if v < block {
+		v = math.MaxUint64
+	}
+	return &v
+}
+
+func boundMax(a, b *uint64) *uint64 {
+	if a == nil {
+		return b
+	}
+	if b == nil || *a > *b {
+		return a
+	}
+	return b
+}
+
+func boundMin(a, b *uint64) *uint64 {
+	if a == nil {
+		return b
+	}
+	if b == nil || *a < *b {
+		return a
+	}
+	return b
+}
+
+func clampInt(v *uint64) int {
+	if v == nil {
+		return 0
+	}
+	if *v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(*v)
+}
@@ -0,0 +1,130 @@
+package runes
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// 内存中的区块来源, 替换 blocks 模拟重组
+type memBlockSource struct {
+	blocks []*types.Block
+}
+
+func (m *memBlockSource) GetBlockCount(ctx context.Context) (int, error) {
+	return len(m.blocks) - 1, nil
+}
+
+func (m *memBlockSource) GetBlockHash(ctx context.Context, height int) (string, error) {
+	return m.blocks[height].Hash, nil
+}
+
+func (m *memBlockSource) GetBlock(ctx context.Context, hash string) (*types.Block, error) {
+	for _, b := range m.blocks {
+		if b.Hash == hash {
+			return b, nil
+		}
+	}
+	return nil, types.ErrNotFound
+}
+
+// 测试中只发行保留名称, 不需要名称承诺
+func (m *memBlockSource) GetTxOut(ctx context.Context, txid string, vout uint32) ([]byte, int, error) {
+	return nil, -1, types.ErrNotFound
+}
+
+func (m *memBlockSource) block(name string, txs ...*types.Tx) {
+	prev := ""
+	if n := len(m.blocks); n > 0 {
+		prev = m.blocks[n-1].Hash
+	}
+	m.blocks = append(m.blocks, &types.Block{Hash: chainhash.HashH([]byte(name)).String(), PrevHash: prev, Txs: txs})
+}
+
+// 交易 name 花费 ins(txid 名称:vout), 输出 0 为 runestone
+func runeTx(t *testing.T, name string, rs *types.Runestone, ins []string, outs ...[]byte) *types.Tx {
+	t.Helper()
+	script, err := Encipher(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &types.Tx{TxID: chainhash.HashH([]byte(name)).String(), TxOut: []types.TxOut{{PkScript: script}}}
+	for _, in := range ins {
+		tx.TxIn = append(tx.TxIn, types.TxIn{PreviousOutPoint: types.TxOutPoint{Hash: types.Hash32(chainhash.HashH([]byte(in))), Index: 1}})
+	}
+	for _, pk := range outs {
+		tx.TxOut = append(tx.TxOut, types.TxOut{Value: 546, PkScript: pk})
+	}
+	return tx
+}
+
+// 发行 -> 铸造 -> 转账 -> cenotaph 销毁 -> 重组回滚
+func TestIndexer(t *testing.T) {
+	ctx := context.Background()
+	alice := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, bytes.Repeat([]byte{1}, 20)...)
+	bob := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, bytes.Repeat([]byte{2}, 20)...)
+	aliceAddr, bobAddr := outputAddress(alice), outputAddress(bob)
+	id := types.RuneID{Block: 1, Tx: 0}
+
+	src := &memBlockSource{}
+	src.block("0")
+	// premine 1000 归 alice, 每次铸造 100, 最多 2 次
+	src.block("1", runeTx(t, "etch", &types.Runestone{Etching: &types.RuneEtching{
+		Premine: big.NewInt(1000),
+		Terms:   &types.RuneTerms{Amount: big.NewInt(100), Cap: big.NewInt(2)},
+	}}, nil, alice))
+	// bob 铸造 100; alice 转给 bob 300, 余下 700 默认回到第一个非 OP_RETURN 输出
+	src.block("2",
+		runeTx(t, "mint", &types.Runestone{Mint: &id}, nil, bob),
+		runeTx(t, "transfer", &types.Runestone{Edicts: []types.RuneEdict{{ID: id, Amount: big.NewInt(300), Output: 2}}},
+			[]string{"etch"}, alice, bob))
+	// cenotaph: 花费的 100 与本次铸造的 100 都被销毁, 铸造次数仍然计入
+	cenotaph := runeTx(t, "cenotaph", &types.Runestone{}, []string{"mint"}, bob)
+	cenotaph.TxOut[0].PkScript = runestoneScript(ints(tagMint, 1, tagMint, 0, tagCenotaph, 0)...)
+	src.block("3", cenotaph)
+
+	store := NewMemoryStore()
+	ix := NewIndexer(src, store, types.Regtest)
+	check := func(stage string, mints int64, minted, burned int64, balances map[string]int64) {
+		t.Helper()
+		r, err := store.GetRune(ctx, id.String())
+		if err != nil || r == nil {
+			t.Fatalf("%s: rune = %v, %v", stage, r, err)
+		}
+		if r.Mints != mints || !r.TotalMints.Equal(decimal.NewFromInt(minted)) || !r.TotalBurns.Equal(decimal.NewFromInt(burned)) {
+			t.Errorf("%s: mints=%d minted=%s burned=%s, want %d/%d/%d", stage, r.Mints, r.TotalMints, r.TotalBurns, mints, minted, burned)
+		}
+		for addr, want := range balances {
+			got, _, err := store.GetAddressBalance(ctx, id.String(), addr)
+			if err != nil || !got.Equal(decimal.NewFromInt(want)) {
+				t.Errorf("%s: balance %s = %s, %v, want %d", stage, addr, got, err, want)
+			}
+		}
+	}
+
+	if tip, err := ix.Sync(ctx); err != nil || tip != 3 {
+		t.Fatalf("sync = %d, %v", tip, err)
+	}
+	check("synced", 2, 1200, 200, map[string]int64{aliceAddr: 700, bobAddr: 300})
+	if b, _ := store.GetOutpointBalances(ctx, src.blocks[2].Txs[0].TxID+":1"); len(b) != 0 {
+		t.Errorf("spent mint output = %+v", b)
+	}
+
+	// 重组: 区块 3 被替换, cenotaph 不在新链上
+	src.blocks = src.blocks[:3]
+	src.block("3b")
+	src.block("4b")
+	if tip, err := ix.Sync(ctx); err != nil || tip != 4 {
+		t.Fatalf("sync after reorg = %d, %v", tip, err)
+	}
+	check("reorg", 1, 1100, 0, map[string]int64{aliceAddr: 700, bobAddr: 400})
+	if b, _ := store.GetOutpointBalances(ctx, src.blocks[2].Txs[0].TxID+":1"); len(b) != 1 || !b[0].Amount.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("mint output after reorg = %+v", b)
+	}
+}
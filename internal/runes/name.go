@@ -0,0 +1,94 @@
+package runes
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// 分隔符显示字符
+const spacerChar = "•"
+
+var big26 = big.NewInt(26)
+
+// NameToValue 把 rune 名称(A-Z)转换为 u128: A=0, Z=25, AA=26 ...
+func NameToValue(name string) (*big.Int, error) {
+	if name == "" {
+		return nil, errors.New("empty rune name")
+	}
+	n := new(big.Int)
+	for i, c := range name {
+		if c < 'A' || c > 'Z' {
+			return nil, fmt.Errorf("invalid rune name character: %q", c)
+		}
+		if i > 0 {
+			n.Add(n, big.NewInt(1))
+		}
+		n.Mul(n, big26)
+		n.Add(n, big.NewInt(int64(c-'A')))
+		if n.Cmp(MaxU128) > 0 {
+			return nil, fmt.Errorf("rune name out of range: %s", name)
+		}
+	}
+	return n, nil
+}
+
+// ValueToName 把 u128 转换为 rune 名称
+func ValueToName(v *big.Int) string {
+	n := new(big.Int).Add(v, big.NewInt(1))
+	var out []byte
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.Sub(n, big.NewInt(1))
+		n.QuoRem(n, big26, mod)
+		out = append(out, byte('A'+mod.Int64()))
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// SpacedName 按 spacers 位图插入分隔符, 超出名称长度的位被忽略
+func SpacedName(name string, spacers uint32) string {
+	var sb strings.Builder
+	for i, c := range name {
+		sb.WriteRune(c)
+		if i < len(name)-1 && spacers&(1<<uint(i)) != 0 {
+			sb.WriteString(spacerChar)
+		}
+	}
+	return sb.String()
+}
+
+// ParseSpacedName 解析带分隔符的名称, 分隔符可以是 • 或 .
+func ParseSpacedName(s string) (string, uint32, error) {
+	var name strings.Builder
+	var spacers uint32
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			name.WriteRune(c)
+		case c == '•' || c == '.':
+			if name.Len() == 0 {
+				return "", 0, errors.New("leading spacer")
+			}
+			flag := uint32(1) << uint(name.Len()-1)
+			if spacers&flag != 0 {
+				return "", 0, errors.New("double spacer")
+			}
+			spacers |= flag
+		default:
+			return "", 0, fmt.Errorf("invalid spaced rune character: %q", c)
+		}
+	}
+	if name.Len() == 0 {
+		return "", 0, errors.New("empty rune name")
+	}
+	if 32-bits.LeadingZeros32(spacers) >= name.Len() {
+		return "", 0, errors.New("trailing spacer")
+	}
+	return name.String(), spacers, nil
+}
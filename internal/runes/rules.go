@@ -0,0 +1,153 @@
+package runes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 名称承诺交易需要的确认数
+const CommitConfirmations = 6
+
+// 减半周期, 名称最短长度每 1/12 个周期解锁一位
+const subsidyHalvingInterval = 210000
+
+// steps[i]: 长度为 i+1 的最小名称值, 即 A / AA / AAA ...
+var steps [28]*big.Int
+
+// reservedRune 保留名称起点(27 个 A), 未指定名称的发行从这里按 block/tx 分配
+var reservedRune *big.Int
+
+func init() {
+	steps[0] = new(big.Int)
+	for i := 1; i < len(steps); i++ {
+		steps[i] = new(big.Int).Add(steps[i-1], big.NewInt(1))
+		steps[i].Mul(steps[i], big26)
+	}
+	reservedRune = steps[26]
+}
+
+// FirstRuneHeight Runes 协议启用高度
+func FirstRuneHeight(net types.Network) int {
+	switch net {
+	case types.Mainnet:
+		return subsidyHalvingInterval * 4
+	case types.Testnet:
+		return subsidyHalvingInterval * 12
+	default:
+		return 0
+	}
+}
+
+// MinimumAtHeight 指定高度允许发行的最小名称值(名称值越小越短)
+func MinimumAtHeight(net types.Network, height int) *big.Int {
+	offset := height + 1
+	interval := subsidyHalvingInterval / 12
+	start := FirstRuneHeight(net)
+	end := start + subsidyHalvingInterval
+	if offset < start {
+		return new(big.Int).Set(steps[12])
+	}
+	if offset >= end {
+		return new(big.Int)
+	}
+	progress := offset - start
+	length := 12 - progress/interval
+	lo, hi := steps[length-1], steps[length]
+	remainder := big.NewInt(int64(progress % interval))
+	// hi - (hi - lo) * remainder / interval
+	delta := new(big.Int).Sub(hi, lo)
+	delta.Mul(delta, remainder)
+	delta.Quo(delta, big.NewInt(int64(interval)))
+	return new(big.Int).Sub(hi, delta)
+}
+
+// IsReserved 名称是否处于保留区间
+func IsReserved(v *big.Int) bool {
+	return v.Cmp(reservedRune) >= 0
+}
+
+// ReservedName 未指定名称的发行分配的保留名称
+func ReservedName(block uint64, tx uint32) string {
+	v := new(big.Int).SetUint64(block)
+	v.Lsh(v, 32)
+	v.Or(v, new(big.Int).SetUint64(uint64(tx)))
+	v.Add(v, reservedRune)
+	return ValueToName(v)
+}
+
+// Commitment 名称承诺: 名称值的小端字节, 去掉末尾的 0
+func Commitment(v *big.Int) []byte {
+	be := v.Bytes()
+	out := make([]byte, len(be))
+	for i := range be {
+		out[i] = be[len(be)-1-i]
+	}
+	return out
+}
+
+// 查找 witness 中包含名称承诺的 tapscript, 返回命中的输入索引(不校验被花费的输出类型与确认数)
+func commitmentInputs(tx *types.Tx, commitment []byte) []int {
+	var out []int
+	for i, in := range tx.TxIn {
+		script, ok := leafScriptFromWitness(in.Witness)
+		if !ok {
+			continue
+		}
+		tok := txscript.MakeScriptTokenizer(0, script)
+		for tok.Next() {
+			if tok.Opcode() > txscript.OP_PUSHDATA4 {
+				continue
+			}
+			if bytes.Equal(tok.Data(), commitment) {
+				out = append(out, i)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// 与 ord 一致: 去掉 annex 后倒数第二个元素即为脚本, 不校验 leaf version
+func leafScriptFromWitness(w [][]byte) ([]byte, bool) {
+	n := len(w)
+	switch {
+	case n >= 3 && len(w[n-1]) > 0 && w[n-1][0] == txscript.TaprootAnnexTag:
+		return w[n-3], true
+	case n >= 2:
+		return w[n-2], true
+	default:
+		return nil, false
+	}
+}
+
+// CheckMint 检查在 height 高度铸造是否满足条款, 返回单次铸造数量.
+// etchBlock 为发行高度, mints 为此前已铸造次数.
+func CheckMint(t *types.RuneTerms, etchBlock uint64, mints int64, height uint64) (*big.Int, error) {
+	if t == nil {
+		return nil, errors.New("rune has no mint terms")
+	}
+	start := boundMax(relative(etchBlock, t.OffsetStart), t.HeightStart)
+	end := boundMin(relative(etchBlock, t.OffsetEnd), t.HeightEnd)
+	if start != nil && height < *start {
+		return nil, fmt.Errorf("mint not started: height %d < %d", height, *start)
+	}
+	if end != nil && height >= *end {
+		return nil, fmt.Errorf("mint ended: height %d >= %d", height, *end)
+	}
+	limit := new(big.Int)
+	if t.Cap != nil {
+		limit = t.Cap
+	}
+	if big.NewInt(mints).Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("mint cap reached: %d >= %s", mints, limit)
+	}
+	if t.Amount == nil {
+		return new(big.Int), nil
+	}
+	return new(big.Int).Set(t.Amount), nil
+}
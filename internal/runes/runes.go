@@ -1 +1,502 @@
+// Package runes 提供 Runes 协议 runestone 的编码与解码
 package runes
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// runestone 输出: OP_RETURN OP_13 <data push>...
+const magicNumber = txscript.OP_13
+
+// 字段 tag, 偶数 tag 未识别时视为 cenotaph, 奇数 tag 可忽略
+const (
+	tagBody         = 0
+	tagFlags        = 2
+	tagRune         = 4
+	tagPremine      = 6
+	tagCap          = 8
+	tagAmount       = 10
+	tagHeightStart  = 12
+	tagHeightEnd    = 14
+	tagOffsetStart  = 16
+	tagOffsetEnd    = 18
+	tagMint         = 20
+	tagPointer      = 22
+	tagCenotaph     = 126
+	tagDivisibility = 1
+	tagSpacers      = 3
+	tagSymbol       = 5
+	tagNop          = 127
+)
+
+// flags 位
+const (
+	flagEtching  = 0
+	flagTerms    = 1
+	flagTurbo    = 2
+	flagCenotaph = 127
+)
+
+const (
+	MaxDivisibility = 38
+	MaxSpacers      = 0b00000111_11111111_11111111_11111111
+)
+
+// DecipherTx 解析交易中的 runestone, 没有 runestone 输出时返回 nil
+func DecipherTx(tx *types.Tx) *types.Runestone {
+	scripts := make([][]byte, len(tx.TxOut))
+	for i := range tx.TxOut {
+		scripts[i] = tx.TxOut[i].PkScript
+	}
+	return Decipher(scripts)
+}
+
+// Decipher 按交易输出脚本解析 runestone, 只解析第一个 OP_RETURN OP_13 输出.
+// 输出数量参与 edict/pointer 的合法性判断, 所以需要传入全部输出.
+func Decipher(pkScripts [][]byte) *types.Runestone {
+	payload, flaw, found := extractPayload(pkScripts)
+	if !found {
+		return nil
+	}
+	if flaw != "" {
+		return &types.Runestone{Cenotaph: true, Flaw: flaw}
+	}
+
+	ret := &types.Runestone{BodyHex: hex.EncodeToString(payload)}
+
+	var integers []*big.Int
+	for i := 0; i < len(payload); {
+		n, size, err := DecodeVarint(payload[i:])
+		if err != nil {
+			ret.Cenotaph = true
+			ret.Flaw = types.RuneFlawVarint
+			return ret
+		}
+		integers = append(integers, n)
+		i += size
+	}
+
+	msg := messageFromIntegers(len(pkScripts), integers)
+	setFlaw := func(f string) {
+		if msg.flaw == "" {
+			msg.flaw = f
+		}
+	}
+
+	flags := new(big.Int)
+	if v, ok := msg.take(tagFlags); ok {
+		flags = v
+	}
+	takeFlag := func(bit uint) bool {
+		set := flags.Bit(int(bit)) == 1
+		flags.SetBit(flags, int(bit), 0)
+		return set
+	}
+
+	var etching *types.RuneEtching
+	if takeFlag(flagEtching) {
+		etching = &types.RuneEtching{}
+		if v, ok := msg.takeIf(tagDivisibility, func(v *big.Int) bool {
+			return v.IsUint64() && v.Uint64() <= MaxDivisibility
+		}); ok {
+			d := uint8(v.Uint64())
+			etching.Divisibility = &d
+		}
+		if v, ok := msg.take(tagPremine); ok {
+			etching.Premine = v
+		}
+		if v, ok := msg.take(tagRune); ok {
+			name := ValueToName(v)
+			etching.Rune = &name
+		}
+		if v, ok := msg.takeIf(tagSpacers, func(v *big.Int) bool {
+			return v.IsUint64() && v.Uint64() <= MaxSpacers
+		}); ok {
+			s := uint32(v.Uint64())
+			etching.Spacers = &s
+		}
+		if v, ok := msg.takeIf(tagSymbol, func(v *big.Int) bool {
+			return v.IsUint64() && v.Uint64() <= utf8.MaxRune && utf8.ValidRune(rune(v.Uint64()))
+		}); ok {
+			s := string(rune(v.Uint64()))
+			etching.Symbol = &s
+		}
+		if takeFlag(flagTerms) {
+			terms := &types.RuneTerms{}
+			if v, ok := msg.take(tagCap); ok {
+				terms.Cap = v
+			}
+			terms.HeightStart = msg.takeU64(tagHeightStart)
+			terms.HeightEnd = msg.takeU64(tagHeightEnd)
+			if v, ok := msg.take(tagAmount); ok {
+				terms.Amount = v
+			}
+			terms.OffsetStart = msg.takeU64(tagOffsetStart)
+			terms.OffsetEnd = msg.takeU64(tagOffsetEnd)
+			etching.Terms = terms
+		}
+		etching.Turbo = takeFlag(flagTurbo)
+		if etching.Rune != nil {
+			var spacers uint32
+			if etching.Spacers != nil {
+				spacers = *etching.Spacers
+			}
+			etching.SpacedRune = SpacedName(*etching.Rune, spacers)
+		}
+	}
+
+	if values, ok := msg.takeN(tagMint, 2, func(v []*big.Int) bool {
+		return v[0].IsUint64() && v[1].IsUint64() && v[1].Uint64() <= 0xffffffff &&
+			!(v[0].Sign() == 0 && v[1].Sign() > 0)
+	}); ok {
+		ret.Mint = &types.RuneID{Block: values[0].Uint64(), Tx: uint32(values[1].Uint64())}
+	}
+	if v, ok := msg.takeIf(tagPointer, func(v *big.Int) bool {
+		return v.IsUint64() && v.Uint64() < uint64(len(pkScripts))
+	}); ok {
+		p := uint32(v.Uint64())
+		ret.Pointer = &p
+	}
+
+	if etching != nil && Supply(etching) == nil {
+		setFlaw(types.RuneFlawSupplyOverflow)
+	}
+	if flags.Sign() != 0 {
+		setFlaw(types.RuneFlawUnrecognizedFlag)
+	}
+	for tag := range msg.fields {
+		if tag%2 == 0 {
+			setFlaw(types.RuneFlawUnrecognizedEvenTag)
+			break
+		}
+	}
+
+	if msg.flaw != "" {
+		// cenotaph 只保留铸造目标与被占用的名称
+		ret.Cenotaph = true
+		ret.Flaw = msg.flaw
+		if etching != nil && etching.Rune != nil {
+			ret.Etching = &types.RuneEtching{Rune: etching.Rune, SpacedRune: *etching.Rune}
+		}
+		return ret
+	}
+
+	ret.Edicts = msg.edicts
+	ret.Etching = etching
+	return ret
+}
+
+// Supply 计算发行总量 premine + cap * amount, 超过 u128 时返回 nil
+func Supply(e *types.RuneEtching) *big.Int {
+	supply := new(big.Int)
+	if e.Premine != nil {
+		supply.Set(e.Premine)
+	}
+	if e.Terms != nil && e.Terms.Cap != nil && e.Terms.Amount != nil {
+		supply.Add(supply, new(big.Int).Mul(e.Terms.Cap, e.Terms.Amount))
+	}
+	if supply.Cmp(MaxU128) > 0 {
+		return nil
+	}
+	return supply
+}
+
+// 找到第一个 runestone 输出并拼接其中的数据 push
+func extractPayload(pkScripts [][]byte) ([]byte, string, bool) {
+	for _, script := range pkScripts {
+		if len(script) < 2 || script[0] != txscript.OP_RETURN || script[1] != magicNumber {
+			continue
+		}
+		var payload []byte
+		tok := txscript.MakeScriptTokenizer(0, script[2:])
+		for tok.Next() {
+			if tok.Opcode() > txscript.OP_PUSHDATA4 {
+				return nil, types.RuneFlawOpcode, true
+			}
+			payload = append(payload, tok.Data()...)
+		}
+		if tok.Err() != nil {
+			return nil, types.RuneFlawInvalidScript, true
+		}
+		return payload, "", true
+	}
+	return nil, "", false
+}
+
+// 整数序列拆分为字段与 edict
+type message struct {
+	flaw   string
+	edicts []types.RuneEdict
+	fields map[uint64][]*big.Int
+}
+
+func messageFromIntegers(outputs int, integers []*big.Int) *message {
+	m := &message{fields: make(map[uint64][]*big.Int)}
+	setFlaw := func(f string) {
+		if m.flaw == "" {
+			m.flaw = f
+		}
+	}
+
+	for i := 0; i < len(integers); i += 2 {
+		tag := integers[i]
+		if tag.Sign() == 0 {
+			var id types.RuneID
+			rest := integers[i+1:]
+			for j := 0; j < len(rest); j += 4 {
+				if len(rest)-j < 4 {
+					setFlaw(types.RuneFlawTrailingIntegers)
+					break
+				}
+				next, ok := nextRuneID(id, rest[j], rest[j+1])
+				if !ok {
+					setFlaw(types.RuneFlawEdictRuneID)
+					break
+				}
+				output := rest[j+3]
+				if !output.IsUint64() || output.Uint64() > uint64(outputs) {
+					setFlaw(types.RuneFlawEdictOutput)
+					break
+				}
+				id = next
+				m.edicts = append(m.edicts, types.RuneEdict{ID: next, Amount: rest[j+2], Output: uint32(output.Uint64())})
+			}
+			break
+		}
+		if i+1 >= len(integers) {
+			setFlaw(types.RuneFlawTruncatedField)
+			break
+		}
+		// 超过 u64 的 tag 一定是未识别的, 统一记为一个偶数/奇数 key
+		key := uint64(tagNop)
+		if tag.IsUint64() {
+			key = tag.Uint64()
+		} else if tag.Bit(0) == 0 {
+			key = tagCenotaph
+		}
+		m.fields[key] = append(m.fields[key], integers[i+1])
+	}
+	return m
+}
+
+// edict 中的 ID 采用差分编码; 与 ord 的 RuneId::new 一致, block 为 0 时 tx 必须为 0
+func nextRuneID(prev types.RuneID, block, tx *big.Int) (types.RuneID, bool) {
+	if !block.IsUint64() || !tx.IsUint64() || tx.Uint64() > 0xffffffff {
+		return types.RuneID{}, false
+	}
+	next := types.RuneID{Block: prev.Block + block.Uint64(), Tx: uint32(tx.Uint64())}
+	if next.Block < prev.Block {
+		return types.RuneID{}, false
+	}
+	if block.Sign() == 0 {
+		t := uint64(prev.Tx) + tx.Uint64()
+		if t > 0xffffffff {
+			return types.RuneID{}, false
+		}
+		next.Tx = uint32(t)
+	}
+	if next.Block == 0 && next.Tx > 0 {
+		return types.RuneID{}, false
+	}
+	return next, true
+}
+
+func (m *message) takeN(tag uint64, n int, valid func([]*big.Int) bool) ([]*big.Int, bool) {
+	values := m.fields[tag]
+	if len(values) < n {
+		return nil, false
+	}
+	if valid != nil && !valid(values[:n]) {
+		return nil, false
+	}
+	taken := values[:n]
+	if len(values) == n {
+		delete(m.fields, tag)
+	} else {
+		m.fields[tag] = values[n:]
+	}
+	return taken, true
+}
+
+func (m *message) takeIf(tag uint64, valid func(*big.Int) bool) (*big.Int, bool) {
+	v, ok := m.takeN(tag, 1, func(v []*big.Int) bool { return valid(v[0]) })
+	if !ok {
+		return nil, false
+	}
+	return v[0], true
+}
+
+func (m *message) take(tag uint64) (*big.Int, bool) {
+	return m.takeIf(tag, func(*big.Int) bool { return true })
+}
+
+func (m *message) takeU64(tag uint64) *uint64 {
+	v, ok := m.takeIf(tag, func(v *big.Int) bool { return v.IsUint64() })
+	if !ok {
+		return nil
+	}
+	u := v.Uint64()
+	return &u
+}
+
+// Encipher 把 runestone 编码为 OP_RETURN 输出脚本, 字段顺序与 ord 一致, edict 按 ID 排序后差分编码
+func Encipher(rs *types.Runestone) ([]byte, error) {
+	var payload []byte
+	put := func(tag uint64, v *big.Int) error {
+		if v.Sign() < 0 || v.Cmp(MaxU128) > 0 {
+			return fmt.Errorf("runestone tag %d value out of range", tag)
+		}
+		payload = append(payload, EncodeVarint(new(big.Int).SetUint64(tag))...)
+		payload = append(payload, EncodeVarint(v)...)
+		return nil
+	}
+	putU64 := func(tag uint64, v *uint64) {
+		if v != nil {
+			_ = put(tag, new(big.Int).SetUint64(*v))
+		}
+	}
+	putBig := func(tag uint64, v *big.Int) error {
+		if v == nil {
+			return nil
+		}
+		return put(tag, v)
+	}
+
+	if e := rs.Etching; e != nil {
+		flags := new(big.Int).SetBit(new(big.Int), flagEtching, 1)
+		if e.Terms != nil {
+			flags.SetBit(flags, flagTerms, 1)
+		}
+		if e.Turbo {
+			flags.SetBit(flags, flagTurbo, 1)
+		}
+		_ = put(tagFlags, flags)
+		if e.Rune != nil {
+			v, err := NameToValue(*e.Rune)
+			if err != nil {
+				return nil, err
+			}
+			_ = put(tagRune, v)
+		}
+		if e.Divisibility != nil {
+			if *e.Divisibility > MaxDivisibility {
+				return nil, fmt.Errorf("divisibility too large: %d", *e.Divisibility)
+			}
+			putU64(tagDivisibility, ptrU64(uint64(*e.Divisibility)))
+		}
+		if e.Spacers != nil {
+			if *e.Spacers > MaxSpacers {
+				return nil, fmt.Errorf("spacers out of range: %d", *e.Spacers)
+			}
+			putU64(tagSpacers, ptrU64(uint64(*e.Spacers)))
+		}
+		if e.Symbol != nil {
+			r, size := utf8.DecodeRuneInString(*e.Symbol)
+			if r == utf8.RuneError || size != len(*e.Symbol) {
+				return nil, fmt.Errorf("symbol must be a single character: %q", *e.Symbol)
+			}
+			putU64(tagSymbol, ptrU64(uint64(r)))
+		}
+		if err := putBig(tagPremine, e.Premine); err != nil {
+			return nil, err
+		}
+		if t := e.Terms; t != nil {
+			if err := putBig(tagAmount, t.Amount); err != nil {
+				return nil, err
+			}
+			if err := putBig(tagCap, t.Cap); err != nil {
+				return nil, err
+			}
+			putU64(tagHeightStart, t.HeightStart)
+			putU64(tagHeightEnd, t.HeightEnd)
+			putU64(tagOffsetStart, t.OffsetStart)
+			putU64(tagOffsetEnd, t.OffsetEnd)
+		}
+		if Supply(e) == nil {
+			return nil, errors.New("rune supply overflows u128")
+		}
+	}
+	if rs.Mint != nil {
+		// 多值 tag 每个值都要重复写 tag
+		_ = put(tagMint, new(big.Int).SetUint64(rs.Mint.Block))
+		_ = put(tagMint, new(big.Int).SetUint64(uint64(rs.Mint.Tx)))
+	}
+	if rs.Pointer != nil {
+		putU64(tagPointer, ptrU64(uint64(*rs.Pointer)))
+	}
+
+	if len(rs.Edicts) > 0 {
+		edicts := append([]types.RuneEdict(nil), rs.Edicts...)
+		sort.SliceStable(edicts, func(i, j int) bool {
+			if edicts[i].ID.Block != edicts[j].ID.Block {
+				return edicts[i].ID.Block < edicts[j].ID.Block
+			}
+			return edicts[i].ID.Tx < edicts[j].ID.Tx
+		})
+		payload = append(payload, EncodeVarint(big.NewInt(tagBody))...)
+		var prev types.RuneID
+		for _, e := range edicts {
+			if e.Amount == nil || e.Amount.Sign() < 0 || e.Amount.Cmp(MaxU128) > 0 {
+				return nil, fmt.Errorf("invalid edict amount for %s", e.ID)
+			}
+			block := e.ID.Block - prev.Block
+			tx := uint64(e.ID.Tx)
+			if block == 0 {
+				tx -= uint64(prev.Tx)
+			}
+			payload = append(payload, EncodeVarint(new(big.Int).SetUint64(block))...)
+			payload = append(payload, EncodeVarint(new(big.Int).SetUint64(tx))...)
+			payload = append(payload, EncodeVarint(e.Amount)...)
+			payload = append(payload, EncodeVarint(new(big.Int).SetUint64(uint64(e.Output)))...)
+			prev = e.ID
+		}
+	}
+
+	script := []byte{txscript.OP_RETURN, magicNumber}
+	for len(payload) > 0 {
+		n := min(len(payload), txscript.MaxScriptElementSize)
+		script = appendPush(script, payload[:n])
+		payload = payload[n:]
+	}
+	return script, nil
+}
+
+// ParseRuneID 解析 "block:tx" 格式
+func ParseRuneID(s string) (types.RuneID, error) {
+	var id types.RuneID
+	if _, err := fmt.Sscanf(s, "%d:%d", &id.Block, &id.Tx); err != nil {
+		return id, fmt.Errorf("invalid rune id: %s", s)
+	}
+	if id.String() != s {
+		return id, fmt.Errorf("invalid rune id: %s", s)
+	}
+	return id, nil
+}
+
+func ptrU64(v uint64) *uint64 { return &v }
+
+// 数据 push, 单字节数据不能替换为 OP_1..OP_16, 否则解码时视为 opcode
+func appendPush(script []byte, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n < txscript.OP_PUSHDATA1:
+		script = append(script, byte(n))
+	case n <= 0xff:
+		script = append(script, txscript.OP_PUSHDATA1, byte(n))
+	default:
+		var buf [2]byte
+		binary.LittleEndian.PutUint16(buf[:], uint16(n))
+		script = append(script, txscript.OP_PUSHDATA2)
+		script = append(script, buf[:]...)
+	}
+	return append(script, data...)
+}
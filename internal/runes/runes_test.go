@@ -0,0 +1,200 @@
+package runes
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 用例取自 ord 的 runestone/varint 测试
+
+// 整数序列编码为 runestone 输出脚本
+func runestoneScript(integers ...*big.Int) []byte {
+	var payload []byte
+	for _, n := range integers {
+		payload = append(payload, EncodeVarint(n)...)
+	}
+	return appendPush([]byte{txscript.OP_RETURN, magicNumber}, payload)
+}
+
+func ints(values ...uint64) []*big.Int {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		out[i] = new(big.Int).SetUint64(v)
+	}
+	return out
+}
+
+// 解析只有 runestone 一个输出(另加 extra 个普通输出)的交易
+func decipher(extra int, integers ...*big.Int) *types.Runestone {
+	scripts := [][]byte{runestoneScript(integers...)}
+	for i := 0; i < extra; i++ {
+		scripts = append(scripts, []byte{txscript.OP_TRUE})
+	}
+	return Decipher(scripts)
+}
+
+func TestDecodeVarint(t *testing.T) {
+	max := append(bytes.Repeat([]byte{0xff}, 18), 0x03)
+	for _, tc := range []struct {
+		name string
+		buf  []byte
+		want *big.Int
+		size int
+		err  error
+	}{
+		{"zero", []byte{0}, big.NewInt(0), 1, nil},
+		{"two bytes", []byte{0x80, 0x01}, big.NewInt(128), 2, nil},
+		{"u128 max", max, MaxU128, 19, nil},
+		{"overflow", append(bytes.Repeat([]byte{0xff}, 18), 0x04), nil, 0, errVarintOverflow},
+		{"overlong", append(bytes.Repeat([]byte{0x80}, 19), 0x00), nil, 0, errVarintOverlong},
+		{"unterminated", []byte{0x80}, nil, 0, errVarintUnterminated},
+	} {
+		n, size, err := DecodeVarint(tc.buf)
+		if err != tc.err || size != tc.size || (tc.want != nil && n.Cmp(tc.want) != 0) {
+			t.Errorf("%s: got %v %d %v, want %v %d %v", tc.name, n, size, err, tc.want, tc.size, tc.err)
+		}
+	}
+	if got := EncodeVarint(MaxU128); !bytes.Equal(got, max) {
+		t.Errorf("encode u128 max = %x", got)
+	}
+}
+
+func TestDecipher(t *testing.T) {
+	edict := types.RuneEdict{ID: types.RuneID{Block: 1, Tx: 1}, Amount: big.NewInt(2), Output: 0}
+	// 超过 u64 的 tag
+	evenTag := new(big.Int).Lsh(big.NewInt(1), 100)
+	oddTag := new(big.Int).Add(evenTag, big.NewInt(1))
+	for _, tc := range []struct {
+		name   string
+		rs     *types.Runestone
+		edicts []types.RuneEdict
+		flaw   string
+	}{
+		{"empty", decipher(0), nil, ""},
+		{"edict", decipher(0, ints(tagBody, 1, 1, 2, 0)...), []types.RuneEdict{edict}, ""},
+		{"edict to split output", decipher(1, ints(tagBody, 1, 1, 2, 2)...),
+			[]types.RuneEdict{{ID: edict.ID, Amount: big.NewInt(2), Output: 2}}, ""},
+		{"edict id delta", decipher(0, ints(tagBody, 1, 1, 2, 0, 0, 3, 4, 0, 2, 5, 6, 0)...), []types.RuneEdict{
+			edict,
+			{ID: types.RuneID{Block: 1, Tx: 4}, Amount: big.NewInt(4), Output: 0},
+			{ID: types.RuneID{Block: 3, Tx: 5}, Amount: big.NewInt(6), Output: 0},
+		}, ""},
+		{"edict for etched rune", decipher(0, ints(tagBody, 0, 0, 2, 0)...),
+			[]types.RuneEdict{{Amount: big.NewInt(2)}}, ""},
+		{"unrecognized odd tag ignored", decipher(0, ints(tagNop, 100, tagBody, 1, 1, 2, 0)...), []types.RuneEdict{edict}, ""},
+		{"oversized odd tag ignored", decipher(0, append([]*big.Int{oddTag, big.NewInt(0)}, ints(tagBody, 1, 1, 2, 0)...)...), []types.RuneEdict{edict}, ""},
+		{"divisibility too large ignored", decipher(0, ints(tagFlags, 1, tagDivisibility, MaxDivisibility+1)...), nil, ""},
+
+		{"unrecognized even tag", decipher(0, ints(tagCenotaph, 0, tagBody, 1, 1, 2, 0)...), nil, types.RuneFlawUnrecognizedEvenTag},
+		{"oversized even tag", decipher(0, evenTag, big.NewInt(0)), nil, types.RuneFlawUnrecognizedEvenTag},
+		{"cenotaph flag", decipher(0, new(big.Int).SetUint64(tagFlags), new(big.Int).Lsh(big.NewInt(1), flagCenotaph)), nil, types.RuneFlawUnrecognizedFlag},
+		{"unknown low flag", decipher(0, ints(tagFlags, 1<<3)...), nil, types.RuneFlawUnrecognizedFlag},
+		{"zero block nonzero tx", decipher(0, ints(tagBody, 0, 1, 2, 0)...), nil, types.RuneFlawEdictRuneID},
+		{"block delta overflow", decipher(0, ints(tagBody, 1, 0, 0, 0, 1<<64-1, 0, 0, 0)...), nil, types.RuneFlawEdictRuneID},
+		{"tx delta overflow", decipher(0, ints(tagBody, 1, 1, 0, 0, 0, 1<<32-1, 0, 0)...), nil, types.RuneFlawEdictRuneID},
+		{"edict output too large", decipher(0, ints(tagBody, 1, 1, 2, 2)...), nil, types.RuneFlawEdictOutput},
+		{"trailing integers", decipher(0, ints(tagBody, 1, 1, 2, 0, 0)...), nil, types.RuneFlawTrailingIntegers},
+		{"truncated field", decipher(0, ints(tagFlags, 1, tagFlags)...), nil, types.RuneFlawTruncatedField},
+		{"invalid mint", decipher(0, ints(tagMint, 0, tagMint, 1)...), nil, types.RuneFlawUnrecognizedEvenTag},
+		{"pointer out of range", decipher(0, ints(tagPointer, 1)...), nil, types.RuneFlawUnrecognizedEvenTag},
+		{"supply overflow", decipher(0, append(ints(tagFlags, 1<<flagEtching|1<<flagTerms, tagCap, 2, tagAmount), MaxU128)...), nil, types.RuneFlawSupplyOverflow},
+		{"varint", Decipher([][]byte{{txscript.OP_RETURN, magicNumber, txscript.OP_DATA_1, 0x80}}), nil, types.RuneFlawVarint},
+		{"opcode", Decipher([][]byte{{txscript.OP_RETURN, magicNumber, txscript.OP_VERIFY}}), nil, types.RuneFlawOpcode},
+		{"invalid script", Decipher([][]byte{{txscript.OP_RETURN, magicNumber, txscript.OP_DATA_4}}), nil, types.RuneFlawInvalidScript},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.rs == nil {
+				t.Fatal("runestone not found")
+			}
+			if tc.rs.Cenotaph != (tc.flaw != "") || tc.rs.Flaw != tc.flaw {
+				t.Fatalf("cenotaph=%v flaw=%q, want %q", tc.rs.Cenotaph, tc.rs.Flaw, tc.flaw)
+			}
+			if !reflect.DeepEqual(tc.rs.Edicts, tc.edicts) {
+				t.Errorf("edicts = %+v, want %+v", tc.rs.Edicts, tc.edicts)
+			}
+		})
+	}
+
+	if rs := Decipher([][]byte{{txscript.OP_RETURN, txscript.OP_14}, {txscript.OP_TRUE}}); rs != nil {
+		t.Errorf("non-runestone OP_RETURN = %+v", rs)
+	}
+	// cenotaph 保留铸造目标与名称
+	rs := decipher(0, ints(tagFlags, 1<<flagEtching, tagRune, 4, tagMint, 1, tagMint, 1, tagCenotaph, 0)...)
+	if !rs.Cenotaph || rs.Mint == nil || *rs.Mint != (types.RuneID{Block: 1, Tx: 1}) || rs.Etching == nil || *rs.Etching.Rune != "E" {
+		t.Errorf("cenotaph = %+v", rs)
+	}
+}
+
+// ord runestone 测试中的完整示例: 字段顺序与 edict 差分编码
+func TestEncipher(t *testing.T) {
+	u8 := func(v uint8) *uint8 { return &v }
+	u32 := func(v uint32) *uint32 { return &v }
+	str := func(v string) *string { return &v }
+	pointer := uint32(0)
+	rs := &types.Runestone{
+		Edicts: []types.RuneEdict{
+			{ID: types.RuneID{Block: 5, Tx: 6}, Amount: big.NewInt(4), Output: 1},
+			{ID: types.RuneID{Block: 2, Tx: 3}, Amount: big.NewInt(1), Output: 0},
+		},
+		Etching: &types.RuneEtching{
+			Divisibility: u8(7),
+			Premine:      big.NewInt(8),
+			Rune:         str(ValueToName(big.NewInt(9))),
+			Spacers:      u32(10),
+			Symbol:       str("@"),
+			Terms: &types.RuneTerms{
+				Cap:         big.NewInt(11),
+				HeightStart: ptrU64(12),
+				HeightEnd:   ptrU64(13),
+				Amount:      big.NewInt(14),
+				OffsetStart: ptrU64(15),
+				OffsetEnd:   ptrU64(16),
+			},
+			Turbo: true,
+		},
+		Mint:    &types.RuneID{Block: 17, Tx: 18},
+		Pointer: &pointer,
+	}
+	script, err := Encipher(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := runestoneScript(ints(
+		tagFlags, 1<<flagEtching|1<<flagTerms|1<<flagTurbo,
+		tagRune, 9,
+		tagDivisibility, 7,
+		tagSpacers, 10,
+		tagSymbol, '@',
+		tagPremine, 8,
+		tagAmount, 14,
+		tagCap, 11,
+		tagHeightStart, 12,
+		tagHeightEnd, 13,
+		tagOffsetStart, 15,
+		tagOffsetEnd, 16,
+		tagMint, 17,
+		tagMint, 18,
+		tagPointer, 0,
+		tagBody, 2, 3, 1, 0, 3, 6, 4, 1,
+	)...)
+	if !bytes.Equal(script, want) {
+		t.Fatalf("encipher = %x\nwant       %x", script, want)
+	}
+
+	// 解码后与原值一致(edict 按 ID 排序)
+	got := Decipher([][]byte{script, {txscript.OP_TRUE}})
+	if got.Cenotaph {
+		t.Fatalf("round trip is cenotaph: %s", got.Flaw)
+	}
+	rs.Edicts[0], rs.Edicts[1] = rs.Edicts[1], rs.Edicts[0]
+	rs.Etching.SpacedRune = SpacedName(*rs.Etching.Rune, 10)
+	rs.BodyHex = got.BodyHex
+	if !reflect.DeepEqual(got, rs) {
+		t.Errorf("round trip = %+v\nwant %+v", got, rs)
+	}
+}
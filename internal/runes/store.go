@@ -0,0 +1,79 @@
+package runes
+
+import (
+	"context"
+
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// 账本事件类型(RunesLedger.Operation)
+const (
+	OpEtching = "etching" // premine 发放
+	OpMint    = "mint"
+	OpSend    = "send"    // 花费带有 rune 的输入
+	OpReceive = "receive" // rune 分配到输出
+	OpBurn    = "burn"    // 转入 OP_RETURN, cenotaph 或无可用输出
+)
+
+// RuneEntry 新发行的 rune: 展示行 + 完整铸造条款(区分未设置与 0)
+type RuneEntry struct {
+	Rune  types.Runes
+	Terms *types.RuneTerms
+}
+
+// RuneState 某个 rune 的当前状态
+type RuneState struct {
+	RuneEntry
+	Mints           int64           // 已铸造次数
+	TotalMints      decimal.Decimal // 累计铸造量(含 premine)
+	TotalBurns      decimal.Decimal // 累计销毁量
+	TotalOperations int64           // 累计事件数
+}
+
+// OutpointBalance 某个输出持有的 rune 余额
+type OutpointBalance struct {
+	Outpoint    string          // txid:vout
+	RuneID      string          // block:tx
+	Amount      decimal.Decimal // 数量(最小单位)
+	Address     string          // 输出地址, 非标准脚本为空
+	BlockHeight int             // 输出所在区块高度
+}
+
+// BlockChanges 一个区块产生的全部变更, 由 Store 原子写入
+type BlockChanges struct {
+	Height         int
+	Hash           string
+	Runes          []RuneEntry
+	Ledger         []types.RunesLedger
+	BalanceChanges []types.RunesBalanceChanges // 本区块内余额有变化的 (rune, address) 的最新余额
+	SupplyChanges  []types.RunesSupplyChanges  // 本区块内有变化的 rune 的供给统计
+	Created        []OutpointBalance           // 新产生的 rune 输出(区块内已被花费的不包含)
+	Spent          []string                    // 本区块花费的历史 rune 输出
+}
+
+// Store 索引存储
+// 所有查询返回已提交区块的状态; 区块内的中间状态由索引器维护.
+type Store interface {
+	// 最新已索引区块, 尚未索引时 height 为 -1
+	Tip(ctx context.Context) (height int, hash string, err error)
+	// 已索引区块的哈希, 不存在时返回空字符串
+	BlockHash(ctx context.Context, height int) (string, error)
+
+	// 已发行 rune 数量(下一个 rune 的 Number)
+	RuneCount(ctx context.Context) (int64, error)
+	// 按ID查询 rune, 不存在时返回 nil
+	GetRune(ctx context.Context, id string) (*RuneState, error)
+	// 按名称(不含分隔符)查询 rune ID, 不存在时返回空字符串
+	GetRuneIDByName(ctx context.Context, name string) (string, error)
+
+	// 查询未花费输出持有的 rune
+	GetOutpointBalances(ctx context.Context, outpoint string) ([]OutpointBalance, error)
+	// 查询地址余额及累计事件数
+	GetAddressBalance(ctx context.Context, runeID, address string) (decimal.Decimal, int64, error)
+
+	// 原子写入一个区块的变更
+	ApplyBlock(ctx context.Context, changes *BlockChanges) error
+	// 回滚 height 及之后的全部区块
+	Rollback(ctx context.Context, height int) error
+}
@@ -0,0 +1,226 @@
+package runes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// MemoryStore 内存存储, 适用于测试与小规模索引.
+// 已花费的输出会保留到回滚窗口之外才能释放, 这里为了简单全部保留.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	blocks    map[int]string
+	tip       int
+	runes     map[string]*RuneEntry
+	names     map[string]string
+	mints     map[string][]int // 每次铸造所在高度
+	supply    map[string][]types.RunesSupplyChanges
+	balances  map[string][]types.RunesBalanceChanges // key: rune_id|address
+	ledger    []types.RunesLedger
+	outpoints map[string][]*memOutpoint
+}
+
+type memOutpoint struct {
+	OutpointBalance
+	spentHeight int // -1 表示未花费
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blocks:    make(map[int]string),
+		tip:       -1,
+		runes:     make(map[string]*RuneEntry),
+		names:     make(map[string]string),
+		mints:     make(map[string][]int),
+		supply:    make(map[string][]types.RunesSupplyChanges),
+		balances:  make(map[string][]types.RunesBalanceChanges),
+		outpoints: make(map[string][]*memOutpoint),
+	}
+}
+
+func (s *MemoryStore) Tip(ctx context.Context) (int, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tip, s.blocks[s.tip], nil
+}
+
+func (s *MemoryStore) BlockHash(ctx context.Context, height int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.blocks[height], nil
+}
+
+func (s *MemoryStore) RuneCount(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.runes)), nil
+}
+
+func (s *MemoryStore) GetRune(ctx context.Context, id string) (*RuneState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.runes[id]
+	if !ok {
+		return nil, nil
+	}
+	st := &RuneState{RuneEntry: *entry, Mints: int64(len(s.mints[id]))}
+	if rows := s.supply[id]; len(rows) > 0 {
+		last := rows[len(rows)-1]
+		st.TotalMints = last.TotalMints
+		st.TotalBurns = last.TotalBurns
+		st.TotalOperations = last.TotalOperations
+	}
+	return st, nil
+}
+
+func (s *MemoryStore) GetRuneIDByName(ctx context.Context, name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.names[name], nil
+}
+
+func (s *MemoryStore) GetOutpointBalances(ctx context.Context, outpoint string) ([]OutpointBalance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []OutpointBalance
+	for _, o := range s.outpoints[outpoint] {
+		if o.spentHeight < 0 {
+			out = append(out, o.OutpointBalance)
+		}
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) GetAddressBalance(ctx context.Context, runeID, address string) (decimal.Decimal, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows := s.balances[runeID+"|"+address]
+	if len(rows) == 0 {
+		return decimal.Zero, 0, nil
+	}
+	last := rows[len(rows)-1]
+	return last.Balance, last.TotalOperations, nil
+}
+
+func (s *MemoryStore) ApplyBlock(ctx context.Context, c *BlockChanges) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[c.Height] = c.Hash
+	s.tip = c.Height
+	for i := range c.Runes {
+		entry := c.Runes[i]
+		s.runes[entry.Rune.ID] = &entry
+		s.names[entry.Rune.Name] = entry.Rune.ID
+	}
+	for _, row := range c.Ledger {
+		if row.Operation == OpMint {
+			s.mints[row.RuneID] = append(s.mints[row.RuneID], row.BlockHeight)
+		}
+	}
+	s.ledger = append(s.ledger, c.Ledger...)
+	for _, row := range c.SupplyChanges {
+		s.supply[row.RuneID] = append(s.supply[row.RuneID], row)
+	}
+	for _, row := range c.BalanceChanges {
+		key := row.RuneID + "|" + row.Address
+		s.balances[key] = append(s.balances[key], row)
+	}
+	for _, op := range c.Spent {
+		for _, o := range s.outpoints[op] {
+			if o.spentHeight < 0 {
+				o.spentHeight = c.Height
+			}
+		}
+	}
+	for _, b := range c.Created {
+		s.outpoints[b.Outpoint] = append(s.outpoints[b.Outpoint], &memOutpoint{OutpointBalance: b, spentHeight: -1})
+	}
+	return nil
+}
+
+func (s *MemoryStore) Rollback(ctx context.Context, height int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for h := range s.blocks {
+		if h >= height {
+			delete(s.blocks, h)
+		}
+	}
+	if s.tip >= height {
+		s.tip = height - 1
+	}
+	for id, entry := range s.runes {
+		if entry.Rune.BlockHeight >= height {
+			delete(s.runes, id)
+			delete(s.names, entry.Rune.Name)
+			delete(s.mints, id)
+			delete(s.supply, id)
+		}
+	}
+	for id, heights := range s.mints {
+		n := len(heights)
+		for n > 0 && heights[n-1] >= height {
+			n--
+		}
+		s.mints[id] = heights[:n]
+	}
+	for id, rows := range s.supply {
+		n := len(rows)
+		for n > 0 && rows[n-1].BlockHeight >= height {
+			n--
+		}
+		s.supply[id] = rows[:n]
+	}
+	for key, rows := range s.balances {
+		n := len(rows)
+		for n > 0 && rows[n-1].BlockHeight >= height {
+			n--
+		}
+		if n == 0 {
+			delete(s.balances, key)
+		} else {
+			s.balances[key] = rows[:n]
+		}
+	}
+	n := len(s.ledger)
+	for n > 0 && s.ledger[n-1].BlockHeight >= height {
+		n--
+	}
+	s.ledger = s.ledger[:n]
+	for op, list := range s.outpoints {
+		kept := list[:0]
+		for _, o := range list {
+			if o.BlockHeight >= height {
+				continue
+			}
+			if o.spentHeight >= height {
+				o.spentHeight = -1
+			}
+			kept = append(kept, o)
+		}
+		if len(kept) == 0 {
+			delete(s.outpoints, op)
+		} else {
+			s.outpoints[op] = kept
+		}
+	}
+	return nil
+}
+
+// Ledger 返回 [from, to] 高度区间内的事件(内存存储额外提供, 便于调试)
+func (s *MemoryStore) Ledger(from, to int) []types.RunesLedger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []types.RunesLedger
+	for _, row := range s.ledger {
+		if row.BlockHeight >= from && row.BlockHeight <= to {
+			out = append(out, row)
+		}
+	}
+	return out
+}
@@ -0,0 +1,323 @@
+package runes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/crazycloudcc/btcapis/types"
+	"github.com/shopspring/decimal"
+)
+
+// PostgresSchema PostgresStore 使用的表结构.
+// runes / ledger / balance_changes / supply_changes 与 types 中的模型一一对应,
+// runes_blocks / runes_outpoints / runes_terms 为索引器内部状态.
+const PostgresSchema = `
+CREATE TABLE IF NOT EXISTS runes_blocks (
+	height     int8 PRIMARY KEY,
+	hash       text NOT NULL
+);
+CREATE TABLE IF NOT EXISTS runes (
+	id                 text PRIMARY KEY,
+	number             int8 NOT NULL,
+	name               text NOT NULL UNIQUE,
+	spaced_name        text NOT NULL,
+	block_hash         text NOT NULL,
+	block_height       int8 NOT NULL,
+	tx_index           int8 NOT NULL,
+	tx_id              text NOT NULL,
+	divisibility       int2 NOT NULL,
+	premine            numeric NOT NULL,
+	symbol             text NOT NULL,
+	terms_amount       numeric NOT NULL,
+	terms_cap          numeric NOT NULL,
+	terms_height_start int8 NOT NULL,
+	terms_height_end   int8 NOT NULL,
+	terms_offset_start int8 NOT NULL,
+	terms_offset_end   int8 NOT NULL,
+	turbo              bool NOT NULL,
+	cenotaph           bool NOT NULL,
+	timestamp          int8 NOT NULL,
+	mints              int8 NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS runes_block_height_idx ON runes (block_height);
+CREATE TABLE IF NOT EXISTS runes_terms (
+	rune_id      text PRIMARY KEY,
+	block_height int8 NOT NULL,
+	terms        text NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ledger (
+	rune_id          text NOT NULL,
+	block_hash       text NOT NULL,
+	block_height     int8 NOT NULL,
+	tx_index         int8 NOT NULL,
+	event_index      int8 NOT NULL,
+	tx_id            text NOT NULL,
+	output           int8 NOT NULL,
+	address          text NOT NULL,
+	receiver_address text NOT NULL,
+	amount           numeric NOT NULL,
+	operation        text NOT NULL,
+	timestamp        int8 NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ledger_block_idx ON ledger (block_height, tx_index, event_index);
+CREATE INDEX IF NOT EXISTS ledger_rune_op_idx ON ledger (rune_id, operation);
+CREATE TABLE IF NOT EXISTS balance_changes (
+	rune_id          text NOT NULL,
+	block_height     int8 NOT NULL,
+	address          text NOT NULL,
+	balance          numeric NOT NULL,
+	total_operations int8 NOT NULL DEFAULT 0,
+	PRIMARY KEY (rune_id, address, block_height)
+);
+CREATE TABLE IF NOT EXISTS supply_changes (
+	rune_id          text NOT NULL,
+	block_height     int8 NOT NULL,
+	minted           numeric NOT NULL,
+	total_mints      numeric NOT NULL,
+	burned           numeric NOT NULL,
+	total_burns      numeric NOT NULL,
+	total_operations int8 NOT NULL DEFAULT 0,
+	PRIMARY KEY (rune_id, block_height)
+);
+CREATE TABLE IF NOT EXISTS runes_outpoints (
+	outpoint     text NOT NULL,
+	rune_id      text NOT NULL,
+	amount       numeric NOT NULL,
+	address      text NOT NULL,
+	block_height int8 NOT NULL,
+	spent_height int8,
+	PRIMARY KEY (outpoint, rune_id)
+);
+CREATE INDEX IF NOT EXISTS runes_outpoints_spent_idx ON runes_outpoints (spent_height);
+`
+
+// PostgresStore PostgreSQL 存储, 只依赖 database/sql, 驱动由调用方注册(例如 pgx / lib/pq)
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Migrate 创建表结构(幂等)
+func (s *PostgresStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, PostgresSchema)
+	return err
+}
+
+func (s *PostgresStore) Tip(ctx context.Context) (int, string, error) {
+	var height int
+	var hash string
+	err := s.db.QueryRowContext(ctx, `SELECT height, hash FROM runes_blocks ORDER BY height DESC LIMIT 1`).Scan(&height, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, "", nil
+	}
+	return height, hash, err
+}
+
+func (s *PostgresStore) BlockHash(ctx context.Context, height int) (string, error) {
+	var hash string
+	err := s.db.QueryRowContext(ctx, `SELECT hash FROM runes_blocks WHERE height = $1`, height).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return hash, err
+}
+
+func (s *PostgresStore) RuneCount(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM runes`).Scan(&n)
+	return n, err
+}
+
+func (s *PostgresStore) GetRune(ctx context.Context, id string) (*RuneState, error) {
+	st := &RuneState{}
+	r := &st.Rune
+	err := s.db.QueryRowContext(ctx, `SELECT id, number, name, spaced_name, block_hash, block_height, tx_index, tx_id,
+		divisibility, premine, symbol, terms_amount, terms_cap, terms_height_start, terms_height_end,
+		terms_offset_start, terms_offset_end, turbo, cenotaph, timestamp, mints FROM runes WHERE id = $1`, id).Scan(
+		&r.ID, &r.Number, &r.Name, &r.SpacedName, &r.BlockHash, &r.BlockHeight, &r.TxIndex, &r.TxID,
+		&r.Divisibility, &r.Premine, &r.Symbol, &r.TermsAmount, &r.TermsCap, &r.TermsHeightStart, &r.TermsHeightEnd,
+		&r.TermsOffsetStart, &r.TermsOffsetEnd, &r.Turbo, &r.Cenotaph, &r.Timestamp, &st.Mints)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var termsJSON string
+	err = s.db.QueryRowContext(ctx, `SELECT terms FROM runes_terms WHERE rune_id = $1`, id).Scan(&termsJSON)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if termsJSON != "" {
+		st.Terms = &types.RuneTerms{}
+		if err := json.Unmarshal([]byte(termsJSON), st.Terms); err != nil {
+			return nil, fmt.Errorf("decode rune terms: %w", err)
+		}
+	}
+
+	err = s.db.QueryRowContext(ctx, `SELECT total_mints, total_burns, total_operations FROM supply_changes
+		WHERE rune_id = $1 ORDER BY block_height DESC LIMIT 1`, id).Scan(&st.TotalMints, &st.TotalBurns, &st.TotalOperations)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *PostgresStore) GetRuneIDByName(ctx context.Context, name string) (string, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM runes WHERE name = $1`, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+func (s *PostgresStore) GetOutpointBalances(ctx context.Context, outpoint string) ([]OutpointBalance, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT outpoint, rune_id, amount, address, block_height FROM runes_outpoints
+		WHERE outpoint = $1 AND spent_height IS NULL`, outpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []OutpointBalance
+	for rows.Next() {
+		var b OutpointBalance
+		if err := rows.Scan(&b.Outpoint, &b.RuneID, &b.Amount, &b.Address, &b.BlockHeight); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStore) GetAddressBalance(ctx context.Context, runeID, address string) (decimal.Decimal, int64, error) {
+	var balance decimal.Decimal
+	var ops int64
+	err := s.db.QueryRowContext(ctx, `SELECT balance, total_operations FROM balance_changes
+		WHERE rune_id = $1 AND address = $2 ORDER BY block_height DESC LIMIT 1`, runeID, address).Scan(&balance, &ops)
+	if errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, 0, nil
+	}
+	return balance, ops, err
+}
+
+func (s *PostgresStore) ApplyBlock(ctx context.Context, c *BlockChanges) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO runes_blocks (height, hash) VALUES ($1, $2)`, c.Height, c.Hash); err != nil {
+		return fmt.Errorf("insert block: %w", err)
+	}
+	for _, e := range c.Runes {
+		r := e.Rune
+		if _, err := tx.ExecContext(ctx, `INSERT INTO runes (id, number, name, spaced_name, block_hash, block_height, tx_index, tx_id,
+			divisibility, premine, symbol, terms_amount, terms_cap, terms_height_start, terms_height_end,
+			terms_offset_start, terms_offset_end, turbo, cenotaph, timestamp)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`,
+			r.ID, r.Number, r.Name, r.SpacedName, r.BlockHash, r.BlockHeight, r.TxIndex, r.TxID,
+			r.Divisibility, r.Premine, r.Symbol, r.TermsAmount, r.TermsCap, r.TermsHeightStart, r.TermsHeightEnd,
+			r.TermsOffsetStart, r.TermsOffsetEnd, r.Turbo, r.Cenotaph, r.Timestamp); err != nil {
+			return fmt.Errorf("insert rune %s: %w", r.ID, err)
+		}
+		if e.Terms != nil {
+			termsJSON, err := json.Marshal(e.Terms)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO runes_terms (rune_id, block_height, terms) VALUES ($1, $2, $3)`,
+				r.ID, r.BlockHeight, string(termsJSON)); err != nil {
+				return fmt.Errorf("insert rune terms %s: %w", r.ID, err)
+			}
+		}
+	}
+	for _, l := range c.Ledger {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO ledger (rune_id, block_hash, block_height, tx_index, event_index, tx_id,
+			output, address, receiver_address, amount, operation, timestamp)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			l.RuneID, l.BlockHash, l.BlockHeight, l.TxIndex, l.EventIndex, l.TxID,
+			l.Output, l.Address, l.ReceiverAddress, l.Amount, l.Operation, l.Timestamp); err != nil {
+			return fmt.Errorf("insert ledger: %w", err)
+		}
+	}
+	// 铸造次数记在 runes 行上, 避免每次查询统计账本
+	mints := make(map[string]int64)
+	var minted []string
+	for _, l := range c.Ledger {
+		if l.Operation == OpMint {
+			if mints[l.RuneID] == 0 {
+				minted = append(minted, l.RuneID)
+			}
+			mints[l.RuneID]++
+		}
+	}
+	for _, id := range minted {
+		if _, err := tx.ExecContext(ctx, `UPDATE runes SET mints = mints + $1 WHERE id = $2`, mints[id], id); err != nil {
+			return fmt.Errorf("update rune mints %s: %w", id, err)
+		}
+	}
+	for _, b := range c.BalanceChanges {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO balance_changes (rune_id, block_height, address, balance, total_operations)
+			VALUES ($1, $2, $3, $4, $5)`, b.RuneID, b.BlockHeight, b.Address, b.Balance, b.TotalOperations); err != nil {
+			return fmt.Errorf("insert balance change: %w", err)
+		}
+	}
+	for _, sc := range c.SupplyChanges {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO supply_changes (rune_id, block_height, minted, total_mints, burned, total_burns, total_operations)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`, sc.RuneID, sc.BlockHeight, sc.Minted, sc.TotalMints, sc.Burned, sc.TotalBurns, sc.TotalOperations); err != nil {
+			return fmt.Errorf("insert supply change: %w", err)
+		}
+	}
+	for _, op := range c.Spent {
+		if _, err := tx.ExecContext(ctx, `UPDATE runes_outpoints SET spent_height = $1 WHERE outpoint = $2 AND spent_height IS NULL`, c.Height, op); err != nil {
+			return fmt.Errorf("spend outpoint: %w", err)
+		}
+	}
+	for _, o := range c.Created {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO runes_outpoints (outpoint, rune_id, amount, address, block_height)
+			VALUES ($1, $2, $3, $4, $5)`, o.Outpoint, o.RuneID, o.Amount, o.Address, o.BlockHeight); err != nil {
+			return fmt.Errorf("insert outpoint: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) Rollback(ctx context.Context, height int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 先按将要删除的账本扣减铸造次数
+	if _, err := tx.ExecContext(ctx, `UPDATE runes SET mints = runes.mints - m.n
+		FROM (SELECT rune_id, count(*) AS n FROM ledger WHERE block_height >= $1 AND operation = $2 GROUP BY rune_id) m
+		WHERE runes.id = m.rune_id`, height, OpMint); err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+	stmts := []string{
+		`DELETE FROM runes_blocks WHERE height >= $1`,
+		`DELETE FROM runes WHERE block_height >= $1`,
+		`DELETE FROM runes_terms WHERE block_height >= $1`,
+		`DELETE FROM ledger WHERE block_height >= $1`,
+		`DELETE FROM balance_changes WHERE block_height >= $1`,
+		`DELETE FROM supply_changes WHERE block_height >= $1`,
+		`DELETE FROM runes_outpoints WHERE block_height >= $1`,
+		`UPDATE runes_outpoints SET spent_height = NULL WHERE spent_height >= $1`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt, height); err != nil {
+			return fmt.Errorf("rollback: %w", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,48 @@
+package runes
+
+import (
+	"errors"
+	"math/big"
+)
+
+// LEB128 变长整数, 最大 u128
+var (
+	errVarintOverlong     = errors.New("varint: overlong")
+	errVarintOverflow     = errors.New("varint: overflow")
+	errVarintUnterminated = errors.New("varint: unterminated")
+)
+
+// MaxU128 u128 最大值
+var MaxU128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// EncodeVarint 编码 u128(调用方保证 0 <= n <= MaxU128)
+func EncodeVarint(n *big.Int) []byte {
+	v := new(big.Int).Set(n)
+	low := big.NewInt(0x7f)
+	var out []byte
+	for v.Cmp(low) > 0 {
+		b := byte(new(big.Int).And(v, low).Uint64())
+		out = append(out, b|0x80)
+		v.Rsh(v, 7)
+	}
+	return append(out, byte(v.Uint64()))
+}
+
+// DecodeVarint 从 buf 开头解码一个 u128, 返回数值与占用字节数
+func DecodeVarint(buf []byte) (*big.Int, int, error) {
+	n := new(big.Int)
+	for i, b := range buf {
+		if i > 18 {
+			return nil, 0, errVarintOverlong
+		}
+		value := uint64(b & 0x7f)
+		if i == 18 && value&0x7c != 0 {
+			return nil, 0, errVarintOverflow
+		}
+		n.Or(n, new(big.Int).Lsh(new(big.Int).SetUint64(value), uint(7*i)))
+		if b&0x80 == 0 {
+			return n, i + 1, nil
+		}
+	}
+	return nil, 0, errVarintUnterminated
+}
@@ -0,0 +1,141 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets 请求耗时直方图的默认分桶(秒)
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Prometheus 内置的 Metrics 实现, 以 Prometheus 文本格式输出(实现 http.Handler, 挂到 /metrics 即可):
+//
+//	btcapis_request_duration_seconds{backend,method}       直方图
+//	btcapis_requests_total{backend,method,error}           请求数, error 为 ErrorClass, 成功为空
+//	btcapis_cache_requests_total{kind,result}              缓存查询数, result 为 hit/miss
+type Prometheus struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	latency  map[[2]string]*histogram
+	requests map[[3]string]uint64
+	cache    map[[2]string]uint64
+}
+
+type histogram struct {
+	counts []uint64 // 与 buckets 对应, 非累计
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheus 创建指标收集器, buckets 为空时使用 DefaultBuckets
+func NewPrometheus(buckets ...float64) *Prometheus {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &Prometheus{
+		buckets:  buckets,
+		latency:  make(map[[2]string]*histogram),
+		requests: make(map[[3]string]uint64),
+		cache:    make(map[[2]string]uint64),
+	}
+}
+
+func (p *Prometheus) ObserveRequest(backend, method string, d time.Duration, errClass string) {
+	secs := d.Seconds()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.latency[[2]string{backend, method}]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.latency[[2]string{backend, method}] = h
+	}
+	if i, _ := slices.BinarySearch(p.buckets, secs); i < len(p.buckets) {
+		h.counts[i]++
+	}
+	h.sum += secs
+	h.count++
+	p.requests[[3]string{backend, method, errClass}]++
+}
+
+func (p *Prometheus) ObserveCache(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	p.mu.Lock()
+	p.cache[[2]string{kind, result}]++
+	p.mu.Unlock()
+}
+
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo 以 Prometheus 文本格式输出全部指标
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	p.mu.Lock()
+
+	b.WriteString("# HELP btcapis_request_duration_seconds Backend request latency.\n")
+	b.WriteString("# TYPE btcapis_request_duration_seconds histogram\n")
+	for _, k := range sortedKeys(p.latency) {
+		h := p.latency[k]
+		labels := fmt.Sprintf(`backend="%s",method="%s"`, escape(k[0]), escape(k[1]))
+		var cum uint64
+		for i, le := range p.buckets {
+			cum += h.counts[i]
+			fmt.Fprintf(&b, "btcapis_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(&b, "btcapis_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&b, "btcapis_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "btcapis_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	b.WriteString("# HELP btcapis_requests_total Backend requests by error class.\n")
+	b.WriteString("# TYPE btcapis_requests_total counter\n")
+	for _, k := range sortedKeys(p.requests) {
+		fmt.Fprintf(&b, "btcapis_requests_total{backend=\"%s\",method=\"%s\",error=\"%s\"} %d\n", escape(k[0]), escape(k[1]), escape(k[2]), p.requests[k])
+	}
+
+	b.WriteString("# HELP btcapis_cache_requests_total Cache lookups by result.\n")
+	b.WriteString("# TYPE btcapis_cache_requests_total counter\n")
+	for _, k := range sortedKeys(p.cache) {
+		fmt.Fprintf(&b, "btcapis_cache_requests_total{kind=\"%s\",result=\"%s\"} %d\n", escape(k[0]), escape(k[1]), p.cache[k])
+	}
+
+	p.mu.Unlock()
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func sortedKeys[K [2]string | [3]string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		for i := range len(a) {
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,163 @@
+// Package telemetry 指标与链路追踪钩子. 默认不设置任何实现, 埋点只做一次原子读取, 没有额外开销;
+// 设置 Metrics/Tracer 后, 每次数据源请求(RPC 方法或 REST 路径)记录耗时, 错误分类并生成 span, 缓存记录命中情况.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Metrics 指标钩子, 实现需要并发安全
+type Metrics interface {
+	// 一次数据源请求: backend 如 bitcoind/electrumx/mempool.space, method 为 RPC 方法或 "GET /api/tx/:id/hex";
+	// errClass 为 ErrorClass(err), 成功时为空
+	ObserveRequest(backend, method string, d time.Duration, errClass string)
+	// 一次缓存查询, kind 为数据类别(如 rawtx, bitcoind:fee)
+	ObserveCache(kind string, hit bool)
+}
+
+// Tracer 链路追踪钩子, 可以用几行代码适配 OpenTelemetry(trace.Tracer.Start + span.SetAttributes/RecordError/End)
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span 一次请求的 span
+type Span interface {
+	End(err error)
+}
+
+// Attr span 属性
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// span 属性名, 与 OpenTelemetry RPC 语义约定一致
+const (
+	AttrBackend = "rpc.system"
+	AttrMethod  = "rpc.method"
+)
+
+type metricsHolder struct{ m Metrics }
+type tracerHolder struct{ t Tracer }
+
+var (
+	metrics atomic.Pointer[metricsHolder]
+	tracer  atomic.Pointer[tracerHolder]
+)
+
+// SetMetrics 设置指标钩子, nil 为关闭
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&metricsHolder{m})
+}
+
+// SetTracer 设置追踪钩子, nil 为关闭
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&tracerHolder{t})
+}
+
+func noop(error) {}
+
+// Start 开始一次数据源请求, 请求结束时调用返回的函数
+func Start(ctx context.Context, backend, method string) (context.Context, func(err error)) {
+	mh, th := metrics.Load(), tracer.Load()
+	if mh == nil && th == nil {
+		return ctx, noop
+	}
+	var span Span
+	if th != nil {
+		ctx, span = th.t.Start(ctx, backend+" "+method, Attr{AttrBackend, backend}, Attr{AttrMethod, method})
+	}
+	start := time.Now()
+	return ctx, func(err error) {
+		if mh != nil {
+			mh.m.ObserveRequest(backend, method, time.Since(start), ErrorClass(err))
+		}
+		if span != nil {
+			span.End(err)
+		}
+	}
+}
+
+// StartHTTP 开始一次 REST 请求, method 为 "GET /api/tx/:id/hex" 形式
+func StartHTTP(ctx context.Context, backend, httpMethod, rawURL string) (context.Context, func(err error)) {
+	if metrics.Load() == nil && tracer.Load() == nil {
+		return ctx, noop
+	}
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+	return Start(ctx, backend, httpMethod+" "+Route(path))
+}
+
+// CacheResult 记录缓存命中情况, kind 取 key 中最后一个 ':' 之前的部分
+func CacheResult(key string, hit bool) {
+	mh := metrics.Load()
+	if mh == nil {
+		return
+	}
+	kind := key
+	if i := strings.LastIndexByte(key, ':'); i > 0 {
+		kind = key[:i]
+	}
+	mh.m.ObserveCache(kind, hit)
+}
+
+// ErrorClass 错误分类, 用作指标标签; 成功时为空
+func ErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, types.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, types.ErrTxRejected):
+		return "rejected"
+	case errors.Is(err, types.ErrInsufficientFunds):
+		return "insufficient_funds"
+	case errors.Is(err, types.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, types.ErrInvalidRequest):
+		return "invalid_request"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, types.ErrBackendUnavailable):
+		return "unavailable"
+	}
+	return "other"
+}
+
+// Route 将 REST 路径中的 txid/地址/高度等参数替换为 :id, 避免指标标签过多
+func Route(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if isParam(p) {
+			parts[i] = ":id"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// 含数字且不是 v1 这类版本号的段视为参数
+func isParam(s string) bool {
+	if len(s) <= 2 {
+		return s != "" && s[0] >= '0' && s[0] <= '9'
+	}
+	return strings.ContainsAny(s, "0123456789")
+}
@@ -0,0 +1,101 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// bitcoind RPC 错误码(src/rpc/protocol.h)
+const (
+	rpcMiscError            = -1
+	rpcTypeError            = -3
+	rpcWalletError          = -4
+	rpcInvalidAddressOrKey  = -5
+	rpcWalletInsufficient   = -6
+	rpcInvalidParameter     = -8
+	rpcClientNotConnected   = -9
+	rpcClientInIBD          = -10
+	rpcWalletNotFound       = -18
+	rpcDeserializationError = -22
+	rpcVerifyError          = -25
+	rpcVerifyRejected       = -26
+	rpcVerifyAlreadyInChain = -27
+	rpcInWarmup             = -28
+	rpcInvalidParams        = -32602
+	rpcMethodNotFound       = -32601
+)
+
+// RPCError 将 bitcoind RPC 错误码映射为 types 中的错误分类; 交易拒绝返回 *types.ErrRejected
+func RPCError(backend string, code int, message string) error {
+	var kind error
+	switch code {
+	case rpcVerifyError, rpcVerifyRejected, rpcVerifyAlreadyInChain:
+		return &types.ErrRejected{Code: code, Reason: message}
+	case rpcInvalidAddressOrKey:
+		// 同一错误码既表示交易/区块不存在, 也表示地址或密钥无效
+		if strings.Contains(message, "Invalid") && !strings.Contains(message, "transaction id") {
+			kind = types.ErrInvalidRequest
+		} else {
+			kind = types.ErrNotFound
+		}
+	case rpcInvalidParameter:
+		if strings.Contains(message, "out of range") {
+			kind = types.ErrNotFound
+		} else {
+			kind = types.ErrInvalidRequest
+		}
+	case rpcWalletNotFound:
+		kind = types.ErrNotFound
+	case rpcWalletInsufficient:
+		kind = types.ErrInsufficientFunds
+	case rpcWalletError:
+		if strings.Contains(strings.ToLower(message), "insufficient funds") {
+			kind = types.ErrInsufficientFunds
+		}
+	case rpcClientNotConnected, rpcClientInIBD, rpcInWarmup:
+		kind = types.ErrBackendUnavailable
+	case rpcTypeError, rpcDeserializationError, rpcInvalidParams, rpcMethodNotFound:
+		kind = types.ErrInvalidRequest
+	case rpcMiscError:
+		if strings.Contains(message, "not found") {
+			kind = types.ErrNotFound
+		}
+	}
+	return &types.BackendError{Backend: backend, Code: code, Message: message, Kind: kind}
+}
+
+// mempool.space/esplora 广播失败时原样返回 bitcoind 错误, 例如
+// sendrawtransaction RPC error: {"code":-26,"message":"min relay fee not met, 200 < 211"}
+var embeddedRPCError = regexp.MustCompile(`\{"code":\s*(-?\d+),\s*"message":\s*"((?:[^"\\]|\\.)*)"\}`)
+
+// StatusError HTTP 状态码错误映射为错误分类; 响应体中带有 bitcoind RPC 错误时按错误码映射
+func StatusError(backend, method, url string, status int, body string) error {
+	body = strings.TrimSpace(body)
+	if m := embeddedRPCError.FindStringSubmatch(body); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		var msg string
+		if err := json.Unmarshal([]byte(`"`+m[2]+`"`), &msg); err != nil {
+			msg = m[2]
+		}
+		return RPCError(backend, code, msg)
+	}
+
+	var kind error
+	switch {
+	case status == http.StatusNotFound:
+		kind = types.ErrNotFound
+	case status == http.StatusTooManyRequests:
+		kind = types.ErrRateLimited
+	case status == http.StatusBadRequest:
+		kind = types.ErrInvalidRequest
+	case status >= 500:
+		kind = types.ErrBackendUnavailable
+	}
+	return &types.BackendError{Backend: backend, Code: status, Message: fmt.Sprintf("%s %s: %s", method, url, body), Kind: kind}
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/internal/telemetry"
+)
+
+// Getter REST 适配器共用的 GET 请求: 并发的相同请求合并为一次, 记录 telemetry, 非 2xx 响应返回 StatusError
+type Getter struct {
+	Header http.Header // 可选, 每个请求附加的请求头
+
+	backend string
+	http    *http.Client
+	flight  Group
+}
+
+// NewGetter backend 为数据源名称(用于 telemetry 与错误信息), client 通常来自 NewClient
+func NewGetter(backend string, client *http.Client) *Getter {
+	return &Getter{backend: backend, http: client}
+}
+
+// Bytes 响应体, 由并发的相同请求共享, 不能修改
+func (g *Getter) Bytes(ctx context.Context, url string) ([]byte, error) {
+	return g.flight.Do(ctx, url, func(ctx context.Context) ([]byte, error) {
+		return g.fetch(ctx, url)
+	})
+}
+
+// JSON 解析 JSON 响应
+func (g *Getter) JSON(ctx context.Context, url string, v any) error {
+	b, err := g.Bytes(ctx, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Text 去掉首尾空白的文本响应
+func (g *Getter) Text(ctx context.Context, url string) (string, error) {
+	b, err := g.Bytes(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (g *Getter) fetch(ctx context.Context, url string) (_ []byte, err error) {
+	ctx, done := telemetry.StartHTTP(ctx, g.backend, http.MethodGet, url)
+	defer func() { done(err) }()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range g.Header {
+		req.Header[k] = v
+	}
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, StatusError(g.backend, http.MethodGet, url, resp.StatusCode, string(body))
+	}
+	return io.ReadAll(resp.Body)
+}
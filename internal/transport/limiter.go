@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter 令牌桶限流器: 每秒补充 rate 个令牌, 最多积累 burst 个
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter 创建限流器, rate <= 0 时返回 nil(不限流)
+func NewLimiter(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait 取得一个令牌, 令牌不足时等待; ctx 结束时归还预占的令牌并返回错误
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		l.cancel()
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	l.tokens = min(l.burst, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// SetRateLimit 为 http.Client 设置限流(每秒请求数, 突发数), rate <= 0 为不限流;
+// 限流作用于每一次实际发出的请求(含重试), 同一后端的所有并发请求共享
+func SetRateLimit(c *http.Client, name string, rate float64, burst int) {
+	rt, ok := c.Transport.(*RoundTripper)
+	if !ok {
+		rt = New(name, c.Transport, DefaultPolicy(c.Timeout))
+		c.Transport = rt
+	}
+	rt.limiter.Store(NewLimiter(rate, burst))
+}
+
+// 已知公共服务(mempool.space, blockstream.info)的默认限流; 其他地址(自建节点)不限流
+var publicRateLimits = map[string]struct {
+	rate  float64
+	burst int
+}{
+	"mempool.space":    {rate: 5, burst: 10},
+	"blockstream.info": {rate: 5, burst: 10},
+}
+
+// DefaultRateLimit 按主机名返回默认限流, 子域名(如 testnet.mempool.space)按主域名处理; 未知主机返回 0(不限流)
+func DefaultRateLimit(host string) (float64, int) {
+	host = strings.ToLower(host)
+	for domain, l := range publicRateLimits {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return l.rate, l.burst
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"context"
+	"sync"
+)
+
+// Group 合并并发的相同请求: 同一 key 同时只发出一次, 所有调用方共享结果
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done chan struct{}
+	val  []byte
+	err  error
+}
+
+// Do 执行 fn 或等待进行中的同 key 调用; 返回的字节切片由调用方共享, 不能修改.
+// fn 不随第一个调用方取消(保留其超时), 每个调用方只等待到自己的 ctx 结束.
+func (g *Group) Do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		g.calls[key] = c
+		g.mu.Unlock()
+		go g.run(ctx, key, c, fn)
+	} else {
+		g.mu.Unlock()
+	}
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *Group) run(ctx context.Context, key string, c *call, fn func(ctx context.Context) ([]byte, error)) {
+	runCtx := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithDeadline(runCtx, deadline)
+		defer cancel()
+	}
+	c.val, c.err = fn(runCtx)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(c.done)
+}
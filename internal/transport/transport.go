@@ -0,0 +1,226 @@
+// Package transport 各 HTTP 适配器共用的传输中间件: 幂等请求的指数退避重试(带抖动),
+// Retry-After 处理, 单次请求超时, 每个后端独立的熔断器与令牌桶限流, 以及相同请求的合并.
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Policy 重试与熔断策略
+type Policy struct {
+	MaxRetries     int           // 最大重试次数, 0 为不重试
+	BaseDelay      time.Duration // 第一次重试的退避时间, 之后每次翻倍
+	MaxDelay       time.Duration // 单次退避(含 Retry-After)上限
+	AttemptTimeout time.Duration // 单次请求超时, 0 为不限制; 总时长由调用方 ctx 控制
+	RetryStatuses  []int         // 可重试的 HTTP 状态码
+
+	BreakerThreshold int           // 连续失败多少次后熔断, 0 为不熔断
+	BreakerCooldown  time.Duration // 熔断持续时间, 之后放行一个探测请求
+}
+
+// DefaultPolicy 默认策略, attemptTimeout 为适配器配置的超时时间
+func DefaultPolicy(attemptTimeout time.Duration) Policy {
+	return Policy{
+		MaxRetries:       3,
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         5 * time.Second,
+		AttemptTimeout:   attemptTimeout,
+		RetryStatuses:    []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// ErrCircuitOpen 后端处于熔断状态, 请求未发出; errors.Is(err, types.ErrBackendUnavailable) 同样成立
+var ErrCircuitOpen = fmt.Errorf("transport: circuit breaker open: %w", types.ErrBackendUnavailable)
+
+type idempotentKey struct{}
+
+// WithIdempotent 标记请求是否可以重试. GET/HEAD 默认可重试, 其他方法默认不重试;
+// POST 的 JSON-RPC 查询, 以及按 txid 幂等的广播(重复广播同一笔交易无副作用)需要显式标记.
+func WithIdempotent(ctx context.Context, idempotent bool) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, idempotent)
+}
+
+func isIdempotent(req *http.Request) bool {
+	if v, ok := req.Context().Value(idempotentKey{}).(bool); ok {
+		return v
+	}
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// RoundTripper 带重试与熔断的 http.RoundTripper, 每个实例对应一个后端
+type RoundTripper struct {
+	name    string
+	base    http.RoundTripper
+	policy  Policy
+	breaker *breaker
+	limiter atomic.Pointer[Limiter]
+}
+
+// New 包装 base(为 nil 时使用 http.DefaultTransport), name 用于错误信息
+func New(name string, base http.RoundTripper, policy Policy) *RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RoundTripper{
+		name:    name,
+		base:    base,
+		policy:  policy,
+		breaker: newBreaker(policy.BreakerThreshold, policy.BreakerCooldown),
+	}
+}
+
+// NewClient 使用默认策略创建 http.Client, 超时由 Policy.AttemptTimeout 控制
+func NewClient(name string, base http.RoundTripper, attemptTimeout time.Duration) *http.Client {
+	return &http.Client{Transport: New(name, base, DefaultPolicy(attemptTimeout))}
+}
+
+// Apply 替换 http.Client 的重试策略, 已包装过的只替换策略(熔断状态重置, 保留限流), 不会重复包装
+func Apply(c *http.Client, name string, policy Policy) {
+	base := c.Transport
+	var limiter *Limiter
+	if rt, ok := base.(*RoundTripper); ok {
+		base, name, limiter = rt.base, rt.name, rt.limiter.Load()
+	}
+	rt := New(name, base, policy)
+	rt.limiter.Store(limiter)
+	c.Transport = rt
+}
+
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := 0
+	if isIdempotent(req) && (req.Body == nil || req.GetBody != nil) {
+		retries = t.policy.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Load().Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("%s: %w: %w", t.name, types.ErrRateLimited, err)
+		}
+		if !t.breaker.allow() {
+			return nil, fmt.Errorf("%s: %w", t.name, ErrCircuitOpen)
+		}
+		resp, err := t.attempt(req)
+		if req.Context().Err() != nil {
+			// 调用方取消或超时, 与后端状态无关
+			t.breaker.release()
+			return resp, err
+		}
+		retryable := t.retryable(resp, err)
+		t.breaker.record(!retryable)
+		if !retryable || attempt >= retries {
+			if err != nil {
+				// 网络错误(含单次请求超时)
+				err = fmt.Errorf("%s: %w: %w", t.name, types.ErrBackendUnavailable, err)
+			}
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp); ok {
+				delay = min(max(delay, ra), t.policy.MaxDelay)
+			}
+			// 丢弃响应体, 复用连接
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < delay {
+			if resp != nil {
+				return nil, StatusError(t.name, req.Method, req.URL.Redacted(), resp.StatusCode, "no time left to retry")
+			}
+			return nil, fmt.Errorf("%s: %w: %w", t.name, types.ErrBackendUnavailable, err)
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// 单次请求: 使用独立的超时, 响应体关闭时释放
+func (t *RoundTripper) attempt(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	cancel := context.CancelFunc(func() {})
+	if t.policy.AttemptTimeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), t.policy.AttemptTimeout)
+		r = r.WithContext(ctx)
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// 网络错误(含单次请求超时)与可重试状态码视为后端故障
+func (t *RoundTripper) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, s := range t.policy.RetryStatuses {
+		if resp.StatusCode == s {
+			return true
+		}
+	}
+	return false
+}
+
+// 指数退避, 在 [d/2, d] 之间随机
+func (t *RoundTripper) backoff(attempt int) time.Duration {
+	d := t.policy.BaseDelay << attempt
+	if d <= 0 || d > t.policy.MaxDelay {
+		d = t.policy.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// Retry-After: 秒数或 HTTP 日期
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
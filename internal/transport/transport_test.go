@@ -0,0 +1,257 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 返回 status 的测试服务, 记录请求次数
+func statusServer(t *testing.T, status *atomic.Int32, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func testPolicy() Policy {
+	p := DefaultPolicy(time.Second)
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 10 * time.Millisecond
+	p.BreakerThreshold = 0
+	return p
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		min   time.Duration
+		ok    bool
+	}{
+		{"3", 3 * time.Second, true},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 59 * time.Minute, true},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	} {
+		resp := &http.Response{Header: http.Header{"Retry-After": {tc.value}}}
+		if d, ok := retryAfter(resp); ok != tc.ok || d < tc.min {
+			t.Errorf("retryAfter(%q) = %v, %v", tc.value, d, ok)
+		}
+	}
+
+	var status atomic.Int32
+	status.Store(http.StatusTooManyRequests)
+	srv, hits := statusServer(t, &status, http.Header{"Retry-After": {"1"}})
+	policy := testPolicy()
+	policy.MaxRetries = 1
+	policy.MaxDelay = 100 * time.Millisecond
+	client := &http.Client{Transport: New("test", nil, policy)}
+
+	// Retry-After 大于退避时间时按 Retry-After 等待, 但不超过 MaxDelay
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if d := time.Since(start); d < policy.MaxDelay || d > time.Second || hits.Load() != 2 {
+		t.Errorf("elapsed = %v, hits = %d", d, hits.Load())
+	}
+
+	// 剩余时间不足以等待时直接返回
+	policy.MaxDelay = 5 * time.Second
+	client = &http.Client{Transport: New("test", nil, policy)}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	start = time.Now()
+	if _, err := client.Do(req); err == nil || time.Since(start) > 50*time.Millisecond {
+		t.Errorf("err = %v after %v, want immediate error", err, time.Since(start))
+	}
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	policy := testPolicy()
+	for _, tc := range []struct {
+		name       string
+		method     string
+		idempotent *bool
+		want       int32
+	}{
+		{"get", http.MethodGet, nil, int32(policy.MaxRetries) + 1},
+		{"post", http.MethodPost, nil, 1},
+		{"post marked not idempotent", http.MethodPost, new(bool), 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, hits := statusServer(t, &status, nil)
+			ctx := context.Background()
+			if tc.idempotent != nil {
+				ctx = WithIdempotent(ctx, *tc.idempotent)
+			}
+			req, _ := http.NewRequestWithContext(ctx, tc.method, srv.URL, bytes.NewReader([]byte("{}")))
+			resp, err := (&http.Client{Transport: New("test", nil, policy)}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if hits.Load() != tc.want {
+				t.Errorf("hits = %d, want %d", hits.Load(), tc.want)
+			}
+		})
+	}
+
+	// 显式标记为幂等的 POST 重试, 每次重新发送请求体
+	var bodies atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		buf.ReadFrom(r.Body)
+		if buf.String() == "{}" {
+			bodies.Add(1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	req, _ := http.NewRequestWithContext(WithIdempotent(context.Background(), true), http.MethodPost, srv.URL, bytes.NewReader([]byte("{}")))
+	resp, err := (&http.Client{Transport: New("test", nil, policy)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := bodies.Load(); n != int32(policy.MaxRetries)+1 {
+		t.Errorf("idempotent post attempts = %d, want %d", n, policy.MaxRetries+1)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusBadGateway)
+	srv, hits := statusServer(t, &status, nil)
+	policy := testPolicy()
+	policy.MaxRetries = 0
+	policy.BreakerThreshold = 2
+	policy.BreakerCooldown = 50 * time.Millisecond
+	client := &http.Client{Transport: New("test", nil, policy)}
+	get := func() error {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// 连续失败达到阈值后熔断, 请求不再发出
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || hits.Load() != 2 {
+		t.Fatalf("open: err = %v, hits = %d", err, hits.Load())
+	}
+
+	// 冷却后放行探测请求, 成功则恢复
+	time.Sleep(policy.BreakerCooldown)
+	status.Store(http.StatusOK)
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("closed: %v", err)
+		}
+	}
+	if hits.Load() != 4 {
+		t.Errorf("hits = %d, want 4", hits.Load())
+	}
+
+	// 探测期间不放行其他请求, 探测失败则继续熔断
+	b := newBreaker(1, 50*time.Millisecond)
+	b.record(false)
+	if b.allow() {
+		t.Fatal("allowed during cooldown")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !b.allow() || b.allow() {
+		t.Fatal("want exactly one probe")
+	}
+	b.record(false)
+	if b.allow() {
+		t.Error("allowed after failed probe")
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	l := NewLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 截止时间早于等待时间: 立即返回
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	// 等待中取消
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 两次取消都归还了令牌, 不影响之后的请求
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("tokens = %v, cancelled waits were not refunded", tokens)
+	}
+}
+
+func TestGroupCancel(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	var calls atomic.Int32
+	var fnErr atomic.Value
+	fn := func(ctx context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		if err := ctx.Err(); err != nil {
+			fnErr.Store(err)
+		}
+		return []byte("ok"), nil
+	}
+
+	// 发起请求的调用方取消后立即返回, 共享的请求继续
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := g.Do(ctx, "k", fn); !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller err = %v", err)
+	}
+
+	// 请求仍在进行, 之后的调用方加入并得到结果
+	second := make(chan []byte, 1)
+	go func() {
+		b, _ := g.Do(context.Background(), "k", fn)
+		second <- b
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if b := <-second; string(b) != "ok" {
+		t.Errorf("second caller = %q", b)
+	}
+	if calls.Load() != 1 || fnErr.Load() != nil {
+		t.Errorf("calls = %d, fn ctx err = %v", calls.Load(), fnErr.Load())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -19,6 +20,13 @@ func (c *Client) CreateTxUsePSBTv0(ctx context.Context, inputParams *types.TxInp
 		return "", err
 	}
 
+	// 允许花费资产 UTXO 时打印警告
+	if inputParams.AllowAssetUTXOs {
+		if _, err := c.CheckPSBTAssets(ctx, unsignedPsbt.PSBTBase64); err != nil {
+			logger.Warn("check psbt assets failed: %v", err)
+		}
+	}
+
 	return unsignedPsbt.PSBTBase64, nil
 }
 
@@ -0,0 +1,125 @@
+package tx
+
+import (
+	"context"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/mempoolapis"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 查询交易详情(含确认状态, 手续费与被花费输出)
+func (c *Client) GetTxDetail(ctx context.Context, txid string) (*types.TxDetail, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dto, err := c.mempoolapisClient.TxGet(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTxDetail()
+}
+
+// 查询交易确认状态
+func (c *Client) GetTxStatus(ctx context.Context, txid string) (*types.TxStatus, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dto, err := c.mempoolapisClient.TxGetStatus(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	st := dto.ToTypes()
+	return &st, nil
+}
+
+// 查询交易全部输出的花费情况, 与输出一一对应
+func (c *Client) GetTxOutspends(ctx context.Context, txid string) ([]types.TxOutspend, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dtos, err := c.mempoolapisClient.TxGetOutspends(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]types.TxOutspend, len(dtos))
+	for i, d := range dtos {
+		ret[i] = d.ToTypes()
+	}
+	return ret, nil
+}
+
+// 查询单个输出的花费情况
+func (c *Client) GetTxOutspend(ctx context.Context, txid string, vout uint32) (*types.TxOutspend, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dto, err := c.mempoolapisClient.TxGetOutspend(ctx, txid, vout)
+	if err != nil {
+		return nil, err
+	}
+	ret := dto.ToTypes()
+	return &ret, nil
+}
+
+// 查询已确认交易的 merkle 证明
+func (c *Client) GetTxMerkleProof(ctx context.Context, txid string) (*types.TxMerkleProof, error) {
+	if c.mempoolapisClient == nil {
+		return nil, mempoolapis.ErrNotConfigured
+	}
+	dto, err := c.mempoolapisClient.TxGetMerkleProof(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTypes(), nil
+}
+
+// 查询已确认交易的 merkleblock 证明(hex), 可用 bitcoind verifytxoutproof 校验
+func (c *Client) GetTxMerkleBlockProof(ctx context.Context, txid string) (string, error) {
+	if c.mempoolapisClient == nil {
+		return "", mempoolapis.ErrNotConfigured
+	}
+	return c.mempoolapisClient.TxGetMerkleBlockProof(ctx, txid)
+}
+
+// 查询未确认交易的 CPFP 分析, 用于判断是否需要追加子交易加速
+func (c *Client) GetTxCPFP(ctx context.Context, txid string) (*types.TxCPFPInfo, error) {
+	ex, err := mempoolapis.AsExtras(c.mempoolapisClient)
+	if err != nil {
+		return nil, err
+	}
+	dto, err := ex.TxGetCPFP(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTypes(), nil
+}
+
+// 查询交易的 RBF 替换历史
+func (c *Client) GetTxRBF(ctx context.Context, txid string) (*types.TxRBFHistory, error) {
+	ex, err := mempoolapis.AsExtras(c.mempoolapisClient)
+	if err != nil {
+		return nil, err
+	}
+	dto, err := ex.TxGetRBF(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTypes(), nil
+}
+
+// 查询最近的 RBF 替换; fullRBF 为 true 时只返回包含未声明 BIP125 交易的替换
+func (c *Client) GetRBFReplacements(ctx context.Context, fullRBF bool) ([]*types.RBFReplacement, error) {
+	ex, err := mempoolapis.AsExtras(c.mempoolapisClient)
+	if err != nil {
+		return nil, err
+	}
+	dtos, err := ex.Replacements(ctx, fullRBF)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*types.RBFReplacement, len(dtos))
+	for i, d := range dtos {
+		ret[i] = d.ToTypes()
+	}
+	return ret, nil
+}
@@ -108,6 +108,21 @@ func (c *Client) TransferAllToNewAddress(
 		logger.Info("    - UTXO[%d]: %s:%d = %.8f BTC", i, utxo.TxID, utxo.Vout, utxo.AmountBTC)
 	}
 
+	// 紧急转移会一并转走带有铭文/rune 的 UTXO(资产随 sat 进入目标地址), 这里只做提示
+	if c.utxoClassifier != nil {
+		outpoints := make([]string, len(utxos))
+		for i, utxo := range utxos {
+			outpoints[i] = fmt.Sprintf("%s:%d", utxo.TxID, utxo.Vout)
+		}
+		if assets, err := c.utxoClassifier.ClassifyUTXOs(ctx, outpoints); err != nil {
+			logger.Warn("  ! 查询UTXO资产失败: %v", err)
+		} else {
+			for op, a := range assets {
+				logger.Warn("  ! UTXO %s 携带资产, 将一并转移: 铭文=%v runes=%v", op, a.Inscriptions, a.Runes)
+			}
+		}
+	}
+
 	// 5. 估算交易费用（优先使用Mempool.space，更准确）
 	logger.Info("[步骤7] 估算交易费用")
 	if feeRate <= 0.01 {
@@ -399,8 +414,8 @@ func (c *Client) buildPSBT(
 	outputAmount := totalBalance - estimatedFee
 
 	if outputAmount <= 0 {
-		return nil, 0, fmt.Errorf("余额不足支付手续费，总额: %d sats, 手续费: %d sats",
-			totalBalance, estimatedFee)
+		return nil, 0, fmt.Errorf("%w: 余额不足支付手续费，总额: %d sats, 手续费: %d sats",
+			types.ErrInsufficientFunds, totalBalance, estimatedFee)
 	}
 
 	logger.Info("    - 预估交易大小: %d vBytes", estimatedSize)
@@ -9,9 +9,10 @@ import (
 
 type Client struct {
 	bitcoindrpcClient *bitcoindrpc.Client
-	mempoolapisClient *mempoolapis.Client
+	mempoolapisClient mempoolapis.Backend // mempool.space 或 Esplora
 	electrumxClient   *electrumx.Client
 	addressClient     *address.Client
+	utxoClassifier    UTXOClassifier // 可选, 识别带有铭文/rune 的 UTXO
 }
 
 // func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient *mempoolapis.Client, addressClient *address.Client) *Client {
@@ -23,7 +24,7 @@ type Client struct {
 // }
 
 // NewWithElectrumX 创建包含ElectrumX支持的交易客户端
-func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient *mempoolapis.Client, electrumxClient *electrumx.Client, addressClient *address.Client) *Client {
+func New(bitcoindrpcClient *bitcoindrpc.Client, mempoolapisClient mempoolapis.Backend, electrumxClient *electrumx.Client, addressClient *address.Client) *Client {
 	return &Client{
 		bitcoindrpcClient: bitcoindrpcClient,
 		mempoolapisClient: mempoolapisClient,
@@ -0,0 +1,395 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/internal/ordinals"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+const (
+	defaultPostageSats  = 10000  // 与 ord 默认 postage 一致
+	txOverheadVsize     = 11     // version + locktime + 输入输出计数 + segwit 标记
+	maxStandardTxWeight = 400000 // bitcoind 标准交易的最大重量, 超过时不会转发
+)
+
+// reveal 脚本及其 taproot 承诺
+type revealScript struct {
+	privKey      *btcec.PrivateKey
+	script       []byte
+	leaf         txscript.TapLeaf
+	controlBlock []byte
+	merkleRoot   []byte
+	pkScript     []byte
+	address      string
+}
+
+// 用临时密钥构建 reveal 脚本对应的 taproot 地址, 内部公钥与脚本中的公钥相同(与 ord 一致)
+func newRevealScript(privKey *btcec.PrivateKey, script []byte) (*revealScript, error) {
+	internalKey := privKey.PubKey()
+	leaf := txscript.NewBaseTapLeaf(script)
+	tree := txscript.AssembleTaprootScriptTree(leaf)
+	merkleRoot := tree.RootNode.TapHash()
+
+	ctrl := tree.LeafMerkleProofs[0].ToControlBlock(internalKey)
+	ctrlBytes, err := ctrl.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("序列化控制块失败: %v", err)
+	}
+
+	outputKey := txscript.ComputeTaprootOutputKey(internalKey, merkleRoot[:])
+	addr, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), types.CurrentNetworkParams)
+	if err != nil {
+		return nil, fmt.Errorf("生成 taproot 地址失败: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("生成 taproot 脚本失败: %v", err)
+	}
+
+	return &revealScript{
+		privKey:      privKey,
+		script:       script,
+		leaf:         leaf,
+		controlBlock: ctrlBytes,
+		merkleRoot:   merkleRoot[:],
+		pkScript:     pkScript,
+		address:      addr.EncodeAddress(),
+	}, nil
+}
+
+// CreateInscription 构建铭刻所需的 commit/reveal 交易.
+// commit 交易以 PSBT 返回给钱包签名; reveal 交易由临时密钥签名.
+// 有父铭文时, 父铭文 UTXO 作为 reveal 的 input 0 并原样返还到 output 0, 此时 reveal 以 PSBT 返回, 等待钱包签名 input 0.
+func (c *Client) CreateInscription(ctx context.Context, params *types.InscribeParams) (*types.InscribeResult, error) {
+	if len(params.Inscriptions) == 0 {
+		return nil, errors.New("no inscriptions")
+	}
+	if params.FeeRate <= 0 {
+		return nil, errors.New("invalid fee rate")
+	}
+	postage := params.PostageSats
+	if postage == 0 {
+		postage = defaultPostageSats
+	}
+
+	// commit 交易签名前后 txid 必须不变, 否则 reveal 交易失效
+	fromType, err := decoders.AddressToType(params.FromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("解析付款地址失败: %v", err)
+	}
+	if fromType != types.AddrP2WPKH && fromType != types.AddrP2TR {
+		return nil, fmt.Errorf("unsupported from address type for inscription: %s", fromType)
+	}
+	changeAddress := params.ChangeAddress
+	if changeAddress == "" {
+		changeAddress = params.FromAddress
+	}
+
+	// 1. 每个铭文单独一个输出, 通过 pointer 指定落点
+	var parentValue int64
+	var parentPkScript []byte
+	if params.Parent != nil {
+		parentValue = params.Parent.Value
+		parentPkScript, err = decoders.AddressToPkScript(params.Parent.Address)
+		if err != nil {
+			return nil, fmt.Errorf("解析父铭文地址失败: %v", err)
+		}
+	}
+
+	fields := make([]ordinals.EnvelopeField, len(params.Inscriptions))
+	destinations := make([][]byte, len(params.Inscriptions))
+	for i := range params.Inscriptions {
+		dest := params.Inscriptions[i].Destination
+		if dest == "" {
+			dest = params.Destination
+		}
+		destinations[i], err = decoders.AddressToPkScript(dest)
+		if err != nil {
+			return nil, fmt.Errorf("解析铭文接收地址失败: %v", err)
+		}
+		destType, err := decoders.AddressToType(dest)
+		if err != nil {
+			return nil, fmt.Errorf("解析铭文接收地址失败: %v", err)
+		}
+		if dust := dustLimit(destType); postage < dust {
+			return nil, fmt.Errorf("postage too small for %s: %d < %d", dest, postage, dust)
+		}
+		pointer := uint64(parentValue + int64(i)*postage)
+		fields[i].Pointer = &pointer
+		if params.Parent != nil {
+			fields[i].Parent = params.Parent.InscriptionID
+		}
+	}
+
+	// 2. 临时密钥与 reveal 脚本
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("生成临时密钥失败: %v", err)
+	}
+	script, err := ordinals.BuildRevealScript(schnorr.SerializePubKey(privKey.PubKey()), params.Inscriptions, fields)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := newRevealScript(privKey, script)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 先用占位 commit 输入构建 reveal, 按真实 witness 大小精确计算手续费
+	reveal := wire.NewMsgTx(2)
+	var prevOuts []*wire.TxOut
+	commitIndex := 0
+	if params.Parent != nil {
+		h, err := chainhash.NewHashFromStr(params.Parent.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("父铭文 txid 解析失败: %v", err)
+		}
+		reveal.AddTxIn(wire.NewTxIn(wire.NewOutPoint(h, params.Parent.Vout), nil, nil))
+		reveal.AddTxOut(wire.NewTxOut(parentValue, parentPkScript))
+		prevOuts = append(prevOuts, wire.NewTxOut(parentValue, parentPkScript))
+		commitIndex = 1
+	}
+	reveal.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	for _, dest := range destinations {
+		reveal.AddTxOut(wire.NewTxOut(postage, dest))
+	}
+
+	// 占位 witness: schnorr 签名 64 字节
+	if params.Parent != nil {
+		reveal.TxIn[0].Witness = dummyWitness(parentPkScript)
+	}
+	reveal.TxIn[commitIndex].Witness = wire.TxWitness{make([]byte, 64), rs.script, rs.controlBlock}
+	if weight := txWeight(reveal); weight > maxStandardTxWeight {
+		return nil, fmt.Errorf("reveal transaction too large: weight %d > %d", weight, maxStandardTxWeight)
+	}
+	revealVsize := txVsize(reveal)
+	revealFee := int64(math.Ceil(float64(revealVsize) * params.FeeRate))
+	commitValue := int64(len(params.Inscriptions))*postage + revealFee
+
+	// 4. 构建 commit 交易
+	commit, utxos, commitFee, err := c.createCommitTx(ctx, params.FromAddress, changeAddress, rs.pkScript, commitValue, params.FeeRate)
+	if err != nil {
+		return nil, err
+	}
+	unsigned, err := c.MsgTxToPSBTV0(ctx, commit, &types.TxInputParams{FromAddress: []string{params.FromAddress}}, utxos)
+	if err != nil {
+		return nil, err
+	}
+	commitHash := commit.TxHash()
+
+	// 5. 签名 reveal 的 commit 输入(script path)
+	reveal.TxIn[commitIndex].PreviousOutPoint = wire.OutPoint{Hash: commitHash, Index: 0}
+	prevOuts = append(prevOuts, wire.NewTxOut(commitValue, rs.pkScript))
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, in := range reveal.TxIn {
+		fetcher.AddPrevOut(in.PreviousOutPoint, prevOuts[i])
+	}
+	sigHashes := txscript.NewTxSigHashes(reveal, fetcher)
+	sig, err := txscript.RawTxInTapscriptSignature(reveal, sigHashes, commitIndex, commitValue, rs.pkScript, rs.leaf, txscript.SigHashDefault, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("reveal 签名失败: %v", err)
+	}
+	reveal.TxIn[commitIndex].Witness = wire.TxWitness{sig, rs.script, rs.controlBlock}
+
+	wif, err := btcutil.NewWIF(privKey, types.CurrentNetworkParams, true)
+	if err != nil {
+		return nil, fmt.Errorf("导出临时密钥失败: %v", err)
+	}
+
+	revealHash := reveal.TxHash()
+	ret := &types.InscribeResult{
+		CommitPSBT:      unsigned.PSBTBase64,
+		CommitTxID:      commitHash.String(),
+		CommitAddress:   rs.address,
+		CommitValue:     commitValue,
+		CommitFee:       commitFee,
+		RevealTxID:      revealHash.String(),
+		RevealFee:       revealFee,
+		RevealVsize:     revealVsize,
+		TapscriptHex:    hex.EncodeToString(rs.script),
+		ControlBlockHex: hex.EncodeToString(rs.controlBlock),
+		RecoveryKeyWIF:  wif.String(),
+	}
+	for i := range params.Inscriptions {
+		ret.InscriptionIDs = append(ret.InscriptionIDs, fmt.Sprintf("%si%d", ret.RevealTxID, i))
+	}
+
+	if params.Parent == nil {
+		var buf bytes.Buffer
+		if err := reveal.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("序列化 reveal 交易失败: %v", err)
+		}
+		ret.RevealTxHex = hex.EncodeToString(buf.Bytes())
+		return ret, nil
+	}
+
+	// 有父铭文: commit 输入已最终化, 父铭文输入留给钱包签名
+	reveal.TxIn[0].Witness = nil
+	witness := reveal.TxIn[commitIndex].Witness
+	reveal.TxIn[commitIndex].Witness = nil
+	packet, err := psbt.NewFromUnsignedTx(reveal)
+	if err != nil {
+		return nil, fmt.Errorf("创建 PSBT 失败: %v", err)
+	}
+	for i := range packet.Inputs {
+		packet.Inputs[i].WitnessUtxo = prevOuts[i]
+	}
+	var wbuf bytes.Buffer
+	if err := psbt.WriteTxWitness(&wbuf, witness); err != nil {
+		return nil, fmt.Errorf("序列化 witness 失败: %v", err)
+	}
+	packet.Inputs[commitIndex].FinalScriptWitness = wbuf.Bytes()
+
+	var pbuf bytes.Buffer
+	if err := packet.Serialize(&pbuf); err != nil {
+		return nil, fmt.Errorf("PSBT 编码失败: %v", err)
+	}
+	ret.RevealPSBT = base64.StdEncoding.EncodeToString(pbuf.Bytes())
+	return ret, nil
+}
+
+// 构建 commit 交易: output 0 为 reveal 脚本地址, 可选找零输出.
+// 返回交易, 选中的 UTXO 与手续费(sats).
+func (c *Client) createCommitTx(ctx context.Context, fromAddress, changeAddress string, commitPkScript []byte, commitValue int64, feeRate float64) (*wire.MsgTx, []*types.TxUTXO, int64, error) {
+	arrUTXOs, err := c.addressClient.GetAddressUTXOs(ctx, fromAddress)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	// 排除带有铭文/rune 的 UTXO, 避免作为手续费花掉; 识别失败时不创建交易
+	if arrUTXOs, err = c.excludeAssetUTXOs(ctx, arrUTXOs); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// 先按带找零的大小估算手续费, 选币不足时 selectUTXOs 返回错误
+	_, commitOutVsize := types.GetOutSize(types.AddrP2TR)
+	feeFor := func(inCount int) int64 {
+		vsize := txOverheadVsize + commitOutVsize + estimateTxSize(inCount, fromAddress, []string{changeAddress}, 0)
+		return int64(math.Ceil(float64(vsize) * feeRate))
+	}
+	selected, totalInputSats, err := selectUTXOs(arrUTXOs, commitValue+feeFor(1))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	fee := feeFor(len(selected))
+	if totalInputSats < commitValue+fee {
+		return nil, nil, 0, fmt.Errorf("%w after fee calculation", types.ErrInsufficientFunds)
+	}
+
+	tx := wire.NewMsgTx(2)
+	for _, utxo := range selected {
+		h, err := chainhash.NewHashFromStr(utxo.OutPoint.Hash.String())
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("TxID(utxo.OutPoint.Hash) 解析失败: %v", err)
+		}
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Hash: *h, Index: utxo.OutPoint.Index},
+			Sequence:         wire.MaxTxInSequenceNum - 2,
+		})
+	}
+	tx.AddTxOut(wire.NewTxOut(commitValue, commitPkScript))
+
+	// 手续费已包含找零输出, 找零低于粉尘限制时并入手续费
+	changeSats := totalInputSats - commitValue - fee
+	changeType, err := decoders.AddressToType(changeAddress)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("解析找零地址失败: %v", err)
+	}
+	if changeSats >= dustLimit(changeType) {
+		changePkScript, err := decoders.AddressToPkScript(changeAddress)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("解析找零地址失败: %v", err)
+		}
+		tx.AddTxOut(wire.NewTxOut(changeSats, changePkScript))
+	} else {
+		fee += changeSats
+	}
+
+	return tx, selected, fee, nil
+}
+
+// CreateInscriptionRecoveryTx 在 reveal 无法广播时, 使用临时密钥通过 key path 取回 commit 输出.
+// 返回已签名交易的 hex.
+func (c *Client) CreateInscriptionRecoveryTx(params *types.InscriptionRecoveryParams) (string, error) {
+	wif, err := btcutil.DecodeWIF(params.RecoveryKeyWIF)
+	if err != nil {
+		return "", fmt.Errorf("解析临时密钥失败: %v", err)
+	}
+	script, err := hex.DecodeString(params.TapscriptHex)
+	if err != nil {
+		return "", fmt.Errorf("解析 tapscript 失败: %v", err)
+	}
+	rs, err := newRevealScript(wif.PrivKey, script)
+	if err != nil {
+		return "", err
+	}
+	h, err := chainhash.NewHashFromStr(params.CommitTxID)
+	if err != nil {
+		return "", fmt.Errorf("commit txid 解析失败: %v", err)
+	}
+	toPkScript, err := decoders.AddressToPkScript(params.ToAddress)
+	if err != nil {
+		return "", fmt.Errorf("解析收款地址失败: %v", err)
+	}
+	toType, err := decoders.AddressToType(params.ToAddress)
+	if err != nil {
+		return "", fmt.Errorf("解析收款地址失败: %v", err)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(h, params.CommitVout), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(0, toPkScript))
+	tx.TxIn[0].Witness = wire.TxWitness{make([]byte, 64)}
+	fee := int64(math.Ceil(float64(txVsize(tx)) * params.FeeRate))
+	if params.CommitValue-fee < dustLimit(toType) {
+		return "", fmt.Errorf("commit value too small to recover: %d, fee %d", params.CommitValue, fee)
+	}
+	tx.TxOut[0].Value = params.CommitValue - fee
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(rs.pkScript, params.CommitValue)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	sig, err := txscript.RawTxInTaprootSignature(tx, sigHashes, 0, params.CommitValue, rs.pkScript, rs.merkleRoot, txscript.SigHashDefault, wif.PrivKey)
+	if err != nil {
+		return "", fmt.Errorf("签名失败: %v", err)
+	}
+	tx.TxIn[0].Witness = wire.TxWitness{sig}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", fmt.Errorf("序列化交易失败: %v", err)
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// helper: 按 BIP141 计算 vsize
+func txVsize(tx *wire.MsgTx) int64 {
+	return (txWeight(tx) + 3) / 4
+}
+
+func txWeight(tx *wire.MsgTx) int64 {
+	return int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+}
+
+// helper: 父铭文输入的占位 witness, 用于估算大小
+func dummyWitness(pkScript []byte) wire.TxWitness {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.WitnessV0PubKeyHashTy:
+		return wire.TxWitness{make([]byte, 72), make([]byte, 33)}
+	default:
+		return wire.TxWitness{make([]byte, 64)}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -86,7 +87,7 @@ func (c *Client) MsgTxToPSBTV0(ctx context.Context, tx *wire.MsgTx, inputParams
 			txout := &wire.TxOut{Value: utxos[i].Value, PkScript: addrScriptInfo.ScriptPubKeyHex}
 			upd.AddInWitnessUtxo(txout, i)
 		default:
-			fmt.Printf("Unsupported address type for PSBT input: %v\n", addrScriptInfo.Typ)
+			logger.Warn("Unsupported address type for PSBT input: %v", addrScriptInfo.Typ)
 		}
 	}
 
@@ -134,7 +135,7 @@ func (c *Client) FinalizePSBT(ctx context.Context, signedPSBT string) ([]byte, e
 		}
 		return true
 	}
-	fmt.Printf("psbt input len: %d, isHex: %v\n", len(normalized), isHex(normalized))
+	logger.Debug("psbt input len: %d, isHex: %v", len(normalized), isHex(normalized))
 
 	// 十六进制转base64
 	if isHex(normalized) {
@@ -146,23 +147,27 @@ func (c *Client) FinalizePSBT(ctx context.Context, signedPSBT string) ([]byte, e
 	} else {
 		psbtBase64 = normalized
 	}
-	fmt.Printf("psbt base64 len: %d\n", len(psbtBase64))
-	fmt.Printf("psbt base64: %s\n", psbtBase64)
+	logger.Debug("psbt base64 len: %d", len(psbtBase64))
+
+	// 签名后的交易若会转走带有铭文/rune 的 UTXO, 广播前打印警告
+	if _, err := c.CheckPSBTAssets(ctx, psbtBase64); err != nil {
+		logger.Warn("check psbt assets failed: %v", err)
+	}
 
 	// finalizepsbt -> 原始交易hex
 	hexString, err := c.bitcoindrpcClient.TxFinalizePsbt(ctx, psbtBase64)
 	if err != nil {
-		fmt.Printf("finalizepsbt error: %v\n", err)
+		logger.Error("finalizepsbt error: %v", err)
 		return nil, err
 	}
-	fmt.Println("finalize rawHex: ", hexString)
+	logger.Debug("finalize raw tx hex len: %d", len(hexString))
 
 	rawTx, err := hex.DecodeString(hexString)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("broadcast tx len: ", len(rawTx))
+	logger.Debug("broadcast tx len: %d", len(rawTx))
 	return rawTx, nil
 }
 
@@ -0,0 +1,338 @@
+package tx
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/internal/runes"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// 各类输出的粉尘限制(bitcoind 默认 dustRelayFee = 3 sat/vB)
+func dustLimit(addrType types.AddressType) int64 {
+	switch addrType {
+	case types.AddrP2PKH:
+		return 546
+	case types.AddrP2SH:
+		return 540
+	case types.AddrP2WPKH:
+		return 294
+	default: // P2WSH / P2TR
+		return 330
+	}
+}
+
+// 承载资产(rune, 铭文/聪)的输出金额: 未指定时使用地址类型的粉尘限制, 指定时不能低于粉尘限制
+func outputPostage(address string, postage int64) (int64, error) {
+	t, err := decoders.AddressToType(address)
+	if err != nil {
+		return 0, fmt.Errorf("解析地址失败: %v", err)
+	}
+	dust := dustLimit(t)
+	if postage == 0 {
+		return dust, nil
+	}
+	if postage < dust {
+		return 0, fmt.Errorf("postage too small for %s: %d < %d", address, postage, dust)
+	}
+	return postage, nil
+}
+
+// 待构建的输出
+type plannedOut struct {
+	address string
+	value   int64
+}
+
+// CreateRuneTransfer 构建 rune 转账交易.
+// 输出布局: 0 为 runestone, 之后依次为接收方, rune 找零(需要时, 由 pointer 指向), BTC 找零.
+// 只花费不带铭文的 rune UTXO, 同一 UTXO 上的其他 rune 随找零返回; 手续费由不带资产的 UTXO 支付.
+func (c *Client) CreateRuneTransfer(ctx context.Context, params *types.RuneTransferParams) (*types.RuneTxResult, error) {
+	if params.FromAddress == "" || len(params.Recipients) == 0 {
+		return nil, errors.New("from address and recipients are required")
+	}
+	if params.FeeRate <= 0 {
+		return nil, fmt.Errorf("invalid fee rate: %v", params.FeeRate)
+	}
+	id, err := runes.ParseRuneID(params.RuneID)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for i, r := range params.Recipients {
+		if r.Amount == nil || r.Amount.Sign() <= 0 {
+			return nil, fmt.Errorf("recipient %d: amount must be positive", i)
+		}
+		total.Add(total, r.Amount)
+	}
+	if total.Cmp(runes.MaxU128) > 0 {
+		return nil, errors.New("total amount overflows u128")
+	}
+	if c.utxoClassifier == nil {
+		return nil, errors.New("utxo classifier required to locate rune balances")
+	}
+	info, err := c.runeInfo(ctx, params.RuneID)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos, err := c.addressClient.GetAddressUTXOs(ctx, params.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	outpoints := make([]string, len(utxos))
+	for i, u := range utxos {
+		outpoints[i] = utxoOutpoint(u)
+	}
+	assets, err := c.utxoClassifier.ClassifyUTXOs(ctx, outpoints)
+	if err != nil {
+		return nil, fmt.Errorf("utxo classifier failed: %w", err)
+	}
+
+	// 1. 选择 rune UTXO
+	var runeUTXOs, clean []types.TxUTXO
+	have := new(big.Int)
+	otherRunes := false
+	for i, u := range utxos {
+		a := assets[outpoints[i]]
+		if !a.HasAssets() {
+			clean = append(clean, u)
+			continue
+		}
+		if len(a.Inscriptions) > 0 || have.Cmp(total) >= 0 {
+			continue
+		}
+		amt := runeAmountOf(a, params.RuneID, info.SpacedRune)
+		if amt == nil {
+			continue
+		}
+		runeUTXOs = append(runeUTXOs, u)
+		have.Add(have, amt)
+		if len(a.Runes) > 1 {
+			otherRunes = true
+		}
+	}
+	if have.Cmp(total) < 0 {
+		return nil, fmt.Errorf("%w: %s balance have %s, need %s", types.ErrInsufficientFunds, info.SpacedRune, have, total)
+	}
+
+	// 2. runestone 与 rune 输出
+	rs := &types.Runestone{}
+	var outs []plannedOut
+	for i, r := range params.Recipients {
+		postage, err := outputPostage(r.Address, params.PostageSats)
+		if err != nil {
+			return nil, err
+		}
+		outs = append(outs, plannedOut{address: r.Address, value: postage})
+		rs.Edicts = append(rs.Edicts, types.RuneEdict{ID: id, Amount: r.Amount, Output: uint32(i + 1)})
+	}
+	if have.Cmp(total) > 0 || otherRunes {
+		changeAddr := params.RuneChangeAddress
+		if changeAddr == "" {
+			changeAddr = params.FromAddress
+		}
+		postage, err := outputPostage(changeAddr, params.PostageSats)
+		if err != nil {
+			return nil, err
+		}
+		outs = append(outs, plannedOut{address: changeAddr, value: postage})
+		pointer := uint32(len(outs))
+		rs.Pointer = &pointer
+	}
+
+	ret, err := c.buildRuneTx(ctx, params.FromAddress, params.ChangeAddress, rs, outs, runeUTXOs, clean, params.FeeRate)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range runeUTXOs {
+		ret.RuneInputs = append(ret.RuneInputs, utxoOutpoint(u))
+	}
+	return ret, nil
+}
+
+// CreateRuneMint 构建公开铸造交易: 0 为 runestone(mint + pointer), 1 为铸造结果输出, 之后为 BTC 找零.
+// 按下一个区块的高度检查铸造窗口与次数上限; 内存池中尚未确认的铸造不计入.
+func (c *Client) CreateRuneMint(ctx context.Context, params *types.RuneMintParams) (*types.RuneTxResult, error) {
+	if params.FromAddress == "" {
+		return nil, errors.New("from address is required")
+	}
+	if params.FeeRate <= 0 {
+		return nil, fmt.Errorf("invalid fee rate: %v", params.FeeRate)
+	}
+	id, err := runes.ParseRuneID(params.RuneID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.runeInfo(ctx, params.RuneID)
+	if err != nil {
+		return nil, err
+	}
+	if c.bitcoindrpcClient == nil {
+		return nil, fmt.Errorf("%w: bitcoind rpc client required to check mint window", types.ErrBackendUnavailable)
+	}
+	tip, err := c.bitcoindrpcClient.ChainGetBlockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := runes.CheckMint(info.Terms, info.Block, info.Mints, uint64(tip+1))
+	if err != nil {
+		return nil, fmt.Errorf("rune %s not mintable: %w", info.SpacedRune, err)
+	}
+
+	dest := params.Destination
+	if dest == "" {
+		dest = params.FromAddress
+	}
+	postage, err := outputPostage(dest, params.PostageSats)
+	if err != nil {
+		return nil, err
+	}
+	pointer := uint32(1)
+	rs := &types.Runestone{Mint: &id, Pointer: &pointer}
+
+	utxos, err := c.addressClient.GetAddressUTXOs(ctx, params.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	if utxos, err = c.excludeAssetUTXOs(ctx, utxos); err != nil {
+		return nil, err
+	}
+	ret, err := c.buildRuneTx(ctx, params.FromAddress, params.ChangeAddress, rs, []plannedOut{{address: dest, value: postage}}, nil, utxos, params.FeeRate)
+	if err != nil {
+		return nil, err
+	}
+	ret.MintAmount = amount
+	return ret, nil
+}
+
+// 组装交易: 先放入必须花费的 runeUTXOs, 再从 clean 中选币支付输出金额与手续费
+func (c *Client) buildRuneTx(ctx context.Context, fromAddress, changeAddress string, rs *types.Runestone, outs []plannedOut, runeUTXOs, clean []types.TxUTXO, feeRate float64) (*types.RuneTxResult, error) {
+	if changeAddress == "" {
+		changeAddress = fromAddress
+	}
+	script, err := runes.Encipher(rs)
+	if err != nil {
+		return nil, err
+	}
+	fromType, err := decoders.AddressToType(fromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("解析地址失败: %v", err)
+	}
+	changeType, err := decoders.AddressToType(changeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("解析找零地址失败: %v", err)
+	}
+	_, changeOutVsize := types.GetOutSize(changeType)
+
+	// 固定部分: 开销 + runestone 输出(8 金额 + 1 长度 + 脚本) + rune 输出 + 找零输出
+	baseVsize := txOverheadVsize + 9 + len(script) + changeOutVsize
+	var outSats int64
+	for _, o := range outs {
+		t, err := decoders.AddressToType(o.address)
+		if err != nil {
+			return nil, fmt.Errorf("解析收款地址失败: %v", err)
+		}
+		_, v := types.GetOutSize(t)
+		baseVsize += v
+		outSats += o.value
+	}
+	inVsize := types.GetInSize(fromType)
+	feeFor := func(inCount int) int64 {
+		return int64(math.Ceil(float64(baseVsize+inVsize*inCount) * feeRate))
+	}
+
+	selected := make([]*types.TxUTXO, 0, len(runeUTXOs)+len(clean))
+	var inSats int64
+	for i := range runeUTXOs {
+		selected = append(selected, &runeUTXOs[i])
+		inSats += runeUTXOs[i].Value
+	}
+	for i := 0; inSats < outSats+feeFor(len(selected)); i++ {
+		if i >= len(clean) {
+			return nil, fmt.Errorf("%w: have %d sats, need %d", types.ErrInsufficientFunds, inSats, outSats+feeFor(len(selected)))
+		}
+		selected = append(selected, &clean[i])
+		inSats += clean[i].Value
+	}
+	fee := feeFor(len(selected))
+
+	tx := wire.NewMsgTx(2)
+	for _, u := range selected {
+		h, err := chainhash.NewHashFromStr(u.OutPoint.Hash.String())
+		if err != nil {
+			return nil, fmt.Errorf("TxID(utxo.OutPoint.Hash) 解析失败: %v", err)
+		}
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Hash: *h, Index: u.OutPoint.Index},
+			Sequence:         wire.MaxTxInSequenceNum - 2,
+		})
+	}
+	tx.AddTxOut(wire.NewTxOut(0, script))
+	for _, o := range outs {
+		pkScript, err := decoders.AddressToPkScript(o.address)
+		if err != nil {
+			return nil, fmt.Errorf("解析收款地址失败: %v", err)
+		}
+		tx.AddTxOut(wire.NewTxOut(o.value, pkScript))
+	}
+	// 找零低于粉尘限制时并入手续费; runestone 设置了 pointer 或全部 rune 已分配, 找零输出不会收到 rune
+	changeSats := inSats - outSats - fee
+	if changeSats >= dustLimit(changeType) {
+		pkScript, err := decoders.AddressToPkScript(changeAddress)
+		if err != nil {
+			return nil, fmt.Errorf("解析找零地址失败: %v", err)
+		}
+		tx.AddTxOut(wire.NewTxOut(changeSats, pkScript))
+	} else {
+		fee += changeSats
+	}
+
+	unsigned, err := c.MsgTxToPSBTV0(ctx, tx, &types.TxInputParams{FromAddress: []string{fromAddress}}, selected)
+	if err != nil {
+		return nil, err
+	}
+	return &types.RuneTxResult{
+		PSBTBase64:   unsigned.PSBTBase64,
+		UnsignedTx:   unsigned.UnsignedTx,
+		RunestoneHex: hex.EncodeToString(script),
+		Fee:          fee,
+		Vsize:        int64(baseVsize + inVsize*len(selected)),
+	}, nil
+}
+
+// 查询 rune 信息, 需要分类器同时实现 RuneInfoSource
+func (c *Client) runeInfo(ctx context.Context, runeID string) (*types.RuneInfo, error) {
+	src, ok := c.utxoClassifier.(RuneInfoSource)
+	if !ok {
+		return nil, fmt.Errorf("%w: rune info source not configured, use an ord server or local runes index classifier", types.ErrBackendUnavailable)
+	}
+	info, err := src.RuneInfo(ctx, runeID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, fmt.Errorf("rune %s: %w", runeID, types.ErrNotFound)
+	}
+	return info, nil
+}
+
+// UTXO 上指定 rune 的数量, 按 ID 或名称匹配(ord server 只返回名称)
+func runeAmountOf(a *types.UTXOAssets, runeID, spacedRune string) *big.Int {
+	for _, r := range a.Runes {
+		if r.RuneID == runeID || (r.RuneID == "" && r.Rune == spacedRune) {
+			v, ok := new(big.Int).SetString(r.Amount, 10)
+			if ok {
+				return v
+			}
+		}
+	}
+	return nil
+}
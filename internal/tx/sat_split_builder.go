@@ -0,0 +1,171 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// CreateSatSplit 拆分 UTXO, 让偏移 Offset 处的 sat 成为独立输出的第一个 sat.
+// 输入布局: [补位输入], 目标 UTXO, [支付手续费的输入]; 输出布局: [前缀], 目标输出, [找零].
+// 前缀不足粉尘限制时在目标 UTXO 前加入一个不带资产的补位输入, 按 FIFO 规则补位的 sat 全部进入前缀输出.
+// 目标输出之后的 sat(包括目标 UTXO 剩余部分)进入找零, 其中若带有其他铭文需要调用方自行确认.
+func (c *Client) CreateSatSplit(ctx context.Context, params *types.SatSplitParams) (*types.SatSplitResult, error) {
+	if params.FromAddress == "" || params.TxID == "" {
+		return nil, errors.New("from address and utxo are required")
+	}
+	if params.FeeRate <= 0 {
+		return nil, fmt.Errorf("invalid fee rate: %v", params.FeeRate)
+	}
+	dest := params.Destination
+	if dest == "" {
+		dest = params.FromAddress
+	}
+	changeAddr := params.ChangeAddress
+	if changeAddr == "" {
+		changeAddr = params.FromAddress
+	}
+	postage, err := outputPostage(dest, params.PostageSats)
+	if err != nil {
+		return nil, err
+	}
+	fromType, err := decoders.AddressToType(params.FromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("解析地址失败: %v", err)
+	}
+	destType, err := decoders.AddressToType(dest)
+	if err != nil {
+		return nil, fmt.Errorf("解析收款地址失败: %v", err)
+	}
+	changeType, err := decoders.AddressToType(changeAddr)
+	if err != nil {
+		return nil, fmt.Errorf("解析找零地址失败: %v", err)
+	}
+	changeDust := dustLimit(changeType)
+
+	// 1. 找到目标 UTXO, 其余不带资产的 UTXO 用于补位和支付手续费
+	utxos, err := c.addressClient.GetAddressUTXOs(ctx, params.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	targetOutpoint := fmt.Sprintf("%s:%d", params.TxID, params.Vout)
+	var target *types.TxUTXO
+	others := make([]types.TxUTXO, 0, len(utxos))
+	for i, u := range utxos {
+		if utxoOutpoint(u) == targetOutpoint {
+			target = &utxos[i]
+			continue
+		}
+		others = append(others, u)
+	}
+	if target == nil {
+		return nil, fmt.Errorf("utxo %s for address %s: %w", targetOutpoint, params.FromAddress, types.ErrNotFound)
+	}
+	if params.Offset >= uint64(target.Value) {
+		return nil, fmt.Errorf("offset %d out of range: utxo value %d", params.Offset, target.Value)
+	}
+	clean, err := c.excludeAssetUTXOs(ctx, others)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 前缀与补位: 补位选择满足粉尘限制的最小 UTXO
+	prefix := int64(params.Offset)
+	var pad *types.TxUTXO
+	if prefix > 0 && prefix < changeDust {
+		sort.Slice(clean, func(i, j int) bool { return clean[i].Value < clean[j].Value })
+		for i := range clean {
+			if prefix+clean[i].Value >= changeDust {
+				pad = &clean[i]
+				clean = append(clean[:i:i], clean[i+1:]...)
+				break
+			}
+		}
+		if pad == nil {
+			return nil, fmt.Errorf("%w: no utxo available to pad prefix of %d sats up to dust limit %d", types.ErrInsufficientFunds, prefix, changeDust)
+		}
+		prefix += pad.Value
+	}
+
+	// 3. 选币支付手续费
+	_, changeOutVsize := types.GetOutSize(changeType)
+	_, destOutVsize := types.GetOutSize(destType)
+	baseVsize := txOverheadVsize + destOutVsize + changeOutVsize
+	if prefix > 0 {
+		baseVsize += changeOutVsize
+	}
+	inVsize := types.GetInSize(fromType)
+	feeFor := func(inCount int) int64 {
+		return int64(math.Ceil(float64(baseVsize+inVsize*inCount) * params.FeeRate))
+	}
+
+	var selected []*types.TxUTXO
+	var inSats int64
+	if pad != nil {
+		selected = append(selected, pad)
+		inSats += pad.Value
+	}
+	selected = append(selected, target)
+	inSats += target.Value
+	outSats := prefix + postage
+	for i := 0; inSats < outSats+feeFor(len(selected)); i++ {
+		if i >= len(clean) {
+			return nil, fmt.Errorf("%w: have %d sats, need %d", types.ErrInsufficientFunds, inSats, outSats+feeFor(len(selected)))
+		}
+		selected = append(selected, &clean[i])
+		inSats += clean[i].Value
+	}
+	fee := feeFor(len(selected))
+
+	// 4. 组装交易
+	tx := wire.NewMsgTx(2)
+	for _, u := range selected {
+		h, err := chainhash.NewHashFromStr(u.OutPoint.Hash.String())
+		if err != nil {
+			return nil, fmt.Errorf("TxID(utxo.OutPoint.Hash) 解析失败: %v", err)
+		}
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Hash: *h, Index: u.OutPoint.Index},
+			Sequence:         wire.MaxTxInSequenceNum - 2,
+		})
+	}
+	changeScript, err := decoders.AddressToPkScript(changeAddr)
+	if err != nil {
+		return nil, fmt.Errorf("解析找零地址失败: %v", err)
+	}
+	if prefix > 0 {
+		tx.AddTxOut(wire.NewTxOut(prefix, changeScript))
+	}
+	destScript, err := decoders.AddressToPkScript(dest)
+	if err != nil {
+		return nil, fmt.Errorf("解析收款地址失败: %v", err)
+	}
+	targetVout := uint32(len(tx.TxOut))
+	tx.AddTxOut(wire.NewTxOut(postage, destScript))
+	// 找零低于粉尘限制时并入手续费
+	changeSats := inSats - outSats - fee
+	if changeSats >= changeDust {
+		tx.AddTxOut(wire.NewTxOut(changeSats, changeScript))
+	} else {
+		fee += changeSats
+	}
+
+	unsigned, err := c.MsgTxToPSBTV0(ctx, tx, &types.TxInputParams{FromAddress: []string{params.FromAddress}}, selected)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SatSplitResult{
+		PSBTBase64:  unsigned.PSBTBase64,
+		UnsignedTx:  unsigned.UnsignedTx,
+		TargetVout:  targetVout,
+		Fee:         fee,
+		PaddingUsed: pad != nil,
+	}, nil
+}
@@ -2,13 +2,13 @@ package tx
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/crazycloudcc/btcapis/internal/decoders"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
 	"github.com/crazycloudcc/btcapis/types"
 )
 
@@ -32,6 +32,13 @@ func (c *Client) createNormalTx(ctx context.Context, inputParams *types.TxInputP
 		return nil, nil, err
 	}
 
+	// 3.1 默认排除带有铭文/rune 的 UTXO
+	if !inputParams.AllowAssetUTXOs {
+		if arrUTXOs, err = c.excludeAssetUTXOs(ctx, arrUTXOs); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// 4. 筛选 UTXO
 	selectedUTXOs, totalInputSats, err := selectUTXOs(arrUTXOs, totalOutAmountSats)
 	if err != nil {
@@ -44,8 +51,8 @@ func (c *Client) createNormalTx(ctx context.Context, inputParams *types.TxInputP
 
 	// 5.1 检查输入金额是否足够
 	if totalInputSats < totalOutAmountSats+estFee {
-		fmt.Printf("totalInputSats=%d, totalOutAmountSats=%d, estFee=%d\n", totalInputSats, totalOutAmountSats, estFee)
-		return nil, nil, errors.New("insufficient funds after fee calculation")
+		logger.Debug("totalInputSats=%d, totalOutAmountSats=%d, estFee=%d", totalInputSats, totalOutAmountSats, estFee)
+		return nil, nil, fmt.Errorf("%w after fee calculation", types.ErrInsufficientFunds)
 	}
 
 	// 6. 构建输入
@@ -134,7 +141,7 @@ func selectUTXOs(utxos []types.TxUTXO, targetSats int64) ([]*types.TxUTXO, int64
 
 	// 余额不足
 	if total < targetSats {
-		return nil, 0, errors.New("insufficient funds")
+		return nil, 0, types.ErrInsufficientFunds
 	}
 
 	return selected, total, nil
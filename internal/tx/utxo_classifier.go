@@ -0,0 +1,292 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/crazycloudcc/btcapis/internal/adapters/ordserver"
+	"github.com/crazycloudcc/btcapis/internal/runes"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// UTXOClassifier 识别 UTXO 上携带的铭文与 rune
+type UTXOClassifier interface {
+	// 返回携带资产的 UTXO, key 为 txid:vout; 没有资产的 UTXO 不出现在结果中
+	ClassifyUTXOs(ctx context.Context, outpoints []string) (map[string]*types.UTXOAssets, error)
+}
+
+// RuneInfoSource 查询 rune 发行信息, 构建 rune 交易时使用(分类器可以同时实现)
+type RuneInfoSource interface {
+	// 不存在时返回 nil
+	RuneInfo(ctx context.Context, runeID string) (*types.RuneInfo, error)
+}
+
+// InscriptionLocator 本地铭文索引: 查询输出上的铭文ID, 实现见 ordinals.InscriptionIndex.
+// 索引不可用时需要返回错误, 不能返回空结果
+type InscriptionLocator interface {
+	InscriptionsAt(ctx context.Context, outpoint string) ([]string, error)
+}
+
+// 设置 UTXO 分类器, 设置后普通转账默认排除带有铭文/rune 的 UTXO
+func (c *Client) SetUTXOClassifier(cl UTXOClassifier) {
+	c.utxoClassifier = cl
+}
+
+// 查询 UTXO 携带的资产, 未设置分类器时返回空结果
+func (c *Client) ClassifyUTXOs(ctx context.Context, outpoints []string) (map[string]*types.UTXOAssets, error) {
+	if c.utxoClassifier == nil || len(outpoints) == 0 {
+		return map[string]*types.UTXOAssets{}, nil
+	}
+	return c.utxoClassifier.ClassifyUTXOs(ctx, outpoints)
+}
+
+// 排除带有资产的 UTXO. 分类失败时返回错误而不是放行, 避免误花费.
+func (c *Client) excludeAssetUTXOs(ctx context.Context, utxos []types.TxUTXO) ([]types.TxUTXO, error) {
+	if c.utxoClassifier == nil {
+		return utxos, nil
+	}
+	outpoints := make([]string, len(utxos))
+	for i, u := range utxos {
+		outpoints[i] = utxoOutpoint(u)
+	}
+	assets, err := c.utxoClassifier.ClassifyUTXOs(ctx, outpoints)
+	if err != nil {
+		return nil, fmt.Errorf("utxo classifier failed: %w", err)
+	}
+	kept := make([]types.TxUTXO, 0, len(utxos))
+	for i, u := range utxos {
+		if a := assets[outpoints[i]]; a.HasAssets() {
+			logger.Debug("skip utxo %s: inscriptions=%d runes=%d", outpoints[i], len(a.Inscriptions), len(a.Runes))
+			continue
+		}
+		kept = append(kept, u)
+	}
+	return kept, nil
+}
+
+// 检查 PSBT 的输入是否携带资产, 按输入顺序返回携带资产的输入; 存在时打印警告
+func (c *Client) CheckPSBTAssets(ctx context.Context, psbtBase64 string) ([]*types.UTXOAssets, error) {
+	raw, err := base64.StdEncoding.DecodeString(psbtBase64)
+	if err != nil {
+		return nil, fmt.Errorf("decode psbt base64: %w", err)
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(raw), false)
+	if err != nil {
+		return nil, fmt.Errorf("parse psbt: %w", err)
+	}
+	outpoints := make([]string, len(packet.UnsignedTx.TxIn))
+	for i, in := range packet.UnsignedTx.TxIn {
+		outpoints[i] = in.PreviousOutPoint.String()
+	}
+	assets, err := c.ClassifyUTXOs(ctx, outpoints)
+	if err != nil {
+		return nil, err
+	}
+	var out []*types.UTXOAssets
+	for i, op := range outpoints {
+		if a := assets[op]; a.HasAssets() {
+			logger.Warn("warning: psbt input %d (%s) carries inscriptions=%v runes=%v", i, op, a.Inscriptions, a.Runes)
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func utxoOutpoint(u types.TxUTXO) string {
+	// 地址查询得到的 UTXO, Hash 中保存的是展示顺序的 txid
+	return fmt.Sprintf("%s:%d", u.OutPoint.Hash.String(), u.OutPoint.Index)
+}
+
+// OrdServerClassifier 使用 ord server 的 /output 接口识别资产
+type OrdServerClassifier struct {
+	client *ordserver.Client
+}
+
+func NewOrdServerClassifier(client *ordserver.Client) *OrdServerClassifier {
+	return &OrdServerClassifier{client: client}
+}
+
+func (o *OrdServerClassifier) ClassifyUTXOs(ctx context.Context, outpoints []string) (map[string]*types.UTXOAssets, error) {
+	out := make(map[string]*types.UTXOAssets)
+	for _, op := range outpoints {
+		dto, err := o.client.OutputGet(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		// ord server 尚未索引到该输出时, inscriptions/runes 为空不代表没有资产
+		if !dto.Indexed {
+			return nil, fmt.Errorf("%w: ord server has not indexed output %s", types.ErrBackendUnavailable, op)
+		}
+		bals, err := dto.ParseRunes()
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", op, err)
+		}
+		a := &types.UTXOAssets{Outpoint: op, Inscriptions: dto.Inscriptions}
+		for _, b := range bals {
+			a.Runes = append(a.Runes, types.UTXORuneBal{Rune: b.SpacedRune, Amount: b.Amount.String()})
+		}
+		if a.HasAssets() {
+			out[op] = a
+		}
+	}
+	return out, nil
+}
+
+func (o *OrdServerClassifier) RuneInfo(ctx context.Context, runeID string) (*types.RuneInfo, error) {
+	dto, err := o.client.RuneGet(ctx, runeID)
+	if err != nil {
+		return nil, err
+	}
+	e := dto.Entry
+	info := &types.RuneInfo{
+		ID:           dto.ID,
+		SpacedRune:   e.SpacedRune,
+		Divisibility: e.Divisibility,
+		Block:        e.Block,
+	}
+	if e.Symbol != nil {
+		info.Symbol = *e.Symbol
+	}
+	if info.Mints, err = strconv.ParseInt(e.Mints.String(), 10, 64); err != nil {
+		return nil, fmt.Errorf("rune %s: invalid mints %q", runeID, e.Mints)
+	}
+	if t := e.Terms; t != nil {
+		info.Terms = &types.RuneTerms{
+			HeightStart: t.Height[0],
+			HeightEnd:   t.Height[1],
+			OffsetStart: t.Offset[0],
+			OffsetEnd:   t.Offset[1],
+		}
+		if info.Terms.Amount, err = parseOptionalBig(t.Amount); err != nil {
+			return nil, fmt.Errorf("rune %s: %w", runeID, err)
+		}
+		if info.Terms.Cap, err = parseOptionalBig(t.Cap); err != nil {
+			return nil, fmt.Errorf("rune %s: %w", runeID, err)
+		}
+	}
+	return info, nil
+}
+
+func parseOptionalBig(n json.Number) (*big.Int, error) {
+	if n == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(n.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", n)
+	}
+	return v, nil
+}
+
+// LocalIndexClassifier 使用本地索引识别资产, 两个来源都可以为 nil
+type LocalIndexClassifier struct {
+	runes        runes.Store
+	inscriptions InscriptionLocator
+}
+
+func NewLocalIndexClassifier(runesStore runes.Store, inscriptions InscriptionLocator) *LocalIndexClassifier {
+	return &LocalIndexClassifier{runes: runesStore, inscriptions: inscriptions}
+}
+
+func (l *LocalIndexClassifier) ClassifyUTXOs(ctx context.Context, outpoints []string) (map[string]*types.UTXOAssets, error) {
+	out := make(map[string]*types.UTXOAssets)
+	for _, op := range outpoints {
+		a := &types.UTXOAssets{Outpoint: op}
+		if l.runes != nil {
+			bals, err := l.runes.GetOutpointBalances(ctx, op)
+			if err != nil {
+				return nil, err
+			}
+			for _, b := range bals {
+				name := b.RuneID
+				if st, err := l.runes.GetRune(ctx, b.RuneID); err == nil && st != nil {
+					name = st.Rune.SpacedName
+				}
+				a.Runes = append(a.Runes, types.UTXORuneBal{Rune: name, RuneID: b.RuneID, Amount: b.Amount.String()})
+			}
+		}
+		if l.inscriptions != nil {
+			ids, err := l.inscriptions.InscriptionsAt(ctx, op)
+			if err != nil {
+				return nil, err
+			}
+			a.Inscriptions = ids
+		}
+		if a.HasAssets() {
+			out[op] = a
+		}
+	}
+	return out, nil
+}
+
+func (l *LocalIndexClassifier) RuneInfo(ctx context.Context, runeID string) (*types.RuneInfo, error) {
+	if l.runes == nil {
+		return nil, fmt.Errorf("%w: local runes index not configured", types.ErrBackendUnavailable)
+	}
+	st, err := l.runes.GetRune(ctx, runeID)
+	if err != nil || st == nil {
+		return nil, err
+	}
+	return &types.RuneInfo{
+		ID:           st.Rune.ID,
+		SpacedRune:   st.Rune.SpacedName,
+		Divisibility: uint8(st.Rune.Divisibility),
+		Symbol:       st.Rune.Symbol,
+		Block:        uint64(st.Rune.BlockHeight),
+		Terms:        st.Terms,
+		Mints:        st.Mints,
+	}, nil
+}
+
+// MultiClassifier 合并多个分类器的结果, 任一分类器失败即失败
+type MultiClassifier []UTXOClassifier
+
+func (m MultiClassifier) ClassifyUTXOs(ctx context.Context, outpoints []string) (map[string]*types.UTXOAssets, error) {
+	out := make(map[string]*types.UTXOAssets)
+	for _, cl := range m {
+		res, err := cl.ClassifyUTXOs(ctx, outpoints)
+		if err != nil {
+			return nil, err
+		}
+		for op, a := range res {
+			cur, ok := out[op]
+			if !ok {
+				out[op] = a
+				continue
+			}
+			for _, id := range a.Inscriptions {
+				if !slices.Contains(cur.Inscriptions, id) {
+					cur.Inscriptions = append(cur.Inscriptions, id)
+				}
+			}
+			cur.Runes = append(cur.Runes, a.Runes...)
+		}
+	}
+	return out, nil
+}
+
+// RuneInfo 依次查询实现了 RuneInfoSource 的分类器, 返回第一个结果
+func (m MultiClassifier) RuneInfo(ctx context.Context, runeID string) (*types.RuneInfo, error) {
+	for _, cl := range m {
+		src, ok := cl.(RuneInfoSource)
+		if !ok {
+			continue
+		}
+		info, err := src.RuneInfo(ctx, runeID)
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			return info, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,382 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/bitcoindrpc"
+	"github.com/crazycloudcc/btcapis/pkg/logger"
+	"github.com/crazycloudcc/btcapis/types"
+)
+
+// Create 创建描述符钱包, 创建后自动加载
+func (c *Client) Create(ctx context.Context, params *types.NodeWalletCreateParams) error {
+	if params == nil {
+		params = &types.NodeWalletCreateParams{}
+	}
+	dto := &bitcoindrpc.WalletCreateDTO{
+		WalletName:         c.name,
+		DisablePrivateKeys: params.WatchOnly,
+		Blank:              params.Blank,
+		Passphrase:         params.Passphrase,
+		AvoidReuse:         params.AvoidReuse,
+		Descriptors:        true,
+	}
+	if params.LoadOnStartup {
+		dto.LoadOnStartup = &params.LoadOnStartup
+	}
+	res, err := c.node.WalletCreate(ctx, dto)
+	if err != nil {
+		return err
+	}
+	printWarnings("createwallet", res)
+	return nil
+}
+
+// Load 加载钱包
+func (c *Client) Load(ctx context.Context) error {
+	res, err := c.node.WalletLoad(ctx, c.name)
+	if err != nil {
+		return err
+	}
+	printWarnings("loadwallet", res)
+	return nil
+}
+
+// Unload 卸载钱包
+func (c *Client) Unload(ctx context.Context) error {
+	res, err := c.node.WalletUnload(ctx, c.name)
+	if err != nil {
+		return err
+	}
+	printWarnings("unloadwallet", res)
+	return nil
+}
+
+func printWarnings(method string, res *bitcoindrpc.WalletLoadResultDTO) {
+	if res == nil {
+		return
+	}
+	for _, w := range res.Warnings {
+		logger.Warn("bitcoind %s: %s", method, w)
+	}
+	if res.Warning != "" {
+		logger.Warn("bitcoind %s: %s", method, res.Warning)
+	}
+}
+
+// ImportDescriptors 导入描述符, 缺少校验和时自动补全; 结果与 descs 一一对应.
+// 导入会按 Timestamp 重新扫描区块, 可能耗时较长.
+func (c *Client) ImportDescriptors(ctx context.Context, descs []types.NodeWalletDescriptor) ([]types.NodeWalletImportResult, error) {
+	reqs := make([]bitcoindrpc.ImportDescriptorDTO, 0, len(descs))
+	for _, d := range descs {
+		desc := d.Desc
+		if !strings.Contains(desc, "#") {
+			info, err := c.node.WalletGetDescriptorInfo(ctx, desc)
+			if err != nil {
+				return nil, fmt.Errorf("descriptor %s: %w", desc, err)
+			}
+			// 规范化描述符会去掉私钥, 这里只追加校验和
+			desc += "#" + info.Checksum
+		}
+		req := bitcoindrpc.ImportDescriptorDTO{
+			Desc:      desc,
+			Active:    d.Active,
+			Internal:  d.Internal,
+			Label:     d.Label,
+			Timestamp: d.Timestamp,
+		}
+		if d.Timestamp < 0 {
+			req.Timestamp = "now"
+		}
+		if d.RangeEnd > 0 {
+			req.Range = []int{0, d.RangeEnd}
+		}
+		if d.NextIndex > 0 {
+			next := d.NextIndex
+			req.NextIndex = &next
+		}
+		reqs = append(reqs, req)
+	}
+
+	dtos, err := c.rpc.WalletImportDescriptors(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]types.NodeWalletImportResult, 0, len(dtos))
+	for _, dto := range dtos {
+		r := types.NodeWalletImportResult{Success: dto.Success, Warnings: dto.Warnings}
+		if dto.Error != nil {
+			r.Error = dto.Error.Message
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// ListDescriptors 钱包中的描述符, private 为 true 时包含私钥(只读钱包不支持)
+func (c *Client) ListDescriptors(ctx context.Context, private bool) ([]types.NodeWalletDescriptor, error) {
+	dto, err := c.rpc.WalletListDescriptors(ctx, private)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]types.NodeWalletDescriptor, 0, len(dto.Descriptors))
+	for _, d := range dto.Descriptors {
+		desc := types.NodeWalletDescriptor{
+			Desc:      d.Desc,
+			Timestamp: d.Timestamp,
+			Active:    d.Active,
+			Internal:  d.Internal != nil && *d.Internal,
+		}
+		if len(d.Range) == 2 {
+			desc.RangeEnd = d.Range[1]
+		}
+		if d.NextIndex != nil {
+			desc.NextIndex = *d.NextIndex
+		} else if d.Next != nil {
+			desc.NextIndex = *d.Next
+		}
+		ret = append(ret, desc)
+	}
+	return ret, nil
+}
+
+// GetNewAddress 从 active 描述符派生新地址, addrType: legacy/p2sh-segwit/bech32/bech32m
+func (c *Client) GetNewAddress(ctx context.Context, label, addrType string) (string, error) {
+	return c.rpc.WalletGetNewAddress(ctx, label, addrType)
+}
+
+// ListUnspent 钱包未花费输出(含未确认), addresses 为空时返回全部
+func (c *Client) ListUnspent(ctx context.Context, addresses ...string) ([]types.TxUTXO, error) {
+	dtos, err := c.rpc.WalletListUnspent(ctx, 0, 9999999, addresses, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(dtos) == 0 {
+		return []types.TxUTXO{}, nil
+	}
+	// listunspent 只有确认数, 按最新高度换算区块高度
+	tip, err := c.node.ChainGetBlockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	utxos := make([]types.TxUTXO, 0, len(dtos))
+	for _, dto := range dtos {
+		txidBytes, err := hex.DecodeString(dto.TxID)
+		if err != nil || len(txidBytes) != 32 {
+			return nil, fmt.Errorf("listunspent: invalid txid %s", dto.TxID)
+		}
+		pkScript, _ := hex.DecodeString(dto.ScriptPubKey)
+		u := types.TxUTXO{
+			OutPoint: types.TxOutPoint{Hash: types.Hash32(txidBytes), Index: dto.Vout},
+			Value:    btcToSats(dto.AmountBTC),
+			PkScript: pkScript,
+			Address:  dto.Address,
+		}
+		if dto.Confirmations > 0 {
+			u.Height = uint32(int64(tip) - dto.Confirmations + 1)
+		}
+		utxos = append(utxos, u)
+	}
+	return utxos, nil
+}
+
+// ListTransactions 钱包交易记录, 按时间倒序分页; label 为空时返回全部
+func (c *Client) ListTransactions(ctx context.Context, label string, count, skip int) ([]types.NodeWalletTx, error) {
+	if label == "" {
+		label = "*"
+	}
+	dtos, err := c.rpc.WalletListTransactions(ctx, label, count, skip, true)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]types.NodeWalletTx, 0, len(dtos))
+	for i := len(dtos) - 1; i >= 0; i-- {
+		dto := dtos[i]
+		ret = append(ret, types.NodeWalletTx{
+			TxID:          dto.TxID,
+			Vout:          dto.Vout,
+			Address:       dto.Address,
+			Category:      dto.Category,
+			Amount:        btcToSats(dto.AmountBTC),
+			Fee:           -btcToSats(dto.FeeBTC),
+			Label:         dto.Label,
+			Confirmations: dto.Confirmations,
+			BlockHash:     dto.BlockHash,
+			BlockHeight:   dto.BlockHeight,
+			BlockTime:     dto.BlockTime,
+			Time:          dto.TimeReceived,
+			Replaceable:   dto.Replaceable == "yes",
+			Abandoned:     dto.Abandoned,
+			ReplacedBy:    dto.ReplacedBy,
+		})
+	}
+	return ret, nil
+}
+
+// IsWatching 地址是否属于钱包(含只读描述符)
+func (c *Client) IsWatching(ctx context.Context, addr string) (bool, error) {
+	info, err := c.rpc.AddressGetInfo(ctx, addr)
+	if err != nil {
+		return false, err
+	}
+	return info.IsMine || info.IsWatchOnly, nil
+}
+
+// GetAddressBalance 地址的确认余额和未确认余额(sats)
+func (c *Client) GetAddressBalance(ctx context.Context, addr string) (float64, float64, error) {
+	dtos, err := c.rpc.WalletListUnspent(ctx, 0, 9999999, []string{addr}, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	var confirmed, unconfirmed int64
+	for _, dto := range dtos {
+		if dto.Confirmations > 0 {
+			confirmed += btcToSats(dto.AmountBTC)
+		} else {
+			unconfirmed += btcToSats(dto.AmountBTC)
+		}
+	}
+	return float64(confirmed), float64(unconfirmed), nil
+}
+
+// ProcessPsbt 补全 PSBT 的 UTXO 与派生路径; sign 为 true 时使用钱包私钥签名并尝试完成交易
+func (c *Client) ProcessPsbt(ctx context.Context, psbtBase64 string, sign bool) (*types.NodeWalletPsbt, error) {
+	dto, err := c.rpc.WalletProcessPsbt(ctx, psbtBase64, sign, "", true)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NodeWalletPsbt{PSBTBase64: dto.Psbt, ChangePos: -1, Complete: dto.Complete, Hex: dto.Hex}, nil
+}
+
+// CreateFundedPsbt 使用钱包选币创建 PSBT, 只读钱包返回的 PSBT 交由外部签名
+func (c *Client) CreateFundedPsbt(ctx context.Context, params *types.NodeWalletFundParams) (*types.NodeWalletPsbt, error) {
+	outputs, inputs, err := fundOutputsInputs(params)
+	if err != nil {
+		return nil, err
+	}
+	options := &bitcoindrpc.WalletFundedPsbtOptionsDTO{
+		ChangeAddress:          params.ChangeAddress,
+		IncludeWatching:        true,
+		LockUnspents:           params.LockUnspents,
+		FeeRate:                params.FeeRate,
+		SubtractFeeFromOutputs: params.SubtractFeeFromOutputs,
+		Replaceable:            &params.Replaceable,
+	}
+	if params.OnlyInputs {
+		addInputs := false
+		options.AddInputs = &addInputs
+	}
+	dto, err := c.rpc.WalletCreateFundedPsbt(ctx, inputs, outputs, params.Locktime, options)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NodeWalletPsbt{PSBTBase64: dto.Psbt, Fee: btcToSats(dto.FeeBTC), ChangePos: dto.ChangePos}, nil
+}
+
+// Send 使用钱包选币, 签名并广播; 钱包无法完成签名(只读钱包)时返回待签名 PSBT
+func (c *Client) Send(ctx context.Context, params *types.NodeWalletFundParams) (*types.NodeWalletSendResult, error) {
+	outputs, inputs, err := fundOutputsInputs(params)
+	if err != nil {
+		return nil, err
+	}
+	options := &bitcoindrpc.SendOptionsDTO{
+		ChangeAddress:          params.ChangeAddress,
+		IncludeWatching:        true,
+		Inputs:                 inputs,
+		LockUnspents:           params.LockUnspents,
+		Locktime:               params.Locktime,
+		SubtractFeeFromOutputs: params.SubtractFeeFromOutputs,
+		Replaceable:            &params.Replaceable,
+	}
+	if params.OnlyInputs {
+		addInputs := false
+		options.AddInputs = &addInputs
+	}
+	dto, err := c.rpc.WalletSend(ctx, outputs, params.FeeRate, options)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NodeWalletSendResult{Complete: dto.Complete, TxID: dto.TxID, PSBTBase64: dto.Psbt}, nil
+}
+
+// BumpFee 提高未确认交易的手续费(RBF), feeRate(sat/vB) 为 0 时使用节点估算; 只读钱包返回待签名 PSBT
+func (c *Client) BumpFee(ctx context.Context, txid string, feeRate float64, watchOnly bool) (*types.NodeWalletBumpFee, error) {
+	dto, err := c.rpc.WalletBumpFee(ctx, txid, &bitcoindrpc.BumpFeeOptionsDTO{FeeRate: feeRate}, watchOnly)
+	if err != nil {
+		return nil, err
+	}
+	if len(dto.Errors) > 0 {
+		return nil, fmt.Errorf("bumpfee %s: %s", txid, strings.Join(dto.Errors, "; "))
+	}
+	return &types.NodeWalletBumpFee{
+		TxID:       dto.TxID,
+		PSBTBase64: dto.Psbt,
+		OrigFee:    btcToSats(dto.OrigFeeBTC),
+		Fee:        btcToSats(dto.FeeBTC),
+	}, nil
+}
+
+// LockUnspent 锁定输出(txid:vout), 避免被钱包选币选中; 锁定在节点重启后失效
+func (c *Client) LockUnspent(ctx context.Context, outpoints ...string) error {
+	return c.lockUnspent(ctx, false, outpoints)
+}
+
+// UnlockUnspent 解锁输出, outpoints 为空时解锁全部
+func (c *Client) UnlockUnspent(ctx context.Context, outpoints ...string) error {
+	return c.lockUnspent(ctx, true, outpoints)
+}
+
+func (c *Client) lockUnspent(ctx context.Context, unlock bool, outpoints []string) error {
+	dtos, err := parseOutpoints(outpoints)
+	if err != nil {
+		return err
+	}
+	ok, err := c.rpc.WalletLockUnspent(ctx, unlock, dtos)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("lockunspent: failed")
+	}
+	return nil
+}
+
+func fundOutputsInputs(params *types.NodeWalletFundParams) ([]map[string]any, []bitcoindrpc.OutpointDTO, error) {
+	if params == nil || len(params.Outputs) == 0 {
+		return nil, nil, errors.New("wallet: no outputs")
+	}
+	outputs := make([]map[string]any, 0, len(params.Outputs))
+	for _, o := range params.Outputs {
+		if o.Data != "" {
+			outputs = append(outputs, map[string]any{"data": o.Data})
+			continue
+		}
+		if o.Address == "" || o.Value <= 0 {
+			return nil, nil, fmt.Errorf("wallet: invalid output %s %d", o.Address, o.Value)
+		}
+		outputs = append(outputs, map[string]any{o.Address: satsToBTC(o.Value)})
+	}
+	inputs, err := parseOutpoints(params.Inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return outputs, inputs, nil
+}
+
+func parseOutpoints(outpoints []string) ([]bitcoindrpc.OutpointDTO, error) {
+	dtos := make([]bitcoindrpc.OutpointDTO, 0, len(outpoints))
+	for _, op := range outpoints {
+		txid, voutStr, ok := strings.Cut(op, ":")
+		vout, err := strconv.ParseUint(voutStr, 10, 32)
+		if !ok || err != nil || len(txid) != 64 {
+			return nil, fmt.Errorf("invalid outpoint %s", op)
+		}
+		dtos = append(dtos, bitcoindrpc.OutpointDTO{TxID: txid, Vout: uint32(vout)})
+	}
+	return dtos, nil
+}
@@ -0,0 +1,44 @@
+// Package wallet bitcoind 钱包操作, 请求发送到 /wallet/<name>.
+// 只读描述符钱包可以作为地址余额/UTXO 的数据来源.
+package wallet
+
+import (
+	"context"
+	"math"
+
+	"github.com/crazycloudcc/btcapis/internal/adapters/bitcoindrpc"
+)
+
+type Client struct {
+	name string
+	node *bitcoindrpc.Client // 节点级接口(创建/加载/卸载)
+	rpc  *bitcoindrpc.Client // 钱包级接口
+}
+
+func New(bitcoindrpcClient *bitcoindrpc.Client, name string) *Client {
+	return &Client{
+		name: name,
+		node: bitcoindrpcClient,
+		rpc:  bitcoindrpcClient.Wallet(name),
+	}
+}
+
+// Name 钱包名称
+func (c *Client) Name() string {
+	return c.name
+}
+
+// List 节点已加载的钱包
+func List(ctx context.Context, bitcoindrpcClient *bitcoindrpc.Client) ([]string, error) {
+	return bitcoindrpcClient.WalletList(ctx)
+}
+
+// bitcoind 金额(BTC) 转换为 sats
+func btcToSats(v float64) int64 {
+	return int64(math.Round(v * 1e8))
+}
+
+// sats 转换为 bitcoind 金额(BTC)
+func satsToBTC(v int64) float64 {
+	return float64(v) / 1e8
+}
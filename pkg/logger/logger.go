@@ -1,8 +1,12 @@
+// Package logger 库内日志. 默认静默, 不向 stdout 输出; 通过 SetLogger 注入 *slog.Logger 或其他实现,
+// 输出前会屏蔽私钥(WIF/xprv), 助记词与 PSBT 等敏感内容.
 package logger
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,12 +19,38 @@ const (
 	ErrorLevel = "error"
 )
 
+// Logger 日志接口, 与 log/slog 一致, *slog.Logger 可直接使用; args 为 key/value 交替的属性
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
 var (
-	// Log 全局日志实例
+	// Log InitLogger 创建的 logrus 实例, 保留用于兼容; 库内日志统一通过 SetLogger 设置的 Logger 输出
 	Log *logrus.Logger
 )
 
-// InitLogger 初始化日志系统
+type holder struct{ l Logger }
+
+var current atomic.Pointer[holder]
+
+// SetLogger 设置库内日志输出(全局), nil 为静默
+func SetLogger(l Logger) {
+	if l == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&holder{l})
+}
+
+// Enabled 是否设置了日志输出, 用于跳过构造代价较大的日志
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// InitLogger 初始化日志系统: 使用 logrus 按 level 输出到 stdout
 func InitLogger(level string) {
 	Log = logrus.New()
 	Log.SetOutput(os.Stdout)
@@ -42,24 +72,56 @@ func InitLogger(level string) {
 	default:
 		Log.SetLevel(logrus.InfoLevel)
 	}
+	SetLogger(logrusLogger{Log})
 }
 
 // Debug 调试日志
 func Debug(format string, args ...interface{}) {
-	Log.Debugf(format, args...)
+	if h := current.Load(); h != nil {
+		h.l.Debug(message(format, args))
+	}
 }
 
 // Info 信息日志
 func Info(format string, args ...interface{}) {
-	Log.Infof(format, args...)
+	if h := current.Load(); h != nil {
+		h.l.Info(message(format, args))
+	}
 }
 
 // Warn 警告日志
 func Warn(format string, args ...interface{}) {
-	Log.Warnf(format, args...)
+	if h := current.Load(); h != nil {
+		h.l.Warn(message(format, args))
+	}
 }
 
 // Error 错误日志
 func Error(format string, args ...interface{}) {
-	Log.Errorf(format, args...)
+	if h := current.Load(); h != nil {
+		h.l.Error(message(format, args))
+	}
+}
+
+func message(format string, args []interface{}) string {
+	if len(args) > 0 {
+		format = fmt.Sprintf(format, args...)
+	}
+	return Redact(format)
+}
+
+// logrus 适配 Logger, key/value 属性转为 Fields
+type logrusLogger struct{ l *logrus.Logger }
+
+func (g logrusLogger) Debug(msg string, args ...any) { g.entry(args).Debug(msg) }
+func (g logrusLogger) Info(msg string, args ...any)  { g.entry(args).Info(msg) }
+func (g logrusLogger) Warn(msg string, args ...any)  { g.entry(args).Warn(msg) }
+func (g logrusLogger) Error(msg string, args ...any) { g.entry(args).Error(msg) }
+
+func (g logrusLogger) entry(args []any) *logrus.Entry {
+	fields := logrus.Fields{}
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[fmt.Sprint(args[i])] = args[i+1]
+	}
+	return g.l.WithFields(fields)
 }
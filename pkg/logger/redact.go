@@ -0,0 +1,31 @@
+package logger
+
+import "regexp"
+
+const redacted = "[REDACTED]"
+
+const base58 = `[1-9A-HJ-NP-Za-km-z]`
+
+var redactions = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// 扩展私钥 xprv/tprv/yprv/zprv 等
+	{regexp.MustCompile(`\b[xyztuvYZUV]prv` + base58 + `{100,112}\b`), redacted},
+	// WIF 私钥: 主网 5/K/L, 测试网 9/c 开头, 51 或 52 位
+	{regexp.MustCompile(`\b[5KL9c]` + base58 + `{50,51}\b`), redacted},
+	// PSBT: base64(cHNidP) 与 hex(70736274ff)
+	{regexp.MustCompile(`cHNidP[A-Za-z0-9+/]*={0,2}`), "[REDACTED PSBT]"},
+	{regexp.MustCompile(`(?i)70736274ff[0-9a-f]*`), "[REDACTED PSBT]"},
+	// 助记词, 私钥, 密码等字段: "mnemonic": "...", Mnemonic:word word ..., privkey=...
+	{regexp.MustCompile(`("?(?i:mnemonic)"?\s*[:=]\s*)("[^"]*"|[a-z]+(?: [a-z]+){11,23})`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)("?(?:xprv|wif|priv(?:ate)?_?key|recovery_?key_?wif|secret|passphrase|password)"?\s*[:=]\s*)("[^"]*"|[^\s,}]+)`), "${1}" + redacted},
+}
+
+// Redact 屏蔽 s 中的私钥(WIF/xprv), 助记词, 密码与 PSBT
+func Redact(s string) string {
+	for _, r := range redactions {
+		s = r.re.ReplaceAllString(s, r.repl)
+	}
+	return s
+}
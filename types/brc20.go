@@ -0,0 +1,80 @@
+package types
+
+import "github.com/shopspring/decimal"
+
+// BRC20 操作类型
+const (
+	BRC20OpDeploy   = "deploy"
+	BRC20OpMint     = "mint"
+	BRC20OpTransfer = "transfer"
+)
+
+// BRC20Operation 铭文内容解析出的 BRC-20 操作(已通过格式校验, 数值仍为原始字符串)
+// 数值字段需要结合部署时的 dec 才能换算, 由状态机处理.
+type BRC20Operation struct {
+	P        string `json:"p"`                   // 固定为 brc-20
+	Op       string `json:"op"`                  // deploy / mint / transfer
+	Tick     string `json:"tick"`                // 代币名称(原样保留大小写, 比较时不区分大小写)
+	Max      string `json:"max,omitempty"`       // deploy: 最大供给
+	Lim      string `json:"lim,omitempty"`       // deploy: 单次 mint 上限, 默认等于 max
+	Dec      string `json:"dec,omitempty"`       // deploy: 小数位, 默认 18
+	SelfMint bool   `json:"self_mint,omitempty"` // deploy: 5 字节 tick 必须为 true, 只有子铭文可以 mint
+	Amt      string `json:"amt,omitempty"`       // mint / transfer: 数量
+}
+
+// BRC20Token 已部署的代币
+type BRC20Token struct {
+	Tick          string          `json:"tick"`           // 部署时的原始 tick
+	InscriptionID string          `json:"inscription_id"` // 部署铭文ID
+	Max           decimal.Decimal `json:"max"`            // 最大供给
+	Lim           decimal.Decimal `json:"lim"`            // 单次 mint 上限
+	Dec           int             `json:"dec"`            // 小数位
+	SelfMint      bool            `json:"self_mint"`      // 是否只允许部署铭文的子铭文 mint
+	Minted        decimal.Decimal `json:"minted"`         // 已铸造数量
+	Deployer      string          `json:"deployer"`       // 部署铭文的首个持有地址
+	DeployHeight  int             `json:"deploy_height"`  // 部署区块高度
+	DeployTxID    string          `json:"deploy_txid"`    // 部署交易ID
+}
+
+// BRC20Balance 地址在某个代币上的余额
+// Available 可以用于铭刻 transfer; Transferable 已被 transfer 铭文锁定, 等待铭文被转移.
+type BRC20Balance struct {
+	Tick         string          `json:"tick"`
+	Address      string          `json:"address"`
+	Available    decimal.Decimal `json:"available"`
+	Transferable decimal.Decimal `json:"transferable"`
+}
+
+// Overall 总余额
+func (b BRC20Balance) Overall() decimal.Decimal {
+	return b.Available.Add(b.Transferable)
+}
+
+// BRC20Transfer 已生效但尚未被转移的 transfer 铭文
+type BRC20Transfer struct {
+	InscriptionID string          `json:"inscription_id"`
+	Tick          string          `json:"tick"`
+	Amount        decimal.Decimal `json:"amount"`
+	From          string          `json:"from"`     // 铭刻时的持有地址
+	Location      string          `json:"location"` // 当前位置 txid:vout:offset
+}
+
+// BRC20 事件类型(BRC20Event.Type)
+const (
+	BRC20EventDeploy           = "deploy"
+	BRC20EventMint             = "mint"
+	BRC20EventInscribeTransfer = "inscribe-transfer" // available -> transferable
+	BRC20EventTransfer         = "transfer"          // 发送方 transferable -> 接收方 available
+)
+
+// BRC20Event 状态机产生的有效事件(无效操作不产生事件)
+type BRC20Event struct {
+	Type          string          `json:"type"`
+	Tick          string          `json:"tick"`
+	InscriptionID string          `json:"inscription_id"`
+	TxID          string          `json:"txid"`
+	BlockHeight   int             `json:"block_height"`
+	From          string          `json:"from,omitempty"`
+	To            string          `json:"to,omitempty"` // transfer 转入 OP_RETURN 时为空(销毁)
+	Amount        decimal.Decimal `json:"amount"`
+}
@@ -43,3 +43,37 @@ type ChainBlock struct {
 	Weight       int      `json:"weight"`       // 权重
 	Tx           []string `json:"tx"`           // 交易
 }
+
+// Block 完整区块(含解码后的交易), 用于索引器逐块处理
+type Block struct {
+	Hash     string // 区块哈希
+	PrevHash string // 上一个区块哈希
+	Height   int    // 高度(原始区块数据不包含高度, 由调用方填充)
+	Time     int64  // 区块时间(Unix 秒)
+	Txs      []*Tx  // 交易, 按区块内顺序
+}
+
+// NodeEventType bitcoind ZMQ 推送事件类型
+type NodeEventType string
+
+const (
+	NodeEventTx                NodeEventType = "tx"                 // rawtx: 交易进入内存池或随区块确认
+	NodeEventTxHash            NodeEventType = "tx_hash"            // hashtx
+	NodeEventBlock             NodeEventType = "block"              // rawblock: 新区块
+	NodeEventBlockHash         NodeEventType = "block_hash"         // hashblock
+	NodeEventBlockConnected    NodeEventType = "block_connected"    // sequence C
+	NodeEventBlockDisconnected NodeEventType = "block_disconnected" // sequence D: 区块因分叉被回滚
+	NodeEventMempoolAdd        NodeEventType = "mempool_add"        // sequence A
+	NodeEventMempoolRemove     NodeEventType = "mempool_remove"     // sequence R: 非打包原因移出内存池(替换, 过期, 冲突等)
+)
+
+// NodeEvent bitcoind ZMQ 推送事件, 按类型填充对应字段
+type NodeEvent struct {
+	Type       NodeEventType `json:"type"`
+	Hash       string        `json:"hash,omitempty"`        // 区块哈希或 txid
+	Tx         *Tx           `json:"tx,omitempty"`          // tx
+	Block      *Block        `json:"block,omitempty"`       // block, 高度为 0
+	MempoolSeq uint64        `json:"mempool_seq,omitempty"` // mempool_add/mempool_remove 的内存池序号, 可与 getrawmempool 结果对齐
+	Seq        uint32        `json:"seq"`                   // 发布端同一主题的消息序号
+	Missed     uint32        `json:"missed,omitempty"`      // 与上一条同主题消息之间丢失的消息数, 大于 0 时应重新同步
+}
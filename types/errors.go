@@ -0,0 +1,47 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 错误分类, 使用 errors.Is 判断; 各数据源的错误(bitcoind RPC 错误码, HTTP 状态码, ElectrumX 错误)都会映射到这些分类
+var (
+	ErrNotFound           = errors.New("btcapis: not found")                    // 交易/区块/UTXO/钱包等不存在
+	ErrInsufficientFunds  = errors.New("btcapis: insufficient funds")           // 余额不足以支付输出与手续费
+	ErrBackendUnavailable = errors.New("btcapis: backend unavailable")          // 数据源未配置, 网络故障, 熔断, 节点启动中/同步中
+	ErrRateLimited        = errors.New("btcapis: rate limited")                 // 数据源返回 429, 或本地限流等待超时
+	ErrInvalidRequest     = errors.New("btcapis: invalid request")              // 参数错误(无效地址, 无效 txid 等)
+	ErrTxRejected         = errors.New("btcapis: transaction rejected by node") // 交易被拒绝, 详情见 *ErrRejected
+)
+
+// ErrRejected 交易被节点/内存池拒绝, 例如 "min relay fee not met", "bad-txns-inputs-missingorspent";
+// 使用 errors.As 获取, errors.Is(err, ErrTxRejected) 同样成立
+type ErrRejected struct {
+	Code   int    // bitcoind RPC 错误码: -25 输入无效或已花费, -26 违反内存池规则, -27 已在链上; 未知时为 0
+	Reason string // 节点返回的拒绝原因
+}
+
+func (e *ErrRejected) Error() string {
+	return fmt.Sprintf("transaction rejected (%d): %s", e.Code, e.Reason)
+}
+
+func (e *ErrRejected) Is(target error) bool {
+	return target == ErrTxRejected
+}
+
+// BackendError 数据源返回的错误, Kind 为对应的错误分类(无法分类时为 nil)
+type BackendError struct {
+	Backend string // bitcoind, mempool.space, esplora, electrumx, ord
+	Code    int    // RPC 错误码或 HTTP 状态码
+	Message string
+	Kind    error
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("%s error %d: %s", e.Backend, e.Code, e.Message)
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Kind
+}
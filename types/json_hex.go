@@ -31,6 +31,8 @@ type txInJSON struct {
 	Sequence  uint32   `json:"Sequence"`
 	ScriptSig string   `json:"ScriptSig"` // hex
 	Witness   []string `json:"Witness"`   // hex
+
+	Tapscript *TapscriptInfo `json:"Tapscript,omitempty"`
 }
 
 type txOutJSON struct {
@@ -49,6 +51,7 @@ func (in TxIn) MarshalJSON() ([]byte, error) {
 		Sequence:  in.Sequence,
 		ScriptSig: hexOf(in.ScriptSig),
 		Witness:   hexList(in.Witness),
+		Tapscript: in.Tapscript,
 	}
 	return json.Marshal(view)
 }
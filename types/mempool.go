@@ -0,0 +1,110 @@
+package types
+
+// TxStatus 交易确认状态
+type TxStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int    `json:"block_height,omitempty"`
+	BlockHash   string `json:"block_hash,omitempty"`
+	BlockTime   int64  `json:"block_time,omitempty"`
+}
+
+// TxDetail 带有确认状态与手续费的交易(索引服务返回)
+type TxDetail struct {
+	Tx       *Tx      `json:"tx"`
+	Status   TxStatus `json:"status"`
+	Fee      int64    `json:"fee"`      // sats
+	Size     int64    `json:"size"`     // 字节
+	Weight   int64    `json:"weight"`   // WU
+	Prevouts []*TxOut `json:"prevouts"` // 与输入一一对应的被花费输出, coinbase 输入为 nil
+}
+
+// TxOutspend 交易输出的花费情况
+type TxOutspend struct {
+	Spent  bool     `json:"spent"`
+	TxID   string   `json:"txid,omitempty"` // 花费该输出的交易
+	Vin    uint32   `json:"vin,omitempty"`  // 花费交易中的输入索引
+	Status TxStatus `json:"status"`
+}
+
+// TxMerkleProof 交易的 merkle 证明(Electrum 格式)
+type TxMerkleProof struct {
+	BlockHeight int      `json:"block_height"`
+	Merkle      []string `json:"merkle"` // 自底向上的兄弟节点哈希
+	Pos         int      `json:"pos"`    // 交易在区块内的位置
+}
+
+// CPFPTx CPFP 分析中的相关交易
+type CPFPTx struct {
+	TxID   string  `json:"txid"`
+	Fee    int64   `json:"fee"`
+	Weight int64   `json:"weight"`
+	Rate   float64 `json:"rate,omitempty"` // sat/vB
+}
+
+// TxCPFPInfo 未确认交易的 CPFP 分析
+type TxCPFPInfo struct {
+	Ancestors            []CPFPTx `json:"ancestors"`
+	Descendants          []CPFPTx `json:"descendants"`
+	BestDescendant       *CPFPTx  `json:"best_descendant,omitempty"`
+	EffectiveFeePerVsize float64  `json:"effective_fee_per_vsize"` // 打包时的有效费率(sat/vB)
+	AdjustedVsize        float64  `json:"adjusted_vsize"`
+}
+
+// MempoolBlockFees 内存池按费率预估的下一批区块
+type MempoolBlockFees struct {
+	BlockSize  int64     `json:"block_size"`
+	BlockVSize float64   `json:"block_vsize"`
+	NTx        int       `json:"n_tx"`
+	TotalFees  int64     `json:"total_fees"`
+	MedianFee  float64   `json:"median_fee"` // sat/vB
+	FeeRange   []float64 `json:"fee_range"`  // sat/vB, 从低到高
+}
+
+// BlockStatus 区块是否在主链上
+type BlockStatus struct {
+	InBestChain bool   `json:"in_best_chain"`
+	Height      int    `json:"height"`
+	NextBest    string `json:"next_best,omitempty"`
+}
+
+// RBFReplacement RBF 替换树: 当前交易及其替换掉的交易
+type RBFReplacement struct {
+	TxID     string            `json:"txid"`
+	Fee      int64             `json:"fee"`
+	Vsize    float64           `json:"vsize"`
+	Value    int64             `json:"value"`
+	Rate     float64           `json:"rate"`
+	RBF      bool              `json:"rbf"`      // 是否声明了 BIP125
+	FullRBF  bool              `json:"full_rbf"` // 替换链中是否存在未声明 BIP125 的交易
+	Mined    bool              `json:"mined"`
+	Time     int64             `json:"time"` // 替换发生时间(Unix 秒)
+	Replaces []*RBFReplacement `json:"replaces,omitempty"`
+}
+
+// TxRBFHistory 单个交易的 RBF 历史
+type TxRBFHistory struct {
+	Replacements *RBFReplacement `json:"replacements"` // 该交易所在替换树, 没有替换时为 nil
+	Replaces     []string        `json:"replaces"`     // 被该交易直接替换的 txid
+}
+
+// MempoolEventType 实时推送事件类型
+type MempoolEventType string
+
+const (
+	MempoolEventSeen          MempoolEventType = "seen"           // 交易进入内存池
+	MempoolEventConfirmed     MempoolEventType = "confirmed"      // 交易被打包
+	MempoolEventReplaced      MempoolEventType = "replaced"       // 交易被替换或移出内存池
+	MempoolEventBlock         MempoolEventType = "block"          // 新区块
+	MempoolEventMempoolBlocks MempoolEventType = "mempool_blocks" // 内存池预估区块更新
+)
+
+// MempoolEvent 实时推送事件, 按类型填充对应字段
+type MempoolEvent struct {
+	Type          MempoolEventType   `json:"type"`
+	Address       string             `json:"address,omitempty"`     // 订阅地址触发时的地址
+	TxID          string             `json:"txid,omitempty"`        // seen/confirmed/replaced
+	ReplacedBy    string             `json:"replaced_by,omitempty"` // replaced 且已知替换交易时
+	Tx            *TxDetail          `json:"tx,omitempty"`          // 推送中包含完整交易时
+	Block         *ChainBlock        `json:"block,omitempty"`       // block, 以及 confirmed 已知区块时
+	MempoolBlocks []MempoolBlockFees `json:"mempool_blocks,omitempty"`
+}
@@ -0,0 +1,126 @@
+package types
+
+// OrdinalsEnvelope 单个输入的 tapscript 中解析出的全部 ord 信封
+// 一个 tapscript 可以包含多个 OP_FALSE OP_IF "ord" ... OP_ENDIF 信封, 每个信封对应一个铭文.
+type OrdinalsEnvelope struct {
+	Inscriptions []Inscription `json:"inscriptions"` // 按脚本中出现顺序排列
+}
+
+// Inscription 单个铭文的解析结果
+// 字段定义参考 ord 的 envelope 规范: 偶数 tag 未识别时铭文不被 ord 承认(unbound/cursed), 奇数 tag 可忽略.
+type Inscription struct {
+	ID              string   `json:"id,omitempty"`               // 铭文ID: <reveal txid>i<index>, 需要知道交易ID才能生成
+	Input           int      `json:"input"`                      // 信封所在的输入索引
+	Index           int      `json:"index"`                      // 在整笔交易中的铭文序号(跨输入递增)
+	ContentType     string   `json:"content_type,omitempty"`     // tag 1: MIME 类型
+	ContentEncoding string   `json:"content_encoding,omitempty"` // tag 9: 内容编码, 例如 br / gzip
+	Body            []byte   `json:"body,omitempty"`             // tag 0 之后全部 push 拼接得到的内容
+	HasBody         bool     `json:"has_body"`                   // 是否存在 body 分隔符(空 body 与无 body 含义不同)
+	Pointer         *uint64  `json:"pointer,omitempty"`          // tag 2: 铭文落在输出中的 sat 偏移
+	Parents         []string `json:"parents,omitempty"`          // tag 3: 父铭文ID(可以有多个)
+	Delegate        string   `json:"delegate,omitempty"`         // tag 11: 委托铭文ID
+	Metaprotocol    string   `json:"metaprotocol,omitempty"`     // tag 7: 元协议标识
+	MetadataHex     string   `json:"metadata_hex,omitempty"`     // tag 5: 原始 CBOR 数据(多段 push 拼接)
+	Metadata        any      `json:"metadata,omitempty"`         // tag 5: CBOR 解码后的数据
+
+	// 解析过程中的异常标记
+	Pushnum               bool `json:"pushnum,omitempty"`                 // 使用了 OP_PUSHNUM_x 作为数据
+	Stutter               bool `json:"stutter,omitempty"`                 // 信封前存在重复的 OP_FALSE
+	DuplicateField        bool `json:"duplicate_field,omitempty"`         // 非分段字段重复出现
+	IncompleteField       bool `json:"incomplete_field,omitempty"`        // tag 没有对应的值
+	UnrecognizedEvenField bool `json:"unrecognized_even_field,omitempty"` // 存在未识别的偶数 tag
+}
+
+// InscriptionData 铭刻时单个铭文的内容
+type InscriptionData struct {
+	ContentType     string `json:"content_type"`               // MIME 类型, 例如 text/plain;charset=utf-8
+	ContentEncoding string `json:"content_encoding,omitempty"` // 可选, 内容编码
+	Body            []byte `json:"body"`                       // 铭文内容
+	Metaprotocol    string `json:"metaprotocol,omitempty"`     // 可选, 元协议
+	MetadataCBOR    []byte `json:"metadata_cbor,omitempty"`    // 可选, 已编码的 CBOR metadata
+	Delegate        string `json:"delegate,omitempty"`         // 可选, 委托铭文ID
+	Destination     string `json:"destination,omitempty"`      // 可选, 接收地址; 为空时使用 InscribeParams.Destination
+}
+
+// InscriptionParent 父铭文所在的 UTXO, reveal 交易会花费它并原样返还, 以此证明父子关系
+type InscriptionParent struct {
+	InscriptionID string `json:"inscription_id"` // 父铭文ID
+	TxID          string `json:"txid"`           // 父铭文所在 UTXO
+	Vout          uint32 `json:"vout"`           // 父铭文所在 UTXO
+	Value         int64  `json:"value"`          // UTXO 金额(sats)
+	Address       string `json:"address"`        // UTXO 所属地址, 父铭文返还到该地址
+}
+
+// InscribeParams 铭刻(commit/reveal)参数
+type InscribeParams struct {
+	FromAddress   string             `json:"from_address"`     // 支付 commit 交易的地址(仅支持 P2WPKH/P2TR, 保证签名前后 txid 不变)
+	ChangeAddress string             `json:"change_address"`   // 找零地址
+	Destination   string             `json:"destination"`      // 默认铭文接收地址
+	Inscriptions  []InscriptionData  `json:"inscriptions"`     // 铭文列表, 多个时为批量铭刻
+	Parent        *InscriptionParent `json:"parent,omitempty"` // 可选, 父铭文
+	PostageSats   int64              `json:"postage"`          // 每个铭文输出的金额(sats), 0 使用默认值
+	FeeRate       float64            `json:"fee_rate"`         // 费率(sat/vB), commit 与 reveal 共用
+}
+
+// InscribeResult 铭刻结果
+// 流程: 钱包签名 CommitPSBT 并广播 -> 广播 RevealTxHex(或签名 RevealPSBT 中的父铭文输入后广播).
+// reveal 失败时使用 RecoveryKeyWIF 通过 key path 取回 commit 输出.
+type InscribeResult struct {
+	CommitPSBT      string   `json:"commit_psbt"`             // 待钱包签名的 commit 交易
+	CommitTxID      string   `json:"commit_txid"`             // commit 交易ID(隔离见证输入, 签名前后不变)
+	CommitAddress   string   `json:"commit_address"`          // commit 输出的 taproot 地址
+	CommitValue     int64    `json:"commit_value"`            // commit 输出金额(sats)
+	CommitFee       int64    `json:"commit_fee"`              // commit 交易手续费(sats)
+	RevealTxHex     string   `json:"reveal_tx_hex,omitempty"` // 已签名的 reveal 交易(无父铭文时)
+	RevealPSBT      string   `json:"reveal_psbt,omitempty"`   // 需要钱包签名父铭文输入的 reveal 交易(有父铭文时)
+	RevealTxID      string   `json:"reveal_txid"`             // reveal 交易ID
+	RevealFee       int64    `json:"reveal_fee"`              // reveal 交易手续费(sats)
+	RevealVsize     int64    `json:"reveal_vsize"`            // reveal 交易 vsize
+	InscriptionIDs  []string `json:"inscription_ids"`         // 铭文ID列表
+	TapscriptHex    string   `json:"tapscript_hex"`           // reveal 脚本
+	ControlBlockHex string   `json:"control_block_hex"`       // reveal 控制块
+	RecoveryKeyWIF  string   `json:"recovery_key_wif"`        // 临时密钥, 请妥善保存直到 reveal 确认
+}
+
+// InscriptionRecoveryParams 取回 commit 输出的参数
+type InscriptionRecoveryParams struct {
+	CommitTxID     string  `json:"commit_txid"`      // commit 交易ID
+	CommitVout     uint32  `json:"commit_vout"`      // commit 输出索引
+	CommitValue    int64   `json:"commit_value"`     // commit 输出金额(sats)
+	TapscriptHex   string  `json:"tapscript_hex"`    // InscribeResult.TapscriptHex
+	RecoveryKeyWIF string  `json:"recovery_key_wif"` // InscribeResult.RecoveryKeyWIF
+	ToAddress      string  `json:"to_address"`       // 取回到的地址
+	FeeRate        float64 `json:"fee_rate"`         // 费率(sat/vB)
+}
+
+// SatRange 连续的 sat 区间 [Start, End)
+type SatRange struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// Size 区间包含的 sat 数量
+func (r SatRange) Size() uint64 {
+	return r.End - r.Start
+}
+
+// SatSplitParams 拆分 UTXO 的参数: 让目标 sat 落在独立输出的第 0 个位置
+type SatSplitParams struct {
+	FromAddress   string  `json:"from_address"`   // UTXO 所属地址, 同时支付手续费
+	TxID          string  `json:"txid"`           // 被拆分的 UTXO
+	Vout          uint32  `json:"vout"`           // 被拆分的 UTXO
+	Offset        uint64  `json:"offset"`         // 目标 sat 在 UTXO 内的偏移
+	Destination   string  `json:"destination"`    // 目标 sat 输出地址, 为空时使用 FromAddress
+	ChangeAddress string  `json:"change_address"` // 前后剩余 sat 的找零地址, 为空时使用 FromAddress
+	PostageSats   int64   `json:"postage"`        // 目标输出金额(sats), 0 时使用输出类型的粉尘限制
+	FeeRate       float64 `json:"fee_rate"`       // 费率(sat/vB)
+}
+
+// SatSplitResult 拆分交易构建结果
+type SatSplitResult struct {
+	PSBTBase64  string `json:"psbt_base64"`     // 待钱包签名的 PSBT
+	UnsignedTx  string `json:"unsigned_tx_hex"` // 调试/核对
+	TargetVout  uint32 `json:"target_vout"`     // 目标 sat 所在输出(偏移为 0)
+	Fee         int64  `json:"fee"`             // 手续费(sats)
+	PaddingUsed bool   `json:"padding_used"`    // 前缀不足粉尘限制时, 是否在前面加入了补位输入
+}
@@ -1,6 +1,121 @@
 package types
 
+import (
+	"fmt"
+	"math/big"
+)
+
 // Runestone 结构体：存储 Runestone 数据
+// 数值字段在协议中均为 u128, 使用 *big.Int 表示; 指针为 nil 表示该字段未出现.
 type Runestone struct {
-	BodyHex string `json:"body_hex"` // 十六进制编码的 payload
+	BodyHex  string       `json:"body_hex"`          // 十六进制编码的 payload(LEB128 整数序列)
+	Edicts   []RuneEdict  `json:"edicts,omitempty"`  // 转账指令
+	Etching  *RuneEtching `json:"etching,omitempty"` // 发行信息
+	Mint     *RuneID      `json:"mint,omitempty"`    // 铸造目标
+	Pointer  *uint32      `json:"pointer,omitempty"` // 未分配余额的默认输出
+	Cenotaph bool         `json:"cenotaph"`          // 是否为无效 runestone(cenotaph), 此时输入中的 rune 全部销毁
+	Flaw     string       `json:"flaw,omitempty"`    // cenotaph 原因(第一个检测到的问题), 取值见 RuneFlaw*
+}
+
+// RuneID 区块高度:交易索引
+type RuneID struct {
+	Block uint64 `json:"block"`
+	Tx    uint32 `json:"tx"`
+}
+
+func (id RuneID) String() string {
+	return fmt.Sprintf("%d:%d", id.Block, id.Tx)
+}
+
+// RuneEdict 转账指令: 把 Amount 个 ID 转到 Output; Amount 为 0 表示全部, Output 等于输出数量表示平分到所有非 OP_RETURN 输出
+type RuneEdict struct {
+	ID     RuneID   `json:"id"`
+	Amount *big.Int `json:"amount"`
+	Output uint32   `json:"output"`
+}
+
+// RuneEtching 发行信息
+// cenotaph 中只保留 Rune 字段(该名称被占用但不会发行).
+type RuneEtching struct {
+	Divisibility *uint8     `json:"divisibility,omitempty"` // 小数位, 最大 38
+	Premine      *big.Int   `json:"premine,omitempty"`      // 预挖数量
+	Rune         *string    `json:"rune,omitempty"`         // 名称(A-Z), 为空时由索引器分配保留名称
+	SpacedRune   string     `json:"spaced_rune,omitempty"`  // 带分隔符的展示名称(仅展示)
+	Spacers      *uint32    `json:"spacers,omitempty"`      // 分隔符位图: 第 i 位表示第 i 个字母之后有分隔符
+	Symbol       *string    `json:"symbol,omitempty"`       // 货币符号(单个字符)
+	Terms        *RuneTerms `json:"terms,omitempty"`        // 公开铸造条款
+	Turbo        bool       `json:"turbo,omitempty"`        // 是否接受未来协议升级
+}
+
+// RuneTerms 公开铸造条款
+type RuneTerms struct {
+	Amount      *big.Int `json:"amount,omitempty"`       // 每次铸造数量
+	Cap         *big.Int `json:"cap,omitempty"`          // 最多铸造次数
+	HeightStart *uint64  `json:"height_start,omitempty"` // 绝对高度窗口起点(含)
+	HeightEnd   *uint64  `json:"height_end,omitempty"`   // 绝对高度窗口终点(不含)
+	OffsetStart *uint64  `json:"offset_start,omitempty"` // 相对发行高度的窗口起点(含)
+	OffsetEnd   *uint64  `json:"offset_end,omitempty"`   // 相对发行高度的窗口终点(不含)
+}
+
+// cenotaph 原因, 与 ord 的 Flaw 命名一致
+const (
+	RuneFlawEdictOutput         = "edict_output"
+	RuneFlawEdictRuneID         = "edict_rune_id"
+	RuneFlawInvalidScript       = "invalid_script"
+	RuneFlawOpcode              = "opcode"
+	RuneFlawSupplyOverflow      = "supply_overflow"
+	RuneFlawTrailingIntegers    = "trailing_integers"
+	RuneFlawTruncatedField      = "truncated_field"
+	RuneFlawUnrecognizedEvenTag = "unrecognized_even_tag"
+	RuneFlawUnrecognizedFlag    = "unrecognized_flag"
+	RuneFlawVarint              = "varint"
+)
+
+// RuneInfo rune 的发行信息与铸造进度, 构建 rune 交易时使用
+type RuneInfo struct {
+	ID           string     `json:"id"`          // block:tx
+	SpacedRune   string     `json:"spaced_rune"` // 带分隔符的名称
+	Divisibility uint8      `json:"divisibility"`
+	Symbol       string     `json:"symbol,omitempty"`
+	Block        uint64     `json:"block"`           // 发行高度
+	Terms        *RuneTerms `json:"terms,omitempty"` // 公开铸造条款, 不可铸造时为 nil
+	Mints        int64      `json:"mints"`           // 已确认的铸造次数
+}
+
+// RuneRecipient rune 接收方
+type RuneRecipient struct {
+	Address string   `json:"address"`
+	Amount  *big.Int `json:"amount"` // 最小单位数量
+}
+
+// RuneTransferParams rune 转账参数
+type RuneTransferParams struct {
+	FromAddress       string          `json:"from_address"`        // 持有 rune 并支付手续费的地址
+	ChangeAddress     string          `json:"change_address"`      // BTC 找零地址, 为空时使用 FromAddress
+	RuneChangeAddress string          `json:"rune_change_address"` // rune 找零地址, 为空时使用 FromAddress
+	RuneID            string          `json:"rune_id"`             // block:tx
+	Recipients        []RuneRecipient `json:"recipients"`          // 接收方列表
+	PostageSats       int64           `json:"postage"`             // rune 输出金额(sats), 0 时使用输出类型的粉尘限制
+	FeeRate           float64         `json:"fee_rate"`            // 费率(sat/vB)
+}
+
+// RuneMintParams rune 公开铸造参数
+type RuneMintParams struct {
+	FromAddress   string  `json:"from_address"`   // 支付手续费的地址
+	ChangeAddress string  `json:"change_address"` // BTC 找零地址, 为空时使用 FromAddress
+	Destination   string  `json:"destination"`    // 铸造结果接收地址, 为空时使用 FromAddress
+	RuneID        string  `json:"rune_id"`        // block:tx
+	PostageSats   int64   `json:"postage"`        // rune 输出金额(sats), 0 时使用输出类型的粉尘限制
+	FeeRate       float64 `json:"fee_rate"`       // 费率(sat/vB)
+}
+
+// RuneTxResult rune 交易构建结果
+type RuneTxResult struct {
+	PSBTBase64   string   `json:"psbt_base64"`     // 待钱包签名的 PSBT
+	UnsignedTx   string   `json:"unsigned_tx_hex"` // 调试/核对
+	RunestoneHex string   `json:"runestone_hex"`   // OP_RETURN 输出脚本
+	Fee          int64    `json:"fee"`             // 手续费(sats)
+	Vsize        int64    `json:"vsize"`           // 估算 vsize
+	RuneInputs   []string `json:"rune_inputs"`     // 花费的 rune UTXO
+	MintAmount   *big.Int `json:"mint_amount,omitempty"`
 }
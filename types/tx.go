@@ -16,6 +16,7 @@ type TxWitness [][]byte
 // 序列化顺序（无见证）：Version | vinCount | vin[...] | voutCount | vout[...] | LockTime
 // 序列化顺序（有见证）：Version | 0x00 | 0x01 | vinCount | vin[...] | voutCount | vout[...] | witnesses(for each vin) | LockTime
 type Tx struct {
+	TxID     string  // 交易ID(非序列化辅助字段, 解码时填充)
 	Version  int32   // 交易版本
 	LockTime uint32  // 交易锁定时间
 	TxIn     []TxIn  // 交易输入
@@ -31,10 +32,11 @@ type Tx struct {
 // - Sequence：nSequence；影响 RBF（<0xffffffff-1）与 CSV；默认 0xffffffff
 // - Witness：隔离见证路径下的见证栈（P2WPKH/P2WSH/P2TR 等）
 type TxIn struct {
-	PreviousOutPoint TxOutPoint // 上一笔交易的输出点
-	Sequence         uint32     // 交易序列号
-	ScriptSig        []byte     // scriptSig
-	Witness          TxWitness  // 若任一输入 Witness 非空，序列化需写入 marker/flag，并在所有 TxOut 之后写入全部 Witness
+	PreviousOutPoint TxOutPoint     // 上一笔交易的输出点
+	Sequence         uint32         // 交易序列号
+	ScriptSig        []byte         // scriptSig
+	Witness          TxWitness      // 若任一输入 Witness 非空，序列化需写入 marker/flag，并在所有 TxOut 之后写入全部 Witness
+	Tapscript        *TapscriptInfo // 可选, P2TR 脚本路径花费时的解析结果
 }
 
 // TxOut：交易输出
@@ -74,6 +76,8 @@ type TxInputParams struct {
 	Data          string    `json:"data"`           // 可选 交付附加数据
 	PublicKey     string    `json:"public_key"`     // 公钥 => 从OKX获取, 后续要删除, 改用其他方式录入钱包
 	ChangeAddress string    `json:"change_address"` // 找零地址
+	// 可选 允许花费带有铭文/rune 的 UTXO(默认排除, 避免误烧资产)
+	AllowAssetUTXOs bool `json:"allow_asset_utxos"`
 }
 
 type TxUnsignedPSBT struct {
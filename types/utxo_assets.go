@@ -0,0 +1,20 @@
+package types
+
+// UTXOAssets UTXO 上携带的铭文与 rune, 这类 UTXO 默认不参与普通转账选币
+type UTXOAssets struct {
+	Outpoint     string        `json:"outpoint"`               // txid:vout
+	Inscriptions []string      `json:"inscriptions,omitempty"` // 铭文ID
+	Runes        []UTXORuneBal `json:"runes,omitempty"`        // rune 余额
+}
+
+// UTXORuneBal UTXO 上的单个 rune 余额
+type UTXORuneBal struct {
+	Rune   string `json:"rune"`              // 名称(带分隔符)或 rune ID
+	RuneID string `json:"rune_id,omitempty"` // block:tx, 来源无法提供时为空
+	Amount string `json:"amount"`            // 最小单位数量
+}
+
+// HasAssets 是否携带铭文或 rune
+func (a *UTXOAssets) HasAssets() bool {
+	return a != nil && (len(a.Inscriptions) > 0 || len(a.Runes) > 0)
+}
@@ -10,3 +10,93 @@ type WalletInfo struct {
 	XPRV       string `json:"xprv"`
 	BTCBalance int64  `json:"btc_balance,omitempty"`
 }
+
+// NodeWalletCreateParams bitcoind 创建钱包参数
+type NodeWalletCreateParams struct {
+	WatchOnly     bool   `json:"watch_only"`      // 只读钱包(禁用私钥), 用于导入 xpub 描述符
+	Blank         bool   `json:"blank"`           // 不生成默认描述符
+	Passphrase    string `json:"passphrase"`      // 可选 加密密码
+	AvoidReuse    bool   `json:"avoid_reuse"`     // 避免地址重用
+	LoadOnStartup bool   `json:"load_on_startup"` // 节点启动时自动加载
+}
+
+// NodeWalletDescriptor bitcoind 钱包描述符
+type NodeWalletDescriptor struct {
+	Desc      string `json:"desc"`       // 描述符, 导入时可以不带校验和
+	Timestamp int64  `json:"timestamp"`  // 创建时间(Unix 秒), 导入时从该时间开始扫描, 0 表示从创世区块, -1 表示 now
+	Active    bool   `json:"active"`     // 作为 getnewaddress 的地址来源
+	Internal  bool   `json:"internal"`   // 找零描述符
+	RangeEnd  int    `json:"range_end"`  // 带派生范围时的最大序号(含), 导入时为 0 使用节点默认值
+	NextIndex int    `json:"next_index"` // 下一个派生序号
+	Label     string `json:"label"`      // 标签, 仅非 active 描述符
+}
+
+// NodeWalletImportResult 导入描述符结果, 与请求一一对应
+type NodeWalletImportResult struct {
+	Success  bool     `json:"success"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// NodeWalletTx bitcoind 钱包交易记录, 同一交易的每个相关输出各一条
+type NodeWalletTx struct {
+	TxID          string `json:"txid"`
+	Vout          uint32 `json:"vout"`
+	Address       string `json:"address"`
+	Category      string `json:"category"`        // send/receive/generate/immature/orphan
+	Amount        int64  `json:"amount"`          // sats, 转出为负
+	Fee           int64  `json:"fee"`             // sats, 仅 send
+	Label         string `json:"label,omitempty"` // 标签
+	Confirmations int64  `json:"confirmations"`   // 确认数, 冲突交易为负
+	BlockHash     string `json:"block_hash,omitempty"`
+	BlockHeight   int64  `json:"block_height,omitempty"`
+	BlockTime     int64  `json:"block_time,omitempty"`
+	Time          int64  `json:"time"`                  // 钱包收到时间
+	Replaceable   bool   `json:"replaceable"`           // BIP125 可替换
+	Abandoned     bool   `json:"abandoned"`             // 已放弃
+	ReplacedBy    string `json:"replaced_by,omitempty"` // 已被替换时的新交易
+}
+
+// NodeWalletOutput 钱包发送的输出, Data 不为空时为 OP_RETURN 输出
+type NodeWalletOutput struct {
+	Address string `json:"address,omitempty"`
+	Value   int64  `json:"value,omitempty"` // sats
+	Data    string `json:"data,omitempty"`  // hex
+}
+
+// NodeWalletFundParams bitcoind 钱包选币参数, 用于创建 PSBT 与发送
+type NodeWalletFundParams struct {
+	Outputs                []NodeWalletOutput `json:"outputs"`
+	Inputs                 []string           `json:"inputs,omitempty"`         // 可选 指定输入(txid:vout)
+	OnlyInputs             bool               `json:"only_inputs"`              // 只使用指定输入, 不自动添加
+	FeeRate                float64            `json:"fee_rate"`                 // sat/vB, 0 使用节点估算
+	ChangeAddress          string             `json:"change_address,omitempty"` // 可选 找零地址
+	SubtractFeeFromOutputs []int              `json:"subtract_fee_from_outputs,omitempty"`
+	Replaceable            bool               `json:"replaceable"`   // BIP125 可替换
+	LockUnspents           bool               `json:"lock_unspents"` // 锁定选中的输出, 避免并发选中
+	Locktime               int64              `json:"locktime"`
+}
+
+// NodeWalletPsbt bitcoind 钱包创建/处理 PSBT 的结果
+type NodeWalletPsbt struct {
+	PSBTBase64 string `json:"psbt_base64"`
+	Fee        int64  `json:"fee,omitempty"` // sats, 仅创建时
+	ChangePos  int    `json:"change_pos"`    // 找零输出位置, 没有找零为 -1, 仅创建时
+	Complete   bool   `json:"complete"`      // 是否已完成签名
+	Hex        string `json:"hex,omitempty"` // 完成签名时的交易
+}
+
+// NodeWalletBumpFee 提高手续费结果; 钱包可签名时直接广播并返回 TxID, 否则返回待签名 PSBT
+type NodeWalletBumpFee struct {
+	TxID       string `json:"txid,omitempty"`
+	PSBTBase64 string `json:"psbt_base64,omitempty"`
+	OrigFee    int64  `json:"orig_fee"` // sats
+	Fee        int64  `json:"fee"`      // sats
+}
+
+// NodeWalletSendResult 钱包发送结果; 签名完成时已广播, 否则返回待签名 PSBT
+type NodeWalletSendResult struct {
+	Complete   bool   `json:"complete"`
+	TxID       string `json:"txid,omitempty"`
+	PSBTBase64 string `json:"psbt_base64,omitempty"`
+}